@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security"
 	"github.com/edgexfoundry/app-functions-sdk-go/pkg/util"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/command"
@@ -30,7 +31,8 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/notifications"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
-	"github.com/google/uuid"
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
 )
 
 // AppFunction is a type alias for func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{})
@@ -46,6 +48,9 @@ type Context struct {
 	CorrelationID string
 	// OutputData is used for specifying the data that is to be outputted. Leverage the .Complete() function to set.
 	OutputData []byte
+	// ResponseContentType is the content type of the OutputData, i.e. application/cbor when set by the CBOR encoder.
+	// The triggers send the OutputData as JSON when it isn't set.
+	ResponseContentType string
 	// This holds the configuration for your service. This is the preferred way to access your custom application settings that have been set in the configuration.
 	Configuration common.ConfigurationStruct
 	// LoggingClient is exposed to allow logging following the preferred logging strategy within EdgeX.
@@ -60,6 +65,20 @@ type Context struct {
 	NotificationsClient notifications.NotificationsClient
 	// RetryData holds the data to be stored for later retry when the pipeline function returns an error
 	RetryData []byte
+	// SecretStoreClient is the SDK's secret store client, which is nil when the secret store is not configured
+	SecretStoreClient security.SecretStoreClient
+	// MessageClient is the message bus client used by the MessageBus trigger, which is nil for other triggers
+	MessageClient messaging.MessageClient
+	// RequestContext is the context of the HTTP request which triggered the pipeline, which is cancelled when the
+	// client disconnects. Context aware functions, such as the HTTP exporters, use it to abandon their requests.
+	// It is nil for other triggers.
+	RequestContext syscontext.Context
+	// TraceParent is the W3C traceparent of the received message, used as the parent of the message's trace when
+	// distributed tracing is enabled. It is only set by the HTTP trigger.
+	TraceParent string
+	// RequestTimeout is the timeout of the calls made to the EdgeX services by MarkAsPushed and PushToCoreData,
+	// none when zero. It is set by the triggers from the SDK's request timeout.
+	RequestTimeout time.Duration
 }
 
 // Complete is optional and provides a way to return the specified data.
@@ -77,10 +96,15 @@ func (context *Context) MarkAsPushed() error {
 		return fmt.Errorf("unable to Mark As Pushed: '%s' is missing from Clients configuration", common.CoreDataClientName)
 	}
 
+	ctx := syscontext.WithValue(syscontext.Background(), clients.CorrelationHeader, context.CorrelationID)
 	if context.EventID != "" {
-		return context.EventClient.MarkPushed(context.EventID, syscontext.WithValue(syscontext.Background(), clients.CorrelationHeader, context.CorrelationID))
+		return common.CallWithTimeout(ctx, context.RequestTimeout, func(ctx syscontext.Context) error {
+			return context.EventClient.MarkPushed(context.EventID, ctx)
+		})
 	} else if context.EventChecksum != "" {
-		return context.EventClient.MarkPushedByChecksum(context.EventChecksum, syscontext.WithValue(syscontext.Background(), clients.CorrelationHeader, context.CorrelationID))
+		return common.CallWithTimeout(ctx, context.RequestTimeout, func(ctx syscontext.Context) error {
+			return context.EventClient.MarkPushedByChecksum(context.EventChecksum, ctx)
+		})
 	} else {
 		return errors.New("No EventID or EventChecksum Provided")
 	}
@@ -92,6 +116,43 @@ func (context *Context) SetRetryData(payload []byte) {
 	context.RetryData = payload
 }
 
+// GetSecret returns the value of the specified key from the secrets at the specified path in the secret store.
+func (context *Context) GetSecret(path string, key string) (string, error) {
+	if context.SecretStoreClient == nil {
+		return "", errors.New("unable to get secret: SecretStore is missing from configuration")
+	}
+
+	return context.SecretStoreClient.GetSecret(path, key)
+}
+
+// PublishToTopic publishes the provided data directly to the specified topic on the configured message bus.
+// The data must be of type []byte, string or implement json.Marshaler. This allows the pipeline to send the same
+// data to multiple topics. It requires the MessageBus trigger to be configured.
+func (context *Context) PublishToTopic(topic string, data interface{}) error {
+	if context.MessageClient == nil {
+		return errors.New("unable to publish to topic: MessageBus trigger is not configured")
+	}
+
+	payload, err := util.CoerceType(data)
+	if err != nil {
+		return err
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: context.CorrelationID,
+		Payload:       payload,
+		ContentType:   clients.ContentTypeJSON,
+	}
+
+	err = context.MessageClient.Publish(envelope, topic)
+	if err != nil {
+		return fmt.Errorf("unable to publish to topic '%s': %v", topic, err)
+	}
+
+	context.LoggingClient.Trace("Published message to bus", "topic", topic, clients.CorrelationHeader, context.CorrelationID)
+	return nil
+}
+
 // PushToCoreData pushes the provided value as an event to CoreData using the device name and reading name that have been set. If validation is turned on in
 // CoreServices then your deviceName and readingName must exist in the CoreMetadata and be properly registered in EdgeX.
 func (context *Context) PushToCoreData(deviceName string, readingName string, value interface{}) (*models.Event, error) {
@@ -117,12 +178,14 @@ func (context *Context) PushToCoreData(deviceName string, readingName string, va
 		Readings: readings,
 	}
 
-	correlation := uuid.New().String()
+	correlation := common.NewCorrelationID()
 	ctx := syscontext.WithValue(syscontext.Background(), clients.CorrelationHeader, correlation)
-	result, err := context.EventClient.Add(newEdgeXEvent, ctx)
+	result, err := common.CallWithTimeoutResult(ctx, context.RequestTimeout, func(ctx syscontext.Context) (interface{}, error) {
+		return context.EventClient.Add(newEdgeXEvent, ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
-	newEdgeXEvent.ID = result
+	newEdgeXEvent.ID = result.(string)
 	return newEdgeXEvent, nil
 }
@@ -21,12 +21,14 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/mocks"
 	"github.com/edgexfoundry/app-functions-sdk-go/pkg/startup"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	messagingTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -179,6 +181,64 @@ func TestMarkAsPushedEventId(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGetSecretNoSecretStore(t *testing.T) {
+	ctx := Context{
+		LoggingClient: lc,
+	}
+	_, err := ctx.GetSecret("mqtt", "username")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "SecretStore is missing from configuration")
+}
+
+func TestGetSecret(t *testing.T) {
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecret", "mqtt", "username").Return("edgex", nil)
+
+	ctx := Context{
+		LoggingClient:     lc,
+		SecretStoreClient: secretStore,
+	}
+	value, err := ctx.GetSecret("mqtt", "username")
+	assert.NoError(t, err)
+	assert.Equal(t, "edgex", value)
+	secretStore.AssertExpectations(t)
+}
+
+func TestPublishToTopicNoMessageClient(t *testing.T) {
+	ctx := Context{
+		LoggingClient: lc,
+	}
+	err := ctx.PublishToTopic("topic", "data")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MessageBus trigger is not configured")
+}
+
+func TestPublishToTopic(t *testing.T) {
+	client := &mockMessageClient{}
+	ctx := Context{
+		CorrelationID: "123",
+		LoggingClient: lc,
+		MessageClient: client,
+	}
+
+	err := ctx.PublishToTopic("topic1", "data")
+	assert.NoError(t, err)
+	err = ctx.PublishToTopic("topic2", []byte("data"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"topic1", "topic2"}, client.topics)
+	assert.Equal(t, []byte("data"), client.published[1].Payload)
+	assert.Equal(t, "123", client.published[1].CorrelationID)
+	assert.Equal(t, clients.ContentTypeJSON, client.published[1].ContentType)
+
+	err = ctx.PublishToTopic("topic3", 1)
+	assert.Error(t, err, "expected error for unsupported data type")
+
+	client.publishError = fmt.Errorf("failed")
+	err = ctx.PublishToTopic("topic4", "data")
+	assert.Error(t, err, "expected error from message client")
+}
+
 func TestSetRetryData(t *testing.T) {
 	ctx := Context{}
 	testData := "output data"
@@ -203,3 +263,30 @@ func (e mockEventEndpoint) Monitor(params types.EndpointParams, ch chan string)
 func (e mockEventEndpoint) Fetch(params types.EndpointParams) string {
 	return fmt.Sprintf("http://%s:%v%s", "localhost", 48080, params.Path)
 }
+
+type mockMessageClient struct {
+	published    []messagingTypes.MessageEnvelope
+	topics       []string
+	publishError error
+}
+
+func (m *mockMessageClient) Connect() error {
+	return nil
+}
+
+func (m *mockMessageClient) Publish(message messagingTypes.MessageEnvelope, topic string) error {
+	if m.publishError != nil {
+		return m.publishError
+	}
+	m.published = append(m.published, message)
+	m.topics = append(m.topics, topic)
+	return nil
+}
+
+func (m *mockMessageClient) Subscribe(topics []messagingTypes.TopicChannel, messageErrors chan error) error {
+	return nil
+}
+
+func (m *mockMessageClient) Disconnect() error {
+	return nil
+}
@@ -0,0 +1,216 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"bytes"
+	syscontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	coreTypes "github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// CommandTokenSecretKey is the key of the Bearer token in the secrets at the Command client's SecretPath
+const CommandTokenSecretKey = "token"
+
+// CommandDevice issues the command with the specified name to the device via the Core Command service. A PUT command
+// is issued with the params as its JSON body, unless isGET is true, in which case a GET command is issued with the
+// params as its query parameters. When the Command client's SecretPath is set, the Bearer token stored in the
+// secret store at that path is used to authenticate. Requires the Command client to be configured.
+func (sdk *AppFunctionsSDK) CommandDevice(deviceName string, commandName string, params map[string]string, isGET bool) error {
+	_, err := sdk.sendCommand(deviceName, commandName, params, isGET)
+	return err
+}
+
+// GetCommandResponse issues the GET command with the specified name to the device via the Core Command service and
+// returns the values of the readings in the response by reading name. This allows pipeline functions to correlate
+// the received data with the device's current state. The response is cached for the configured CommandCacheTTL,
+// and each call returns its own copy of the readings.
+// Requires the Command client to be configured.
+func (sdk *AppFunctionsSDK) GetCommandResponse(deviceName string, commandName string) (map[string]interface{}, error) {
+	cacheKey := deviceName + "/" + commandName
+	if sdk.commandCache != nil {
+		if readings, ok := sdk.commandCache.get(cacheKey); ok {
+			return copyCommandReadings(readings), nil
+		}
+	}
+
+	body, err := sdk.sendCommand(deviceName, commandName, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	event := models.Event{}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal response of command '%s' for device '%s': %s", commandName, deviceName, err.Error())
+	}
+
+	readings := make(map[string]interface{}, len(event.Readings))
+	for _, reading := range event.Readings {
+		if len(reading.BinaryValue) > 0 {
+			readings[reading.Name] = reading.BinaryValue
+		} else {
+			readings[reading.Name] = reading.Value
+		}
+	}
+
+	if sdk.commandCache != nil {
+		sdk.commandCache.store(cacheKey, copyCommandReadings(readings))
+	}
+
+	return readings, nil
+}
+
+// copyCommandReadings copies the readings of a command response, along with their binary values, so that the
+// callers can't modify the cached response.
+func copyCommandReadings(readings map[string]interface{}) map[string]interface{} {
+	readingsCopy := make(map[string]interface{}, len(readings))
+	for name, value := range readings {
+		if binaryValue, ok := value.([]byte); ok {
+			value = append([]byte(nil), binaryValue...)
+		}
+		readingsCopy[name] = value
+	}
+
+	return readingsCopy
+}
+
+// sendCommand issues the command to Core Command and returns the body of the response
+func (sdk *AppFunctionsSDK) sendCommand(deviceName string, commandName string, params map[string]string, isGET bool) ([]byte, error) {
+	clientInfo, ok := sdk.config.Clients[common.CoreCommandClientName]
+	if !ok {
+		return nil, errors.New("Command client is missing from configuration")
+	}
+
+	commandURL := clientInfo.Url() + clients.ApiDeviceRoute + "/name/" + url.PathEscape(deviceName) +
+		"/command/" + url.PathEscape(commandName)
+
+	var request *nethttp.Request
+	var err error
+	if isGET {
+		if len(params) > 0 {
+			query := url.Values{}
+			for name, value := range params {
+				query.Set(name, value)
+			}
+			commandURL += "?" + query.Encode()
+		}
+		request, err = nethttp.NewRequest(nethttp.MethodGet, commandURL, nil)
+	} else {
+		var body []byte
+		body, err = json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		request, err = nethttp.NewRequest(nethttp.MethodPut, commandURL, bytes.NewReader(body))
+		if err == nil {
+			request.Header.Set(clients.ContentType, clients.ContentTypeJSON)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if clientInfo.SecretPath != "" {
+		if sdk.secretStoreClient == nil {
+			return nil, errors.New("Command client's SecretPath is set, but SecretStore is missing from configuration")
+		}
+		token, err := sdk.secretStoreClient.GetSecret(clientInfo.SecretPath, CommandTokenSecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Command client token: %s", err.Error())
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if timeout := sdk.getRequestTimeout(); timeout > 0 {
+		ctx, cancel := syscontext.WithTimeout(syscontext.Background(), timeout)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+
+	client := nethttp.Client{Timeout: time.Duration(sdk.config.Service.Timeout) * time.Millisecond}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, coreTypes.NewErrServiceClient(response.StatusCode, body)
+	}
+
+	sdk.LoggingClient.Debug(fmt.Sprintf("Command '%s' issued to device '%s'", commandName, deviceName))
+	return body, nil
+}
+
+// commandCache caches the readings of GET command responses by device and command name
+type commandCache struct {
+	ttl       time.Duration
+	responses sync.Map
+}
+
+type commandCacheEntry struct {
+	readings map[string]interface{}
+	expires  time.Time
+}
+
+func (cache *commandCache) get(key string) (map[string]interface{}, bool) {
+	entry, ok := cache.responses.Load(key)
+	if !ok {
+		return nil, false
+	}
+	cached := entry.(commandCacheEntry)
+	if time.Now().After(cached.expires) {
+		cache.responses.Delete(key)
+		return nil, false
+	}
+	return cached.readings, true
+}
+
+func (cache *commandCache) store(key string, readings map[string]interface{}) {
+	cache.responses.Store(key, commandCacheEntry{readings: readings, expires: time.Now().Add(cache.ttl)})
+}
+
+// initializeCommandCache creates the command response cache when CommandCacheTTL is set
+func (sdk *AppFunctionsSDK) initializeCommandCache() {
+	if sdk.config.Service.CommandCacheTTL == "" {
+		return
+	}
+
+	ttl, err := time.ParseDuration(sdk.config.Service.CommandCacheTTL)
+	if err != nil {
+		sdk.LoggingClient.Error(fmt.Sprintf("Invalid CommandCacheTTL '%s', command responses will not be cached: %s",
+			sdk.config.Service.CommandCacheTTL, err.Error()))
+		return
+	}
+
+	sdk.commandCache = &commandCache{ttl: ttl}
+}
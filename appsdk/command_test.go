@@ -0,0 +1,234 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCommandTestSDK(t *testing.T, serverURL string, secretPath string) *AppFunctionsSDK {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(parsed.Port())
+
+	return &AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Clients: map[string]common.ClientInfo{
+				common.CoreCommandClientName: {
+					Protocol:   parsed.Scheme,
+					Host:       parsed.Hostname(),
+					Port:       port,
+					SecretPath: secretPath,
+				},
+			},
+		},
+	}
+}
+
+func TestCommandDevice(t *testing.T) {
+	var method, path, query, authorization string
+	var body map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		query = r.URL.RawQuery
+		authorization = r.Header.Get("Authorization")
+		body = nil
+		data, _ := ioutil.ReadAll(r.Body)
+		if len(data) > 0 {
+			json.Unmarshal(data, &body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	sdk := newCommandTestSDK(t, ts.URL, "")
+
+	err := sdk.CommandDevice("thermostat1", "setpoint", map[string]string{"temperature": "21"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/api/v1/device/name/thermostat1/command/setpoint", path)
+	assert.Equal(t, "21", body["temperature"])
+	assert.Equal(t, "", authorization)
+
+	err = sdk.CommandDevice("thermostat1", "status", map[string]string{"ds-pushevent": "no"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "/api/v1/device/name/thermostat1/command/status", path)
+	assert.Equal(t, "ds-pushevent=no", query)
+}
+
+func TestCommandDeviceAuth(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	sdk := newCommandTestSDK(t, ts.URL, "command")
+	err := sdk.CommandDevice("thermostat1", "setpoint", nil, false)
+	assert.Error(t, err, "expected error when secret store is not configured")
+
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecret", "command", CommandTokenSecretKey).Return("my-token", nil)
+	sdk.secretStoreClient = secretStore
+
+	err = sdk.CommandDevice("thermostat1", "setpoint", nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer my-token", authorization)
+
+	failingStore := &mocks.SecretStoreClient{}
+	failingStore.On("GetSecret", "command", CommandTokenSecretKey).Return("", errors.New("secret not found"))
+	sdk.secretStoreClient = failingStore
+	err = sdk.CommandDevice("thermostat1", "setpoint", nil, false)
+	assert.Error(t, err, "expected error when token can't be retrieved")
+}
+
+func TestCommandDeviceErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	sdk := newCommandTestSDK(t, ts.URL, "")
+	err := sdk.CommandDevice("bogus", "setpoint", nil, false)
+	assert.Error(t, err, "expected error for non-2xx response")
+
+	sdk = &AppFunctionsSDK{LoggingClient: lc}
+	err = sdk.CommandDevice("thermostat1", "setpoint", nil, false)
+	assert.Error(t, err, "expected error when Command client is not configured")
+}
+
+func TestCommandDeviceTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	sdk := newCommandTestSDK(t, ts.URL, "")
+	sdk.SetRequestTimeout(10 * time.Millisecond)
+	err := sdk.CommandDevice("thermostat1", "setpoint", nil, false)
+	if !assert.Error(t, err, "expected error when the request times out") {
+		t.Fatal()
+	}
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+}
+
+func TestGetRequestTimeout(t *testing.T) {
+	sdk := AppFunctionsSDK{}
+	assert.Equal(t, 10*time.Second, sdk.getRequestTimeout())
+
+	sdk.SetRequestTimeout(0)
+	assert.Equal(t, time.Duration(0), sdk.getRequestTimeout(), "expected no timeout")
+
+	sdk.SetRequestTimeout(time.Minute)
+	assert.Equal(t, time.Minute, sdk.getRequestTimeout())
+}
+
+func TestGetCommandResponse(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected method %s, expected %s", r.Method, http.MethodGet)
+		}
+		w.Write([]byte(`{"device":"thermostat1","readings":[{"name":"temperature","value":"21.5"},{"name":"mode","value":"heat"}]}`))
+	}))
+	defer ts.Close()
+
+	sdk := newCommandTestSDK(t, ts.URL, "")
+
+	readings, err := sdk.GetCommandResponse("thermostat1", "status")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "21.5", readings["temperature"])
+	assert.Equal(t, "heat", readings["mode"])
+
+	// Not cached when CommandCacheTTL isn't set
+	_, err = sdk.GetCommandResponse("thermostat1", "status")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	sdk.config.Service.CommandCacheTTL = "1m"
+	sdk.initializeCommandCache()
+	readings, err = sdk.GetCommandResponse("thermostat1", "status")
+	assert.NoError(t, err)
+	readings["mode"] = "cool"
+	readings, err = sdk.GetCommandResponse("thermostat1", "status")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "heat", readings["mode"], "changes made by a caller must not affect the cached response")
+	delete(readings, "mode")
+	readings, _ = sdk.GetCommandResponse("thermostat1", "status")
+	assert.Len(t, readings, 2)
+
+	_, err = sdk.GetCommandResponse("thermostat1", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestGetCommandResponseErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/device/name/thermostat1/command/bad" {
+			w.Write([]byte(`not json`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	sdk := newCommandTestSDK(t, ts.URL, "")
+	_, err := sdk.GetCommandResponse("bogus", "status")
+	assert.Error(t, err, "expected error for non-2xx response")
+
+	_, err = sdk.GetCommandResponse("thermostat1", "bad")
+	assert.Error(t, err, "expected error for invalid response")
+}
+
+func TestInitializeCommandCacheInvalidTTL(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Service: common.ServiceInfo{CommandCacheTTL: "bogus"},
+		},
+	}
+	sdk.initializeCommandCache()
+	assert.Nil(t, sdk.commandCache)
+}
@@ -30,6 +30,7 @@ import (
 const (
 	ValueDescriptors = "valuedescriptors"
 	DeviceNames      = "devicenames"
+	Devices          = "devices"
 	Key              = "key"
 	InitVector       = "initvector"
 	Url              = "url"
@@ -56,9 +57,13 @@ type AppFunctionsSDKConfigurable struct {
 // This function will return an error and stop the pipeline if a non-edgex
 // event is received or if no data is recieved.
 // For example, data generated by a motor does not get passed to functions only interested in data from a thermostat.
+// The devices are specified by the DeviceNames parameter, or by Devices.
 // This function is a configuration function and returns a function pointer.
 func (dynamic AppFunctionsSDKConfigurable) FilterByDeviceName(parameters map[string]string) appcontext.AppFunction {
 	deviceNames, ok := parameters[DeviceNames]
+	if !ok {
+		deviceNames, ok = parameters[Devices]
+	}
 	if !ok {
 		dynamic.Sdk.LoggingClient.Error("Could not find " + DeviceNames)
 		return nil
@@ -112,6 +117,28 @@ func (dynamic AppFunctionsSDKConfigurable) TransformToJSON() appcontext.AppFunct
 	return transform.TransformToJSON
 }
 
+// TransformToCBOR encodes the data, i.e. an EdgeX event, as CBOR.
+// It will return an error and stop the pipeline if no data is received.
+// This function is a configuration function and returns a function pointer.
+func (dynamic AppFunctionsSDKConfigurable) TransformToCBOR() appcontext.AppFunction {
+	return transforms.NewCBOREncoder().TransformToCBOR
+}
+
+// TransformToMessagePack encodes the data, i.e. an EdgeX event, as MessagePack.
+// It will return an error and stop the pipeline if no data is received.
+// This function is a configuration function and returns a function pointer.
+func (dynamic AppFunctionsSDKConfigurable) TransformToMessagePack() appcontext.AppFunction {
+	return transforms.NewMessagePackEncoder().Encode
+}
+
+// TransformToProtobuf encodes an EdgeX event as the EdgeXEvent protobuf message.
+// It will return an error and stop the pipeline if a non-edgex
+// event is received or if no data is recieved.
+// This function is a configuration function and returns a function pointer.
+func (dynamic AppFunctionsSDKConfigurable) TransformToProtobuf() appcontext.AppFunction {
+	return transforms.NewProtobufEncoder().TransformToProtobuf
+}
+
 // MarkAsPushed will make a request to CoreData to mark the event that triggered the pipeline as pushed.
 // This function is a configuration function and returns a function pointer.
 func (dynamic AppFunctionsSDKConfigurable) MarkAsPushed() appcontext.AppFunction {
@@ -98,6 +98,27 @@ func TestConfigurableTransformToJSON(t *testing.T) {
 	assert.NotNil(t, trx, "return result from TransformToJSON should not be nil")
 }
 
+func TestConfigurableTransformToCBOR(t *testing.T) {
+	configurable := AppFunctionsSDKConfigurable{}
+
+	trx := configurable.TransformToCBOR()
+	assert.NotNil(t, trx, "return result from TransformToCBOR should not be nil")
+}
+
+func TestConfigurableTransformToMessagePack(t *testing.T) {
+	configurable := AppFunctionsSDKConfigurable{}
+
+	trx := configurable.TransformToMessagePack()
+	assert.NotNil(t, trx, "return result from TransformToMessagePack should not be nil")
+}
+
+func TestConfigurableTransformToProtobuf(t *testing.T) {
+	configurable := AppFunctionsSDKConfigurable{}
+
+	trx := configurable.TransformToProtobuf()
+	assert.NotNil(t, trx, "return result from TransformToProtobuf should not be nil")
+}
+
 func TestConfigurableHTTPPost(t *testing.T) {
 	configurable := AppFunctionsSDKConfigurable{
 		Sdk: &AppFunctionsSDK{
@@ -0,0 +1,113 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+// AppConfig is the configuration of the application service
+type AppConfig = common.ConfigurationStruct
+
+// configWatch holds the handlers of the single watch on the configuration in the Registry
+type configWatch struct {
+	mutex    sync.Mutex
+	handlers []func(newConfig *AppConfig)
+	last     AppConfig
+}
+
+// WatchConfiguration calls onChange with the new configuration whenever the service's configuration changes in
+// the Registry, which the Registry client watches with blocking queries. A change which fails the validation of
+// ValidateConfiguration's required settings is logged and skipped. Every call adds a handler to the one watch on
+// the configuration. Requires the Registry to be used.
+func (sdk *AppFunctionsSDK) WatchConfiguration(onChange func(newConfig *AppConfig)) error {
+	if !sdk.useRegistry || sdk.registryClient == nil {
+		return errors.New("Registry is not enabled")
+	}
+	if onChange == nil {
+		return errors.New("Configuration change handler must not be nil")
+	}
+
+	sdk.configWatch.mutex.Lock()
+	defer sdk.configWatch.mutex.Unlock()
+
+	sdk.configWatch.handlers = append(sdk.configWatch.handlers, onChange)
+	if len(sdk.configWatch.handlers) == 1 {
+		sdk.configWatch.last = sdk.config
+		go sdk.watchConfiguration()
+	}
+
+	return nil
+}
+
+// watchConfiguration receives the configuration from the Registry and calls the handlers for every valid change
+func (sdk *AppFunctionsSDK) watchConfiguration() {
+	updates := make(chan interface{})
+	registryErrors := make(chan error)
+
+	sdk.LoggingClient.Info("Watching for configuration changes from registry")
+	sdk.registryClient.WatchForChanges(updates, registryErrors, &common.ConfigurationStruct{}, "")
+
+	for {
+		select {
+		case err := <-registryErrors:
+			sdk.LoggingClient.Error(err.Error())
+
+		case raw, ok := <-updates:
+			if !ok {
+				sdk.LoggingClient.Error("Failed to receive configuration changes from update channel")
+				return
+			}
+
+			newConfig, ok := raw.(*common.ConfigurationStruct)
+			if !ok {
+				sdk.LoggingClient.Error("watchConfiguration() type check failed")
+				return
+			}
+
+			sdk.notifyConfigurationChange(newConfig)
+		}
+	}
+}
+
+// notifyConfigurationChange calls the handlers with the new configuration unless it is unchanged or invalid
+func (sdk *AppFunctionsSDK) notifyConfigurationChange(newConfig *AppConfig) {
+	sdk.configWatch.mutex.Lock()
+	if reflect.DeepEqual(sdk.configWatch.last, *newConfig) {
+		sdk.configWatch.mutex.Unlock()
+		return
+	}
+	if errs := sdk.validateSettings(*newConfig); len(errs) > 0 {
+		sdk.configWatch.mutex.Unlock()
+		sdk.LoggingClient.Error("Skipping invalid configuration change from registry: " + ConfigurationErrors(errs).Error())
+		return
+	}
+	sdk.configWatch.last = *newConfig
+	// the handlers are called without holding the lock, so they can add handlers
+	handlers := make([]func(newConfig *AppConfig), len(sdk.configWatch.handlers))
+	copy(handlers, sdk.configWatch.handlers)
+	sdk.configWatch.mutex.Unlock()
+
+	sdk.LoggingClient.Info("Configuration has been changed in registry")
+	for _, handler := range handlers {
+		handler(newConfig)
+	}
+}
@@ -0,0 +1,87 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/go-mod-registry/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// watchingRegistry is a registry.Client which records the channels of WatchForChanges
+type watchingRegistry struct {
+	registry.Client
+	watches chan chan<- interface{}
+}
+
+func (r *watchingRegistry) WatchForChanges(updateChannel chan<- interface{}, errorChannel chan<- error, configuration interface{}, watchKey string) {
+	r.watches <- updateChannel
+}
+
+func TestWatchConfigurationNoRegistry(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	err := sdk.WatchConfiguration(func(newConfig *AppConfig) {})
+	assert.EqualError(t, err, "Registry is not enabled")
+}
+
+func TestWatchConfiguration(t *testing.T) {
+	watches := make(chan chan<- interface{}, 2)
+	sdk := newValidSDK()
+	sdk.useRegistry = true
+	sdk.registryClient = &watchingRegistry{watches: watches}
+	sdk.config.Registry = common.RegistryInfo{Type: "consul", Host: "localhost", Port: 8500}
+
+	changes1 := make(chan *AppConfig, 2)
+	changes2 := make(chan *AppConfig, 2)
+	assert.NoError(t, sdk.WatchConfiguration(func(newConfig *AppConfig) { changes1 <- newConfig }))
+	assert.NoError(t, sdk.WatchConfiguration(func(newConfig *AppConfig) { changes2 <- newConfig }))
+
+	var updates chan<- interface{}
+	select {
+	case updates = <-watches:
+	case <-time.After(time.Second):
+		t.Fatal("expected the configuration to be watched")
+	}
+
+	// the current configuration is not a change
+	unchanged := sdk.config
+	updates <- &unchanged
+
+	invalid := sdk.config
+	invalid.Service.Port = 0
+	updates <- &invalid
+
+	changed := sdk.config
+	changed.Writable.LogLevel = "DEBUG"
+	updates <- &changed
+
+	for _, changes := range []chan *AppConfig{changes1, changes2} {
+		select {
+		case newConfig := <-changes:
+			assert.Equal(t, "DEBUG", newConfig.Writable.LogLevel)
+			assert.Equal(t, 48095, newConfig.Service.Port)
+		case <-time.After(time.Second):
+			t.Fatal("expected the change handler to be called")
+		}
+		assert.Len(t, changes, 0, "expected only the valid change")
+	}
+	assert.Len(t, watches, 0, "expected a single watch")
+}
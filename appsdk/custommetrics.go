@@ -0,0 +1,334 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/telemetry"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// DefaultHistogramBuckets are the upper bounds of the buckets of the histograms which aren't registered by
+// RegisterCustomHistogram, the default buckets of the Prometheus client libraries
+var DefaultHistogramBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// CustomMetric is the last value recorded by RecordCustomMetric for a metric and set of label values
+type CustomMetric struct {
+	Name   string
+	Labels map[string]string `json:",omitempty"`
+	Value  float64
+}
+
+// CustomHistogram is the distribution of the values observed by ObserveCustomHistogram for a histogram and set of
+// label values
+type CustomHistogram struct {
+	Name   string
+	Labels map[string]string `json:",omitempty"`
+	// Buckets are the number of values observed less than or equal to the upper bound of each bucket
+	Buckets []HistogramBucket
+	Count   uint64
+	Sum     float64
+}
+
+// HistogramBucket is a bucket of a CustomHistogram
+type HistogramBucket struct {
+	UpperBound float64
+	// Count is cumulative, including the values of the buckets with a lower upper bound
+	Count uint64
+}
+
+// customMetrics holds the gauges recorded by RecordCustomMetric and the histograms observed by
+// ObserveCustomHistogram by name. A name is either a gauge or a histogram.
+type customMetrics struct {
+	mutex      sync.Mutex
+	gauges     map[string]*customGauge
+	histograms map[string]*customHistogram
+}
+
+// customGauge is a gauge with the label names it was created with, holding a value per set of label values
+type customGauge struct {
+	labelNames []string
+	values     map[string]CustomMetric
+}
+
+// customHistogram is a histogram with its buckets and the label names of its first observation, holding a
+// distribution per set of label values
+type customHistogram struct {
+	buckets    []float64
+	labelNames []string
+	values     map[string]*CustomHistogram
+}
+
+// RecordCustomMetric records the value of the gauge with the specified name and labels, i.e. a business metric
+// computed by a pipeline function. The gauge is created by the first call with the name, which sets its label
+// names, and a value is kept for each set of label values. The recorded values are included in GetMetrics, in
+// the /api/v1/metrics route and in the Prometheus metrics. A name which isn't a valid Prometheus metric name,
+// conflicts with the built-in metrics of the SDK or is recorded with other label names than the gauge's is
+// rejected with an error logged.
+func (sdk *AppFunctionsSDK) RecordCustomMetric(name string, value float64, labels map[string]string) {
+	if err := sdk.customMetrics.record(name, value, labels); err != nil {
+		sdk.LoggingClient.Error(fmt.Sprintf("Unable to record custom metric '%s': %s", name, err.Error()))
+	}
+}
+
+// RegisterCustomHistogram sets the upper bounds of the buckets of the histogram with the specified name, which
+// must be increasing. Must be called before the histogram is first observed, the histograms which aren't
+// registered using DefaultHistogramBuckets.
+func (sdk *AppFunctionsSDK) RegisterCustomHistogram(name string, buckets []float64) error {
+	return sdk.customMetrics.registerHistogram(name, buckets)
+}
+
+// ObserveCustomHistogram adds the value to the histogram with the specified name and labels, i.e. the duration or
+// size of a business operation. The histogram's label names are set by its first observation, and a distribution
+// is kept for each set of label values. The distributions are included in GetMetrics, in the /api/v1/metrics route
+// and in the Prometheus metrics. The same names as RecordCustomMetric's are rejected with an error logged, as well
+// as the name of a gauge and the 'le' label.
+func (sdk *AppFunctionsSDK) ObserveCustomHistogram(name string, value float64, labels map[string]string) {
+	if err := sdk.customMetrics.observe(name, value, labels); err != nil {
+		sdk.LoggingClient.Error(fmt.Sprintf("Unable to observe custom histogram '%s': %s", name, err.Error()))
+	}
+}
+
+func (metrics *customMetrics) record(name string, value float64, labels map[string]string) error {
+	labelNames, err := validateMetric(name, labels)
+	if err != nil {
+		return err
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	if _, ok := metrics.histograms[name]; ok {
+		return fmt.Errorf("'%s' is a histogram", name)
+	}
+	if metrics.gauges == nil {
+		metrics.gauges = make(map[string]*customGauge)
+	}
+	gauge, ok := metrics.gauges[name]
+	if !ok {
+		gauge = &customGauge{labelNames: labelNames, values: make(map[string]CustomMetric)}
+		metrics.gauges[name] = gauge
+	} else if err := matchLabelNames(labelNames, gauge.labelNames); err != nil {
+		return err
+	}
+
+	key, copied := labelValues(labelNames, labels)
+	gauge.values[key] = CustomMetric{Name: name, Labels: copied, Value: value}
+	return nil
+}
+
+func (metrics *customMetrics) registerHistogram(name string, buckets []float64) error {
+	if _, err := validateMetric(name, nil); err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		return errors.New("Histogram buckets must be specified")
+	}
+	for index := 1; index < len(buckets); index++ {
+		if buckets[index] <= buckets[index-1] {
+			return errors.New("Histogram buckets must be increasing")
+		}
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	if _, ok := metrics.gauges[name]; ok {
+		return fmt.Errorf("'%s' is a gauge", name)
+	}
+	if _, ok := metrics.histograms[name]; ok {
+		return fmt.Errorf("Histogram '%s' is already registered or observed", name)
+	}
+	if metrics.histograms == nil {
+		metrics.histograms = make(map[string]*customHistogram)
+	}
+	metrics.histograms[name] = &customHistogram{
+		buckets: append([]float64{}, buckets...),
+		values:  make(map[string]*CustomHistogram),
+	}
+	return nil
+}
+
+func (metrics *customMetrics) observe(name string, value float64, labels map[string]string) error {
+	labelNames, err := validateMetric(name, labels)
+	if err != nil {
+		return err
+	}
+	if _, ok := labels["le"]; ok {
+		return errors.New("'le' is reserved for the buckets of histograms")
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	if _, ok := metrics.gauges[name]; ok {
+		return fmt.Errorf("'%s' is a gauge", name)
+	}
+	if metrics.histograms == nil {
+		metrics.histograms = make(map[string]*customHistogram)
+	}
+	histogram, ok := metrics.histograms[name]
+	if !ok {
+		histogram = &customHistogram{buckets: DefaultHistogramBuckets, values: make(map[string]*CustomHistogram)}
+		metrics.histograms[name] = histogram
+	}
+	if histogram.labelNames == nil {
+		histogram.labelNames = labelNames
+	} else if err := matchLabelNames(labelNames, histogram.labelNames); err != nil {
+		return err
+	}
+
+	key, copied := labelValues(labelNames, labels)
+	distribution, ok := histogram.values[key]
+	if !ok {
+		distribution = &CustomHistogram{Name: name, Labels: copied, Buckets: make([]HistogramBucket, len(histogram.buckets))}
+		for index, upperBound := range histogram.buckets {
+			distribution.Buckets[index].UpperBound = upperBound
+		}
+		histogram.values[key] = distribution
+	}
+
+	for index := range distribution.Buckets {
+		if value <= distribution.Buckets[index].UpperBound {
+			distribution.Buckets[index].Count++
+		}
+	}
+	distribution.Count++
+	distribution.Sum += value
+	return nil
+}
+
+// validateMetric checks the name and label names of a metric, returning the sorted label names
+func validateMetric(name string, labels map[string]string) ([]string, error) {
+	if !metricNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("'%s' is not a valid metric name", name)
+	}
+	if isBuiltInMetric(name) {
+		return nil, fmt.Errorf("'%s' conflicts with a built-in metric", name)
+	}
+
+	labelNames := make([]string, 0, len(labels))
+	for labelName := range labels {
+		if !labelNamePattern.MatchString(labelName) || strings.HasPrefix(labelName, "__") {
+			return nil, fmt.Errorf("'%s' is not a valid label name", labelName)
+		}
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+	return labelNames, nil
+}
+
+func matchLabelNames(labelNames []string, expected []string) error {
+	if !reflect.DeepEqual(labelNames, expected) {
+		return fmt.Errorf("labels [%s] don't match the labels [%s] of the metric",
+			strings.Join(labelNames, ", "), strings.Join(expected, ", "))
+	}
+	return nil
+}
+
+// labelValues returns the key of the label values, in the order of the label names, and a copy of the labels
+func labelValues(labelNames []string, labels map[string]string) (string, map[string]string) {
+	values := make([]string, len(labelNames))
+	copied := make(map[string]string, len(labels))
+	for index, labelName := range labelNames {
+		values[index] = labels[labelName]
+		copied[labelName] = labels[labelName]
+	}
+	return strings.Join(values, "\x00"), copied
+}
+
+// snapshot returns the recorded values, sorted by metric name and label values
+func (metrics *customMetrics) snapshot() []CustomMetric {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	var snapshot []CustomMetric
+	for _, name := range sortedKeys(metrics.gauges) {
+		gauge := metrics.gauges[name]
+		for _, key := range sortedKeys(gauge.values) {
+			snapshot = append(snapshot, gauge.values[key])
+		}
+	}
+	return snapshot
+}
+
+// histogramSnapshot returns copies of the observed distributions, sorted by histogram name and label values
+func (metrics *customMetrics) histogramSnapshot() []CustomHistogram {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	var snapshot []CustomHistogram
+	for _, name := range sortedKeys(metrics.histograms) {
+		histogram := metrics.histograms[name]
+		for _, key := range sortedKeys(histogram.values) {
+			distribution := *histogram.values[key]
+			distribution.Buckets = append([]HistogramBucket{}, distribution.Buckets...)
+			snapshot = append(snapshot, distribution)
+		}
+	}
+	return snapshot
+}
+
+// resetHistograms discards the observed distributions, keeping the buckets and label names of the histograms
+func (metrics *customMetrics) resetHistograms() {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	for _, histogram := range metrics.histograms {
+		histogram.values = make(map[string]*CustomHistogram)
+	}
+}
+
+// sortedKeys returns the sorted keys of a map with string keys
+func sortedKeys(values interface{}) []string {
+	keys := make([]string, 0)
+	for _, key := range reflect.ValueOf(values).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isBuiltInMetric returns whether the name is the name of one of the metrics reported by the SDK, ignoring case,
+// either in the metrics route or in the Prometheus metrics
+func isBuiltInMetric(name string) bool {
+	for _, metrics := range []interface{}{Metrics{}, telemetry.SystemUsage{}} {
+		metricsType := reflect.TypeOf(metrics)
+		for index := 0; index < metricsType.NumField(); index++ {
+			if strings.EqualFold(metricsType.Field(index).Name, name) {
+				return true
+			}
+		}
+	}
+	for _, builtIn := range prometheusMetrics {
+		if strings.EqualFold(builtIn.name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordCustomMetric(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	sdk.RecordCustomMetric("orders_total", 5, map[string]string{"site": "north", "line": "1"})
+	sdk.RecordCustomMetric("orders_total", 7, map[string]string{"site": "north", "line": "1"})
+	sdk.RecordCustomMetric("orders_total", 3, map[string]string{"line": "2", "site": "north"})
+	sdk.RecordCustomMetric("anomaly_score", 0.75, nil)
+
+	expected := []CustomMetric{
+		{Name: "anomaly_score", Labels: map[string]string{}, Value: 0.75},
+		{Name: "orders_total", Labels: map[string]string{"line": "1", "site": "north"}, Value: 7},
+		{Name: "orders_total", Labels: map[string]string{"line": "2", "site": "north"}, Value: 3},
+	}
+	assert.Equal(t, expected, sdk.GetMetrics().CustomMetrics)
+}
+
+func TestRecordCustomMetricErrors(t *testing.T) {
+	metrics := customMetrics{}
+
+	err := metrics.record("orders total", 1, nil)
+	assert.EqualError(t, err, "'orders total' is not a valid metric name")
+
+	err = metrics.record("EventsReceived", 1, nil)
+	assert.EqualError(t, err, "'EventsReceived' conflicts with a built-in metric")
+	err = metrics.record("cpubusyavg", 1, nil)
+	assert.EqualError(t, err, "'cpubusyavg' conflicts with a built-in metric")
+
+	assert.NoError(t, metrics.record("orders_total", 1, map[string]string{"site": "north"}))
+	err = metrics.record("orders_total", 1, map[string]string{"site": "north", "line": "1"})
+	assert.EqualError(t, err, "labels [line, site] don't match the labels [site] of the metric")
+
+	assert.Len(t, metrics.snapshot(), 1)
+
+	err = metrics.record("orders_total", 1, map[string]string{"site-name": "north"})
+	assert.EqualError(t, err, "'site-name' is not a valid label name")
+	err = metrics.record("uptime_seconds", 1, nil)
+	assert.EqualError(t, err, "'uptime_seconds' conflicts with a built-in metric")
+}
+
+func TestObserveCustomHistogram(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+	assert.NoError(t, sdk.RegisterCustomHistogram("order_seconds", []float64{1, 5}))
+
+	sdk.ObserveCustomHistogram("order_seconds", 0.5, map[string]string{"site": "north"})
+	sdk.ObserveCustomHistogram("order_seconds", 3, map[string]string{"site": "north"})
+	sdk.ObserveCustomHistogram("order_seconds", 7, map[string]string{"site": "north"})
+	sdk.ObserveCustomHistogram("order_bytes", 0.05, nil)
+
+	histograms := sdk.GetMetrics().CustomHistograms
+	if !assert.Len(t, histograms, 2) {
+		t.Fatal()
+	}
+	assert.Equal(t, "order_bytes", histograms[0].Name)
+	assert.Len(t, histograms[0].Buckets, len(DefaultHistogramBuckets), "expected the default buckets")
+	assert.Equal(t, CustomHistogram{
+		Name:    "order_seconds",
+		Labels:  map[string]string{"site": "north"},
+		Buckets: []HistogramBucket{{UpperBound: 1, Count: 1}, {UpperBound: 5, Count: 2}},
+		Count:   3,
+		Sum:     10.5,
+	}, histograms[1])
+}
+
+func TestObserveCustomHistogramErrors(t *testing.T) {
+	metrics := customMetrics{}
+
+	assert.EqualError(t, metrics.registerHistogram("order_seconds", nil), "Histogram buckets must be specified")
+	assert.EqualError(t, metrics.registerHistogram("order_seconds", []float64{5, 1}), "Histogram buckets must be increasing")
+	assert.NoError(t, metrics.registerHistogram("order_seconds", []float64{1, 5}))
+	assert.EqualError(t, metrics.registerHistogram("order_seconds", []float64{1, 5}), "Histogram 'order_seconds' is already registered or observed")
+
+	assert.EqualError(t, metrics.observe("order_seconds", 1, map[string]string{"le": "1"}), "'le' is reserved for the buckets of histograms")
+	assert.NoError(t, metrics.observe("order_seconds", 1, map[string]string{"site": "north"}))
+	err := metrics.observe("order_seconds", 1, nil)
+	assert.EqualError(t, err, "labels [] don't match the labels [site] of the metric")
+
+	assert.NoError(t, metrics.record("orders_total", 1, nil))
+	assert.EqualError(t, metrics.observe("orders_total", 1, nil), "'orders_total' is a gauge")
+	assert.EqualError(t, metrics.record("order_seconds", 1, nil), "'order_seconds' is a histogram")
+}
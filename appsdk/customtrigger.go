@@ -0,0 +1,119 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// Trigger is implemented by custom triggers to begin the execution of the functions pipeline
+type Trigger interface {
+	// Initialize performs post creation initializations, i.e. starts listening for incoming data
+	Initialize() error
+}
+
+// MessageRouter routes the messages received by a custom trigger thru the functions pipeline
+type MessageRouter interface {
+	// ProcessMessage sends the message thru the functions pipeline. The error returned, if any, has already been logged.
+	ProcessMessage(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error
+}
+
+// TriggerConfig provides the configuration and helpers a custom trigger needs
+type TriggerConfig struct {
+	// Config is the Binding configuration of the service
+	Config common.BindingInfo
+	// Logger is the EdgeX logger client used to log messages
+	Logger logger.LoggingClient
+	// ContextBuilder creates the context, populated with the SDK's clients and configuration, for a received message
+	ContextBuilder func(envelope types.MessageEnvelope) *appcontext.Context
+}
+
+// TriggerBuilder creates a custom trigger from the trigger configuration and the router for received messages
+type TriggerBuilder func(c TriggerConfig, router MessageRouter) (Trigger, error)
+
+// SetCustomTrigger registers a builder for a custom trigger to use when the Binding Type is set to name.
+// The name is case insensitive and must not be one of the built-in trigger types. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SetCustomTrigger(name string, builder TriggerBuilder) error {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return errors.New("Custom trigger name must be specified")
+	}
+	if builder == nil {
+		return errors.New("Custom trigger builder must be specified")
+	}
+	if key == "HTTP" || key == "MESSAGEBUS" {
+		return errors.New("Custom trigger name is reserved for the built-in " + name + " trigger")
+	}
+
+	if sdk.customTriggerBuilders == nil {
+		sdk.customTriggerBuilders = make(map[string]TriggerBuilder)
+	}
+	sdk.customTriggerBuilders[key] = builder
+
+	return nil
+}
+
+// runtimeRouter adapts the runtime to the MessageRouter interface for custom triggers
+type runtimeRouter struct {
+	runtime *runtime.GolangRuntime
+}
+
+func (router runtimeRouter) ProcessMessage(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error {
+	router.runtime.AcquireExecution()
+	defer router.runtime.ReleaseExecution()
+
+	messageError := router.runtime.ProcessMessage(edgexcontext, envelope)
+	if messageError != nil {
+		return messageError.Err
+	}
+	return nil
+}
+
+// setupCustomTrigger creates the custom trigger using the specified builder
+func (sdk *AppFunctionsSDK) setupCustomTrigger(configuration common.ConfigurationStruct, runtime *runtime.GolangRuntime, builder TriggerBuilder) (Trigger, error) {
+	triggerConfig := TriggerConfig{
+		Config: configuration.Binding,
+		Logger: sdk.LoggingClient,
+		ContextBuilder: func(envelope types.MessageEnvelope) *appcontext.Context {
+			return sdk.newContext(configuration, envelope.CorrelationID)
+		},
+	}
+
+	return builder(triggerConfig, runtimeRouter{runtime: runtime})
+}
+
+// newContext creates a context populated with the SDK's clients and the configuration
+func (sdk *AppFunctionsSDK) newContext(configuration common.ConfigurationStruct, correlationID string) *appcontext.Context {
+	return &appcontext.Context{
+		CorrelationID:         correlationID,
+		Configuration:         configuration,
+		LoggingClient:         sdk.edgexClients.LoggingClient,
+		EventClient:           sdk.edgexClients.EventClient,
+		ValueDescriptorClient: sdk.edgexClients.ValueDescriptorClient,
+		CommandClient:         sdk.edgexClients.CommandClient,
+		NotificationsClient:   sdk.edgexClients.NotificationsClient,
+		SecretStoreClient:     sdk.secretStoreClient,
+		RequestTimeout:        sdk.getRequestTimeout(),
+	}
+}
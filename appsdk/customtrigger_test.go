@@ -0,0 +1,114 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fileTrigger struct {
+	config TriggerConfig
+	router MessageRouter
+}
+
+func (trigger *fileTrigger) Initialize() error {
+	return nil
+}
+
+func TestSetCustomTrigger(t *testing.T) {
+	builder := func(c TriggerConfig, router MessageRouter) (Trigger, error) {
+		return &fileTrigger{config: c, router: router}, nil
+	}
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	assert.NoError(t, sdk.SetCustomTrigger("file", builder))
+	assert.Error(t, sdk.SetCustomTrigger("", builder), "expected error for empty name")
+	assert.Error(t, sdk.SetCustomTrigger("file", nil), "expected error for nil builder")
+	assert.Error(t, sdk.SetCustomTrigger("http", builder), "expected error for built-in trigger name")
+	assert.Error(t, sdk.SetCustomTrigger("MessageBus", builder), "expected error for built-in trigger name")
+}
+
+func TestSetupCustomTrigger(t *testing.T) {
+	var called bool
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		edgexClients:  common.EdgeXClients{LoggingClient: lc},
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{
+				Type: "FiLe",
+				Name: "input.json",
+			},
+		},
+	}
+	sdk.SetCustomTrigger("file", func(c TriggerConfig, router MessageRouter) (Trigger, error) {
+		return &fileTrigger{config: c, router: router}, nil
+	})
+	runtime := &runtime.GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			called = true
+			return false, nil
+		},
+	})
+
+	trigger := sdk.setupTrigger(sdk.config, runtime)
+	custom, ok := trigger.(*fileTrigger)
+	if !assert.True(t, ok, "Expected Instance of custom trigger") {
+		t.Fatal()
+	}
+	assert.Equal(t, "input.json", custom.config.Config.Name)
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       []byte(`{"device":"device1"}`),
+		ContentType:   clients.ContentTypeJSON,
+	}
+	edgexcontext := custom.config.ContextBuilder(envelope)
+	assert.Equal(t, "123-234-345-456", edgexcontext.CorrelationID)
+	assert.Equal(t, lc, edgexcontext.LoggingClient)
+
+	err := custom.router.ProcessMessage(edgexcontext, envelope)
+	assert.NoError(t, err)
+	assert.True(t, called, "expected pipeline function to be called")
+}
+
+func TestSetupCustomTriggerBuilderError(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{
+				Type: "file",
+			},
+		},
+	}
+	sdk.SetCustomTrigger("file", func(c TriggerConfig, router MessageRouter) (Trigger, error) {
+		return nil, errors.New("file not found")
+	})
+
+	trigger := sdk.setupTrigger(sdk.config, &runtime.GolangRuntime{})
+	assert.Nil(t, trigger, "expected no trigger when builder fails")
+}
@@ -0,0 +1,252 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	syscontext "context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
+	coreTypes "github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+const defaultDeviceCacheTTL = 5 * time.Minute
+
+// ErrDeviceNotFound is returned when the device doesn't exist in Core Metadata
+type ErrDeviceNotFound struct {
+	DeviceName string
+}
+
+func (e ErrDeviceNotFound) Error() string {
+	return fmt.Sprintf("Device '%s' not found", e.DeviceName)
+}
+
+// ErrProfileNotFound is returned when the device profile doesn't exist in Core Metadata
+type ErrProfileNotFound struct {
+	ProfileName string
+}
+
+func (e ErrProfileNotFound) Error() string {
+	return fmt.Sprintf("Device profile '%s' not found", e.ProfileName)
+}
+
+// deviceCache caches the devices and device profiles retrieved from Core Metadata by name
+type deviceCache struct {
+	client        metadata.DeviceClient
+	profileClient metadata.DeviceProfileClient
+	ttl           time.Duration
+	timeout       time.Duration
+	devices       sync.Map
+	profiles      sync.Map
+}
+
+type deviceCacheEntry struct {
+	device  models.Device
+	expires time.Time
+}
+
+type profileCacheEntry struct {
+	profile models.DeviceProfile
+	expires time.Time
+}
+
+func newDeviceCache(client metadata.DeviceClient, profileClient metadata.DeviceProfileClient, ttl time.Duration, timeout time.Duration) *deviceCache {
+	return &deviceCache{
+		client:        client,
+		profileClient: profileClient,
+		ttl:           ttl,
+		timeout:       timeout,
+	}
+}
+
+// get returns the cached device, retrieving it from Core Metadata when it isn't cached or the entry has expired.
+// The device is removed from the cache when Core Metadata responds that it doesn't exist.
+func (cache *deviceCache) get(name string) (models.Device, error) {
+	if entry, ok := cache.devices.Load(name); ok {
+		cached := entry.(deviceCacheEntry)
+		if time.Now().Before(cached.expires) {
+			return cached.device, nil
+		}
+	}
+
+	result, err := common.CallWithTimeoutResult(syscontext.Background(), cache.timeout, func(ctx syscontext.Context) (interface{}, error) {
+		return cache.client.DeviceForName(name, ctx)
+	})
+	if err != nil {
+		if serviceError, ok := err.(coreTypes.ErrServiceClient); ok && serviceError.StatusCode == http.StatusNotFound {
+			cache.devices.Delete(name)
+			return models.Device{}, ErrDeviceNotFound{DeviceName: name}
+		}
+		return models.Device{}, err
+	}
+
+	device := result.(models.Device)
+	cache.devices.Store(name, deviceCacheEntry{device: device, expires: time.Now().Add(cache.ttl)})
+	return device, nil
+}
+
+// getProfile returns the cached device profile, retrieving it from Core Metadata when it isn't cached or the entry
+// has expired. The profile is removed from the cache when Core Metadata responds that it doesn't exist.
+func (cache *deviceCache) getProfile(name string) (models.DeviceProfile, error) {
+	if entry, ok := cache.profiles.Load(name); ok {
+		cached := entry.(profileCacheEntry)
+		if time.Now().Before(cached.expires) {
+			return cached.profile, nil
+		}
+	}
+
+	result, err := common.CallWithTimeoutResult(syscontext.Background(), cache.timeout, func(ctx syscontext.Context) (interface{}, error) {
+		return cache.profileClient.DeviceProfileForName(name, ctx)
+	})
+	if err != nil {
+		if serviceError, ok := err.(coreTypes.ErrServiceClient); ok && serviceError.StatusCode == http.StatusNotFound {
+			cache.profiles.Delete(name)
+			return models.DeviceProfile{}, ErrProfileNotFound{ProfileName: name}
+		}
+		return models.DeviceProfile{}, err
+	}
+
+	profile := result.(models.DeviceProfile)
+	cache.profiles.Store(name, profileCacheEntry{profile: profile, expires: time.Now().Add(cache.ttl)})
+	return profile, nil
+}
+
+// invalidate removes the device from the cache so it is retrieved again from Core Metadata
+func (cache *deviceCache) invalidate(name string) {
+	cache.devices.Delete(name)
+}
+
+// prewarm caches all the devices from Core Metadata
+func (cache *deviceCache) prewarm() (int, error) {
+	result, err := common.CallWithTimeoutResult(syscontext.Background(), cache.timeout, func(ctx syscontext.Context) (interface{}, error) {
+		return cache.client.Devices(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	devices := result.([]models.Device)
+
+	expires := time.Now().Add(cache.ttl)
+	for _, device := range devices {
+		cache.devices.Store(device.Name, deviceCacheEntry{device: device, expires: expires})
+	}
+
+	return len(devices), nil
+}
+
+// GetDeviceByName returns the device with the specified name from Core Metadata. The device is cached for the
+// configured DeviceCacheTTL. ErrDeviceNotFound is returned when the device doesn't exist. Requires the Metadata
+// client to be configured.
+func (sdk *AppFunctionsSDK) GetDeviceByName(name string) (*models.Device, error) {
+	if sdk.deviceCache == nil {
+		return nil, errors.New("Metadata client is missing from configuration")
+	}
+
+	device, err := sdk.deviceCache.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &device, nil
+}
+
+// GetDeviceProfile returns the device profile with the specified name from Core Metadata. The profile is cached for
+// the configured DeviceCacheTTL, along with the devices. ErrProfileNotFound is returned when the profile doesn't
+// exist. Requires the Metadata client to be configured.
+func (sdk *AppFunctionsSDK) GetDeviceProfile(profileName string) (*models.DeviceProfile, error) {
+	if sdk.deviceCache == nil || sdk.deviceCache.profileClient == nil {
+		return nil, errors.New("Metadata client is missing from configuration")
+	}
+
+	profile, err := sdk.deviceCache.getProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// InvalidateDeviceCache removes the device with the specified name from the device cache, which forces it to be
+// retrieved again from Core Metadata on the next use.
+func (sdk *AppFunctionsSDK) InvalidateDeviceCache(name string) {
+	if sdk.deviceCache != nil {
+		sdk.deviceCache.invalidate(name)
+	}
+}
+
+// GetDeviceResource returns the device resource with the specified name from the profile of the specified device,
+// which provides the metadata, such as units and value type, of the device's readings. The device is retrieved
+// from Core Metadata and cached for the configured DeviceCacheTTL. ErrDeviceNotFound is returned when the
+// device doesn't exist. Requires the Metadata client to be configured.
+func (sdk *AppFunctionsSDK) GetDeviceResource(deviceName string, resourceName string) (*models.DeviceResource, error) {
+	device, err := sdk.GetDeviceByName(deviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range device.Profile.DeviceResources {
+		if resource.Name == resourceName {
+			return &resource, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Device resource '%s' not found in profile '%s' of device '%s'", resourceName, device.Profile.Name, deviceName)
+}
+
+// initializeDeviceCache creates the device cache when the Metadata client is configured
+func (sdk *AppFunctionsSDK) initializeDeviceCache() {
+	if sdk.edgexClients.DeviceClient == nil {
+		return
+	}
+
+	ttl := defaultDeviceCacheTTL
+	if sdk.config.Service.DeviceCacheTTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(sdk.config.Service.DeviceCacheTTL)
+		if err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Invalid DeviceCacheTTL '%s', using default of %s: %s",
+				sdk.config.Service.DeviceCacheTTL, defaultDeviceCacheTTL, err.Error()))
+			ttl = defaultDeviceCacheTTL
+		}
+	}
+
+	sdk.deviceCache = newDeviceCache(sdk.edgexClients.DeviceClient, sdk.edgexClients.DeviceProfileClient, ttl, sdk.getRequestTimeout())
+}
+
+// prewarmDeviceCache loads all devices in to the device cache. Failing to do so isn't fatal, since the devices
+// are retrieved on first use.
+func (sdk *AppFunctionsSDK) prewarmDeviceCache() {
+	if sdk.deviceCache == nil {
+		sdk.LoggingClient.Warn("PrewarmDeviceCache is set, but the Metadata client is missing from configuration")
+		return
+	}
+
+	count, err := sdk.deviceCache.prewarm()
+	if err != nil {
+		sdk.LoggingClient.Error(fmt.Sprintf("Unable to prewarm device cache: %s", err.Error()))
+		return
+	}
+
+	sdk.LoggingClient.Info(fmt.Sprintf("Device cache prewarmed with %d devices", count))
+}
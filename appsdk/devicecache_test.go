@@ -0,0 +1,242 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata/mocks"
+	coreTypes "github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testDevice = models.Device{
+	Name: "thermostat1",
+	Profile: models.DeviceProfile{
+		Name: "thermostat",
+		DeviceResources: []models.DeviceResource{
+			{Name: "temperature", Properties: models.ProfileProperty{Value: models.PropertyValue{Type: "Float32"}, Units: models.Units{DefaultValue: "C"}}},
+			{Name: "humidity", Properties: models.ProfileProperty{Value: models.PropertyValue{Type: "Int32"}, Units: models.Units{DefaultValue: "%"}}},
+		},
+	},
+}
+
+func newDeviceCacheTestSDK(deviceClient *mocks.DeviceClient, ttl string) *AppFunctionsSDK {
+	sdk := &AppFunctionsSDK{
+		LoggingClient: lc,
+		edgexClients:  common.EdgeXClients{LoggingClient: lc, DeviceClient: deviceClient},
+		config: common.ConfigurationStruct{
+			Service: common.ServiceInfo{DeviceCacheTTL: ttl},
+		},
+	}
+	sdk.initializeDeviceCache()
+	return sdk
+}
+
+func TestGetDeviceResource(t *testing.T) {
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).Return(testDevice, nil).Once()
+	sdk := newDeviceCacheTestSDK(deviceClient, "")
+
+	resource, err := sdk.GetDeviceResource("thermostat1", "humidity")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "Int32", resource.Properties.Value.Type)
+	assert.Equal(t, "%", resource.Properties.Units.DefaultValue)
+
+	// Served from the cache, so Core Metadata is only called once
+	resource, err = sdk.GetDeviceResource("thermostat1", "temperature")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "Float32", resource.Properties.Value.Type)
+	deviceClient.AssertNumberOfCalls(t, "DeviceForName", 1)
+
+	_, err = sdk.GetDeviceResource("thermostat1", "pressure")
+	assert.Error(t, err, "expected error for unknown resource")
+}
+
+func TestGetDeviceResourceExpired(t *testing.T) {
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).Return(testDevice, nil)
+	sdk := newDeviceCacheTestSDK(deviceClient, "1ms")
+	assert.Equal(t, time.Millisecond, sdk.deviceCache.ttl)
+
+	_, err := sdk.GetDeviceResource("thermostat1", "humidity")
+	assert.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = sdk.GetDeviceResource("thermostat1", "humidity")
+	assert.NoError(t, err)
+	deviceClient.AssertNumberOfCalls(t, "DeviceForName", 2)
+}
+
+func TestGetDeviceResourceDeviceNotFound(t *testing.T) {
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).Return(testDevice, nil).Once()
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).Return(models.Device{}, coreTypes.NewErrServiceClient(http.StatusNotFound, nil))
+	deviceClient.On("DeviceForName", "bogus", mock.Anything).Return(models.Device{}, errors.New("connection refused"))
+	sdk := newDeviceCacheTestSDK(deviceClient, "1ms")
+
+	_, err := sdk.GetDeviceResource("thermostat1", "humidity")
+	assert.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+
+	_, err = sdk.GetDeviceResource("thermostat1", "humidity")
+	assert.Equal(t, ErrDeviceNotFound{DeviceName: "thermostat1"}, err)
+	_, cached := sdk.deviceCache.devices.Load("thermostat1")
+	assert.False(t, cached, "expected device to be removed from the cache")
+
+	_, err = sdk.GetDeviceResource("bogus", "humidity")
+	assert.Error(t, err)
+	_, isNotFound := err.(ErrDeviceNotFound)
+	assert.False(t, isNotFound, "expected error other than ErrDeviceNotFound")
+}
+
+func TestGetDeviceResourceNoMetadataClient(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	sdk.initializeDeviceCache()
+
+	_, err := sdk.GetDeviceResource("thermostat1", "humidity")
+	assert.Error(t, err, "expected error when Metadata client is not configured")
+}
+
+func TestInitializeDeviceCacheInvalidTTL(t *testing.T) {
+	sdk := newDeviceCacheTestSDK(&mocks.DeviceClient{}, "bogus")
+	assert.Equal(t, defaultDeviceCacheTTL, sdk.deviceCache.ttl)
+}
+
+func TestGetDeviceByName(t *testing.T) {
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).Return(testDevice, nil)
+	sdk := newDeviceCacheTestSDK(deviceClient, "")
+
+	device, err := sdk.GetDeviceByName("thermostat1")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "thermostat", device.Profile.Name)
+
+	_, err = sdk.GetDeviceByName("thermostat1")
+	assert.NoError(t, err)
+	deviceClient.AssertNumberOfCalls(t, "DeviceForName", 1)
+
+	sdk.InvalidateDeviceCache("thermostat1")
+	_, err = sdk.GetDeviceByName("thermostat1")
+	assert.NoError(t, err)
+	deviceClient.AssertNumberOfCalls(t, "DeviceForName", 2)
+}
+
+func TestPrewarmDeviceCache(t *testing.T) {
+	otherDevice := models.Device{Name: "thermostat2"}
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("Devices", mock.Anything).Return([]models.Device{testDevice, otherDevice}, nil)
+	sdk := newDeviceCacheTestSDK(deviceClient, "")
+
+	sdk.prewarmDeviceCache()
+
+	device, err := sdk.GetDeviceByName("thermostat2")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "thermostat2", device.Name)
+	deviceClient.AssertNotCalled(t, "DeviceForName", "thermostat2", mock.Anything)
+}
+
+func TestPrewarmDeviceCacheError(t *testing.T) {
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("Devices", mock.Anything).Return(nil, errors.New("connection refused"))
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).Return(testDevice, nil)
+	sdk := newDeviceCacheTestSDK(deviceClient, "")
+
+	sdk.prewarmDeviceCache()
+
+	_, err := sdk.GetDeviceByName("thermostat1")
+	assert.NoError(t, err)
+	deviceClient.AssertNumberOfCalls(t, "DeviceForName", 1)
+}
+
+func TestGetDeviceProfile(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case clients.ApiDeviceProfileRoute + "/name/thermostat":
+			w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+			json.NewEncoder(w).Encode(testDevice.Profile)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	sdk := newDeviceCacheTestSDK(&mocks.DeviceClient{}, "")
+	sdk.deviceCache.profileClient = metadata.NewDeviceProfileClient(
+		coreTypes.EndpointParams{Url: ts.URL + clients.ApiDeviceProfileRoute}, nil)
+
+	profile, err := sdk.GetDeviceProfile("thermostat")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "thermostat", profile.Name)
+	assert.Equal(t, 2, len(profile.DeviceResources))
+
+	// Served from the cache, so Core Metadata is only called once
+	_, err = sdk.GetDeviceProfile("thermostat")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	_, err = sdk.GetDeviceProfile("bogus")
+	assert.Equal(t, ErrProfileNotFound{ProfileName: "bogus"}, err)
+}
+
+func TestGetDeviceProfileNoMetadataClient(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	sdk.initializeDeviceCache()
+
+	_, err := sdk.GetDeviceProfile("thermostat")
+	assert.Error(t, err, "expected error when Metadata client is not configured")
+}
+
+func TestGetDeviceByNameTimeout(t *testing.T) {
+	release := make(chan time.Time)
+	defer close(release)
+	deviceClient := &mocks.DeviceClient{}
+	deviceClient.On("DeviceForName", "thermostat1", mock.Anything).WaitUntil(release).Return(testDevice, nil)
+
+	sdk := newDeviceCacheTestSDK(deviceClient, "")
+	assert.Equal(t, common.DefaultRequestTimeout, sdk.deviceCache.timeout)
+	sdk.SetRequestTimeout(time.Millisecond)
+
+	_, err := sdk.GetDeviceByName("thermostat1")
+	assert.EqualError(t, err, "Request to EdgeX service timed out after 1ms")
+}
@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Metrics are the runtime statistics of the application service
+type Metrics struct {
+	// EventsReceived is the number of messages received by the trigger
+	EventsReceived uint64
+	// EventsProcessed is the number of messages processed thru the functions pipeline without error
+	EventsProcessed uint64
+	// EventsFailed is the number of messages which resulted in an error
+	EventsFailed uint64
+	// StoreForwardQueueDepth is the number of objects waiting in the store when Store and Forward is enabled
+	StoreForwardQueueDepth int
+	// MessageQueueDepth is the number of messages received from the message bus waiting to be processed when
+	// SetMessageQueueDepth has been called
+	MessageQueueDepth int
+	// AveragePipelineLatencyMs is the average time taken to process a message thru the functions pipeline
+	AveragePipelineLatencyMs float64
+	// UptimeSeconds is the time since the SDK was initialized
+	UptimeSeconds float64
+	// CustomMetrics are the values recorded by RecordCustomMetric
+	CustomMetrics []CustomMetric `json:",omitempty"`
+	// CustomHistograms are the distributions observed by ObserveCustomHistogram
+	CustomHistograms []CustomHistogram `json:",omitempty"`
+}
+
+// GetMetrics returns a snapshot of the current runtime statistics. These are also available as JSON, under
+// Application, from the GET /api/v1/metrics route.
+func (sdk *AppFunctionsSDK) GetMetrics() *Metrics {
+	metrics := &Metrics{}
+
+	// a reset happens either before or after the snapshot of the counters and histograms, never in between
+	sdk.metricsMutex.RLock()
+	defer sdk.metricsMutex.RUnlock()
+
+	if sdk.runtime != nil {
+		runtimeMetrics := sdk.runtime.GetMetrics()
+		metrics.EventsReceived = runtimeMetrics.EventsReceived
+		metrics.EventsProcessed = runtimeMetrics.EventsProcessed
+		metrics.EventsFailed = runtimeMetrics.EventsFailed
+		metrics.AveragePipelineLatencyMs = runtimeMetrics.AveragePipelineLatencyMs
+		metrics.MessageQueueDepth = runtimeMetrics.MessageQueueDepth
+	}
+
+	if sdk.config.Writable.StoreAndForward.Enabled && sdk.storeClient != nil {
+		count, err := sdk.storeClient.Count(sdk.ServiceKey)
+		if err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Unable to retrieve the Store and Forward queue depth: %s", err.Error()))
+		} else {
+			metrics.StoreForwardQueueDepth = count
+		}
+	}
+
+	if !sdk.startTime.IsZero() {
+		metrics.UptimeSeconds = time.Since(sdk.startTime).Seconds()
+	}
+
+	metrics.CustomMetrics = sdk.customMetrics.snapshot()
+	metrics.CustomHistograms = sdk.customMetrics.histogramSnapshot()
+
+	return metrics
+}
+
+// ResetMetrics zeros the event counters, the average pipeline latency and the buckets of the histograms observed
+// by ObserveCustomHistogram in one step, i.e. after a configuration change. The uptime, the Store and Forward
+// queue depth and the gauges recorded by RecordCustomMetric are not affected. Also available from the
+// POST /api/v1/metrics/reset route when Writable.MaintenanceMode is enabled.
+func (sdk *AppFunctionsSDK) ResetMetrics() {
+	sdk.metricsMutex.Lock()
+	defer sdk.metricsMutex.Unlock()
+
+	if sdk.runtime != nil {
+		sdk.runtime.ResetMetrics()
+	}
+	sdk.customMetrics.resetHistograms()
+}
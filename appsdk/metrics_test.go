@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+func TestGetMetricsNotRunning(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	assert.Equal(t, &Metrics{}, sdk.GetMetrics())
+}
+
+func TestGetMetrics(t *testing.T) {
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("Count", "myService").Return(3, nil)
+
+	sdk := AppFunctionsSDK{
+		ServiceKey:    "myService",
+		LoggingClient: lc,
+		runtime:       &runtime.GolangRuntime{TargetType: &[]byte{}},
+		storeClient:   storeClient,
+		startTime:     time.Now().Add(-time.Minute),
+	}
+	sdk.config.Writable.StoreAndForward.Enabled = true
+	sdk.runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return false, errors.New("pipeline failed")
+		},
+	})
+
+	context := &appcontext.Context{LoggingClient: lc}
+	sdk.runtime.ProcessMessage(context, types.MessageEnvelope{Payload: []byte("data"), ContentType: clients.ContentTypeJSON})
+
+	metrics := sdk.GetMetrics()
+	assert.Equal(t, uint64(1), metrics.EventsReceived)
+	assert.Equal(t, uint64(0), metrics.EventsProcessed)
+	assert.Equal(t, uint64(1), metrics.EventsFailed)
+	assert.Equal(t, 3, metrics.StoreForwardQueueDepth)
+	assert.True(t, metrics.UptimeSeconds >= 60, "Expected uptime since the SDK was initialized")
+
+	storeClient.ExpectedCalls = nil
+	storeClient.On("Count", "myService").Return(0, errors.New("store unavailable"))
+	assert.Equal(t, 0, sdk.GetMetrics().StoreForwardQueueDepth)
+}
+
+func TestResetMetrics(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	sdk.ResetMetrics()
+
+	sdk.runtime = &runtime.GolangRuntime{TargetType: &[]byte{}}
+	context := &appcontext.Context{LoggingClient: lc}
+	sdk.runtime.ProcessMessage(context, types.MessageEnvelope{Payload: []byte("data")})
+	assert.Equal(t, uint64(1), sdk.GetMetrics().EventsReceived)
+
+	sdk.ResetMetrics()
+	assert.Equal(t, &Metrics{}, sdk.GetMetrics())
+}
+
+func TestResetMetricsHistograms(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	if !assert.NoError(t, sdk.RegisterCustomHistogram("order_duration_seconds", []float64{1, 5})) {
+		t.Fatal()
+	}
+	sdk.ObserveCustomHistogram("order_duration_seconds", 2, map[string]string{"site": "north"})
+	sdk.RecordCustomMetric("orders_total", 7, nil)
+
+	sdk.ResetMetrics()
+	metrics := sdk.GetMetrics()
+	assert.Empty(t, metrics.CustomHistograms)
+	assert.Len(t, metrics.CustomMetrics, 1, "Expected gauges to be kept")
+
+	sdk.ObserveCustomHistogram("order_duration_seconds", 3, map[string]string{"site": "north"})
+	histograms := sdk.GetMetrics().CustomHistograms
+	if !assert.Len(t, histograms, 1) {
+		t.Fatal()
+	}
+	assert.Equal(t, uint64(1), histograms[0].Count)
+	assert.Equal(t, []HistogramBucket{{UpperBound: 1, Count: 0}, {UpperBound: 5, Count: 1}}, histograms[0].Buckets,
+		"Expected registered buckets to be kept")
+}
@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// MessageProcessor routes a message received by the trigger to the functions pipeline
+type MessageProcessor func(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error
+
+// TriggerMiddleware wraps the MessageProcessor of the next middleware, or the one routing to the functions pipeline.
+// It can inspect or modify the context and message, or return an error without calling next to reject the message.
+type TriggerMiddleware func(next MessageProcessor) MessageProcessor
+
+// AddTriggerMiddleware adds middleware which is applied to every message received by the trigger before it is
+// processed by the functions pipeline. Middleware is applied in the order added, the first being the outermost.
+// Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) AddTriggerMiddleware(mw TriggerMiddleware) {
+	if mw == nil {
+		return
+	}
+
+	sdk.triggerMiddleware = append(sdk.triggerMiddleware, mw)
+}
+
+// setupTriggerMiddleware adds the trigger middleware to the runtime
+func (sdk *AppFunctionsSDK) setupTriggerMiddleware() {
+	for _, mw := range sdk.triggerMiddleware {
+		mw := mw
+		sdk.runtime.AddMiddleware(func(next runtime.MessageProcessor) runtime.MessageProcessor {
+			return runtime.MessageProcessor(mw(MessageProcessor(next)))
+		})
+	}
+}
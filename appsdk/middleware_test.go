@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTriggerMiddleware(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	var correlationIDs []string
+	sdk.AddTriggerMiddleware(func(next MessageProcessor) MessageProcessor {
+		return func(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error {
+			correlationIDs = append(correlationIDs, envelope.CorrelationID)
+			return next(edgexcontext, envelope)
+		}
+	})
+	sdk.AddTriggerMiddleware(nil)
+	assert.Len(t, sdk.triggerMiddleware, 1, "expected nil middleware to be ignored")
+
+	called := false
+	sdk.runtime = &runtime.GolangRuntime{}
+	sdk.runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			called = true
+			return true, nil
+		},
+	})
+	sdk.setupTriggerMiddleware()
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       []byte(`{"device":"device1"}`),
+		ContentType:   clients.ContentTypeJSON,
+	}
+	result := sdk.runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, envelope)
+	assert.Nil(t, result)
+	assert.True(t, called, "expected pipeline function to be called")
+	assert.Equal(t, []string{"123-234-345-456"}, correlationIDs)
+}
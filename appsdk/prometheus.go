@@ -0,0 +1,144 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format written by
+// WritePrometheusMetrics
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusMetric is a built-in metric of the SDK in the Prometheus metrics
+type prometheusMetric struct {
+	name       string
+	help       string
+	metricType string
+	value      func(metrics *Metrics) float64
+}
+
+var prometheusMetrics = []prometheusMetric{
+	{"events_received_total", "Number of messages received by the trigger", "counter",
+		func(metrics *Metrics) float64 { return float64(metrics.EventsReceived) }},
+	{"events_processed_total", "Number of messages processed thru the functions pipeline without error", "counter",
+		func(metrics *Metrics) float64 { return float64(metrics.EventsProcessed) }},
+	{"events_failed_total", "Number of messages which resulted in an error", "counter",
+		func(metrics *Metrics) float64 { return float64(metrics.EventsFailed) }},
+	{"store_forward_queue_depth", "Number of objects waiting in the Store and Forward store", "gauge",
+		func(metrics *Metrics) float64 { return float64(metrics.StoreForwardQueueDepth) }},
+	{"message_queue_depth", "Number of messages waiting to be processed", "gauge",
+		func(metrics *Metrics) float64 { return float64(metrics.MessageQueueDepth) }},
+	{"pipeline_latency_average_milliseconds", "Average time taken to process a message thru the functions pipeline", "gauge",
+		func(metrics *Metrics) float64 { return metrics.AveragePipelineLatencyMs }},
+	{"uptime_seconds", "Time since the SDK was initialized", "gauge",
+		func(metrics *Metrics) float64 { return metrics.UptimeSeconds }},
+}
+
+// WritePrometheusMetrics writes the built-in metrics of GetMetrics, the gauges recorded by RecordCustomMetric and
+// the histograms observed by ObserveCustomHistogram in the Prometheus text exposition format. It is also served by
+// the GET /api/v1/metrics/prometheus route, so the service can be scraped by Prometheus.
+func (sdk *AppFunctionsSDK) WritePrometheusMetrics(writer io.Writer) error {
+	metrics := sdk.GetMetrics()
+	buffered := bufio.NewWriter(writer)
+
+	for _, builtIn := range prometheusMetrics {
+		writePrometheusHeader(buffered, builtIn.name, builtIn.help, builtIn.metricType)
+		fmt.Fprintf(buffered, "%s %s\n", builtIn.name, formatPrometheusValue(builtIn.value(metrics)))
+	}
+
+	previous := ""
+	for _, gauge := range metrics.CustomMetrics {
+		if gauge.Name != previous {
+			writePrometheusHeader(buffered, gauge.Name, "", "gauge")
+			previous = gauge.Name
+		}
+		fmt.Fprintf(buffered, "%s%s %s\n", gauge.Name, formatPrometheusLabels(gauge.Labels, ""), formatPrometheusValue(gauge.Value))
+	}
+
+	previous = ""
+	for _, histogram := range metrics.CustomHistograms {
+		if histogram.Name != previous {
+			writePrometheusHeader(buffered, histogram.Name, "", "histogram")
+			previous = histogram.Name
+		}
+		for _, bucket := range histogram.Buckets {
+			fmt.Fprintf(buffered, "%s_bucket%s %d\n", histogram.Name,
+				formatPrometheusLabels(histogram.Labels, formatPrometheusValue(bucket.UpperBound)), bucket.Count)
+		}
+		fmt.Fprintf(buffered, "%s_bucket%s %d\n", histogram.Name, formatPrometheusLabels(histogram.Labels, "+Inf"), histogram.Count)
+		fmt.Fprintf(buffered, "%s_sum%s %s\n", histogram.Name, formatPrometheusLabels(histogram.Labels, ""), formatPrometheusValue(histogram.Sum))
+		fmt.Fprintf(buffered, "%s_count%s %d\n", histogram.Name, formatPrometheusLabels(histogram.Labels, ""), histogram.Count)
+	}
+
+	return buffered.Flush()
+}
+
+func writePrometheusHeader(writer io.Writer, name string, help string, metricType string) {
+	if help != "" {
+		fmt.Fprintf(writer, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(writer, "# TYPE %s %s\n", name, metricType)
+}
+
+// formatPrometheusLabels formats the labels sorted by name, followed by the le label of a histogram bucket when
+// upperBound isn't empty
+func formatPrometheusLabels(labels map[string]string, upperBound string) string {
+	if len(labels) == 0 && upperBound == "" {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+escapePrometheusLabelValue(labels[name])+`"`)
+	}
+	if upperBound != "" {
+		pairs = append(pairs, `le="`+upperBound+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var prometheusLabelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapePrometheusLabelValue(value string) string {
+	return prometheusLabelValueEscaper.Replace(value)
+}
+
+func formatPrometheusValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+	sdk.RecordCustomMetric("orders_total", 7, map[string]string{"site": "north \"A\"", "line": "1"})
+	sdk.RecordCustomMetric("anomaly_score", math.Inf(1), nil)
+	assert.NoError(t, sdk.RegisterCustomHistogram("order_seconds", []float64{0.5, 1}))
+	sdk.ObserveCustomHistogram("order_seconds", 0.25, nil)
+	sdk.ObserveCustomHistogram("order_seconds", 2, nil)
+
+	var buffer bytes.Buffer
+	if !assert.NoError(t, sdk.WritePrometheusMetrics(&buffer)) {
+		t.Fatal()
+	}
+
+	expected := `# HELP events_received_total Number of messages received by the trigger
+# TYPE events_received_total counter
+events_received_total 0
+# HELP events_processed_total Number of messages processed thru the functions pipeline without error
+# TYPE events_processed_total counter
+events_processed_total 0
+# HELP events_failed_total Number of messages which resulted in an error
+# TYPE events_failed_total counter
+events_failed_total 0
+# HELP store_forward_queue_depth Number of objects waiting in the Store and Forward store
+# TYPE store_forward_queue_depth gauge
+store_forward_queue_depth 0
+# HELP message_queue_depth Number of messages waiting to be processed
+# TYPE message_queue_depth gauge
+message_queue_depth 0
+# HELP pipeline_latency_average_milliseconds Average time taken to process a message thru the functions pipeline
+# TYPE pipeline_latency_average_milliseconds gauge
+pipeline_latency_average_milliseconds 0
+# HELP uptime_seconds Time since the SDK was initialized
+# TYPE uptime_seconds gauge
+uptime_seconds 0
+# TYPE anomaly_score gauge
+anomaly_score +Inf
+# TYPE orders_total gauge
+orders_total{line="1",site="north \"A\""} 7
+# TYPE order_seconds histogram
+order_seconds_bucket{le="0.5"} 1
+order_seconds_bucket{le="1"} 1
+order_seconds_bucket{le="+Inf"} 2
+order_seconds_sum 2.25
+order_seconds_count 2
+`
+	assert.Equal(t, expected, buffer.String())
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/trigger"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/trigger/messagebus"
+)
+
+// PublishEventToMessageBus publishes the event to the topic of the message bus configured in the MessageBus
+// section, or to the Binding PublishTopic when topic is empty. This allows pipeline functions to publish derived
+// events, i.e. anomaly alerts, without going through a trigger. The event is published as is when it is a []byte,
+// otherwise it is marshaled to JSON. The connection of the message bus trigger is used when it is the trigger,
+// otherwise a connection to the message bus is created by the first call and disconnected when MakeItRun
+// terminates.
+func (sdk *AppFunctionsSDK) PublishEventToMessageBus(event interface{}, topic string) error {
+	if event == nil {
+		return errors.New("Event to publish must be specified")
+	}
+	if topic == "" {
+		topic = sdk.config.Binding.PublishTopic
+	}
+	if topic == "" {
+		return errors.New("Topic must be specified when Binding PublishTopic isn't set")
+	}
+
+	payload, ok := event.([]byte)
+	if !ok {
+		var err error
+		payload, err = json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("unable to marshal event to publish: %s", err.Error())
+		}
+	}
+
+	client, err := sdk.getPublishClient()
+	if err != nil {
+		return err
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: common.NewCorrelationID(),
+		Payload:       payload,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	if err := client.Publish(envelope, topic); err != nil {
+		return fmt.Errorf("unable to publish event to topic '%s': %s", topic, err.Error())
+	}
+
+	sdk.LoggingClient.Trace("Published event to bus", "topic", topic, clients.CorrelationHeader, envelope.CorrelationID)
+	return nil
+}
+
+// getPublishClient returns the client used by PublishEventToMessageBus, connecting it on first use
+func (sdk *AppFunctionsSDK) getPublishClient() (messaging.MessageClient, error) {
+	sdk.publishMutex.Lock()
+	defer sdk.publishMutex.Unlock()
+
+	if sdk.publishClient != nil {
+		return sdk.publishClient, nil
+	}
+	if sdk.publishClosed {
+		return nil, errors.New("Message bus connection is closed")
+	}
+
+	client, err := messaging.NewMessageClient(sdk.config.MessageBus)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.Connect(); err != nil {
+		return nil, err
+	}
+
+	sdk.publishClient = messagebus.NewLockedClient(client)
+	sdk.ownsPublishClient = true
+	return sdk.publishClient, nil
+}
+
+// setupPublishClient makes PublishEventToMessageBus use the connection of the message bus trigger, since a second
+// connection can't bind the PublishHost bound by the trigger, and registers the disconnection of the connection
+// created by PublishEventToMessageBus when MakeItRun terminates.
+func (sdk *AppFunctionsSDK) setupPublishClient(configuredTrigger trigger.Trigger) {
+	sdk.publishMutex.Lock()
+	if messageBusTrigger, ok := configuredTrigger.(*messagebus.Trigger); ok && sdk.publishClient == nil && messageBusTrigger.Client() != nil {
+		sdk.publishClient = messageBusTrigger.Client()
+	}
+	sdk.publishMutex.Unlock()
+
+	sdk.addCleanupFunc("message bus publisher", func() {
+		sdk.publishMutex.Lock()
+		defer sdk.publishMutex.Unlock()
+
+		if sdk.ownsPublishClient {
+			if err := sdk.publishClient.Disconnect(); err != nil {
+				sdk.LoggingClient.Error(fmt.Sprintf("Unable to disconnect the message bus publisher: %s", err.Error()))
+			}
+		}
+		sdk.publishClient = nil
+		sdk.ownsPublishClient = false
+		sdk.publishClosed = true
+	})
+}
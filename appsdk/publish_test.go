@@ -0,0 +1,126 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+type mockPublishClient struct {
+	mockSubscribeClient
+	messages   []types.MessageEnvelope
+	topics     []string
+	publishErr error
+}
+
+func (m *mockPublishClient) Publish(message types.MessageEnvelope, topic string) error {
+	m.messages = append(m.messages, message)
+	m.topics = append(m.topics, topic)
+	return m.publishErr
+}
+
+func TestPublishEventToMessageBus(t *testing.T) {
+	client := &mockPublishClient{}
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		publishClient: client,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{PublishTopic: "events"},
+		},
+	}
+
+	event := models.Event{Device: "thermostat1", Readings: []models.Reading{{Name: "temperature", Value: "99"}}}
+	err := sdk.PublishEventToMessageBus(event, "alerts")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.Len(t, client.messages, 1) {
+		t.Fatal()
+	}
+	assert.Equal(t, "alerts", client.topics[0])
+	assert.Equal(t, clients.ContentTypeJSON, client.messages[0].ContentType)
+	assert.NotEmpty(t, client.messages[0].CorrelationID)
+	published := models.Event{}
+	if !assert.NoError(t, json.Unmarshal(client.messages[0].Payload, &published)) {
+		t.Fatal()
+	}
+	assert.Equal(t, "thermostat1", published.Device)
+
+	// A []byte is published as is, to the PublishTopic when no topic is specified
+	err = sdk.PublishEventToMessageBus([]byte(`{"device":"raw"}`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "events", client.topics[1])
+	assert.Equal(t, `{"device":"raw"}`, string(client.messages[1].Payload))
+}
+
+func TestPublishEventToMessageBusErrors(t *testing.T) {
+	client := &mockPublishClient{publishErr: errors.New("not connected")}
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		publishClient: client,
+	}
+
+	err := sdk.PublishEventToMessageBus(nil, "alerts")
+	assert.EqualError(t, err, "Event to publish must be specified")
+
+	err = sdk.PublishEventToMessageBus(models.Event{}, "")
+	assert.EqualError(t, err, "Topic must be specified when Binding PublishTopic isn't set")
+
+	err = sdk.PublishEventToMessageBus(make(chan int), "alerts")
+	assert.Error(t, err)
+
+	err = sdk.PublishEventToMessageBus(models.Event{}, "alerts")
+	assert.EqualError(t, err, "unable to publish event to topic 'alerts': not connected")
+}
+
+func TestPublishEventToMessageBusDisconnect(t *testing.T) {
+	client := &mockPublishClient{}
+	sdk := AppFunctionsSDK{
+		LoggingClient:     lc,
+		publishClient:     client,
+		ownsPublishClient: true,
+	}
+	sdk.setupPublishClient(nil)
+
+	sdk.runCleanupFuncs()
+	assert.True(t, client.disconnected, "expected the connection created by the SDK to be disconnected")
+
+	err := sdk.PublishEventToMessageBus(models.Event{}, "alerts")
+	assert.EqualError(t, err, "Message bus connection is closed")
+}
+
+func TestPublishEventToMessageBusTriggerClient(t *testing.T) {
+	client := &mockPublishClient{}
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		publishClient: client,
+	}
+	sdk.setupPublishClient(nil)
+
+	// the connection of the trigger isn't disconnected by the SDK
+	sdk.runCleanupFuncs()
+	assert.False(t, client.disconnected)
+}
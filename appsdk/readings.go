@@ -0,0 +1,210 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	syscontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	coreTypes "github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+const (
+	readingsPageSize        = 10
+	defaultReadingsCacheTTL = 10 * time.Second
+	// maxReadingsCacheEntries bounds the number of time ranges cached
+	maxReadingsCacheEntries = 100
+)
+
+// GetReadingsByTimeRange returns all the readings of the device's resource created between start and end from
+// Core Data, following the pages of the response. The readings are cached briefly, so a burst of identical
+// queries results in a single set of requests. The returned slice is the caller's to modify. Requires the CoreData
+// client to be configured.
+func (sdk *AppFunctionsSDK) GetReadingsByTimeRange(deviceName string, resourceName string, start time.Time, end time.Time) ([]models.Reading, error) {
+	clientInfo, ok := sdk.config.Clients[common.CoreDataClientName]
+	if !ok {
+		return nil, errors.New("CoreData client is missing from configuration")
+	}
+	if end.Before(start) {
+		return nil, errors.New("Start of the time range must not be after its end")
+	}
+
+	startMillis := start.UnixNano() / int64(time.Millisecond)
+	endMillis := end.UnixNano() / int64(time.Millisecond)
+	cacheKey := fmt.Sprintf("%s/%s/%d/%d", deviceName, resourceName, startMillis, endMillis)
+	if readings, ok := sdk.readingsCache.get(cacheKey); ok {
+		return readings, nil
+	}
+
+	baseURL := clientInfo.Url() + clients.ApiReadingRoute + "/name/" + url.PathEscape(resourceName) +
+		"/device/" + url.PathEscape(deviceName) + "/"
+
+	// Core Data has no offset, so each page starts at the creation time of the last reading of the previous page,
+	// since the next readings may share it, and the readings already returned are skipped by ID. The limit of the
+	// page is raised by the number of readings sharing that creation time, so that a page is never filled by the
+	// readings already returned when more readings share a creation time than fit in a page.
+	readings := []models.Reading{}
+	seen := make(map[string]bool)
+	limit := readingsPageSize
+	for {
+		page, err := sdk.getReadingsPage(baseURL + strconv.FormatInt(startMillis, 10) + "/" +
+			strconv.FormatInt(endMillis, 10) + "/" + strconv.Itoa(limit))
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, reading := range page {
+			if reading.Created > startMillis {
+				startMillis = reading.Created
+			}
+			if reading.Id != "" {
+				if seen[reading.Id] {
+					continue
+				}
+				seen[reading.Id] = true
+			}
+			readings = append(readings, reading)
+			added++
+		}
+
+		if len(page) < limit || added == 0 {
+			break
+		}
+
+		sharingStart := 0
+		for _, reading := range page {
+			if reading.Created == startMillis {
+				sharingStart++
+			}
+		}
+		limit = sharingStart + readingsPageSize
+	}
+
+	sdk.readingsCache.store(cacheKey, readings)
+	return readings, nil
+}
+
+// getReadingsPage requests a page of readings from Core Data
+func (sdk *AppFunctionsSDK) getReadingsPage(readingsURL string) ([]models.Reading, error) {
+	request, err := nethttp.NewRequest(nethttp.MethodGet, readingsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout := sdk.getRequestTimeout(); timeout > 0 {
+		ctx, cancel := syscontext.WithTimeout(syscontext.Background(), timeout)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+
+	client := nethttp.Client{Timeout: time.Duration(sdk.config.Service.Timeout) * time.Millisecond}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, coreTypes.NewErrServiceClient(response.StatusCode, body)
+	}
+
+	page := []models.Reading{}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal readings from Core Data: %s", err.Error())
+	}
+	return page, nil
+}
+
+// readingsCache caches the readings retrieved by GetReadingsByTimeRange by device, resource and time range. The
+// expired entries are removed when readings are stored, and the entry expiring first is removed when the cache holds
+// maxReadingsCacheEntries. The readings are copied in and out of the cache, so callers may modify them.
+type readingsCache struct {
+	mutex     sync.Mutex
+	responses map[string]readingsCacheEntry
+}
+
+type readingsCacheEntry struct {
+	readings []models.Reading
+	expires  time.Time
+}
+
+func (cache *readingsCache) get(key string) ([]models.Reading, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cached, ok := cache.responses[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(cached.expires) {
+		delete(cache.responses, key)
+		return nil, false
+	}
+	return copyReadings(cached.readings), true
+}
+
+func (cache *readingsCache) store(key string, readings []models.Reading) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.responses == nil {
+		cache.responses = make(map[string]readingsCacheEntry)
+	}
+
+	now := time.Now()
+	for cachedKey, cached := range cache.responses {
+		if now.After(cached.expires) {
+			delete(cache.responses, cachedKey)
+		}
+	}
+
+	if _, ok := cache.responses[key]; !ok && len(cache.responses) >= maxReadingsCacheEntries {
+		var oldestKey string
+		var oldest time.Time
+		for cachedKey, cached := range cache.responses {
+			if oldestKey == "" || cached.expires.Before(oldest) {
+				oldestKey, oldest = cachedKey, cached.expires
+			}
+		}
+		delete(cache.responses, oldestKey)
+	}
+
+	cache.responses[key] = readingsCacheEntry{readings: copyReadings(readings), expires: now.Add(defaultReadingsCacheTTL)}
+}
+
+func copyReadings(readings []models.Reading) []models.Reading {
+	copied := make([]models.Reading, len(readings))
+	copy(copied, readings)
+	return copied
+}
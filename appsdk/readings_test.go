@@ -0,0 +1,204 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+func newReadingsTestSDK(t *testing.T, serverURL string) *AppFunctionsSDK {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(parsed.Port())
+
+	return &AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Clients: map[string]common.ClientInfo{
+				common.CoreDataClientName: {
+					Protocol: parsed.Scheme,
+					Host:     parsed.Hostname(),
+					Port:     port,
+				},
+			},
+		},
+	}
+}
+
+// newReadingsServer serves the stored readings created in the requested time range, up to the requested limit
+func newReadingsServer(t *testing.T, stored []models.Reading, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path)
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/reading/name/temperature/device/thermostat1/"), "/")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		start, _ := strconv.ParseInt(parts[0], 10, 64)
+		end, _ := strconv.ParseInt(parts[1], 10, 64)
+		limit, _ := strconv.Atoi(parts[2])
+
+		page := []models.Reading{}
+		for _, reading := range stored {
+			if reading.Created >= start && reading.Created <= end && len(page) < limit {
+				page = append(page, reading)
+			}
+		}
+		data, _ := json.Marshal(page)
+		w.Write(data)
+	}))
+}
+
+func TestGetReadingsByTimeRange(t *testing.T) {
+	stored := []models.Reading{}
+	for i := 1; i <= 25; i++ {
+		// Pairs of readings share their creation time, so some share it across pages
+		stored = append(stored, models.Reading{Id: strconv.Itoa(i), Name: "temperature", Device: "thermostat1",
+			Value: strconv.Itoa(i), Created: int64(1000 + i/2)})
+	}
+	var requests []string
+	ts := newReadingsServer(t, stored, &requests)
+	defer ts.Close()
+
+	sdk := newReadingsTestSDK(t, ts.URL)
+	start := time.Unix(1, 0)
+	end := time.Unix(2, 0)
+
+	readings, err := sdk.GetReadingsByTimeRange("thermostat1", "temperature", start, end)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.Len(t, readings, 25) {
+		t.Fatal()
+	}
+	for i, reading := range readings {
+		assert.Equal(t, strconv.Itoa(i+1), reading.Id)
+	}
+	assert.Equal(t, "/api/v1/reading/name/temperature/device/thermostat1/1000/2000/10", requests[0])
+	assert.True(t, len(requests) > 1, "expected the readings to be paginated")
+
+	// A repeated query is served from the cache
+	requestCount := len(requests)
+	readings, err = sdk.GetReadingsByTimeRange("thermostat1", "temperature", start, end)
+	assert.NoError(t, err)
+	assert.Len(t, readings, 25)
+	assert.Equal(t, requestCount, len(requests))
+}
+
+func TestGetReadingsByTimeRangeSharedCreated(t *testing.T) {
+	stored := []models.Reading{}
+	for i := 1; i <= 25; i++ {
+		// More readings share the creation time than fit in a page
+		stored = append(stored, models.Reading{Id: strconv.Itoa(i), Name: "temperature", Device: "thermostat1",
+			Value: strconv.Itoa(i), Created: 1500})
+	}
+	stored = append(stored, models.Reading{Id: "26", Name: "temperature", Device: "thermostat1", Value: "26", Created: 1600})
+	var requests []string
+	ts := newReadingsServer(t, stored, &requests)
+	defer ts.Close()
+
+	sdk := newReadingsTestSDK(t, ts.URL)
+	readings, err := sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(1, 0), time.Unix(2, 0))
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.Len(t, readings, 26, "expected no reading to be truncated") {
+		t.Fatal()
+	}
+	for i, reading := range readings {
+		assert.Equal(t, strconv.Itoa(i+1), reading.Id)
+	}
+}
+
+func TestGetReadingsByTimeRangeReturnsCopy(t *testing.T) {
+	stored := []models.Reading{{Id: "1", Name: "temperature", Device: "thermostat1", Value: "20", Created: 1500}}
+	var requests []string
+	ts := newReadingsServer(t, stored, &requests)
+	defer ts.Close()
+
+	sdk := newReadingsTestSDK(t, ts.URL)
+	readings, err := sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(1, 0), time.Unix(2, 0))
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	readings[0].Value = "modified"
+
+	readings, err = sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(1, 0), time.Unix(2, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "20", readings[0].Value, "expected the cached readings not to be modified")
+	assert.Len(t, requests, 1)
+}
+
+func TestReadingsCacheEviction(t *testing.T) {
+	cache := readingsCache{}
+	cache.store("expired", []models.Reading{{Id: "1"}})
+	cache.responses["expired"] = readingsCacheEntry{expires: time.Now().Add(-time.Second)}
+
+	for i := 0; i < maxReadingsCacheEntries+10; i++ {
+		cache.store(strconv.Itoa(i), []models.Reading{{Id: strconv.Itoa(i)}})
+	}
+
+	assert.Len(t, cache.responses, maxReadingsCacheEntries)
+	_, ok := cache.responses["expired"]
+	assert.False(t, ok, "expected the expired entry to be removed")
+	_, ok = cache.get(strconv.Itoa(maxReadingsCacheEntries + 9))
+	assert.True(t, ok, "expected the last stored entry to be cached")
+}
+
+func TestGetReadingsByTimeRangeEmpty(t *testing.T) {
+	var requests []string
+	ts := newReadingsServer(t, nil, &requests)
+	defer ts.Close()
+
+	sdk := newReadingsTestSDK(t, ts.URL)
+	readings, err := sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(1, 0), time.Unix(2, 0))
+	assert.NoError(t, err)
+	assert.Empty(t, readings)
+	assert.Len(t, requests, 1)
+}
+
+func TestGetReadingsByTimeRangeErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	sdk := newReadingsTestSDK(t, ts.URL)
+	_, err := sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(1, 0), time.Unix(2, 0))
+	assert.Error(t, err)
+
+	_, err = sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(2, 0), time.Unix(1, 0))
+	assert.EqualError(t, err, "Start of the time range must not be after its end")
+
+	sdk.config.Clients = map[string]common.ClientInfo{}
+	_, err = sdk.GetReadingsByTimeRange("thermostat1", "temperature", time.Unix(1, 0), time.Unix(2, 0))
+	assert.EqualError(t, err, "CoreData client is missing from configuration")
+}
@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EnableEventReplay reprocesses the objects stored for the appServiceKey which were last modified within the
+// time range. Each object's payload is passed thru the functions pipeline starting at the position it was stored
+// from, and the objects which are processed successfully are removed from the store. The replay is limited to
+// Writable.StoreAndForward.ReplayRPS objects per second. Must be called after MakeItRun has started the pipeline.
+func (sdk *AppFunctionsSDK) EnableEventReplay(from, to time.Time, appServiceKey string) error {
+	client, err := sdk.GetStoreClient()
+	if err != nil {
+		return err
+	}
+	if sdk.runtime == nil {
+		return errors.New("Functions pipeline is not running, MakeItRun must be called first")
+	}
+	if to.Before(from) {
+		return errors.New("Replay time range end must not be before its start")
+	}
+
+	objects, err := client.RetrieveFromStore(appServiceKey)
+	if err != nil {
+		return err
+	}
+
+	var throttle <-chan time.Time
+	if rps := sdk.config.Writable.StoreAndForward.ReplayRPS; rps > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	fromMillis := from.UnixNano() / int64(time.Millisecond)
+	toMillis := to.UnixNano() / int64(time.Millisecond)
+	replayed := 0
+	failed := 0
+
+	for _, object := range objects {
+		if object.LastModifiedAt < fromMillis || object.LastModifiedAt > toMillis {
+			continue
+		}
+
+		if throttle != nil && replayed+failed > 0 {
+			<-throttle
+		}
+
+		edgexcontext := sdk.newContext(sdk.config, object.CorrelationID)
+		edgexcontext.EventID = object.EventID
+		edgexcontext.EventChecksum = object.EventChecksum
+
+		if messageError := sdk.runtime.ExecuteFromPosition(edgexcontext, object.Payload, object.PipelinePosition); messageError != nil {
+			failed++
+			continue
+		}
+
+		if err := client.RemoveFromStore(object); err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Unable to remove replayed object %s from the store: %s", object.ID, err.Error()))
+			failed++
+			continue
+		}
+		replayed++
+	}
+
+	sdk.LoggingClient.Info(fmt.Sprintf("Replayed %d stored objects for '%s', %d failed", replayed, appServiceKey, failed))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d stored objects failed to replay", failed, replayed+failed)
+	}
+	return nil
+}
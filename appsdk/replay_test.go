@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+)
+
+func newReplaySDK(storeClient *mocks.StoreClient, transforms ...appcontext.AppFunction) *AppFunctionsSDK {
+	sdk := &AppFunctionsSDK{
+		LoggingClient: lc,
+		edgexClients:  common.EdgeXClients{LoggingClient: lc},
+		storeClient:   storeClient,
+		runtime:       &runtime.GolangRuntime{},
+	}
+	sdk.config.Writable.StoreAndForward.Enabled = true
+	sdk.runtime.SetTransforms(transforms)
+	return sdk
+}
+
+func TestEnableEventReplayNotRunning(t *testing.T) {
+	sdk := newReplaySDK(&mocks.StoreClient{})
+	sdk.runtime = nil
+
+	err := sdk.EnableEventReplay(time.Now().Add(-time.Hour), time.Now(), "key")
+	assert.EqualError(t, err, "Functions pipeline is not running, MakeItRun must be called first")
+}
+
+func TestEnableEventReplayInvalidRange(t *testing.T) {
+	sdk := newReplaySDK(&mocks.StoreClient{})
+
+	err := sdk.EnableEventReplay(time.Now(), time.Now().Add(-time.Hour), "key")
+	assert.EqualError(t, err, "Replay time range end must not be before its start")
+}
+
+func TestEnableEventReplay(t *testing.T) {
+	now := time.Now()
+	inRange := contracts.StoredObject{
+		ID:               "in-range",
+		AppServiceKey:    "key",
+		Payload:          []byte("in range"),
+		PipelinePosition: 1,
+		LastModifiedAt:   now.Add(-30*time.Minute).UnixNano() / int64(time.Millisecond),
+	}
+	outOfRange := contracts.StoredObject{
+		ID:             "out-of-range",
+		AppServiceKey:  "key",
+		Payload:        []byte("out of range"),
+		LastModifiedAt: now.Add(-2*time.Hour).UnixNano() / int64(time.Millisecond),
+	}
+
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{inRange, outOfRange}, nil)
+	storeClient.On("RemoveFromStore", mock.Anything).Return(nil)
+
+	var replayed []string
+	sdk := newReplaySDK(storeClient,
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			assert.Fail(t, "expected functions before the stored position not to be called")
+			return false, nil
+		},
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			replayed = append(replayed, string(params[0].([]byte)))
+			return true, nil
+		},
+	)
+	sdk.config.Writable.StoreAndForward.ReplayRPS = 100
+
+	err := sdk.EnableEventReplay(now.Add(-time.Hour), now, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in range"}, replayed)
+	storeClient.AssertCalled(t, "RemoveFromStore", inRange)
+	storeClient.AssertNotCalled(t, "RemoveFromStore", outOfRange)
+}
+
+func TestEnableEventReplayPipelineError(t *testing.T) {
+	now := time.Now()
+	object := contracts.StoredObject{
+		ID:             "failing",
+		AppServiceKey:  "key",
+		Payload:        []byte("failing"),
+		LastModifiedAt: now.UnixNano() / int64(time.Millisecond),
+	}
+
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{object}, nil)
+
+	sdk := newReplaySDK(storeClient,
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return false, errors.New("pipeline failed")
+		},
+	)
+
+	err := sdk.EnableEventReplay(now.Add(-time.Minute), now.Add(time.Minute), "key")
+	assert.EqualError(t, err, "1 of 1 stored objects failed to replay")
+	storeClient.AssertNotCalled(t, "RemoveFromStore", mock.Anything)
+}
@@ -21,11 +21,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	nethttp "net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"reflect"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -36,7 +39,12 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/config"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/logging"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/jwt"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/telemetry"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/trigger"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/trigger/http"
@@ -48,9 +56,11 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/command"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/notifications"
 	coreTypes "github.com/edgexfoundry/go-mod-core-contracts/clients/types"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
 	registryTypes "github.com/edgexfoundry/go-mod-registry/pkg/types"
 	"github.com/edgexfoundry/go-mod-registry/registry"
 )
@@ -58,6 +68,9 @@ import (
 // ProfileSuffixPlaceholder is used to create unique names for profiles
 const ProfileSuffixPlaceholder = "<profile>"
 
+// maxRateLimitedClients is the number of clients whose rate limits are kept by EnableRateLimiting
+const maxRateLimitedClients = 10000
+
 // The key type is unexported to prevent collisions with context keys defined in
 // other packages.
 type key int
@@ -67,6 +80,10 @@ type key int
 // different integer values.
 const SDKKey key = 0
 
+// PipelineFunctionFactory creates a pipeline function from the parameters specified for it in the configurable
+// pipeline. The parameter names are all lowercase.
+type PipelineFunctionFactory func(parameters map[string]string) (appcontext.AppFunction, error)
+
 // AppFunctionsSDK provides the necessary struct to create an instance of the Application Functions SDK. Be sure and provide a ServiceKey
 // when creating an instance of the SDK. After creating an instance, you'll first want to call .Initialize(), to start up the SDK. Secondly,
 // provide the desired transforms for your pipeline by calling .SetFunctionsPipeline(). Lastly, call .MakeItRun() to start listening for events based on
@@ -81,8 +98,10 @@ type AppFunctionsSDK struct {
 	// except when &[]byte{} is specified. In this case the []byte data is pass to the first function in the Pipeline.
 	TargetType                interface{}
 	transforms                []appcontext.AppFunction
+	functionNames             []string
 	configProfile             string
 	configDir                 string
+	configFile                string
 	useRegistry               bool
 	usingConfigurablePipeline bool
 	httpErrors                chan error
@@ -90,7 +109,50 @@ type AppFunctionsSDK struct {
 	webserver                 *webserver.WebServer
 	edgexClients              common.EdgeXClients
 	registryClient            registry.Client
+	secretStoreClient         security.SecretStoreClient
+	storeClient               interfaces.StoreClient
+	functionFactories         map[string]PipelineFunctionFactory
+	functionPipelines         []runtime.FunctionPipeline
+	customTriggerBuilders     map[string]TriggerBuilder
+	triggerMiddleware         []TriggerMiddleware
+	deviceCache               *deviceCache
+	commandCache              *commandCache
+	readingsCache             readingsCache
+	publishClient             messaging.MessageClient
+	ownsPublishClient         bool
+	publishClosed             bool
+	publishMutex              sync.Mutex
+	topicSubscriptions        []topicSubscription
+	maxConcurrentExecutions   int
+	messageQueueDepth         int
+	autoRestartEnabled        bool
+	maxRestarts               int
+	restartCooldown           time.Duration
+	customMetrics             customMetrics
+	metricsMutex              sync.RWMutex
+	triggerAuthenticators     triggerAuthenticators
+	rateLimiter               *http.RateLimiter
 	config                    common.ConfigurationStruct
+	startTime                 time.Time
+	persistOnError            func(err error) bool
+	pipelineErrorHandler      PipelineErrorHandler
+	deadLetterTopic           string
+	requestTimeout            time.Duration
+	requestTimeoutSet         bool
+	logFormat                 string
+	cleanupFuncs              []cleanupFunc
+	configWatch               configWatch
+}
+
+// PipelineErrorHandler handles the error returned by a pipeline function which stops the pipeline. It is passed
+// the stage name of the function, see SetFunctionNames, its index in the pipeline, the error and the data the
+// function received. A panic of the handler is recovered and logged.
+type PipelineErrorHandler func(fn string, stageIndex int, err error, event interface{})
+
+// cleanupFunc is a named shutdown hook registered with RegisterCleanupFunc
+type cleanupFunc struct {
+	name string
+	fn   func()
 }
 
 // AddRoute allows you to leverage the existing webserver to add routes.
@@ -99,7 +161,14 @@ func (sdk *AppFunctionsSDK) AddRoute(route string, handler func(nethttp.Response
 		route == clients.ApiConfigRoute ||
 		route == clients.ApiMetricsRoute ||
 		route == clients.ApiVersionRoute ||
-		route == internal.ApiTriggerRoute {
+		route == internal.ApiTriggerRoute ||
+		strings.HasPrefix(route, internal.ApiTriggerRoute+"/") ||
+		route == internal.ApiLogLevelRoute ||
+		route == internal.ApiMetricsResetRoute ||
+		route == internal.ApiStoreForwardQueueRoute ||
+		route == internal.ApiConfigExportRoute ||
+		route == internal.ApiPrometheusMetricsRoute ||
+		strings.HasPrefix(route, internal.ApiProfilingRoute) {
 		return errors.New("Route is reserved")
 	}
 	sdk.webserver.AddRoute(route, sdk.addContext(handler), methods...)
@@ -112,24 +181,150 @@ func (sdk *AppFunctionsSDK) addContext(next func(nethttp.ResponseWriter, *nethtt
 	})
 }
 
+// EnableProfiling mounts the Go pprof routes under /debug/pprof/ on the SDK's webserver.
+// Service.ProfilingEnabled must be set to true in the configuration, otherwise an error is returned.
+// The profiling routes are not authenticated and expose details about the running process, such as
+// the command line, goroutine stacks and heap contents, so only enable profiling when the service
+// port is not reachable from untrusted networks.
+func (sdk *AppFunctionsSDK) EnableProfiling() error {
+	if !sdk.config.Service.ProfilingEnabled {
+		return errors.New("Profiling is not enabled in the Service configuration")
+	}
+
+	sdk.webserver.ConfigureProfilingRoutes()
+	return nil
+}
+
+// EnableCORS allows browser clients, i.e. dashboards, from the origin to call the service's routes, by setting the
+// Access-Control-Allow-Origin, Access-Control-Allow-Methods and Access-Control-Allow-Headers headers on all the
+// responses and responding to the OPTIONS preflight requests. The origin is "*" for any origin, or a comma
+// separated list of origins such as "https://dashboard.example.com:8443". The methods and headers are comma
+// separated lists, i.e. "GET, POST". An error is returned, and CORS is not enabled, when the origin is empty or
+// isn't "*" or a scheme and host without a path. Must be called after Initialize and before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableCORS(origin string, methods string, headers string) error {
+	if strings.TrimSpace(strings.Replace(origin, ",", "", -1)) == "" {
+		return errors.New("CORS origin must be specified")
+	}
+
+	for _, allowed := range strings.Split(origin, ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "" || allowed == "*" {
+			continue
+		}
+		parsed, err := url.Parse(allowed)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.User != nil || parsed.Path != "" ||
+			parsed.RawQuery != "" || parsed.Fragment != "" {
+			return fmt.Errorf("'%s' is not a valid CORS origin, it must be '*' or a scheme and host, i.e. https://dashboard.example.com", allowed)
+		}
+	}
+
+	sdk.webserver.ConfigureCORS(origin, methods, headers)
+	return nil
+}
+
+// EnableGzipCompression compresses the webserver's responses with gzip, or deflate, for the requests accepting it in
+// their Accept-Encoding header. Only the responses with a Content-Type of application/json, text/plain or
+// application/xml are compressed. Must be called after Initialize and before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableGzipCompression() {
+	sdk.webserver.ConfigureCompression()
+}
+
+// EnableETagCaching sets an ETag header on the responses of the GET /api/v1/config and /api/v1/metrics routes, so
+// polling clients and proxies sending it back in an If-None-Match header get a 304 without a body while the response
+// is unchanged. Must be called after Initialize and before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableETagCaching() {
+	sdk.webserver.ConfigureETagCaching()
+}
+
+// EnableRequestLogging logs an access log entry with the logger for every request to the webserver, holding the
+// method, path, status code, response size, latency and X-Correlation-ID header of the request. The sdk's
+// LoggingClient is used when logger is nil. The entries are logged at the INFO level. Must be called after
+// Initialize and before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableRequestLogging(logger logger.LoggingClient) {
+	if logger == nil {
+		logger = sdk.LoggingClient
+	}
+	sdk.webserver.ConfigureRequestLogging(logger)
+}
+
 // MakeItRun will initialize and start the trigger as specifed in the
 // configuration. It will also configure the webserver and start listening on
 // the specified port.
 func (sdk *AppFunctionsSDK) MakeItRun() error {
+	if sdk.ServiceKey == "" {
+		if err := sdk.RequireServiceKey(sdk.ServiceKey); err != nil {
+			sdk.LoggingClient.Error(err.Error())
+			return err
+		}
+	}
+
+	if err := sdk.ValidateConfiguration(); err != nil {
+		sdk.LoggingClient.Error(err.Error())
+		return err
+	}
+
 	httpErrors := make(chan error)
 	defer close(httpErrors)
 
 	sdk.runtime = &runtime.GolangRuntime{TargetType: sdk.TargetType} //Transforms: sdk.transforms
 	sdk.runtime.SetTransforms(sdk.transforms)
+	sdk.runtime.SetFunctionNames(sdk.functionNames)
+	if sdk.maxConcurrentExecutions > 0 {
+		sdk.runtime.SetMaxConcurrentExecutions(sdk.maxConcurrentExecutions)
+	}
+	if sdk.messageQueueDepth > 0 {
+		sdk.runtime.SetMessageQueueDepth(sdk.messageQueueDepth)
+	}
+	restartErrors := make(chan error, 1)
+	if sdk.autoRestartEnabled {
+		sdk.runtime.EnableAutoRestart(sdk.maxRestarts, sdk.restartCooldown, func(err error) {
+			select {
+			case restartErrors <- err:
+			default:
+			}
+		})
+	}
+	if sdk.storeClient != nil {
+		sdk.runtime.StoreForward = runtime.StoreForward{
+			StoreClient:    sdk.storeClient,
+			ServiceKey:     sdk.ServiceKey,
+			PersistOnError: sdk.persistOnError,
+		}
+	}
+	if sdk.pipelineErrorHandler != nil {
+		sdk.runtime.ErrorHandler = runtime.ErrorHandler(sdk.pipelineErrorHandler)
+	}
+	for _, pipeline := range sdk.functionPipelines {
+		if err := sdk.runtime.AddFunctionPipeline(pipeline); err != nil {
+			return err
+		}
+	}
+	sdk.setupTriggerMiddleware()
 
 	// determine input type and create trigger for it
 	trigger := sdk.setupTrigger(sdk.config, sdk.runtime)
+	if trigger == nil {
+		return fmt.Errorf("Unable to create trigger for Binding Type '%s'", sdk.config.Binding.Type)
+	}
 
 	// Initialize the trigger (i.e. start a web server, or connect to message bus)
 	err := trigger.Initialize()
 	if err != nil {
 		sdk.LoggingClient.Error(err.Error())
 	}
+	sdk.setupPublishClient(trigger)
+	if messageBusTrigger, ok := trigger.(*messagebus.Trigger); ok {
+		sdk.addCleanupFunc("topic filter subscriptions", func() {
+			if err := messageBusTrigger.Close(); err != nil {
+				sdk.LoggingClient.Error(fmt.Sprintf("Unable to close the topic filter subscriptions: %s", err.Error()))
+			}
+		})
+	}
+
+	if err := sdk.startTopicSubscriptions(); err != nil {
+		sdk.LoggingClient.Error(err.Error())
+		return err
+	}
 
 	sdk.LoggingClient.Info(sdk.config.Service.StartupMsg)
 
@@ -141,16 +336,89 @@ func (sdk *AppFunctionsSDK) MakeItRun() error {
 	select {
 	case httpError := <-sdk.httpErrors:
 		sdk.LoggingClient.Info("Terminating: ", httpError.Error())
+		sdk.drainPipelines()
+		sdk.runCleanupFuncs()
 		return httpError
 
+	case restartError := <-restartErrors:
+		sdk.LoggingClient.Error("Terminating: " + restartError.Error())
+		sdk.drainPipelines()
+		sdk.runCleanupFuncs()
+		return restartError
+
 	case signalReceived := <-signals:
 		sdk.LoggingClient.Info("Terminating: " + signalReceived.String())
+		sdk.drainPipelines()
+		sdk.runCleanupFuncs()
+	}
+
+	return nil
+}
 
+// RegisterCleanupFunc registers a function which is called when MakeItRun terminates, i.e. to close files or
+// flush buffers. The functions are called in the reverse order of their registration. Must be called before
+// MakeItRun.
+func (sdk *AppFunctionsSDK) RegisterCleanupFunc(name string, fn func()) error {
+	if sdk.runtime != nil {
+		return errors.New("Cleanup functions must be registered before MakeItRun is called")
+	}
+	if name == "" || fn == nil {
+		return errors.New("Cleanup function name and function must be specified")
 	}
 
+	sdk.addCleanupFunc(name, fn)
 	return nil
 }
 
+// addCleanupFunc registers a cleanup function for a connection opened by the SDK itself, which is closed when
+// MakeItRun terminates.
+func (sdk *AppFunctionsSDK) addCleanupFunc(name string, fn func()) {
+	sdk.cleanupFuncs = append(sdk.cleanupFuncs, cleanupFunc{name: name, fn: fn})
+}
+
+// runCleanupFuncs calls the cleanup functions, last registered first. A cleanup function which panics is logged
+// and doesn't prevent the others from being called.
+func (sdk *AppFunctionsSDK) runCleanupFuncs() {
+	for index := len(sdk.cleanupFuncs) - 1; index >= 0; index-- {
+		cleanup := sdk.cleanupFuncs[index]
+		sdk.LoggingClient.Info(fmt.Sprintf("Running cleanup function '%s'", cleanup.name))
+
+		start := time.Now()
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					sdk.LoggingClient.Error(fmt.Sprintf("Cleanup function '%s' panicked: %v", cleanup.name, recovered))
+				}
+			}()
+			cleanup.fn()
+		}()
+
+		sdk.LoggingClient.Info(fmt.Sprintf("Cleanup function '%s' completed in %s", cleanup.name, time.Since(start)))
+	}
+}
+
+// drainPipelines stops the runtime from processing new messages and waits up to the configured DrainTimeout for
+// the running pipelines to complete, after which their contexts are cancelled
+func (sdk *AppFunctionsSDK) drainPipelines() {
+	if sdk.config.Service.DrainTimeout == "" {
+		return
+	}
+
+	timeout, err := time.ParseDuration(sdk.config.Service.DrainTimeout)
+	if err != nil {
+		sdk.LoggingClient.Error(fmt.Sprintf("Invalid DrainTimeout '%s', running pipelines will not be waited for: %s",
+			sdk.config.Service.DrainTimeout, err.Error()))
+		return
+	}
+
+	sdk.LoggingClient.Info(fmt.Sprintf("Waiting up to %s for running pipelines to complete", timeout))
+	if !sdk.runtime.Drain(timeout) {
+		sdk.LoggingClient.Warn(fmt.Sprintf("Running pipelines did not complete within %s and were cancelled", timeout))
+		return
+	}
+	sdk.LoggingClient.Info("Running pipelines completed")
+}
+
 // LoadConfigurablePipeline ...
 func (sdk *AppFunctionsSDK) LoadConfigurablePipeline() ([]appcontext.AppFunction, error) {
 	var pipeline []appcontext.AppFunction
@@ -181,6 +449,29 @@ func (sdk *AppFunctionsSDK) LoadConfigurablePipeline() ([]appcontext.AppFunction
 			return nil, fmt.Errorf("Function %s configuration not found in Pipeline.Functions section", functionName)
 		}
 
+		//set keys to be all lowercase to avoid casing issues from configuration
+		for key := range configuration.Parameters {
+			configuration.Parameters[strings.ToLower(key)] = configuration.Parameters[key]
+		}
+
+		// Name is optional and allows the same function to be configured multiple times under different sections
+		if name := strings.TrimSpace(configuration.Name); name != "" {
+			functionName = name
+		}
+
+		if factory, ok := sdk.functionFactories[functionName]; ok {
+			function, err := factory(configuration.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("Function %s could not be created: %v", functionName, err)
+			}
+			if function == nil {
+				return nil, fmt.Errorf("Invalid/Missing configuration for %s", functionName)
+			}
+			pipeline = append(pipeline, function)
+			sdk.LoggingClient.Debug(fmt.Sprintf("%s registered function added to configurable pipeline", functionName))
+			continue
+		}
+
 		result := valueOfType.MethodByName(functionName)
 		if result.Kind() == reflect.Invalid {
 			return nil, fmt.Errorf("Function %s is not a built in SDK function", functionName)
@@ -190,10 +481,6 @@ func (sdk *AppFunctionsSDK) LoadConfigurablePipeline() ([]appcontext.AppFunction
 
 		//determine number of parameters required for function call
 		inputParameters := make([]reflect.Value, result.Type().NumIn())
-		//set keys to be all lowercase to avoid casing issues from configuration
-		for key := range configuration.Parameters {
-			configuration.Parameters[strings.ToLower(key)] = configuration.Parameters[key]
-		}
 		for index := range inputParameters {
 			parameter := result.Type().In(index)
 
@@ -220,6 +507,88 @@ func (sdk *AppFunctionsSDK) LoadConfigurablePipeline() ([]appcontext.AppFunction
 	return pipeline, nil
 }
 
+// AddFunctionPipelineForTopics adds a functions pipeline, identified by id, that is executed for the messages received
+// on the topics matching the topic filter when using the MessageBus trigger. The topic filter supports the MQTT style
+// wildcards '+' and '#'. Every pipeline whose topic filter covers the topic receives the message, in addition to the
+// pipeline set by SetFunctionsPipeline for the Binding's SubscribeTopic. Each topic received is checked against the
+// whole topic filter, so 'events/+/temp' receives 'events/a/temp' but not 'events/a/b', and 'events/#' receives
+// 'events' and all its sub-topics. The subscriptions are closed when MakeItRun terminates.
+// Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) AddFunctionPipelineForTopics(id string, topicFilter string, transforms ...appcontext.AppFunction) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("Function pipeline id must be specified")
+	}
+	if len(transforms) == 0 {
+		return errors.New("No transforms provided to pipeline")
+	}
+	if err := messagebus.ValidateTopicFilter(topicFilter); err != nil {
+		return fmt.Errorf("Invalid topic filter for function pipeline %s: %v", id, err)
+	}
+
+	for _, pipeline := range sdk.functionPipelines {
+		if pipeline.Id == id {
+			return fmt.Errorf("Function pipeline %s already exists", id)
+		}
+	}
+
+	sdk.functionPipelines = append(sdk.functionPipelines, runtime.FunctionPipeline{
+		Id:          id,
+		TopicFilter: topicFilter,
+		Transforms:  transforms,
+	})
+
+	return nil
+}
+
+// AddTriggerRoute adds a functions pipeline that is executed, instead of the pipeline set by SetFunctionsPipeline,
+// for the requests posted to /api/v1/trigger/<path> when using the HTTP trigger. Each path has its own pipeline.
+// Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) AddTriggerRoute(path string, transforms ...appcontext.AppFunction) error {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return errors.New("Trigger route path must be specified")
+	}
+	if len(transforms) == 0 {
+		return errors.New("No transforms provided to pipeline")
+	}
+
+	id := "trigger/" + path
+	for _, pipeline := range sdk.functionPipelines {
+		if pipeline.Id == id {
+			return fmt.Errorf("Trigger route %s already exists", path)
+		}
+	}
+
+	sdk.functionPipelines = append(sdk.functionPipelines, runtime.FunctionPipeline{
+		Id:         id,
+		Path:       path,
+		Transforms: transforms,
+	})
+
+	return nil
+}
+
+// RegisterFunctionFactory registers a factory for a custom function so that it can be used in the configurable
+// pipeline by specifying its name in the ExecutionOrder or as the Name of a function in the Pipeline.Functions section.
+func (sdk *AppFunctionsSDK) RegisterFunctionFactory(name string, factory PipelineFunctionFactory) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("Function name must be specified")
+	}
+	if factory == nil {
+		return fmt.Errorf("Function factory for %s must not be nil", name)
+	}
+	if reflect.ValueOf(AppFunctionsSDKConfigurable{}).MethodByName(name).IsValid() {
+		return fmt.Errorf("Function %s is a built in SDK function", name)
+	}
+
+	if sdk.functionFactories == nil {
+		sdk.functionFactories = make(map[string]PipelineFunctionFactory)
+	}
+	sdk.functionFactories[name] = factory
+	return nil
+}
+
 //
 // SetFunctionsPipeline allows you to define each fgitunction to execute and the order in which each function
 // will be called as each event comes in.
@@ -229,15 +598,466 @@ func (sdk *AppFunctionsSDK) SetFunctionsPipeline(transforms ...appcontext.AppFun
 	}
 
 	sdk.transforms = transforms
+	sdk.functionNames = nil
 
 	if sdk.runtime != nil {
 		sdk.runtime.SetTransforms(transforms)
+		sdk.runtime.SetFunctionNames(nil)
+		sdk.runtime.TargetType = sdk.TargetType
+	}
+
+	return nil
+}
+
+// PipelineFunction is a function of the functions pipeline
+type PipelineFunction = appcontext.AppFunction
+
+// RegisterNamedFunction appends the function to the functions pipeline under the specified name, which is used in
+// the log messages and traces of the pipeline and returned by GetPipelineFunctionNames. The names must be unique.
+// SetFunctionsPipeline replaces the functions pipeline, including the named functions.
+func (sdk *AppFunctionsSDK) RegisterNamedFunction(name string, fn PipelineFunction) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("Function name must be specified")
+	}
+	if fn == nil {
+		return fmt.Errorf("Pipeline function %s must not be nil", name)
+	}
+	for _, existing := range sdk.functionNames {
+		if existing == name {
+			return fmt.Errorf("Pipeline function %s already exists", name)
+		}
+	}
+
+	// Copy so that the pipeline used by the runtime, and the slice passed to SetFunctionsPipeline, aren't changed
+	transforms := make([]appcontext.AppFunction, len(sdk.transforms), len(sdk.transforms)+1)
+	copy(transforms, sdk.transforms)
+	names := make([]string, len(sdk.transforms), len(sdk.transforms)+1)
+	copy(names, sdk.functionNames)
+	sdk.transforms = append(transforms, fn)
+	sdk.functionNames = append(names, name)
+
+	if sdk.runtime != nil {
+		sdk.runtime.SetTransforms(sdk.transforms)
+		sdk.runtime.SetFunctionNames(sdk.functionNames)
 		sdk.runtime.TargetType = sdk.TargetType
 	}
 
 	return nil
 }
 
+// GetPipelineFunctionNames returns the names of the functions of the functions pipeline, in order. The functions
+// which weren't added with RegisterNamedFunction are named "stage_<index>".
+func (sdk *AppFunctionsSDK) GetPipelineFunctionNames() []string {
+	return runtime.StageNames(sdk.functionNames, len(sdk.transforms))
+}
+
+// SimplePipelineFunction is a pipeline function which receives only the data from the previous function, or the
+// received data for the first function in the pipeline.
+type SimplePipelineFunction func(ctx *appcontext.Context, data interface{}) (bool, interface{})
+
+// UseFunctionPipeline is the same as SetFunctionsPipeline, but for functions which only need the data passed to them.
+func (sdk *AppFunctionsSDK) UseFunctionPipeline(fns ...SimplePipelineFunction) error {
+	transforms := make([]appcontext.AppFunction, len(fns))
+	for index, fn := range fns {
+		if fn == nil {
+			return fmt.Errorf("Pipeline function #%d is nil", index)
+		}
+
+		fn := fn
+		transforms[index] = func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			if len(params) < 1 {
+				return false, errors.New("No Data Received")
+			}
+			return fn(edgexcontext, params[0])
+		}
+	}
+
+	return sdk.SetFunctionsPipeline(transforms...)
+}
+
+// EdgeXClients are the clients of the EdgeX services used by the SDK
+type EdgeXClients = common.EdgeXClients
+
+// GetEdgeXClients returns the clients of the EdgeX services created by Initialize from the Clients section of the
+// configuration, so that pipeline functions can call Core Data, Core Metadata, Core Command and Support
+// Notifications. The clients of services missing from the configuration are nil. When the Registry is used, the
+// clients resolve the endpoints of the services from the Registry.
+func (sdk *AppFunctionsSDK) GetEdgeXClients() EdgeXClients {
+	return sdk.edgexClients
+}
+
+// ErrServiceNotFound is returned by GetServiceEndpoint when the service isn't registered or isn't healthy, and by
+// GetServiceConfig when the service has no configuration in the Registry
+type ErrServiceNotFound struct {
+	ServiceKey string
+}
+
+func (e ErrServiceNotFound) Error() string {
+	return fmt.Sprintf("Service '%s' not found in the Registry", e.ServiceKey)
+}
+
+// GetServiceEndpoint returns the base URL, i.e. http://edgex-core-data:48080, of the EdgeX service with the
+// specified key from the Registry. ErrServiceNotFound is returned when the service isn't registered or the Registry
+// doesn't report it as healthy, any other error means the Registry couldn't be queried. Requires the Registry to be
+// used.
+func (sdk *AppFunctionsSDK) GetServiceEndpoint(serviceKey string) (string, error) {
+	if !sdk.useRegistry || sdk.registryClient == nil {
+		return "", errors.New("Registry is not enabled")
+	}
+
+	endpoint, err := sdk.registryClient.GetServiceEndpoint(serviceKey)
+	if err != nil {
+		return "", fmt.Errorf("Unable to get the endpoint of service '%s' from the Registry: %w", serviceKey, err)
+	}
+	if endpoint.Host == "" || endpoint.Port == 0 {
+		return "", ErrServiceNotFound{ServiceKey: serviceKey}
+	}
+
+	if err := sdk.registryClient.IsServiceAvailable(serviceKey); err != nil {
+		// the Registry client reports the failures to query the Registry as "unable to check ..."
+		if strings.HasPrefix(err.Error(), "unable to check") {
+			return "", fmt.Errorf("Unable to check the health of service '%s' in the Registry: %w", serviceKey, err)
+		}
+		sdk.LoggingClient.Debug(fmt.Sprintf("Service '%s' is not available: %s", serviceKey, err.Error()))
+		return "", ErrServiceNotFound{ServiceKey: serviceKey}
+	}
+
+	return fmt.Sprintf("http://%s:%d", endpoint.Host, endpoint.Port), nil
+}
+
+// GetSecretStore returns the secret store client used by the SDK, so that pipeline functions can read and
+// store secrets. An error is returned if the SecretStore section is missing from the configuration.
+func (sdk *AppFunctionsSDK) GetSecretStore() (security.SecretStoreClient, error) {
+	if sdk.secretStoreClient == nil {
+		return nil, errors.New("Secret Store is not configured")
+	}
+
+	return sdk.secretStoreClient, nil
+}
+
+// GetMultipleSecrets returns the secrets at each of the specified paths, relative to the secret store's
+// configured Path, keyed by path. The paths are retrieved concurrently, which avoids waiting for a round trip to
+// the secret store per path. An error is returned if any of the paths can't be retrieved.
+func (sdk *AppFunctionsSDK) GetMultipleSecrets(paths ...string) (map[string]map[string]string, error) {
+	secretStore, err := sdk.GetSecretStore()
+	if err != nil {
+		return nil, err
+	}
+
+	type pathSecrets struct {
+		path    string
+		secrets map[string]string
+		err     error
+	}
+
+	results := make(chan pathSecrets, len(paths))
+	for _, path := range paths {
+		go func(path string) {
+			secrets, err := secretStore.GetSecrets(path)
+			results <- pathSecrets{path: path, secrets: secrets, err: err}
+		}(path)
+	}
+
+	secrets := make(map[string]map[string]string, len(paths))
+	for range paths {
+		result := <-results
+		if result.err != nil && err == nil {
+			err = result.err
+		}
+		secrets[result.path] = result.secrets
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// ErrSecretStoreUnavailable is returned by ListSecretPaths when the secret store can't be reached
+var ErrSecretStoreUnavailable = security.ErrSecretStoreUnavailable
+
+// ListSecretPaths returns the paths of the secrets stored below the ServiceKey in the configured secret store,
+// relative to the ServiceKey, i.e. "credentials" for the secrets stored at "<ServiceKey>/credentials". Intended
+// for debugging the secret store configuration. ErrSecretStoreUnavailable is returned when the secret store
+// can't be reached.
+func (sdk *AppFunctionsSDK) ListSecretPaths() ([]string, error) {
+	secretStore, err := sdk.GetSecretStore()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	pending := []string{""}
+	for len(pending) > 0 {
+		relativePath := pending[0]
+		pending = pending[1:]
+
+		keys, err := secretStore.ListSecrets(sdk.ServiceKey + "/" + relativePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if strings.HasSuffix(key, "/") {
+				pending = append(pending, relativePath+key)
+			} else {
+				paths = append(paths, relativePath+key)
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// StoreSecret stores the secrets at the specified path in the configured secret store. The path is relative to
+// the secret store's configured Path and must start with the ServiceKey, i.e. "<ServiceKey>/credentials", so
+// that a service can't overwrite the secrets of other services. Empty and "." segments are removed from the path
+// and paths with ".." segments are rejected.
+func (sdk *AppFunctionsSDK) StoreSecret(path string, secrets map[string]string) error {
+	secretStore, err := sdk.GetSecretStore()
+	if err != nil {
+		return err
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return fmt.Errorf("Secret path '%s' must not contain '..'", path)
+		}
+		segments = append(segments, segment)
+	}
+
+	path = strings.Join(segments, "/")
+	if path != sdk.ServiceKey && !strings.HasPrefix(path, sdk.ServiceKey+"/") {
+		return fmt.Errorf("Secret path '%s' must start with the service key '%s'", path, sdk.ServiceKey)
+	}
+
+	return secretStore.StoreSecret(path, secrets)
+}
+
+// SetLogLevel changes the log level of the SDK's logger at runtime. The level is one of TRACE, DEBUG, INFO,
+// WARN or ERROR, an error is returned for any other level.
+func (sdk *AppFunctionsSDK) SetLogLevel(level string) error {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if !logger.IsValidLogLevel(level) {
+		return fmt.Errorf("Invalid log level '%s', must be one of TRACE, DEBUG, INFO, WARN or ERROR", level)
+	}
+
+	if err := sdk.LoggingClient.SetLogLevel(level); err != nil {
+		return err
+	}
+	sdk.config.Writable.LogLevel = level
+	sdk.LoggingClient.Info(fmt.Sprintf("Log level set to %s", level))
+
+	return nil
+}
+
+// SetCorrelationIDGenerator sets the generator of the correlation IDs created by the SDK for messages received
+// without one and for the events pushed to Core Data, i.e. to generate sortable UUID v7s. The default UUID v4
+// generator is used when gen is nil or returns an empty string.
+func (sdk *AppFunctionsSDK) SetCorrelationIDGenerator(gen func() string) {
+	common.SetCorrelationIDGenerator(gen)
+}
+
+// EnableStructuredLogging configures the SDK's logger to write machine-readable structured logs, with the
+// level, timestamp, service, correlation_id and message fields, in the json or logfmt format. Must be called
+// before Initialize.
+func (sdk *AppFunctionsSDK) EnableStructuredLogging(format string) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != logging.JSON && format != logging.Logfmt {
+		return fmt.Errorf("Invalid log format '%s', must be %s or %s", format, logging.JSON, logging.Logfmt)
+	}
+	if !sdk.startTime.IsZero() {
+		return errors.New("Structured logging must be enabled before Initialize is called")
+	}
+
+	sdk.logFormat = format
+	return nil
+}
+
+// SetPersistOnError sets the predicate which decides whether the data of a failed export is stored for later
+// retry when Store and Forward is enabled, based on the error returned by the export function. This allows
+// errors which will not succeed on retry, such as payload validation failures, not to be stored. All errors are
+// stored when no predicate is set. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SetPersistOnError(predicate func(err error) bool) {
+	sdk.persistOnError = predicate
+}
+
+// SetPipelineErrorHandler sets the handler which is called whenever a pipeline function stops the pipeline
+// with an error, in addition to the data being stored for later retry when Store and Forward is enabled. This
+// allows alerts to be raised, custom metrics to be incremented or the data to be sent to a dead-letter queue.
+// The handler is called in its own goroutine so it doesn't block the pipeline. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SetPipelineErrorHandler(handler PipelineErrorHandler) {
+	sdk.pipelineErrorHandler = handler
+}
+
+// DeadLetterEnvelope is the message published to the dead-letter topic set with SetDeadLetterTopic
+type DeadLetterEnvelope = common.DeadLetterEnvelope
+
+// SetDeadLetterTopic sets the topic on the message bus which the messages that fail to be processed by the
+// functions pipeline are published to, unless their data is stored for later retry by Store and Forward. The
+// message is published as a DeadLetterEnvelope, which carries the original message along with the topic it was
+// received on, the time and error of the failure and the number of attempts. It only applies to the MessageBus
+// trigger. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SetDeadLetterTopic(topic string) {
+	sdk.deadLetterTopic = topic
+}
+
+// GetStoreClient returns the store client used for Store and Forward, so that advanced users can perform
+// custom operations on the stored data. An error is returned if Store and Forward is not enabled.
+func (sdk *AppFunctionsSDK) GetStoreClient() (interfaces.StoreClient, error) {
+	if !sdk.config.Writable.StoreAndForward.Enabled {
+		return nil, errors.New("Store and Forward is not enabled")
+	}
+	if sdk.storeClient == nil {
+		return nil, errors.New("Store client is not initialized")
+	}
+
+	return sdk.storeClient, nil
+}
+
+// GetStoreAndForwardQueueDepth returns the number of objects stored for later retry by Store and Forward for
+// the service, or -1 when Store and Forward is not enabled. The depth is also returned by the
+// /api/v1/storeforward/queue route and in the X-StoreForward-Queue-Depth header of the ping response.
+func (sdk *AppFunctionsSDK) GetStoreAndForwardQueueDepth() (int, error) {
+	if !sdk.config.Writable.StoreAndForward.Enabled || sdk.storeClient == nil {
+		return -1, nil
+	}
+
+	return sdk.storeClient.Count(sdk.ServiceKey)
+}
+
+// SetRequestTimeout sets the timeout of the calls made by the SDK to the EdgeX services, such as Core Metadata by
+// GetDeviceByName, Core Command by IssueGetCommand and Core Data by MarkAsPushed and PushToCoreData. The timeout
+// defaults to 10 seconds, and a zero timeout allows the calls to wait without limit. Should be called before
+// MakeItRun. The clients returned by GetEdgeXClients aren't affected, their callers pass their own context.
+func (sdk *AppFunctionsSDK) SetRequestTimeout(d time.Duration) {
+	sdk.requestTimeout = d
+	sdk.requestTimeoutSet = true
+	if sdk.deviceCache != nil {
+		sdk.deviceCache.timeout = d
+	}
+}
+
+// SetMaxConcurrentPipelineExecutions limits the number of pipeline invocations running concurrently to n, to
+// prevent CPU and memory spikes under a high message bus throughput. While n pipelines are running, the message
+// bus trigger stops receiving messages, back-pressuring the subscription, and the HTTP trigger's requests wait.
+// The invocations aren't limited by default. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SetMaxConcurrentPipelineExecutions(n int) error {
+	if n < 1 {
+		return errors.New("Maximum concurrent pipeline executions must be at least 1")
+	}
+	if sdk.runtime != nil {
+		return errors.New("Maximum concurrent pipeline executions must be set before MakeItRun is called")
+	}
+
+	sdk.maxConcurrentExecutions = n
+	return nil
+}
+
+// SetMessageQueueDepth queues up to n messages received from the message bus while the pipelines fall behind, i.e.
+// while the limit set by SetMaxConcurrentPipelineExecutions is reached. While the queue is full, no further
+// messages are received from the subscription, back-pressuring the message bus. ZMQ has no NACK, so its messages
+// are then buffered by the publisher's socket up to its high water mark. The number of messages waiting in the
+// queue is the MessageQueueDepth of GetMetrics. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SetMessageQueueDepth(n int) error {
+	if n < 1 {
+		return errors.New("Message queue depth must be at least 1")
+	}
+	if sdk.runtime != nil {
+		return errors.New("Message queue depth must be set before MakeItRun is called")
+	}
+
+	sdk.messageQueueDepth = n
+	return nil
+}
+
+// EnableAutoRestart recovers the panics of the pipeline functions, which would otherwise crash the service. After
+// each panic, the stack trace is logged and the processing of the message pauses for the cooldown before it fails,
+// up to maxRestarts times. Once there are more than maxRestarts consecutive panics, each within the cooldown of the
+// previous restart, the service shuts down and MakeItRun returns an error. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableAutoRestart(maxRestarts int, cooldown time.Duration) {
+	sdk.autoRestartEnabled = true
+	sdk.maxRestarts = maxRestarts
+	sdk.restartCooldown = cooldown
+}
+
+// JWTAuthOptions are the claims checked by EnableJWTAuthWithOptions in addition to the signature and expiration
+type JWTAuthOptions struct {
+	// Issuer must be the iss claim of the token, not checked when empty
+	Issuer string
+	// Audience must be in the aud claim of the token, not checked when empty
+	Audience string
+	// RequiredScopes must all be in the scope claim of the token
+	RequiredScopes []string
+}
+
+// EnableJWTAuth authenticates the requests to the routes of the HTTP trigger with the JSON Web Token of their
+// Authorization: Bearer header, signed with one of the keys of the JWKS at jwksURL. The keys are cached and fetched
+// again hourly, or when a token is signed with an unknown key, to pick up rotated keys. The token must have an exp
+// claim. Requests with a missing, invalid or expired token are rejected with 401. Only applies to the HTTP trigger.
+// Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableJWTAuth(jwksURL string) {
+	sdk.EnableJWTAuthWithOptions(jwksURL, JWTAuthOptions{})
+}
+
+// EnableJWTAuthWithOptions authenticates the requests to the routes of the HTTP trigger like EnableJWTAuth, also
+// checking the issuer, audience and scopes of the token set in the options. Requests with a token of another
+// issuer or audience are rejected with 401, and requests with a valid token lacking one of the RequiredScopes in
+// its scope claim with 403.
+func (sdk *AppFunctionsSDK) EnableJWTAuthWithOptions(jwksURL string, options JWTAuthOptions) {
+	sdk.triggerAuthenticators = append(sdk.triggerAuthenticators, &jwt.Validator{
+		KeySet:         jwt.NewKeySet(jwksURL, sdk.getRequestTimeout()),
+		Issuer:         options.Issuer,
+		Audience:       options.Audience,
+		RequiredScopes: options.RequiredScopes,
+	})
+}
+
+// EnableRateLimiting limits the requests to the routes of the HTTP trigger to rps requests per second from each
+// client IP, allowing bursts of up to burst requests. Requests beyond the limit are rejected with 429 and a
+// Retry-After header. The limits of up to 10000 clients are kept, evicting the least recently seen clients beyond
+// that. An error is returned, and rate limiting is not enabled, when rps or burst isn't positive. Only applies to
+// the HTTP trigger. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableRateLimiting(rps float64, burst int) error {
+	if rps <= 0 {
+		return fmt.Errorf("Rate limit must be more than 0 requests per second, not %v", rps)
+	}
+	if burst < 1 {
+		return fmt.Errorf("Rate limit burst must be at least 1 request, not %d", burst)
+	}
+
+	sdk.rateLimiter = http.NewRateLimiter(rps, burst, maxRateLimitedClients)
+	return nil
+}
+
+// getRequestTimeout returns the timeout set with SetRequestTimeout, or DefaultRequestTimeout when it isn't set
+func (sdk *AppFunctionsSDK) getRequestTimeout() time.Duration {
+	if !sdk.requestTimeoutSet {
+		return common.DefaultRequestTimeout
+	}
+	return sdk.requestTimeout
+}
+
+// ExportConfiguration writes the effective configuration of the service, after the environment variable overlay
+// and the configuration from the Registry have been applied, to w in the "toml", "yaml" or "json" format. The
+// values of the SecretPath settings and of the settings whose name contains Password or Token are replaced by
+// "[REDACTED]". The configuration is also exported by the /api/v1/config/export?format=<format> route.
+func (sdk *AppFunctionsSDK) ExportConfiguration(w io.Writer, format string) error {
+	return common.ExportConfiguration(sdk.config, w, format)
+}
+
+// GetServiceKey returns the service key of the application service, i.e. to tag the metrics or log entries of the
+// pipeline functions. It is the key the service is registered with and the AppServiceKey of the objects stored for
+// Store and Forward, with the ProfileSuffixPlaceholder replaced once Initialize has been called.
+func (sdk *AppFunctionsSDK) GetServiceKey() string {
+	return sdk.ServiceKey
+}
+
 // ApplicationSettings returns the values specifed in the custom configuration section.
 func (sdk *AppFunctionsSDK) ApplicationSettings() map[string]string {
 	return sdk.config.ApplicationSettings
@@ -248,13 +1068,28 @@ func (sdk *AppFunctionsSDK) setupTrigger(configuration common.ConfigurationStruc
 	var trigger trigger.Trigger
 	// Need to make dynamic, search for the binding that is input
 
+	if builder, ok := sdk.customTriggerBuilders[strings.ToUpper(configuration.Binding.Type)]; ok {
+		sdk.LoggingClient.Info(fmt.Sprintf("Custom trigger '%s' selected", configuration.Binding.Type))
+		customTrigger, err := sdk.setupCustomTrigger(configuration, runtime, builder)
+		if err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Unable to create custom trigger '%s': %s", configuration.Binding.Type, err.Error()))
+			return nil
+		}
+		return customTrigger
+	}
+
 	switch strings.ToUpper(configuration.Binding.Type) {
 	case "HTTP":
 		sdk.LoggingClient.Info("HTTP trigger selected")
-		trigger = &http.Trigger{Configuration: configuration, Runtime: runtime, Webserver: sdk.webserver, EdgeXClients: sdk.edgexClients}
+		httpTrigger := &http.Trigger{Configuration: configuration, Runtime: runtime, Webserver: sdk.webserver, EdgeXClients: sdk.edgexClients, SecretStore: sdk.secretStoreClient, RequestTimeout: sdk.getRequestTimeout()}
+		if len(sdk.triggerAuthenticators) > 0 {
+			httpTrigger.Authenticator = sdk.triggerAuthenticators
+		}
+		httpTrigger.RateLimiter = sdk.rateLimiter
+		trigger = httpTrigger
 	case "MESSAGEBUS":
 		sdk.LoggingClient.Info("MessageBus trigger selected")
-		trigger = &messagebus.Trigger{Configuration: configuration, Runtime: runtime, EdgeXClients: sdk.edgexClients}
+		trigger = &messagebus.Trigger{Configuration: configuration, Runtime: runtime, EdgeXClients: sdk.edgexClients, SecretStore: sdk.secretStoreClient, DeadLetterTopic: sdk.deadLetterTopic, RequestTimeout: sdk.getRequestTimeout()}
 	}
 
 	return trigger
@@ -263,6 +1098,7 @@ func (sdk *AppFunctionsSDK) setupTrigger(configuration common.ConfigurationStruc
 // Initialize will parse command line flags, register for interrupts,
 // initialize the logging system, and ingest configuration.
 func (sdk *AppFunctionsSDK) Initialize() error {
+	sdk.startTime = time.Now()
 
 	flag.BoolVar(&sdk.useRegistry, "registry", false, "Indicates the service should use the registry.")
 	flag.BoolVar(&sdk.useRegistry, "r", false, "Indicates the service should use registry.")
@@ -273,6 +1109,9 @@ func (sdk *AppFunctionsSDK) Initialize() error {
 	flag.StringVar(&sdk.configDir, "confdir", "", "Specify an alternate configuration directory.")
 	flag.StringVar(&sdk.configDir, "c", "", "Specify an alternate configuration directory.")
 
+	flag.StringVar(&sdk.configFile, "file", internal.ConfigFileName, "Specify an alternate configuration file name, ending in .yaml or .yml for YAML or .json for JSON.")
+	flag.StringVar(&sdk.configFile, "f", internal.ConfigFileName, "Specify an alternate configuration file name, ending in .yaml or .yml for YAML or .json for JSON.")
+
 	flag.Parse()
 
 	// Service keys must be unique. If an executable is run multiple times, it must have a different
@@ -293,6 +1132,8 @@ func (sdk *AppFunctionsSDK) Initialize() error {
 
 	loggerInitialized := false
 	configurationInitialized := false
+	secretStoreInitialized := false
+	storeClientInitialized := false
 	bootstrapComplete := false
 
 	// Bootstrap retry loop to ensure all dependencies are ready before continuing.
@@ -300,6 +1141,11 @@ func (sdk *AppFunctionsSDK) Initialize() error {
 	for time.Now().Before(until) {
 		if !configurationInitialized {
 			err := sdk.initializeConfiguration()
+			if parseErr, ok := err.(common.ParseError); ok {
+				// a malformed configuration file won't be fixed by retrying
+				fmt.Printf("invalid configuration: %v\n", parseErr)
+				return parseErr
+			}
 			if err != nil {
 				fmt.Printf("failed to initialize Registry: %v\n", err)
 				goto ContinueWithSleep
@@ -315,12 +1161,34 @@ func (sdk *AppFunctionsSDK) Initialize() error {
 				goto ContinueWithSleep
 			}
 
-			sdk.LoggingClient = logger.NewClient(sdk.ServiceKey, sdk.config.Logging.EnableRemote, loggingTarget, sdk.config.Writable.LogLevel)
+			sdk.LoggingClient, err = sdk.newLoggingClient(loggingTarget)
+			if err != nil {
+				fmt.Printf("logger initialization failed: %v", err)
+				goto ContinueWithSleep
+			}
 			sdk.LoggingClient.Info("Configuration and logger successfully initialized")
 			sdk.edgexClients.LoggingClient = sdk.LoggingClient
 			loggerInitialized = true
 		}
 
+		if !secretStoreInitialized {
+			err := sdk.initializeSecretStore()
+			if err != nil {
+				sdk.LoggingClient.Error(fmt.Sprintf("failed to initialize Secret Store: %v", err))
+				goto ContinueWithSleep
+			}
+			secretStoreInitialized = true
+		}
+
+		if !storeClientInitialized {
+			err := sdk.initializeStoreClient()
+			if err != nil {
+				sdk.LoggingClient.Error(fmt.Sprintf("failed to initialize Store Client: %v", err))
+				goto ContinueWithSleep
+			}
+			storeClientInitialized = true
+		}
+
 		sdk.initializeClients()
 		sdk.LoggingClient.Info("Clients initialized")
 		bootstrapComplete = true
@@ -339,11 +1207,19 @@ func (sdk *AppFunctionsSDK) Initialize() error {
 	}
 
 	sdk.initializeClients()
+	if sdk.config.Service.PrewarmDeviceCache {
+		sdk.prewarmDeviceCache()
+	}
 
 	go telemetry.StartCpuUsageAverage()
 
 	sdk.webserver = webserver.NewWebServer(&sdk.config, sdk.LoggingClient, mux.NewRouter())
 	sdk.webserver.ConfigureStandardRoutes()
+	sdk.webserver.ConfigureLogLevelRoute(sdk.SetLogLevel)
+	sdk.webserver.ConfigureApplicationMetrics(func() interface{} { return sdk.GetMetrics() })
+	sdk.webserver.ConfigureMetricsResetRoute(sdk.ResetMetrics)
+	sdk.webserver.ConfigurePrometheusMetricsRoute(PrometheusContentType, sdk.WritePrometheusMetrics)
+	sdk.webserver.ConfigureStoreForwardQueueRoute(sdk.GetStoreAndForwardQueueDepth)
 
 	return nil
 }
@@ -352,6 +1228,13 @@ func (sdk *AppFunctionsSDK) initializeClients() {
 	// Need when passing all Clients to other components
 	sdk.edgexClients.LoggingClient = sdk.LoggingClient
 
+	// The EdgeX clients, and the other HTTP clients of the SDK, use the default transport
+	if transport, ok := nethttp.DefaultTransport.(*nethttp.Transport); ok {
+		if err := common.ConfigureTransport(transport, sdk.config.RESTClient); err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Invalid RESTClient configuration, using the defaults: %s", err.Error()))
+		}
+	}
+
 	// Use of these client interfaces is optional, so they are not required to be configured. For instance if not
 	// sending commands, then don't need to have the Command client in the configuration.
 	if _, ok := sdk.config.Clients[common.CoreDataClientName]; ok {
@@ -371,6 +1254,48 @@ func (sdk *AppFunctionsSDK) initializeClients() {
 		params := sdk.getClientParams(clients.SupportNotificationsServiceKey, common.NotificationsClientName, clients.ApiNotificationRoute)
 		sdk.edgexClients.NotificationsClient = notifications.NewNotificationsClient(params, startup.Endpoint{RegistryClient: &sdk.registryClient})
 	}
+
+	if _, ok := sdk.config.Clients[common.CoreMetadataClientName]; ok {
+		params := sdk.getClientParams(clients.CoreMetaDataServiceKey, common.CoreMetadataClientName, clients.ApiDeviceRoute)
+		sdk.edgexClients.DeviceClient = metadata.NewDeviceClient(params, startup.Endpoint{RegistryClient: &sdk.registryClient})
+		params = sdk.getClientParams(clients.CoreMetaDataServiceKey, common.CoreMetadataClientName, clients.ApiDeviceProfileRoute)
+		sdk.edgexClients.DeviceProfileClient = metadata.NewDeviceProfileClient(params, startup.Endpoint{RegistryClient: &sdk.registryClient})
+	}
+
+	sdk.initializeDeviceCache()
+	sdk.initializeCommandCache()
+}
+
+// initializeSecretStore creates the secret store client when a secret store is specified in the configuration.
+// Use of the secret store is optional, so it is not required to be configured.
+func (sdk *AppFunctionsSDK) initializeSecretStore() error {
+	if sdk.config.SecretStore.Type == "" {
+		return nil
+	}
+
+	client, err := security.NewSecretStoreClient(sdk.config.SecretStore)
+	if err != nil {
+		return err
+	}
+
+	sdk.secretStoreClient = client
+	sdk.LoggingClient.Info("Secret Store initialized")
+	return nil
+}
+
+func (sdk *AppFunctionsSDK) initializeStoreClient() error {
+	if !sdk.config.Writable.StoreAndForward.Enabled {
+		return nil
+	}
+
+	client, err := store.NewStoreClient(sdk.config.Database)
+	if err != nil {
+		return err
+	}
+
+	sdk.storeClient = client
+	sdk.LoggingClient.Info("Store Client initialized")
+	return nil
 }
 
 func (sdk *AppFunctionsSDK) getClientParams(serviceKey string, clientName string, route string) coreTypes.EndpointParams {
@@ -386,10 +1311,13 @@ func (sdk *AppFunctionsSDK) getClientParams(serviceKey string, clientName string
 func (sdk *AppFunctionsSDK) initializeConfiguration() error {
 
 	// Currently have to load configuration from filesystem first in order to obtain Registry Host/Port
-	configuration, err := common.LoadFromFile(sdk.configProfile, sdk.configDir)
+	configuration, err := common.LoadFromFile(sdk.configProfile, sdk.configDir, sdk.configFile)
 	if err != nil {
 		return err
 	}
+	if err := config.NewEnvironment().OverlayConfiguration(configuration); err != nil {
+		return fmt.Errorf("Unable to apply environment variables to configuration: %v", err)
+	}
 
 	if sdk.useRegistry {
 		e := config.NewEnvironment()
@@ -437,6 +1365,10 @@ func (sdk *AppFunctionsSDK) initializeConfiguration() error {
 				return fmt.Errorf("Configuration from Registry failed type check")
 			}
 			configuration = actual
+			// The environment variables also take precedence over the configuration in the Registry
+			if err := e.OverlayConfiguration(configuration); err != nil {
+				return fmt.Errorf("Unable to apply environment variables to configuration: %v", err)
+			}
 
 			//Check that information was successfully read from Consul
 			if configuration.Service.Port == 0 {
@@ -536,6 +1468,32 @@ func (sdk *AppFunctionsSDK) listenForConfigChanges() {
 
 }
 
+// newLoggingClient creates the structured logging client when structured logging is enabled, otherwise the
+// EdgeX logging client. Structured logs are written to stdout and the log file, remote logging isn't supported.
+func (sdk *AppFunctionsSDK) newLoggingClient(loggingTarget string) (logger.LoggingClient, error) {
+	if sdk.logFormat == "" {
+		return logger.NewClient(sdk.ServiceKey, sdk.config.Logging.EnableRemote, loggingTarget, sdk.config.Writable.LogLevel), nil
+	}
+
+	logFile := loggingTarget
+	if sdk.config.Logging.EnableRemote {
+		logFile = ""
+	}
+	writer, err := logging.NewWriter(logFile)
+	if err != nil {
+		return nil, err
+	}
+
+	loggingClient, err := logging.NewClient(sdk.ServiceKey, sdk.logFormat, sdk.config.Writable.LogLevel, writer)
+	if err != nil {
+		return nil, err
+	}
+	if sdk.config.Logging.EnableRemote {
+		loggingClient.Warn("Remote logging is not supported with structured logging, logging to stdout only")
+	}
+	return loggingClient, nil
+}
+
 func (sdk *AppFunctionsSDK) setLoggingTarget() (string, error) {
 	if sdk.config.Logging.EnableRemote {
 		logging, ok := sdk.config.Clients[common.LoggingClientName]
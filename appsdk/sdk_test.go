@@ -17,18 +17,35 @@
 package appsdk
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/mocks"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db"
+	storeMocks "github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
 	triggerHttp "github.com/edgexfoundry/app-functions-sdk-go/internal/trigger/http"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/trigger/messagebus"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/webserver"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	registryTypes "github.com/edgexfoundry/go-mod-registry/pkg/types"
+	"github.com/edgexfoundry/go-mod-registry/registry"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
@@ -63,6 +80,99 @@ func TestAddRoute(t *testing.T) {
 
 }
 
+func TestAddRouteReservedProfiling(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		webserver: webserver.NewWebServer(&common.ConfigurationStruct{}, lc, mux.NewRouter()),
+	}
+	err := sdk.AddRoute("/debug/pprof/heap", func(http.ResponseWriter, *http.Request) {}, "GET")
+	assert.Error(t, err, "Expected error for reserved route")
+}
+
+func TestAddRouteReservedStoreForwardQueue(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		webserver: webserver.NewWebServer(&common.ConfigurationStruct{}, lc, mux.NewRouter()),
+	}
+	err := sdk.AddRoute(internal.ApiStoreForwardQueueRoute, func(http.ResponseWriter, *http.Request) {}, "GET")
+	assert.Error(t, err, "Expected error for reserved route")
+}
+
+func TestAddRouteReservedConfigExport(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		webserver: webserver.NewWebServer(&common.ConfigurationStruct{}, lc, mux.NewRouter()),
+	}
+	err := sdk.AddRoute(internal.ApiConfigExportRoute, func(http.ResponseWriter, *http.Request) {}, "GET")
+	assert.Error(t, err, "Expected error for reserved route")
+}
+
+func TestAddRouteReservedPrometheusMetrics(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		webserver: webserver.NewWebServer(&common.ConfigurationStruct{}, lc, mux.NewRouter()),
+	}
+	err := sdk.AddRoute(internal.ApiPrometheusMetricsRoute, func(http.ResponseWriter, *http.Request) {}, "GET")
+	assert.Error(t, err, "Expected error for reserved route")
+}
+
+func TestEnableProfilingDisabled(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		webserver:     webserver.NewWebServer(&common.ConfigurationStruct{}, lc, mux.NewRouter()),
+	}
+	err := sdk.EnableProfiling()
+	assert.Error(t, err, "Expected error when profiling is not enabled in configuration")
+}
+
+func TestEnableProfiling(t *testing.T) {
+	router := mux.NewRouter()
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		webserver:     webserver.NewWebServer(&common.ConfigurationStruct{}, lc, router),
+		config: common.ConfigurationStruct{
+			Service: common.ServiceInfo{
+				ProfilingEnabled: true,
+			},
+		},
+	}
+	err := sdk.EnableProfiling()
+	assert.NoError(t, err, "Expected no error when profiling is enabled in configuration")
+
+	var match mux.RouteMatch
+	req, _ := http.NewRequest("GET", "/debug/pprof/heap", nil)
+	assert.True(t, router.Match(req, &match), "Expected profiling route to be registered")
+}
+
+func TestEnableCORS(t *testing.T) {
+	router := mux.NewRouter()
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		webserver:     webserver.NewWebServer(&common.ConfigurationStruct{}, lc, router),
+	}
+
+	tests := []struct {
+		name        string
+		origin      string
+		expectError bool
+	}{
+		{"Any origin", "*", false},
+		{"Origins", "https://dashboard.example.com:8443, http://localhost:3000", false},
+		{"Empty origin", "", true},
+		{"Only separators", " , ", true},
+		{"No scheme", "dashboard.example.com", true},
+		{"Path", "https://dashboard.example.com/", true},
+		{"Invalid origin in list", "https://dashboard.example.com, bogus", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sdk.EnableCORS(test.origin, "GET", "Content-Type")
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestSetupHTTPTrigger(t *testing.T) {
 	sdk := AppFunctionsSDK{
 		LoggingClient: lc,
@@ -78,6 +188,91 @@ func TestSetupHTTPTrigger(t *testing.T) {
 	result := IsInstanceOf(trigger, (*triggerHttp.Trigger)(nil))
 	assert.True(t, result, "Expected Instance of HTTP Trigger")
 }
+
+func TestSetupHTTPTriggerJWTAuth(t *testing.T) {
+	router := mux.NewRouter()
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{
+				Type: "http",
+			},
+		},
+		webserver:    webserver.NewWebServer(&common.ConfigurationStruct{}, lc, router),
+		edgexClients: common.EdgeXClients{LoggingClient: lc},
+	}
+	sdk.EnableJWTAuthWithOptions("http://localhost:1/jwks", JWTAuthOptions{
+		Issuer:         "https://auth.example.com",
+		Audience:       "edgex",
+		RequiredScopes: []string{"trigger"},
+	})
+
+	trigger := sdk.setupTrigger(sdk.config, &runtime.GolangRuntime{})
+	httpTrigger, ok := trigger.(*triggerHttp.Trigger)
+	if !assert.True(t, ok, "Expected Instance of HTTP Trigger") {
+		t.Fatal()
+	}
+	assert.Equal(t, sdk.triggerAuthenticators, httpTrigger.Authenticator)
+	if !assert.NoError(t, trigger.Initialize()) {
+		t.Fatal()
+	}
+
+	for _, route := range []string{internal.ApiTriggerRoute, internal.ApiTriggerRoute + "/orders"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, route, strings.NewReader("data")))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, route)
+		assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestSetupHTTPTriggerRateLimiting(t *testing.T) {
+	router := mux.NewRouter()
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{
+				Type: "http",
+			},
+		},
+		webserver:    webserver.NewWebServer(&common.ConfigurationStruct{}, lc, router),
+		edgexClients: common.EdgeXClients{LoggingClient: lc},
+	}
+	if !assert.NoError(t, sdk.EnableRateLimiting(0.001, 1)) {
+		t.Fatal()
+	}
+	sdk.EnableJWTAuth("http://localhost:1/jwks")
+
+	trigger := sdk.setupTrigger(sdk.config, &runtime.GolangRuntime{})
+	httpTrigger, ok := trigger.(*triggerHttp.Trigger)
+	if !assert.True(t, ok, "Expected Instance of HTTP Trigger") {
+		t.Fatal()
+	}
+	assert.Equal(t, sdk.rateLimiter, httpTrigger.RateLimiter)
+	if !assert.NoError(t, trigger.Initialize()) {
+		t.Fatal()
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, internal.ApiTriggerRoute, strings.NewReader("data")))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "first request should pass the rate limit")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, internal.ApiTriggerRoute, strings.NewReader("data")))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+}
+
+func TestEnableRateLimitingInvalid(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	assert.Error(t, sdk.EnableRateLimiting(0, 10))
+	assert.Nil(t, sdk.rateLimiter)
+	assert.Error(t, sdk.EnableRateLimiting(-1, 10))
+	assert.Nil(t, sdk.rateLimiter)
+	assert.Error(t, sdk.EnableRateLimiting(10, 0))
+	assert.Nil(t, sdk.rateLimiter)
+}
+
 func TestSetupMessageBusTrigger(t *testing.T) {
 	sdk := AppFunctionsSDK{
 		LoggingClient: lc,
@@ -93,6 +288,23 @@ func TestSetupMessageBusTrigger(t *testing.T) {
 	result := IsInstanceOf(trigger, (*messagebus.Trigger)(nil))
 	assert.True(t, result, "Expected Instance of Message Bus Trigger")
 }
+func TestSetupMessageBusTriggerDeadLetterTopic(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{
+				Type: "messagebus",
+			},
+		},
+	}
+	sdk.SetDeadLetterTopic("deadletter")
+	trigger := sdk.setupTrigger(sdk.config, &runtime.GolangRuntime{})
+	messageBusTrigger, ok := trigger.(*messagebus.Trigger)
+	if !assert.True(t, ok, "Expected Instance of Message Bus Trigger") {
+		t.Fatal()
+	}
+	assert.Equal(t, "deadletter", messageBusTrigger.DeadLetterTopic)
+}
 func TestSetFunctionsPipelineNoTransforms(t *testing.T) {
 	sdk := AppFunctionsSDK{
 		LoggingClient: lc,
@@ -124,6 +336,89 @@ func TestSetFunctionsPipelineOneTransform(t *testing.T) {
 	assert.Nil(t, err, "There should be no error")
 	assert.Equal(t, 1, len(sdk.transforms))
 }
+func TestRegisterNamedFunction(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		runtime:       &runtime.GolangRuntime{},
+	}
+	function := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, nil
+	}
+
+	err := sdk.SetFunctionsPipeline(function)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	err = sdk.RegisterNamedFunction("filter", function)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	err = sdk.RegisterNamedFunction(" export ", function)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, 3, len(sdk.transforms))
+	assert.Equal(t, []string{"stage_0", "filter", "export"}, sdk.GetPipelineFunctionNames())
+
+	err = sdk.RegisterNamedFunction("filter", function)
+	assert.EqualError(t, err, "Pipeline function filter already exists")
+	err = sdk.RegisterNamedFunction(" ", function)
+	assert.EqualError(t, err, "Function name must be specified")
+	err = sdk.RegisterNamedFunction("transform", nil)
+	assert.EqualError(t, err, "Pipeline function transform must not be nil")
+	assert.Equal(t, 3, len(sdk.transforms))
+
+	err = sdk.SetFunctionsPipeline(function, function)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, []string{"stage_0", "stage_1"}, sdk.GetPipelineFunctionNames())
+}
+
+func TestGetPipelineFunctionNamesNoPipeline(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	assert.Empty(t, sdk.GetPipelineFunctionNames())
+}
+
+func TestUseFunctionPipeline(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		runtime:       &runtime.GolangRuntime{},
+	}
+
+	var received interface{}
+	function := func(ctx *appcontext.Context, data interface{}) (bool, interface{}) {
+		received = data
+		return true, "result"
+	}
+
+	err := sdk.UseFunctionPipeline(function)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, 1, len(sdk.transforms))
+
+	continuePipeline, result := sdk.transforms[0](context, "data", "application/json")
+	assert.True(t, continuePipeline)
+	assert.Equal(t, "result", result)
+	assert.Equal(t, "data", received)
+
+	continuePipeline, result = sdk.transforms[0](context)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+
+	assert.EqualError(t, sdk.UseFunctionPipeline(), "No transforms provided to pipeline")
+	assert.EqualError(t, sdk.UseFunctionPipeline(function, nil), "Pipeline function #1 is nil")
+}
+func TestGetServiceKey(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		ServiceKey: "AppService-rules-engine",
+	}
+	assert.Equal(t, "AppService-rules-engine", sdk.GetServiceKey())
+}
+
 func TestApplicationSettings(t *testing.T) {
 	expectedSettingKey := "ApplicationName"
 	expectedSettingValue := "simple-filter-xml"
@@ -383,3 +678,559 @@ func TestInitializeClientsJustData(t *testing.T) {
 	assert.Nil(t, sdk.edgexClients.CommandClient)
 	assert.Nil(t, sdk.edgexClients.NotificationsClient)
 }
+
+func TestGetSecretStoreNotConfigured(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	err := sdk.initializeSecretStore()
+	assert.NoError(t, err, "Expected no error when Secret Store is not configured")
+
+	_, err = sdk.GetSecretStore()
+	assert.Error(t, err, "Expected error when Secret Store is not configured")
+}
+
+func TestGetSecretStoreUnsupported(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			SecretStore: common.SecretStoreInfo{
+				Type: "bogus",
+			},
+		},
+	}
+
+	err := sdk.initializeSecretStore()
+	assert.Error(t, err, "Expected error for unsupported Secret Store type")
+}
+
+func TestGetMultipleSecrets(t *testing.T) {
+	mqtt := map[string]string{"username": "edgex", "password": "mqtt"}
+	cloud := map[string]string{"apikey": "1234"}
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecrets", "mqtt").Return(mqtt, nil)
+	secretStore.On("GetSecrets", "cloud").Return(cloud, nil)
+	secretStore.On("GetSecrets", "bogus").Return(nil, errors.New("no secrets found at path 'bogus'"))
+
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	_, err := sdk.GetMultipleSecrets("mqtt")
+	assert.Error(t, err, "Expected error when Secret Store is not configured")
+
+	sdk.secretStoreClient = secretStore
+	secrets, err := sdk.GetMultipleSecrets("mqtt", "cloud")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"mqtt": mqtt, "cloud": cloud}, secrets)
+
+	_, err = sdk.GetMultipleSecrets("mqtt", "bogus")
+	assert.EqualError(t, err, "no secrets found at path 'bogus'")
+}
+
+func TestListSecretPaths(t *testing.T) {
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("ListSecrets", "myService/").Return([]string{"mqtt", "cloud/"}, nil)
+	secretStore.On("ListSecrets", "myService/cloud/").Return([]string{"aws", "azure"}, nil)
+
+	sdk := AppFunctionsSDK{
+		ServiceKey:    "myService",
+		LoggingClient: lc,
+	}
+	_, err := sdk.ListSecretPaths()
+	assert.Error(t, err, "Expected error when Secret Store is not configured")
+
+	sdk.secretStoreClient = secretStore
+	paths, err := sdk.ListSecretPaths()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mqtt", "cloud/aws", "cloud/azure"}, paths)
+
+	unavailable := &mocks.SecretStoreClient{}
+	unavailable.On("ListSecrets", "myService/").Return(nil, ErrSecretStoreUnavailable)
+	sdk.secretStoreClient = unavailable
+	_, err = sdk.ListSecretPaths()
+	assert.Equal(t, ErrSecretStoreUnavailable, err)
+}
+
+func TestStoreSecret(t *testing.T) {
+	secrets := map[string]string{"username": "admin"}
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("StoreSecret", "myService/credentials", secrets).Return(nil)
+
+	sdk := AppFunctionsSDK{
+		ServiceKey:    "myService",
+		LoggingClient: lc,
+	}
+	err := sdk.StoreSecret("myService/credentials", secrets)
+	assert.Error(t, err, "Expected error when Secret Store is not configured")
+
+	sdk.secretStoreClient = secretStore
+	err = sdk.StoreSecret("/myService/credentials/", secrets)
+	assert.NoError(t, err)
+	secretStore.AssertCalled(t, "StoreSecret", "myService/credentials", secrets)
+
+	err = sdk.StoreSecret("otherService/credentials", secrets)
+	assert.Error(t, err, "Expected error for path of another service")
+	err = sdk.StoreSecret("myServiceOther/credentials", secrets)
+	assert.Error(t, err, "Expected error for path not below the service key")
+	err = sdk.StoreSecret("myService/../otherService/credentials", secrets)
+	assert.Error(t, err, "Expected error for path leaving the service key")
+	err = sdk.StoreSecret("myService/..", secrets)
+	assert.Error(t, err, "Expected error for path leaving the service key")
+	secretStore.AssertNumberOfCalls(t, "StoreSecret", 1)
+
+	err = sdk.StoreSecret("./myService//./credentials", secrets)
+	assert.NoError(t, err)
+	secretStore.AssertNumberOfCalls(t, "StoreSecret", 2)
+}
+
+func TestGetStoreClientNotEnabled(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	err := sdk.initializeStoreClient()
+	assert.NoError(t, err, "Expected no error when Store and Forward is not enabled")
+
+	_, err = sdk.GetStoreClient()
+	assert.EqualError(t, err, "Store and Forward is not enabled")
+}
+
+func TestGetStoreClient(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	sdk.config.Writable.StoreAndForward.Enabled = true
+
+	_, err := sdk.GetStoreClient()
+	assert.EqualError(t, err, "Store client is not initialized")
+
+	sdk.config.Database.Type = "bogus"
+	err = sdk.initializeStoreClient()
+	assert.Error(t, err, "Expected error for unsupported Database type")
+
+	sdk.config.Database = db.DatabaseInfo{
+		Type:      db.RedisDB,
+		Host:      "localhost",
+		Port:      6379,
+		MaxIdle:   5000,
+		BatchSize: 1337,
+	}
+	err = sdk.initializeStoreClient()
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	client, err := sdk.GetStoreClient()
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestGetStoreAndForwardQueueDepth(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		ServiceKey:    "myService",
+	}
+
+	depth, err := sdk.GetStoreAndForwardQueueDepth()
+	assert.NoError(t, err)
+	assert.Equal(t, -1, depth, "Expected -1 when Store and Forward is not enabled")
+
+	storeClient := &storeMocks.StoreClient{}
+	storeClient.On("Count", "myService").Return(5, nil)
+	sdk.config.Writable.StoreAndForward.Enabled = true
+	sdk.storeClient = storeClient
+
+	depth, err = sdk.GetStoreAndForwardQueueDepth()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, depth)
+}
+
+func TestLoadConfigurablePipelineFunctionName(t *testing.T) {
+	functions := make(map[string]common.PipelineFunction)
+	functions["FilterSensors"] = common.PipelineFunction{
+		Name:       "FilterByDeviceName",
+		Parameters: map[string]string{"DeviceNames": "sensor1"},
+	}
+	functions["FilterActuators"] = common.PipelineFunction{
+		Name:       "FilterByDeviceName",
+		Parameters: map[string]string{"DeviceNames": "actuator1"},
+	}
+
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "FilterSensors, FilterActuators",
+					Functions:      functions,
+				},
+			},
+		},
+	}
+
+	appFunctions, err := sdk.LoadConfigurablePipeline()
+	assert.NoError(t, err, "")
+	assert.Equal(t, 2, len(appFunctions))
+}
+
+func TestLoadConfigurablePipelineRegisteredFunction(t *testing.T) {
+	var receivedParameters []map[string]string
+	factory := func(parameters map[string]string) (appcontext.AppFunction, error) {
+		receivedParameters = append(receivedParameters, parameters)
+		return func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return true, nil
+		}, nil
+	}
+
+	functions := make(map[string]common.PipelineFunction)
+	functions["MyFunction"] = common.PipelineFunction{
+		Parameters: map[string]string{"Threshold": "10"},
+	}
+	functions["MyOtherFunction"] = common.PipelineFunction{
+		Name: "MyFunction",
+	}
+
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "MyFunction, MyOtherFunction",
+					Functions:      functions,
+				},
+			},
+		},
+	}
+
+	err := sdk.RegisterFunctionFactory("MyFunction", factory)
+	assert.NoError(t, err, "")
+
+	appFunctions, err := sdk.LoadConfigurablePipeline()
+	assert.NoError(t, err, "")
+	assert.Equal(t, 2, len(appFunctions))
+	assert.Equal(t, 2, len(receivedParameters))
+	assert.Equal(t, "10", receivedParameters[0]["threshold"])
+}
+
+func TestLoadConfigurablePipelineFunctionSection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configuration")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	defer os.RemoveAll(dir)
+	contents := `
+[Writable.Pipeline]
+ExecutionOrder = "MyFilter"
+[Writable.Pipeline.Functions.MyFilter]
+Name = "FilterByDeviceName"
+Devices = ["sensor1"]
+`
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "configuration.toml"), []byte(contents), 0644)) {
+		t.Fatal()
+	}
+
+	configuration, err := common.LoadFromFile("", dir, "")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	sdk := AppFunctionsSDK{LoggingClient: lc, config: *configuration}
+
+	appFunctions, err := sdk.LoadConfigurablePipeline()
+	if !assert.NoError(t, err) || !assert.Equal(t, 1, len(appFunctions)) {
+		t.Fatal()
+	}
+
+	context := &appcontext.Context{LoggingClient: lc}
+	continuePipeline, result := appFunctions[0](context, models.Event{Device: "sensor1"})
+	assert.True(t, continuePipeline)
+	assert.Equal(t, models.Event{Device: "sensor1"}, result)
+	continuePipeline, _ = appFunctions[0](context, models.Event{Device: "sensor2"})
+	assert.False(t, continuePipeline, "expected the events of the other devices to be filtered out")
+}
+
+func TestLoadConfigurablePipelineRegisteredFunctionError(t *testing.T) {
+	factory := func(parameters map[string]string) (appcontext.AppFunction, error) {
+		return nil, errors.New("bad parameters")
+	}
+
+	functions := make(map[string]common.PipelineFunction)
+	functions["MyFunction"] = common.PipelineFunction{}
+
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Writable: common.WritableInfo{
+				Pipeline: common.PipelineInfo{
+					ExecutionOrder: "MyFunction",
+					Functions:      functions,
+				},
+			},
+		},
+	}
+
+	err := sdk.RegisterFunctionFactory("MyFunction", factory)
+	assert.NoError(t, err, "")
+
+	appFunctions, err := sdk.LoadConfigurablePipeline()
+	assert.Error(t, err, "expected error from factory")
+	assert.Equal(t, "Function MyFunction could not be created: bad parameters", err.Error())
+	assert.Nil(t, appFunctions, "expected app functions list to be nil")
+}
+
+func TestRegisterFunctionFactoryErrors(t *testing.T) {
+	factory := func(parameters map[string]string) (appcontext.AppFunction, error) {
+		return nil, nil
+	}
+	sdk := AppFunctionsSDK{}
+
+	assert.Error(t, sdk.RegisterFunctionFactory(" ", factory), "expected error for empty name")
+	assert.Error(t, sdk.RegisterFunctionFactory("MyFunction", nil), "expected error for nil factory")
+	assert.Error(t, sdk.RegisterFunctionFactory("TransformToXML", factory), "expected error for built in function")
+}
+
+func TestAddFunctionPipelineForTopics(t *testing.T) {
+	function := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, nil
+	}
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	err := sdk.AddFunctionPipelineForTopics("profile1", "edgex/events/device/+/profile1", function)
+	assert.NoError(t, err)
+	err = sdk.AddFunctionPipelineForTopics("all", "edgex/events/#", function)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(sdk.functionPipelines))
+
+	assert.Error(t, sdk.AddFunctionPipelineForTopics("all", "edgex/other", function), "expected error for duplicate id")
+	assert.Error(t, sdk.AddFunctionPipelineForTopics("", "edgex/other", function), "expected error for empty id")
+	assert.Error(t, sdk.AddFunctionPipelineForTopics("bad", "edgex/#/other", function), "expected error for invalid filter")
+	assert.Error(t, sdk.AddFunctionPipelineForTopics("none", "edgex/other"), "expected error for no transforms")
+}
+
+func TestAddTriggerRoute(t *testing.T) {
+	function := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, nil
+	}
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	err := sdk.AddTriggerRoute("/thermostats/", function)
+	assert.NoError(t, err)
+	err = sdk.AddTriggerRoute("pumps", function, function)
+	assert.NoError(t, err)
+	if !assert.Equal(t, 2, len(sdk.functionPipelines)) {
+		t.Fatal()
+	}
+	assert.Equal(t, "thermostats", sdk.functionPipelines[0].Path)
+	assert.Empty(t, sdk.functionPipelines[0].TopicFilter, "expected no topic filter for a trigger route")
+	assert.Equal(t, 2, len(sdk.functionPipelines[1].Transforms))
+
+	assert.Error(t, sdk.AddTriggerRoute("pumps", function), "expected error for duplicate path")
+	assert.Error(t, sdk.AddTriggerRoute(" / ", function), "expected error for empty path")
+	assert.Error(t, sdk.AddTriggerRoute("none"), "expected error for no transforms")
+}
+
+func TestSetLogLevel(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: logger.NewClient("app_functions_sdk_go", false, "./test.log", "INFO"),
+	}
+
+	assert.NoError(t, sdk.SetLogLevel("debug"))
+	assert.Equal(t, "DEBUG", sdk.config.Writable.LogLevel)
+
+	err := sdk.SetLogLevel("VERBOSE")
+	assert.EqualError(t, err, "Invalid log level 'VERBOSE', must be one of TRACE, DEBUG, INFO, WARN or ERROR")
+	assert.Equal(t, "DEBUG", sdk.config.Writable.LogLevel)
+}
+
+func TestGetEdgeXClients(t *testing.T) {
+	sdk := AppFunctionsSDK{}
+	assert.Nil(t, sdk.GetEdgeXClients().EventClient, "Expected no clients before Initialize")
+
+	sdk.config.Clients = map[string]common.ClientInfo{
+		common.CoreDataClientName: {Protocol: "http", Host: "localhost", Port: 48080},
+	}
+	sdk.LoggingClient = lc
+	sdk.initializeClients()
+
+	clients := sdk.GetEdgeXClients()
+	assert.NotNil(t, clients.EventClient)
+	assert.NotNil(t, clients.ValueDescriptorClient)
+	assert.Nil(t, clients.CommandClient, "Expected no client for a service missing from the configuration")
+	assert.Equal(t, lc, clients.LoggingClient)
+}
+
+// fakeRegistry implements the service discovery methods of registry.Client, the other methods are not used
+type fakeRegistry struct {
+	registry.Client
+	endpoints map[string]registryTypes.ServiceEndpoint
+	// endpointErr and availableErr are the errors of querying the Registry
+	endpointErr  error
+	availableErr error
+}
+
+func (r *fakeRegistry) IsServiceAvailable(serviceKey string) error {
+	if r.availableErr != nil {
+		return r.availableErr
+	}
+	if _, ok := r.endpoints[serviceKey]; !ok {
+		return errors.New("service is not registered")
+	}
+	return nil
+}
+
+func (r *fakeRegistry) GetServiceEndpoint(serviceKey string) (registryTypes.ServiceEndpoint, error) {
+	return r.endpoints[serviceKey], r.endpointErr
+}
+
+func TestGetServiceEndpoint(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	_, err := sdk.GetServiceEndpoint("edgex-core-data")
+	assert.EqualError(t, err, "Registry is not enabled")
+
+	sdk.useRegistry = true
+	sdk.registryClient = &fakeRegistry{endpoints: map[string]registryTypes.ServiceEndpoint{
+		"edgex-core-data":     {ServiceId: "edgex-core-data", Host: "edgex-core-data", Port: 48080},
+		"edgex-core-metadata": {ServiceId: "edgex-core-metadata"},
+	}}
+
+	endpoint, err := sdk.GetServiceEndpoint("edgex-core-data")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://edgex-core-data:48080", endpoint)
+
+	_, err = sdk.GetServiceEndpoint("edgex-core-command")
+	assert.Equal(t, ErrServiceNotFound{ServiceKey: "edgex-core-command"}, err)
+
+	_, err = sdk.GetServiceEndpoint("edgex-core-metadata")
+	assert.Equal(t, ErrServiceNotFound{ServiceKey: "edgex-core-metadata"}, err)
+}
+
+func TestGetServiceEndpointRegistryError(t *testing.T) {
+	failure := errors.New("connection refused")
+	registryClient := &fakeRegistry{
+		endpoints: map[string]registryTypes.ServiceEndpoint{
+			"edgex-core-data": {ServiceId: "edgex-core-data", Host: "edgex-core-data", Port: 48080},
+		},
+		endpointErr: failure,
+	}
+	sdk := AppFunctionsSDK{LoggingClient: lc, useRegistry: true, registryClient: registryClient}
+
+	_, err := sdk.GetServiceEndpoint("edgex-core-data")
+	assert.True(t, errors.Is(err, failure), "Expected the Registry error, not %v", err)
+
+	registryClient.endpointErr = nil
+	registryClient.availableErr = errors.New("unable to check health of service edgex-core-data: connection refused")
+	_, err = sdk.GetServiceEndpoint("edgex-core-data")
+	assert.True(t, errors.Is(err, registryClient.availableErr), "Expected the Registry error, not %v", err)
+
+	registryClient.availableErr = errors.New(" edgex-core-data service not healthy...")
+	_, err = sdk.GetServiceEndpoint("edgex-core-data")
+	assert.Equal(t, ErrServiceNotFound{ServiceKey: "edgex-core-data"}, err)
+}
+
+func TestRegisterCleanupFunc(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	var calls []string
+	assert.NoError(t, sdk.RegisterCleanupFunc("first", func() { calls = append(calls, "first") }))
+	assert.NoError(t, sdk.RegisterCleanupFunc("panics", func() { panic("cleanup failed") }))
+	assert.NoError(t, sdk.RegisterCleanupFunc("last", func() { calls = append(calls, "last") }))
+	assert.Error(t, sdk.RegisterCleanupFunc("", func() {}))
+	assert.Error(t, sdk.RegisterCleanupFunc("nil", nil))
+
+	sdk.runCleanupFuncs()
+	assert.Equal(t, []string{"last", "first"}, calls, "Expected the cleanup functions to be called in LIFO order")
+
+	sdk.runtime = &runtime.GolangRuntime{}
+	err := sdk.RegisterCleanupFunc("late", func() {})
+	assert.EqualError(t, err, "Cleanup functions must be registered before MakeItRun is called")
+}
+
+func TestSetCorrelationIDGenerator(t *testing.T) {
+	sdk := AppFunctionsSDK{}
+	defer sdk.SetCorrelationIDGenerator(nil)
+
+	sdk.SetCorrelationIDGenerator(func() string { return "custom-id" })
+	assert.Equal(t, "custom-id", common.NewCorrelationID())
+
+	sdk.SetCorrelationIDGenerator(func() string { return "" })
+	_, err := uuid.Parse(common.NewCorrelationID())
+	assert.NoError(t, err, "Expected the default generator when the generator returns an empty string")
+
+	sdk.SetCorrelationIDGenerator(nil)
+	_, err = uuid.Parse(common.NewCorrelationID())
+	assert.NoError(t, err)
+}
+
+func TestEnableStructuredLogging(t *testing.T) {
+	sdk := AppFunctionsSDK{ServiceKey: "myService"}
+
+	err := sdk.EnableStructuredLogging("xml")
+	assert.EqualError(t, err, "Invalid log format 'xml', must be json or logfmt")
+
+	if !assert.NoError(t, sdk.EnableStructuredLogging("JSON")) {
+		t.Fatal()
+	}
+	assert.Equal(t, "json", sdk.logFormat)
+
+	logFile := filepath.Join(os.TempDir(), fmt.Sprintf("structured-%d.log", time.Now().UnixNano()))
+	defer os.Remove(logFile)
+	sdk.config.Writable.LogLevel = "INFO"
+	loggingClient, err := sdk.newLoggingClient(logFile)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	loggingClient.Info("Structured", clients.CorrelationHeader, "123")
+
+	data, err := ioutil.ReadFile(logFile)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	entry := make(map[string]interface{})
+	if !assert.NoError(t, json.Unmarshal(data, &entry)) {
+		t.Fatal()
+	}
+	assert.Equal(t, "myService", entry["service"])
+	assert.Equal(t, "123", entry["correlation_id"])
+	assert.Equal(t, "Structured", entry["message"])
+
+	sdk.startTime = time.Now()
+	assert.Error(t, sdk.EnableStructuredLogging("logfmt"), "Expected an error after Initialize")
+}
+
+func TestSetMaxConcurrentPipelineExecutions(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	assert.EqualError(t, sdk.SetMaxConcurrentPipelineExecutions(0), "Maximum concurrent pipeline executions must be at least 1")
+	assert.NoError(t, sdk.SetMaxConcurrentPipelineExecutions(4))
+	assert.Equal(t, 4, sdk.maxConcurrentExecutions)
+
+	sdk.runtime = &runtime.GolangRuntime{}
+	err := sdk.SetMaxConcurrentPipelineExecutions(8)
+	assert.EqualError(t, err, "Maximum concurrent pipeline executions must be set before MakeItRun is called")
+}
+
+func TestSetMessageQueueDepth(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	assert.EqualError(t, sdk.SetMessageQueueDepth(0), "Message queue depth must be at least 1")
+	assert.NoError(t, sdk.SetMessageQueueDepth(100))
+	assert.Equal(t, 100, sdk.messageQueueDepth)
+
+	sdk.runtime = &runtime.GolangRuntime{}
+	err := sdk.SetMessageQueueDepth(10)
+	assert.EqualError(t, err, "Message queue depth must be set before MakeItRun is called")
+}
+
+func TestEnableAutoRestart(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	sdk.EnableAutoRestart(3, time.Second)
+	assert.True(t, sdk.autoRestartEnabled)
+	assert.Equal(t, 3, sdk.maxRestarts)
+	assert.Equal(t, time.Second, sdk.restartCooldown)
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"strings"
+
+	coreTypes "github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal"
+)
+
+// consulKeyValue is a key and its base64 encoded value as returned by the Consul KV API
+type consulKeyValue struct {
+	Key   string
+	Value *string
+}
+
+// GetServiceConfig returns the configuration of another EdgeX service, stored in the Registry's key/value store
+// under edgex/appconfig/<serviceKey>, for coordinating with it. The configuration is returned as nested maps
+// following the key paths, with the values as strings. ErrServiceNotFound is returned when there are no keys
+// under the prefix. Requires the Registry to be used.
+func (sdk *AppFunctionsSDK) GetServiceConfig(serviceKey string) (map[string]interface{}, error) {
+	if !sdk.useRegistry {
+		return nil, errors.New("Registry is not enabled")
+	}
+	if serviceKey == "" {
+		return nil, errors.New("Service key must be specified")
+	}
+
+	// The Registry client only reads the configuration of its own service, so the Consul KV API is called directly
+	prefix := internal.ServiceConfigRegistryStem + serviceKey
+	registryURL := fmt.Sprintf("http://%s:%d/v1/kv/%s?recurse=true", sdk.config.Registry.Host, sdk.config.Registry.Port, prefix)
+
+	client := nethttp.Client{Timeout: sdk.getRequestTimeout()}
+	response, err := client.Get(registryURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == nethttp.StatusNotFound {
+		return nil, ErrServiceNotFound{ServiceKey: serviceKey}
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, coreTypes.NewErrServiceClient(response.StatusCode, body)
+	}
+
+	pairs := []consulKeyValue{}
+	if err := json.Unmarshal(body, &pairs); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal configuration of service '%s': %s", serviceKey, err.Error())
+	}
+
+	configuration := make(map[string]interface{})
+	for _, pair := range pairs {
+		// Keys of other services sharing the prefix, i.e. <serviceKey>-2, and folders are skipped
+		path := strings.TrimPrefix(pair.Key, prefix)
+		if !strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") || pair.Value == nil {
+			continue
+		}
+
+		value, err := base64.StdEncoding.DecodeString(*pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode value of '%s': %s", pair.Key, err.Error())
+		}
+		setConfigValue(configuration, strings.Split(strings.TrimPrefix(path, "/"), "/"), string(value))
+	}
+
+	if len(configuration) == 0 {
+		return nil, ErrServiceNotFound{ServiceKey: serviceKey}
+	}
+
+	return configuration, nil
+}
+
+// setConfigValue sets the value at the key path in the nested configuration maps, creating the missing maps
+func setConfigValue(configuration map[string]interface{}, path []string, value string) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := configuration[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			configuration[name] = child
+		}
+		configuration = child
+	}
+	configuration[path[len(path)-1]] = value
+}
@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+func newServiceConfigTestSDK(t *testing.T, serverURL string) *AppFunctionsSDK {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(parsed.Port())
+
+	return &AppFunctionsSDK{
+		LoggingClient: lc,
+		useRegistry:   true,
+		config: common.ConfigurationStruct{
+			Registry: common.RegistryInfo{Host: parsed.Hostname(), Port: port, Type: "consul"},
+		},
+	}
+}
+
+func TestGetServiceConfig(t *testing.T) {
+	encode := func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) }
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.URL.Path != "/v1/kv/edgex/appconfig/app-rules" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `[
+			{"Key":"edgex/appconfig/app-rules/","Value":null},
+			{"Key":"edgex/appconfig/app-rules/Service/Port","Value":"%s"},
+			{"Key":"edgex/appconfig/app-rules/Service/Host","Value":"%s"},
+			{"Key":"edgex/appconfig/app-rules/Writable/LogLevel","Value":"%s"},
+			{"Key":"edgex/appconfig/app-rules-2/Service/Port","Value":"%s"}
+		]`, encode("48095"), encode("localhost"), encode("DEBUG"), encode("48096"))
+	}))
+	defer ts.Close()
+
+	sdk := newServiceConfigTestSDK(t, ts.URL)
+
+	config, err := sdk.GetServiceConfig("app-rules")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "/v1/kv/edgex/appconfig/app-rules", path)
+	expected := map[string]interface{}{
+		"Service":  map[string]interface{}{"Port": "48095", "Host": "localhost"},
+		"Writable": map[string]interface{}{"LogLevel": "DEBUG"},
+	}
+	assert.Equal(t, expected, config)
+
+	_, err = sdk.GetServiceConfig("app-unknown")
+	assert.Equal(t, ErrServiceNotFound{ServiceKey: "app-unknown"}, err)
+}
+
+func TestGetServiceConfigNoKeys(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"Key":"edgex/appconfig/app-rules/","Value":null}]`))
+	}))
+	defer ts.Close()
+
+	sdk := newServiceConfigTestSDK(t, ts.URL)
+	_, err := sdk.GetServiceConfig("app-rules")
+	assert.Equal(t, ErrServiceNotFound{ServiceKey: "app-rules"}, err)
+}
+
+func TestGetServiceConfigRegistryNotEnabled(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+	_, err := sdk.GetServiceConfig("app-rules")
+	assert.EqualError(t, err, "Registry is not enabled")
+}
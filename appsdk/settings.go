@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSettingNotFound is returned when the setting is not present in the ApplicationSettings section
+	ErrSettingNotFound = errors.New("Application setting not found")
+	// ErrSettingTypeMismatch is returned when the setting's value can not be parsed as the requested type
+	ErrSettingTypeMismatch = errors.New("Application setting is not of the requested type")
+)
+
+// GetApplicationSettingsMap returns a copy of all the settings in the ApplicationSettings section of the
+// configuration, so that changes to the returned map do not affect the SDK's configuration.
+func (sdk *AppFunctionsSDK) GetApplicationSettingsMap() map[string]string {
+	settings := make(map[string]string, len(sdk.config.ApplicationSettings))
+	for name, value := range sdk.config.ApplicationSettings {
+		settings[name] = value
+	}
+
+	return settings
+}
+
+// GetAppSetting returns the value of the named setting from the ApplicationSettings section of the configuration.
+// ErrSettingNotFound is returned when the setting is not present.
+func (sdk *AppFunctionsSDK) GetAppSetting(name string) (string, error) {
+	value, ok := sdk.config.ApplicationSettings[name]
+	if !ok {
+		return "", ErrSettingNotFound
+	}
+
+	return value, nil
+}
+
+// GetAppSettingAsInt returns the value of the named setting as an int.
+// ErrSettingTypeMismatch is returned when the value is not an integer.
+func (sdk *AppFunctionsSDK) GetAppSettingAsInt(name string) (int, error) {
+	value, err := sdk.GetAppSetting(name)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, ErrSettingTypeMismatch
+	}
+
+	return result, nil
+}
+
+// GetAppSettingAsBool returns the value of the named setting as a bool.
+// ErrSettingTypeMismatch is returned when the value is not a boolean as accepted by strconv.ParseBool.
+func (sdk *AppFunctionsSDK) GetAppSettingAsBool(name string) (bool, error) {
+	value, err := sdk.GetAppSetting(name)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, ErrSettingTypeMismatch
+	}
+
+	return result, nil
+}
+
+// GetAppSettingAsDuration returns the value of the named setting as a time.Duration, i.e. "30s" or "5m".
+// ErrSettingTypeMismatch is returned when the value is not a duration as accepted by time.ParseDuration.
+func (sdk *AppFunctionsSDK) GetAppSettingAsDuration(name string) (time.Duration, error) {
+	value, err := sdk.GetAppSetting(name)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, ErrSettingTypeMismatch
+	}
+
+	return result, nil
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSettingsSDK() *AppFunctionsSDK {
+	return &AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			ApplicationSettings: map[string]string{
+				"ApplicationName": "My Application Service",
+				"BatchSize":       " 10 ",
+				"Enabled":         "true",
+				"Interval":        "30s",
+			},
+		},
+	}
+}
+
+func TestGetApplicationSettingsMap(t *testing.T) {
+	sdk := newSettingsSDK()
+
+	settings := sdk.GetApplicationSettingsMap()
+	assert.Equal(t, sdk.config.ApplicationSettings, settings)
+
+	settings["ApplicationName"] = "Changed"
+	delete(settings, "Enabled")
+	assert.Equal(t, "My Application Service", sdk.config.ApplicationSettings["ApplicationName"])
+	assert.Contains(t, sdk.config.ApplicationSettings, "Enabled")
+
+	assert.Empty(t, (&AppFunctionsSDK{}).GetApplicationSettingsMap())
+}
+
+func TestGetAppSetting(t *testing.T) {
+	sdk := newSettingsSDK()
+
+	value, err := sdk.GetAppSetting("ApplicationName")
+	assert.NoError(t, err)
+	assert.Equal(t, "My Application Service", value)
+
+	_, err = sdk.GetAppSetting("Missing")
+	assert.Equal(t, ErrSettingNotFound, err)
+
+	_, err = (&AppFunctionsSDK{}).GetAppSetting("ApplicationName")
+	assert.Equal(t, ErrSettingNotFound, err, "expected not found when there are no application settings")
+}
+
+func TestGetAppSettingTyped(t *testing.T) {
+	sdk := newSettingsSDK()
+
+	size, err := sdk.GetAppSettingAsInt("BatchSize")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, size)
+
+	enabled, err := sdk.GetAppSettingAsBool("Enabled")
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	interval, err := sdk.GetAppSettingAsDuration("Interval")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+
+	_, err = sdk.GetAppSettingAsInt("ApplicationName")
+	assert.Equal(t, ErrSettingTypeMismatch, err)
+	_, err = sdk.GetAppSettingAsBool("Interval")
+	assert.Equal(t, ErrSettingTypeMismatch, err)
+	_, err = sdk.GetAppSettingAsDuration("BatchSize")
+	assert.Equal(t, ErrSettingTypeMismatch, err)
+
+	_, err = sdk.GetAppSettingAsInt("Missing")
+	assert.Equal(t, ErrSettingNotFound, err)
+	_, err = sdk.GetAppSettingAsBool("Missing")
+	assert.Equal(t, ErrSettingNotFound, err)
+	_, err = sdk.GetAppSettingAsDuration("Missing")
+	assert.Equal(t, ErrSettingNotFound, err)
+}
@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// MessageEnvelope is a message received from the message bus, with its payload, content type and correlation ID
+type MessageEnvelope = types.MessageEnvelope
+
+// topicSubscription is a subscription registered by SubscribeToTopic
+type topicSubscription struct {
+	topic   string
+	handler func(message MessageEnvelope)
+}
+
+// SubscribeToTopic registers a subscription to the topic of the message bus configured in the MessageBus section,
+// independent of the functions pipeline, so a service can consume from unrelated topics. The subscriptions are
+// started by MakeItRun on their own connection, separate from the trigger's, which is disconnected when MakeItRun
+// terminates. The handler of each subscription runs in its own goroutine, receiving the messages of its topic one
+// at a time. A panic of the handler is recovered and logged. Must be called before MakeItRun.
+func (sdk *AppFunctionsSDK) SubscribeToTopic(topic string, handler func(message MessageEnvelope)) error {
+	if sdk.runtime != nil {
+		return errors.New("Topic subscriptions must be registered before MakeItRun is called")
+	}
+	if topic == "" || handler == nil {
+		return errors.New("Topic and handler must be specified")
+	}
+
+	sdk.topicSubscriptions = append(sdk.topicSubscriptions, topicSubscription{topic: topic, handler: handler})
+	return nil
+}
+
+// startTopicSubscriptions connects to the message bus and starts the subscriptions registered by SubscribeToTopic
+func (sdk *AppFunctionsSDK) startTopicSubscriptions() error {
+	if len(sdk.topicSubscriptions) == 0 {
+		return nil
+	}
+
+	client, err := messaging.NewMessageClient(sdk.config.MessageBus)
+	if err != nil {
+		return err
+	}
+	if err = client.Connect(); err != nil {
+		return err
+	}
+
+	return sdk.subscribeToTopics(client)
+}
+
+func (sdk *AppFunctionsSDK) subscribeToTopics(client messaging.MessageClient) error {
+	topics := make([]types.TopicChannel, len(sdk.topicSubscriptions))
+	for index, subscription := range sdk.topicSubscriptions {
+		topics[index] = types.TopicChannel{Topic: subscription.topic, Messages: make(chan types.MessageEnvelope)}
+	}
+
+	messageErrors := make(chan error)
+	if err := client.Subscribe(topics, messageErrors); err != nil {
+		_ = client.Disconnect()
+		return fmt.Errorf("unable to subscribe to topics: %s", err.Error())
+	}
+	sdk.addCleanupFunc("topic subscriptions", func() {
+		if err := client.Disconnect(); err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Unable to disconnect the topic subscriptions: %s", err.Error()))
+		}
+	})
+
+	go func() {
+		for err := range messageErrors {
+			sdk.LoggingClient.Error(fmt.Sprintf("Error receiving message from subscribed topic: %s", err.Error()))
+		}
+	}()
+
+	for index, subscription := range sdk.topicSubscriptions {
+		go func(subscription topicSubscription, messages chan types.MessageEnvelope) {
+			for msg := range messages {
+				sdk.LoggingClient.Trace("Received message from subscribed topic", "topic", subscription.topic, clients.CorrelationHeader, msg.CorrelationID)
+				sdk.handleTopicMessage(subscription, msg)
+			}
+		}(subscription, topics[index].Messages)
+
+		sdk.LoggingClient.Info(fmt.Sprintf("Subscribed to topic '%s'", subscription.topic))
+	}
+
+	return nil
+}
+
+// handleTopicMessage calls the handler of the subscription, recovering from its panic so that the subscription
+// keeps receiving messages
+func (sdk *AppFunctionsSDK) handleTopicMessage(subscription topicSubscription, msg types.MessageEnvelope) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Handler of subscribed topic '%s' panicked: %v", subscription.topic, recovered), clients.CorrelationHeader, msg.CorrelationID)
+		}
+	}()
+
+	subscription.handler(msg)
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+)
+
+func TestSubscribeToTopic(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	alerts := make(chan MessageEnvelope, 1)
+	commands := make(chan MessageEnvelope, 1)
+	assert.NoError(t, sdk.SubscribeToTopic("alerts", func(message MessageEnvelope) { alerts <- message }))
+	assert.NoError(t, sdk.SubscribeToTopic("commands", func(message MessageEnvelope) { commands <- message }))
+
+	client := &mockSubscribeClient{}
+	err := sdk.subscribeToTopics(client)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.Len(t, client.topics, 2) {
+		t.Fatal()
+	}
+	assert.Equal(t, "alerts", client.topics[0].Topic)
+	assert.Equal(t, "commands", client.topics[1].Topic)
+
+	client.topics[1].Messages <- types.MessageEnvelope{CorrelationID: "123", Payload: []byte("reboot")}
+
+	select {
+	case message := <-commands:
+		assert.Equal(t, "123", message.CorrelationID)
+		assert.Equal(t, "reboot", string(message.Payload))
+	case <-time.After(time.Second):
+		t.Fatal("expected the handler of the topic to be invoked")
+	}
+	assert.Empty(t, alerts)
+
+	sdk.runCleanupFuncs()
+	assert.True(t, client.disconnected, "expected the subscriptions to be disconnected")
+}
+
+func TestSubscribeToTopicHandlerPanic(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	handled := make(chan MessageEnvelope, 1)
+	assert.NoError(t, sdk.SubscribeToTopic("alerts", func(message MessageEnvelope) {
+		handled <- message
+		if string(message.Payload) == "panic" {
+			panic("handler failed")
+		}
+	}))
+
+	client := &mockSubscribeClient{}
+	if !assert.NoError(t, sdk.subscribeToTopics(client)) {
+		t.Fatal()
+	}
+
+	// the subscription keeps receiving messages after the handler panics
+	for _, payload := range []string{"panic", "next"} {
+		client.topics[0].Messages <- types.MessageEnvelope{Payload: []byte(payload)}
+		select {
+		case message := <-handled:
+			assert.Equal(t, payload, string(message.Payload))
+		case <-time.After(time.Second):
+			t.Fatal("expected the handler of the topic to be invoked")
+		}
+	}
+}
+
+func TestSubscribeToTopicErrors(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+
+	err := sdk.SubscribeToTopic("", func(message MessageEnvelope) {})
+	assert.EqualError(t, err, "Topic and handler must be specified")
+	err = sdk.SubscribeToTopic("alerts", nil)
+	assert.EqualError(t, err, "Topic and handler must be specified")
+
+	assert.NoError(t, sdk.SubscribeToTopic("alerts", func(message MessageEnvelope) {}))
+	failing := &mockSubscribeClient{subscribeErr: errors.New("not connected")}
+	err = sdk.subscribeToTopics(failing)
+	assert.EqualError(t, err, "unable to subscribe to topics: not connected")
+	assert.True(t, failing.disconnected)
+
+	sdk.runtime = &runtime.GolangRuntime{}
+	err = sdk.SubscribeToTopic("commands", func(message MessageEnvelope) {})
+	assert.EqualError(t, err, "Topic subscriptions must be registered before MakeItRun is called")
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// DefaultSystemEventsTopic is the message bus topic of the system events when Binding SystemEventsTopic isn't set
+const DefaultSystemEventsTopic = "edgex/system-events"
+
+// SystemEvent is an EdgeX system event, such as a device being added or a profile being updated
+type SystemEvent struct {
+	// Type is the type of object the event is about, i.e. "device" or "deviceprofile"
+	Type string `json:"type"`
+	// Action is what happened to the object, i.e. "add", "update" or "delete"
+	Action string `json:"action"`
+	// Owner is the service that published the event
+	Owner string `json:"owner"`
+	// Tags are additional key/value pairs describing the event
+	Tags map[string]string `json:"tags"`
+	// Details holds the object the event is about
+	Details interface{} `json:"details"`
+}
+
+// SystemEventHandler handles the system events received by SubscribeToSystemEvents
+type SystemEventHandler func(event SystemEvent) error
+
+// SubscribeToSystemEvents subscribes to the EdgeX system events on the message bus configured in the MessageBus
+// section, using its own connection separate from the trigger's, which is disconnected when MakeItRun terminates.
+// The handler is invoked concurrently for each received system event, so it must be safe for concurrent use.
+// Errors returned by the handler are logged.
+func (sdk *AppFunctionsSDK) SubscribeToSystemEvents(handler SystemEventHandler) error {
+	if handler == nil {
+		return errors.New("System event handler must be specified")
+	}
+
+	client, err := messaging.NewMessageClient(sdk.config.MessageBus)
+	if err != nil {
+		return err
+	}
+	if err = client.Connect(); err != nil {
+		return err
+	}
+
+	return sdk.subscribeToSystemEvents(client, handler)
+}
+
+func (sdk *AppFunctionsSDK) subscribeToSystemEvents(client messaging.MessageClient, handler SystemEventHandler) error {
+	topic := sdk.config.Binding.SystemEventsTopic
+	if topic == "" {
+		topic = DefaultSystemEventsTopic
+	}
+
+	topics := []types.TopicChannel{{Topic: topic, Messages: make(chan types.MessageEnvelope)}}
+	messageErrors := make(chan error)
+	if err := client.Subscribe(topics, messageErrors); err != nil {
+		_ = client.Disconnect()
+		return fmt.Errorf("unable to subscribe to system events topic '%s': %s", topic, err.Error())
+	}
+	sdk.addCleanupFunc("system events subscription", func() {
+		if err := client.Disconnect(); err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Unable to disconnect the system events subscription: %s", err.Error()))
+		}
+	})
+	sdk.LoggingClient.Info(fmt.Sprintf("Subscribed to system events on topic '%s'", topic))
+
+	go func() {
+		for {
+			select {
+			case err := <-messageErrors:
+				sdk.LoggingClient.Error(fmt.Sprintf("Error receiving system event: %s", err.Error()))
+
+			case msg := <-topics[0].Messages:
+				go func(msg types.MessageEnvelope) {
+					event := SystemEvent{}
+					if err := json.Unmarshal(msg.Payload, &event); err != nil {
+						sdk.LoggingClient.Error(fmt.Sprintf("Unable to unmarshal system event: %s", err.Error()))
+						return
+					}
+					if err := handler(event); err != nil {
+						sdk.LoggingClient.Error(fmt.Sprintf("System event handler failed for %s %s event: %s", event.Type, event.Action, err.Error()))
+					}
+				}(msg)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,131 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSubscribeClient struct {
+	topics        []types.TopicChannel
+	messageErrors chan error
+	subscribeErr  error
+	disconnected  bool
+}
+
+func (m *mockSubscribeClient) Connect() error {
+	return nil
+}
+
+func (m *mockSubscribeClient) Publish(message types.MessageEnvelope, topic string) error {
+	return nil
+}
+
+func (m *mockSubscribeClient) Subscribe(topics []types.TopicChannel, messageErrors chan error) error {
+	m.topics = topics
+	m.messageErrors = messageErrors
+	return m.subscribeErr
+}
+
+func (m *mockSubscribeClient) Disconnect() error {
+	m.disconnected = true
+	return nil
+}
+
+func TestSubscribeToSystemEvents(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	client := &mockSubscribeClient{}
+	received := make(chan SystemEvent, 2)
+	handler := func(event SystemEvent) error {
+		received <- event
+		if event.Action == "delete" {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+
+	err := sdk.subscribeToSystemEvents(client, handler)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, 1, len(client.topics)) {
+		t.Fatal()
+	}
+	assert.Equal(t, DefaultSystemEventsTopic, client.topics[0].Topic)
+
+	client.topics[0].Messages <- types.MessageEnvelope{Payload: []byte(`not json`)}
+	client.messageErrors <- errors.New("receive failed")
+	client.topics[0].Messages <- types.MessageEnvelope{
+		Payload: []byte(`{"type":"device","action":"add","owner":"core-metadata","tags":{"profile":"thermostat"},"details":{"name":"thermostat1"}}`),
+	}
+	client.topics[0].Messages <- types.MessageEnvelope{Payload: []byte(`{"type":"device","action":"delete"}`)}
+
+	events := map[string]SystemEvent{}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-received:
+			events[event.Action] = event
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for system event")
+		}
+	}
+	added := events["add"]
+	assert.Equal(t, "device", added.Type)
+	assert.Equal(t, "core-metadata", added.Owner)
+	assert.Equal(t, "thermostat", added.Tags["profile"])
+	assert.Equal(t, "thermostat1", added.Details.(map[string]interface{})["name"])
+	assert.Equal(t, "device", events["delete"].Type)
+
+	assert.False(t, client.disconnected)
+	sdk.runCleanupFuncs()
+	assert.True(t, client.disconnected, "the subscription must be disconnected on shutdown")
+}
+
+func TestSubscribeToSystemEventsTopic(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{SystemEventsTopic: "custom/system-events"},
+		},
+	}
+	client := &mockSubscribeClient{}
+
+	err := sdk.subscribeToSystemEvents(client, func(event SystemEvent) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "custom/system-events", client.topics[0].Topic)
+
+	client = &mockSubscribeClient{subscribeErr: errors.New("subscribe failed")}
+	err = sdk.subscribeToSystemEvents(client, func(event SystemEvent) error { return nil })
+	assert.Error(t, err)
+	assert.True(t, client.disconnected)
+}
+
+func TestSubscribeToSystemEventsNoHandler(t *testing.T) {
+	sdk := AppFunctionsSDK{
+		LoggingClient: lc,
+	}
+	err := sdk.SubscribeToSystemEvents(nil)
+	assert.Error(t, err, "expected error for nil handler")
+}
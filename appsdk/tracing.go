@@ -0,0 +1,77 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
+)
+
+// TracingConfig configures the export of the traces of the processed messages
+type TracingConfig struct {
+	// Exporter is the tracing backend the spans are sent to, one of jaeger, otlp or zipkin. Jaeger ingests OTLP,
+	// so the jaeger exporter sends the spans to the collector's OTLP/HTTP endpoint.
+	Exporter string
+	// Endpoint is the URL the spans are sent to, i.e. http://localhost:4318/v1/traces for jaeger and otlp or
+	// http://localhost:9411/api/v2/spans for zipkin
+	Endpoint string
+	// SamplingRate is the fraction, between 0 and 1, of the messages which are traced when the message doesn't
+	// carry the trace context of a sampled trace
+	SamplingRate float64
+	// ServiceName is the name the spans are recorded for, defaults to the service key
+	ServiceName string
+}
+
+// EnableDistributedTracing records a trace of the processing of the sampled messages, with spans for the
+// message receive, each pipeline function, the Store and Forward operations and the HTTP exports. The W3C
+// traceparent header received by the HTTP trigger is honored and the HTTP exports propagate it. The spans are
+// recorded by the SDK itself rather than by an OpenTelemetry TracerProvider, so only the configuration is public:
+// pipeline functions can't add spans or attributes to the traces.
+func (sdk *AppFunctionsSDK) EnableDistributedTracing(config TracingConfig) error {
+	if config.SamplingRate < 0 || config.SamplingRate > 1 {
+		return fmt.Errorf("SamplingRate must be between 0 and 1, not %v", config.SamplingRate)
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = sdk.ServiceKey
+	}
+	if serviceName == "" {
+		return errors.New("ServiceName must be specified when the service key is not set")
+	}
+
+	exporter, err := tracing.NewExporter(config.Exporter, config.Endpoint, serviceName)
+	if err != nil {
+		return fmt.Errorf("Unable to enable distributed tracing: %s", err.Error())
+	}
+
+	tracer := tracing.NewTracer(serviceName, config.SamplingRate, exporter, func(err error) {
+		if sdk.LoggingClient != nil {
+			sdk.LoggingClient.Error(err.Error())
+		}
+	})
+	tracing.SetTracer(tracer)
+	// exports the spans not exported yet when the service stops
+	sdk.addCleanupFunc("distributed tracing", tracer.Shutdown)
+
+	if sdk.LoggingClient != nil {
+		sdk.LoggingClient.Info(fmt.Sprintf("Distributed tracing enabled using the %s exporter", config.Exporter))
+	}
+	return nil
+}
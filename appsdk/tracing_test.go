@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
+)
+
+func TestEnableDistributedTracing(t *testing.T) {
+	defer tracing.SetTracer(nil)
+
+	sdk := AppFunctionsSDK{
+		ServiceKey:    "myService",
+		LoggingClient: lc,
+	}
+
+	tests := []struct {
+		name        string
+		config      TracingConfig
+		expectError bool
+	}{
+		{"OTLP", TracingConfig{Exporter: "otlp", Endpoint: "http://localhost:4318/v1/traces", SamplingRate: 0.5}, false},
+		{"Jaeger", TracingConfig{Exporter: "jaeger", Endpoint: "http://localhost:4318/v1/traces", SamplingRate: 1}, false},
+		{"Zipkin", TracingConfig{Exporter: "zipkin", Endpoint: "http://localhost:9411/api/v2/spans", ServiceName: "other"}, false},
+		{"Unknown exporter", TracingConfig{Exporter: "bogus", Endpoint: "http://localhost:4318/v1/traces"}, true},
+		{"No endpoint", TracingConfig{Exporter: "otlp"}, true},
+		{"Invalid sampling rate", TracingConfig{Exporter: "otlp", Endpoint: "http://localhost:4318/v1/traces", SamplingRate: 2}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sdk.EnableDistributedTracing(test.config)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// each tracer is shut down when the service stops
+	if assert.Len(t, sdk.cleanupFuncs, 3) {
+		assert.Equal(t, "distributed tracing", sdk.cleanupFuncs[0].name)
+	}
+}
+
+func TestEnableDistributedTracingNoServiceName(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+
+	err := sdk.EnableDistributedTracing(TracingConfig{Exporter: "otlp", Endpoint: "http://localhost:4318/v1/traces"})
+	assert.Error(t, err)
+}
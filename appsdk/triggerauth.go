@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/trigger/http"
+)
+
+const defaultKeyRefreshInterval = 5 * time.Minute
+
+// triggerAuthenticators authenticates the requests to the HTTP trigger with every authenticator enabled, in the
+// order they were enabled, rejecting the request as soon as one of them does
+type triggerAuthenticators []http.RequestAuthenticator
+
+func (authenticators triggerAuthenticators) Authenticate(r *nethttp.Request) (int, error) {
+	for _, authenticator := range authenticators {
+		if status, err := authenticator.Authenticate(r); err != nil {
+			return status, err
+		}
+	}
+	return nethttp.StatusOK, nil
+}
+
+// EnableAPIKeyAuth authenticates the requests to the routes of the HTTP trigger with the API key in their header,
+// which must match the value stored in the secret store at secretPath under secretKey. Requests with a missing or
+// mismatching key are rejected with 401. The key is read again from the secret store once the Binding
+// KeyRefreshInterval, 5m by default, has elapsed, so a rotated key is picked up. Only applies to the HTTP trigger.
+// Must be called after Initialize and before MakeItRun.
+func (sdk *AppFunctionsSDK) EnableAPIKeyAuth(header string, secretPath string, secretKey string) {
+	refreshInterval := defaultKeyRefreshInterval
+	if sdk.config.Binding.KeyRefreshInterval != "" {
+		interval, err := time.ParseDuration(sdk.config.Binding.KeyRefreshInterval)
+		if err != nil {
+			sdk.LoggingClient.Error(fmt.Sprintf("Invalid KeyRefreshInterval '%s', using the default %s: %s",
+				sdk.config.Binding.KeyRefreshInterval, defaultKeyRefreshInterval, err.Error()))
+		} else {
+			refreshInterval = interval
+		}
+	}
+
+	sdk.triggerAuthenticators = append(sdk.triggerAuthenticators, &apiKeyAuthenticator{
+		header:          header,
+		secretPath:      secretPath,
+		secretKey:       secretKey,
+		refreshInterval: refreshInterval,
+		secretStore:     sdk.secretStoreClient,
+		logger:          sdk.LoggingClient,
+	})
+}
+
+// apiKeyAuthenticator authenticates the requests with the API key in their header, caching the expected key read
+// from the secret store for the refresh interval
+type apiKeyAuthenticator struct {
+	header          string
+	secretPath      string
+	secretKey       string
+	refreshInterval time.Duration
+	secretStore     security.SecretStoreClient
+	logger          logger.LoggingClient
+	mutex           sync.Mutex
+	key             string
+	readAt          time.Time
+}
+
+func (authenticator *apiKeyAuthenticator) Authenticate(r *nethttp.Request) (int, error) {
+	expected, err := authenticator.expectedKey()
+	if err != nil {
+		return nethttp.StatusInternalServerError, err
+	}
+
+	provided := r.Header.Get(authenticator.header)
+	if provided == "" {
+		return nethttp.StatusUnauthorized, fmt.Errorf("Missing API key header '%s'", authenticator.header)
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+		return nethttp.StatusUnauthorized, errors.New("Invalid API key")
+	}
+	return nethttp.StatusOK, nil
+}
+
+// expectedKey returns the API key from the secret store, reading it again once the refresh interval has elapsed.
+// The key last read is kept when the secret store can't be read.
+func (authenticator *apiKeyAuthenticator) expectedKey() (string, error) {
+	authenticator.mutex.Lock()
+	defer authenticator.mutex.Unlock()
+
+	if authenticator.key != "" && time.Since(authenticator.readAt) < authenticator.refreshInterval {
+		return authenticator.key, nil
+	}
+
+	key, err := authenticator.readKey()
+	if err != nil {
+		if authenticator.key != "" {
+			authenticator.logger.Warn("Unable to read the API key again, using the last key read: " + err.Error())
+			return authenticator.key, nil
+		}
+		authenticator.logger.Error("Unable to read the API key: " + err.Error())
+		return "", errors.New("API key could not be read from the secret store")
+	}
+
+	authenticator.key = key
+	authenticator.readAt = time.Now()
+	return key, nil
+}
+
+func (authenticator *apiKeyAuthenticator) readKey() (string, error) {
+	if authenticator.secretStore == nil {
+		return "", errors.New("SecretStore is missing from configuration")
+	}
+
+	key, err := authenticator.secretStore.GetSecret(authenticator.secretPath, authenticator.secretKey)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", fmt.Errorf("no value for key '%s' at path '%s'", authenticator.secretKey, authenticator.secretPath)
+	}
+	return key, nil
+}
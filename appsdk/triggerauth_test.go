@@ -0,0 +1,147 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/jwt"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/mocks"
+)
+
+func newAPIKeyRequest(key string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/trigger", nil)
+	if key != "" {
+		request.Header.Set("X-API-Key", key)
+	}
+	return request
+}
+
+func TestEnableAPIKeyAuth(t *testing.T) {
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecret", "trigger", "apikey").Return("secret-1", nil).Once()
+	secretStore.On("GetSecret", "trigger", "apikey").Return("secret-2", nil).Once()
+
+	sdk := AppFunctionsSDK{
+		LoggingClient:     lc,
+		secretStoreClient: secretStore,
+		config: common.ConfigurationStruct{
+			Binding: common.BindingInfo{KeyRefreshInterval: "20ms"},
+		},
+	}
+	sdk.EnableAPIKeyAuth("X-API-Key", "trigger", "apikey")
+	if !assert.Len(t, sdk.triggerAuthenticators, 1) {
+		t.Fatal()
+	}
+	authenticator := sdk.triggerAuthenticators
+
+	status, err := authenticator.Authenticate(newAPIKeyRequest("secret-1"))
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, err)
+
+	status, err = authenticator.Authenticate(newAPIKeyRequest(""))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, "Missing API key header 'X-API-Key'")
+
+	status, err = authenticator.Authenticate(newAPIKeyRequest("secret-2"))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, "Invalid API key")
+
+	// The rotated key is picked up once the refresh interval has elapsed
+	time.Sleep(30 * time.Millisecond)
+	status, _ = authenticator.Authenticate(newAPIKeyRequest("secret-2"))
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = authenticator.Authenticate(newAPIKeyRequest("secret-1"))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	secretStore.AssertExpectations(t)
+}
+
+func TestEnableAPIKeyAuthSecretStoreErrors(t *testing.T) {
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecret", "trigger", "apikey").Return("", errors.New("vault sealed")).Once()
+	secretStore.On("GetSecret", "trigger", "apikey").Return("secret-1", nil).Once()
+	secretStore.On("GetSecret", "trigger", "apikey").Return("", errors.New("vault sealed"))
+
+	authenticator := &apiKeyAuthenticator{
+		header:      "X-API-Key",
+		secretPath:  "trigger",
+		secretKey:   "apikey",
+		secretStore: secretStore,
+		logger:      lc,
+	}
+
+	status, err := authenticator.Authenticate(newAPIKeyRequest("secret-1"))
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.EqualError(t, err, "API key could not be read from the secret store")
+
+	status, _ = authenticator.Authenticate(newAPIKeyRequest("secret-1"))
+	assert.Equal(t, http.StatusOK, status)
+
+	// The refresh interval is zero, so the key is read for every request, and the last key read is kept on errors
+	status, _ = authenticator.Authenticate(newAPIKeyRequest("secret-1"))
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestTriggerAuthenticators(t *testing.T) {
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecret", "trigger", "apikey").Return("secret-1", nil)
+
+	sdk := AppFunctionsSDK{LoggingClient: lc, secretStoreClient: secretStore}
+	sdk.EnableAPIKeyAuth("X-API-Key", "trigger", "apikey")
+	sdk.EnableJWTAuth("http://localhost:1/jwks")
+
+	// The API key is accepted, but the request is then rejected for lacking a bearer token
+	status, err := sdk.triggerAuthenticators.Authenticate(newAPIKeyRequest("secret-1"))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, "Missing bearer token")
+}
+
+func TestEnableJWTAuthWithOptions(t *testing.T) {
+	sdk := AppFunctionsSDK{LoggingClient: lc}
+	sdk.EnableJWTAuth("http://localhost:1/jwks")
+	sdk.EnableJWTAuthWithOptions("http://localhost:1/jwks", JWTAuthOptions{
+		Issuer:         "https://auth.example.com",
+		Audience:       "edgex",
+		RequiredScopes: []string{"trigger"},
+	})
+	if !assert.Len(t, sdk.triggerAuthenticators, 2) {
+		t.Fatal()
+	}
+
+	validator, ok := sdk.triggerAuthenticators[0].(*jwt.Validator)
+	if !assert.True(t, ok) {
+		t.Fatal()
+	}
+	assert.Empty(t, validator.Issuer)
+	assert.Empty(t, validator.Audience)
+	assert.Empty(t, validator.RequiredScopes)
+
+	validator, ok = sdk.triggerAuthenticators[1].(*jwt.Validator)
+	if !assert.True(t, ok) {
+		t.Fatal()
+	}
+	assert.Equal(t, "https://auth.example.com", validator.Issuer)
+	assert.Equal(t, "edgex", validator.Audience)
+	assert.Equal(t, []string{"trigger"}, validator.RequiredScopes)
+}
@@ -0,0 +1,196 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+// serviceKeyPattern is the format of the service keys accepted by RequireServiceKey
+var serviceKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-_]{0,63}$`)
+
+// ConfigurationErrors lists all the problems found by ValidateConfiguration
+type ConfigurationErrors []error
+
+func (e ConfigurationErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+
+	return "Invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors.
+func (e ConfigurationErrors) Unwrap() []error {
+	return e
+}
+
+// ValidateConfiguration checks that the required configuration is set and that the client URLs are valid, that
+// the secret store, the Store and Forward database and the Registry, when used, can be reached, and that a
+// functions pipeline has been set. All the problems found are returned as ConfigurationErrors. It is called by
+// MakeItRun before the trigger is started, so must be called after Initialize and after the functions pipeline
+// has been set. MakeItRun therefore fails for a configuration it used to accept, i.e. without a reachable
+// secret store.
+func (sdk *AppFunctionsSDK) ValidateConfiguration() error {
+	var errs ConfigurationErrors
+	errs = append(errs, sdk.validateSettings(sdk.config)...)
+	errs = append(errs, sdk.validateConnectivity()...)
+
+	if len(sdk.transforms) == 0 && len(sdk.functionPipelines) == 0 {
+		errs = append(errs, errors.New("No functions pipeline has been set"))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// RequireServiceKey checks that the key is a valid service key, which starts with a letter followed by at most 63
+// letters, digits, '-' or '_'. It is called by MakeItRun when the ServiceKey is left blank, so that services don't
+// accidentally share the configuration and registration of the blank service key.
+func (sdk *AppFunctionsSDK) RequireServiceKey(key string) error {
+	if key == "" {
+		return errors.New("Service key must be specified")
+	}
+	if !serviceKeyPattern.MatchString(key) {
+		return fmt.Errorf("Service key '%s' must start with a letter followed by at most 63 letters, digits, '-' or '_'", key)
+	}
+	return nil
+}
+
+// PreflightCheck validates the configuration as ValidateConfiguration does and logs each problem found, so that
+// CLI validation tools can check a service's configuration without running it.
+func (sdk *AppFunctionsSDK) PreflightCheck() error {
+	err := sdk.ValidateConfiguration()
+	if errs, ok := err.(ConfigurationErrors); ok {
+		for _, problem := range errs {
+			sdk.LoggingClient.Error("Preflight check failed: " + problem.Error())
+		}
+		return err
+	}
+
+	sdk.LoggingClient.Info("Preflight check passed")
+	return nil
+}
+
+// validateSettings checks the required settings of the configuration without connecting to any service
+func (sdk *AppFunctionsSDK) validateSettings(config common.ConfigurationStruct) []error {
+	var errs []error
+
+	if config.Service.Host == "" {
+		errs = append(errs, errors.New("Service Host is not set"))
+	}
+	if !isValidPort(config.Service.Port) {
+		errs = append(errs, fmt.Errorf("Service Port %d is not valid", config.Service.Port))
+	}
+
+	bindingType := strings.ToUpper(config.Binding.Type)
+	_, isCustomTrigger := sdk.customTriggerBuilders[bindingType]
+	switch {
+	case bindingType == "":
+		errs = append(errs, errors.New("Binding Type is not set"))
+	case bindingType == "MESSAGEBUS":
+		if config.MessageBus.Type == "" {
+			errs = append(errs, errors.New("MessageBus Type is not set"))
+		}
+		if config.MessageBus.SubscribeHost.Host == "" || !isValidPort(config.MessageBus.SubscribeHost.Port) {
+			errs = append(errs, fmt.Errorf("MessageBus SubscribeHost '%s:%d' is not valid",
+				config.MessageBus.SubscribeHost.Host, config.MessageBus.SubscribeHost.Port))
+		}
+	case bindingType != "HTTP" && !isCustomTrigger:
+		errs = append(errs, fmt.Errorf("Binding Type '%s' is not a supported trigger", config.Binding.Type))
+	}
+
+	for name, client := range config.Clients {
+		if err := validateURL(client.Url(), client.Host, client.Port); err != nil {
+			errs = append(errs, fmt.Errorf("Clients %s URL '%s' is not valid: %v", name, client.Url(), err))
+		}
+	}
+
+	if sdk.useRegistry {
+		if config.Registry.Type == "" || config.Registry.Host == "" || !isValidPort(config.Registry.Port) {
+			errs = append(errs, fmt.Errorf("Registry Type, Host and Port must be set, got '%s://%s:%d'",
+				config.Registry.Type, config.Registry.Host, config.Registry.Port))
+		}
+	}
+
+	if err := config.RESTClient.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if config.Writable.StoreAndForward.Enabled {
+		if config.Database.Type == "" || config.Database.Host == "" || !isValidPort(config.Database.Port) {
+			errs = append(errs, fmt.Errorf("Database Type, Host and Port must be set for Store and Forward, got '%s://%s:%d'",
+				config.Database.Type, config.Database.Host, config.Database.Port))
+		}
+	}
+
+	return errs
+}
+
+// validateConnectivity checks that the secret store, the Store and Forward database and the Registry, when used,
+// can be reached
+func (sdk *AppFunctionsSDK) validateConnectivity() []error {
+	var errs []error
+
+	if sdk.secretStoreClient != nil {
+		if err := sdk.secretStoreClient.HealthCheck(); err != nil {
+			errs = append(errs, fmt.Errorf("Secret Store can't be reached: %v", err))
+		}
+	}
+
+	if sdk.storeClient != nil {
+		if _, err := sdk.storeClient.Count(sdk.ServiceKey); err != nil {
+			errs = append(errs, fmt.Errorf("Database can't be reached: %v", err))
+		}
+	}
+
+	if sdk.useRegistry && sdk.registryClient != nil && !sdk.registryClient.IsAlive() {
+		errs = append(errs, fmt.Errorf("Registry (%s) is not running", sdk.config.Registry.Type))
+	}
+
+	return errs
+}
+
+func validateURL(rawURL string, host string, port int) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("protocol '%s' is not http or https", parsed.Scheme)
+	}
+	if host == "" {
+		return errors.New("host is not set")
+	}
+	if !isValidPort(port) {
+		return fmt.Errorf("port %d is not valid", port)
+	}
+	return nil
+}
+
+func isValidPort(port int) bool {
+	return port > 0 && port <= 65535
+}
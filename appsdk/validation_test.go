@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package appsdk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	securityMocks "github.com/edgexfoundry/app-functions-sdk-go/internal/security/mocks"
+	storeMocks "github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidSDK() *AppFunctionsSDK {
+	sdk := &AppFunctionsSDK{
+		LoggingClient: lc,
+		ServiceKey:    "myService",
+		config: common.ConfigurationStruct{
+			Service: common.ServiceInfo{Host: "localhost", Port: 48095},
+			Binding: common.BindingInfo{Type: "messagebus"},
+			MessageBus: types.MessageBusConfig{
+				Type:          "zero",
+				SubscribeHost: types.HostInfo{Host: "localhost", Port: 5563, Protocol: "tcp"},
+			},
+			Clients: map[string]common.ClientInfo{
+				"CoreData": {Protocol: "http", Host: "localhost", Port: 48080},
+			},
+		},
+	}
+	sdk.transforms = []appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) { return true, nil },
+	}
+	return sdk
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	sdk := newValidSDK()
+	assert.NoError(t, sdk.ValidateConfiguration())
+	assert.NoError(t, sdk.PreflightCheck())
+
+	sdk.config.Binding.Type = "HTTP"
+	assert.NoError(t, sdk.ValidateConfiguration())
+}
+
+func TestValidateConfigurationErrors(t *testing.T) {
+	sdk := newValidSDK()
+	sdk.transforms = nil
+	sdk.config.Service.Host = ""
+	sdk.config.Service.Port = 0
+	sdk.config.MessageBus.Type = ""
+	sdk.config.Clients["Metadata"] = common.ClientInfo{Protocol: "tcp", Host: "localhost", Port: 48081}
+	sdk.config.Writable.StoreAndForward.Enabled = true
+
+	err := sdk.ValidateConfiguration()
+	errs, ok := err.(ConfigurationErrors)
+	if !assert.True(t, ok, "Expected ConfigurationErrors") {
+		t.Fatal()
+	}
+	assert.Equal(t, []error(errs), errs.Unwrap())
+
+	expected := []string{
+		"Service Host is not set",
+		"Service Port 0 is not valid",
+		"MessageBus Type is not set",
+		"Clients Metadata URL 'tcp://localhost:48081' is not valid: protocol 'tcp' is not http or https",
+		"Database Type, Host and Port must be set for Store and Forward, got '://:0'",
+		"No functions pipeline has been set",
+	}
+	if !assert.Len(t, errs, len(expected)) {
+		t.Fatal(err)
+	}
+	for index, message := range expected {
+		assert.Equal(t, message, errs[index].Error())
+	}
+	assert.Contains(t, err.Error(), "Invalid configuration: Service Host is not set; ")
+
+	assert.Equal(t, err, sdk.PreflightCheck())
+}
+
+func TestValidateConfigurationBindingType(t *testing.T) {
+	sdk := newValidSDK()
+	sdk.config.Binding.Type = "bogus"
+	assert.EqualError(t, sdk.ValidateConfiguration(), "Invalid configuration: Binding Type 'bogus' is not a supported trigger")
+
+	err := sdk.SetCustomTrigger("bogus", func(c TriggerConfig, router MessageRouter) (Trigger, error) { return nil, nil })
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.NoError(t, sdk.ValidateConfiguration())
+}
+
+func TestValidateConfigurationRESTClient(t *testing.T) {
+	sdk := newValidSDK()
+	sdk.config.RESTClient = common.RESTClientConfig{MaxIdleConns: 200, IdleConnTimeout: "30s"}
+	assert.NoError(t, sdk.ValidateConfiguration())
+
+	sdk.config.RESTClient.IdleConnTimeout = "30"
+	assert.EqualError(t, sdk.ValidateConfiguration(), "Invalid configuration: RESTClient IdleConnTimeout '30' is not a valid duration")
+}
+
+func TestValidateConfigurationConnectivity(t *testing.T) {
+	sdk := newValidSDK()
+
+	secretStore := &securityMocks.SecretStoreClient{}
+	secretStore.On("HealthCheck").Return(errors.New("connection refused"))
+	sdk.secretStoreClient = secretStore
+
+	storeClient := &storeMocks.StoreClient{}
+	storeClient.On("Count", "myService").Return(0, errors.New("connection refused"))
+	sdk.storeClient = storeClient
+
+	assert.EqualError(t, sdk.ValidateConfiguration(), "Invalid configuration: Secret Store can't be reached: connection refused; Database can't be reached: connection refused")
+}
+
+func TestRequireServiceKey(t *testing.T) {
+	sdk := newValidSDK()
+
+	tests := []struct {
+		name        string
+		key         string
+		expectError bool
+	}{
+		{"valid", "AppService-rules_engine2", false},
+		{"single letter", "a", false},
+		{"max length", "a" + strings.Repeat("b", 63), false},
+		{"empty", "", true},
+		{"leading digit", "1service", true},
+		{"leading dash", "-service", true},
+		{"invalid character", "app.service", true},
+		{"profile placeholder", "AppService-" + ProfileSuffixPlaceholder, true},
+		{"too long", "a" + strings.Repeat("b", 64), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sdk.RequireServiceKey(test.key)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMakeItRunRequiresServiceKey(t *testing.T) {
+	sdk := newValidSDK()
+	sdk.ServiceKey = ""
+
+	err := sdk.MakeItRun()
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "Service key must be specified", err.Error())
+	assert.Nil(t, sdk.runtime, "the trigger should not have been started")
+}
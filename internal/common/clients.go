@@ -17,9 +17,14 @@
 package common
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/command"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/notifications"
 )
 
@@ -28,6 +33,7 @@ const (
 	CoreCommandClientName   = "Command"
 	CoreDataClientName      = "CoreData"
 	NotificationsClientName = "Notifications"
+	CoreMetadataClientName  = "Metadata"
 )
 
 type EdgeXClients struct {
@@ -36,4 +42,51 @@ type EdgeXClients struct {
 	CommandClient         command.CommandClient
 	ValueDescriptorClient coredata.ValueDescriptorClient
 	NotificationsClient   notifications.NotificationsClient
+	DeviceClient          metadata.DeviceClient
+	DeviceProfileClient   metadata.DeviceProfileClient
+}
+
+// DefaultRequestTimeout is the timeout of the calls made by the SDK to the EdgeX services, unless changed with
+// SetRequestTimeout on the SDK
+const DefaultRequestTimeout = 10 * time.Second
+
+// callResult is the result of the call made by CallWithTimeoutResult
+type callResult struct {
+	value interface{}
+	err   error
+}
+
+// CallWithTimeout calls call with a context which is cancelled once the timeout expires, or without a timeout when
+// the timeout is zero or negative. The EdgeX clients don't cancel their requests when their context is cancelled,
+// so an error is returned once the timeout expires without waiting for call to return. As call may still be running
+// after the timeout, it must not write any variable of the caller, CallWithTimeoutResult returns its result instead.
+func CallWithTimeout(ctx context.Context, timeout time.Duration, call func(ctx context.Context) error) error {
+	_, err := CallWithTimeoutResult(ctx, timeout, func(ctx context.Context) (interface{}, error) {
+		return nil, call(ctx)
+	})
+	return err
+}
+
+// CallWithTimeoutResult calls call as CallWithTimeout does, and returns the value it returns. The value is nil when
+// the timeout expires, and it is discarded when call returns after the timeout.
+func CallWithTimeoutResult(ctx context.Context, timeout time.Duration, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan callResult, 1)
+	go func() {
+		value, err := call(ctx)
+		done <- callResult{value: value, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("Request to EdgeX service timed out after %s", timeout)
+	}
 }
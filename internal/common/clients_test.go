@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallWithTimeout(t *testing.T) {
+	expected := errors.New("not found")
+	err := CallWithTimeout(context.Background(), time.Second, func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "expected the context to have a deadline")
+		return expected
+	})
+	assert.Equal(t, expected, err)
+}
+
+func TestCallWithTimeoutExpired(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	err := CallWithTimeout(context.Background(), time.Millisecond, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	assert.EqualError(t, err, "Request to EdgeX service timed out after 1ms")
+}
+
+func TestCallWithTimeoutNoTimeout(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "key", "value")
+	err := CallWithTimeout(ctx, 0, func(callCtx context.Context) error {
+		_, hasDeadline := callCtx.Deadline()
+		assert.False(t, hasDeadline, "expected no deadline")
+		assert.Equal(t, ctx, callCtx)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestCallWithTimeoutResult(t *testing.T) {
+	value, err := CallWithTimeoutResult(context.Background(), time.Second, func(ctx context.Context) (interface{}, error) {
+		return "id", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "id", value)
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	value, err = CallWithTimeoutResult(context.Background(), time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		defer close(returned)
+		<-release
+		return "late", nil
+	})
+	assert.EqualError(t, err, "Request to EdgeX service timed out after 1ms")
+	assert.Nil(t, value)
+
+	// the result of the call returning after the timeout is discarded
+	close(release)
+	<-returned
+	assert.Nil(t, value)
+}
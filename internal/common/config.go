@@ -29,6 +29,8 @@ type WritableInfo struct {
 	LogLevel        string
 	Pipeline        PipelineInfo
 	StoreAndForward StoreAndForwardInfo
+	// MaintenanceMode enables the administrative routes, such as resetting the metrics
+	MaintenanceMode bool
 }
 
 // ClientInfo provides the host and port of another service in the eco-system.
@@ -39,6 +41,9 @@ type ClientInfo struct {
 	Port int
 	// Protocol indicates the protocol to use when accessing a given service
 	Protocol string
+	// SecretPath is the optional path in the secret store of the Bearer token used to access a given service.
+	// Currently only used by the Command client.
+	SecretPath string
 }
 
 func (c ClientInfo) Url() string {
@@ -57,6 +62,8 @@ type ConfigurationStruct struct {
 	ApplicationSettings map[string]string
 	Clients             map[string]ClientInfo
 	Database            db.DatabaseInfo
+	SecretStore         SecretStoreInfo
+	RESTClient          RESTClientConfig
 }
 
 // RegistryInfo ...
@@ -83,6 +90,17 @@ type ServiceInfo struct {
 	StartupMsg    string
 	ReadMaxLimit  int
 	Timeout       int
+	// ProfilingEnabled allows the pprof routes to be mounted on the webserver via EnableProfiling().
+	ProfilingEnabled bool
+	// DeviceCacheTTL is the duration, i.e. "5m", the devices retrieved from Core Metadata are cached. Defaults to 5m.
+	DeviceCacheTTL string
+	// PrewarmDeviceCache loads all devices from Core Metadata in to the device cache at startup.
+	PrewarmDeviceCache bool
+	// CommandCacheTTL is the duration, i.e. "10s", the responses of GET commands are cached. Not cached when empty.
+	CommandCacheTTL string
+	// DrainTimeout is the duration, i.e. "30s", to wait on shutdown for the running pipelines to complete. Not
+	// waited for when empty.
+	DrainTimeout string
 }
 
 // BindingInfo contains Metadata associated with each binding
@@ -91,6 +109,11 @@ type BindingInfo struct {
 	Name           string
 	SubscribeTopic string
 	PublishTopic   string
+	// SystemEventsTopic is the message bus topic EdgeX publishes system events on. Defaults to "edgex/system-events".
+	SystemEventsTopic string
+	// KeyRefreshInterval is the duration, i.e. "5m", after which the API key of the HTTP trigger is read again from
+	// the secret store to pick up a rotated key. Defaults to 5m.
+	KeyRefreshInterval string
 }
 
 type PipelineInfo struct {
@@ -100,13 +123,45 @@ type PipelineInfo struct {
 }
 
 type PipelineFunction struct {
-	// Name	string
+	// Name is the name of the built in or registered function to use. Defaults to the name of the function's section,
+	// which allows the same function to be used multiple times in the pipeline with different parameters.
+	Name        string
 	Parameters  map[string]string
 	Addressable models.Addressable
 }
 
+// SecretStoreInfo contains the connection information for the secret store
+type SecretStoreInfo struct {
+	Type      string
+	Host      string
+	Port      int
+	Path      string
+	Protocol  string
+	TokenFile string
+	Timeout   int
+	// KubeConfig is the kubeconfig file used to connect to Kubernetes for the kubesecrets secret store. The
+	// service account of the pod is used when not set.
+	KubeConfig string
+}
+
+// RESTClientConfig contains the connection pooling settings of the HTTP transport shared by the REST clients of
+// the EdgeX services and the other HTTP clients of the SDK. The defaults of Go's http.DefaultTransport are kept for
+// the settings which aren't set.
+type RESTClientConfig struct {
+	// MaxIdleConns is the maximum number of idle connections kept across all hosts. Defaults to 100.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept for each host. Defaults to 2.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the duration, i.e. "90s", an idle connection is kept before being closed. Defaults to 90s.
+	IdleConnTimeout string
+	// TLSHandshakeTimeout is the duration, i.e. "10s", to wait for a TLS handshake. Defaults to 10s.
+	TLSHandshakeTimeout string
+}
+
 type StoreAndForwardInfo struct {
 	Enabled       bool
 	RetryInterval int
 	MaxRetryCount int
+	// ReplayRPS limits how many stored objects are replayed per second by EnableEventReplay, unlimited when zero
+	ReplayRPS float64
 }
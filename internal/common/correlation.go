@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	correlationIDGenerator func() string
+	correlationIDMutex     sync.RWMutex
+)
+
+// SetCorrelationIDGenerator sets the generator of the correlation IDs created by the SDK, the default UUID v4
+// generator is used when nil
+func SetCorrelationIDGenerator(generator func() string) {
+	correlationIDMutex.Lock()
+	correlationIDGenerator = generator
+	correlationIDMutex.Unlock()
+}
+
+// NewCorrelationID returns a correlation ID from the generator set by SetCorrelationIDGenerator, or a UUID v4
+// when no generator is set or it returns an empty string
+func NewCorrelationID() string {
+	correlationIDMutex.RLock()
+	generator := correlationIDGenerator
+	correlationIDMutex.RUnlock()
+
+	if generator != nil {
+		if correlationID := generator(); correlationID != "" {
+			return correlationID
+		}
+	}
+	return uuid.New().String()
+}
@@ -0,0 +1,38 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// DeadLetterEnvelope is the message published to the dead-letter topic for a message which failed to be processed
+// by the functions pipeline and wasn't stored for later retry. It carries the original message along with the
+// details of the failure.
+type DeadLetterEnvelope struct {
+	types.MessageEnvelope
+	// OriginalTopic is the topic the message was received on
+	OriginalTopic string
+	// FailedAt is the time the processing of the message failed
+	FailedAt time.Time
+	// ErrorMessage is the error which stopped the functions pipeline
+	ErrorMessage string
+	// Attempts is the number of times processing the message was attempted
+	Attempts int
+}
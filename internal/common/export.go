@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Redacted replaces the values of the sensitive settings in the exported configuration
+const Redacted = "[REDACTED]"
+
+// UnsupportedFormatError is returned by ExportConfiguration for a format other than toml, yaml and json
+type UnsupportedFormatError struct {
+	Format string
+}
+
+func (e UnsupportedFormatError) Error() string {
+	return fmt.Sprintf("Unsupported configuration format '%s', must be toml, yaml or json", e.Format)
+}
+
+// ExportConfiguration writes the configuration to w in the toml, yaml or json format, with the values of the
+// SecretPath settings and of the settings whose name contains Password or Token replaced by Redacted. Empty
+// values are not redacted, so that missing settings can be seen.
+func ExportConfiguration(configuration ConfigurationStruct, w io.Writer, format string) error {
+	format = strings.ToLower(format)
+	switch format {
+	case "toml", "yaml", "json":
+	default:
+		return UnsupportedFormatError{Format: format}
+	}
+
+	contents, err := toml.Marshal(configuration)
+	if err != nil {
+		return err
+	}
+	tree, err := toml.LoadBytes(contents)
+	if err != nil {
+		return err
+	}
+	values := redact(tree.ToMap())
+
+	switch format {
+	case "toml":
+		redactedTree, err := toml.TreeFromMap(values)
+		if err != nil {
+			return err
+		}
+		_, err = redactedTree.WriteTo(w)
+		return err
+	case "yaml":
+		contents, err = yaml.Marshal(values)
+	default:
+		contents, err = json.MarshalIndent(values, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(contents)
+	return err
+}
+
+// redact replaces the non-empty values of the sensitive settings, in place, at all levels of the configuration
+func redact(values map[string]interface{}) map[string]interface{} {
+	for key, value := range values {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			redact(value)
+		case string:
+			if value != "" && isSensitive(key) {
+				values[key] = Redacted
+			}
+		}
+	}
+	return values
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return key == "secretpath" || strings.Contains(key, "password") || strings.Contains(key, "token")
+}
@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func exportTestConfiguration() ConfigurationStruct {
+	configuration := ConfigurationStruct{}
+	configuration.Service.Host = "localhost"
+	configuration.Service.Port = 48095
+	configuration.Database.Username = "admin"
+	configuration.Database.Password = "secret"
+	configuration.SecretStore.Path = "/v1/secret/edgex/appservice/"
+	configuration.SecretStore.TokenFile = "/vault/config/assets/resp-init.json"
+	configuration.ApplicationSettings = map[string]string{
+		"SecretPath": "mqtt",
+		"ApiToken":   "abc123",
+		"DeviceName": "Random-Float-Device",
+	}
+	configuration.Clients = map[string]ClientInfo{
+		"CoreData": {Host: "localhost", Port: 48080, Protocol: "http"},
+	}
+	return configuration
+}
+
+func TestExportConfigurationTOML(t *testing.T) {
+	var buffer bytes.Buffer
+	err := ExportConfiguration(exportTestConfiguration(), &buffer, "toml")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	tree, err := toml.LoadBytes(buffer.Bytes())
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "localhost", tree.GetPath([]string{"Service", "Host"}))
+	assert.Equal(t, int64(48095), tree.GetPath([]string{"Service", "Port"}))
+	assert.Equal(t, "admin", tree.GetPath([]string{"Database", "Username"}))
+	assert.Equal(t, Redacted, tree.GetPath([]string{"Database", "Password"}))
+	assert.Equal(t, Redacted, tree.GetPath([]string{"SecretStore", "TokenFile"}))
+	assert.Equal(t, Redacted, tree.GetPath([]string{"ApplicationSettings", "SecretPath"}))
+	assert.Equal(t, Redacted, tree.GetPath([]string{"ApplicationSettings", "ApiToken"}))
+	assert.Equal(t, "Random-Float-Device", tree.GetPath([]string{"ApplicationSettings", "DeviceName"}))
+	assert.Equal(t, int64(48080), tree.GetPath([]string{"Clients", "CoreData", "Port"}))
+}
+
+func TestExportConfigurationYAML(t *testing.T) {
+	var buffer bytes.Buffer
+	err := ExportConfiguration(exportTestConfiguration(), &buffer, "YAML")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	exported := map[string]map[string]interface{}{}
+	err = yaml.Unmarshal(buffer.Bytes(), &exported)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "localhost", exported["Service"]["Host"])
+	assert.Equal(t, 48095, exported["Service"]["Port"])
+	assert.Equal(t, Redacted, exported["Database"]["Password"])
+	assert.Equal(t, Redacted, exported["SecretStore"]["TokenFile"])
+	assert.Equal(t, "/v1/secret/edgex/appservice/", exported["SecretStore"]["Path"])
+	assert.NotContains(t, buffer.String(), "abc123")
+	assert.Contains(t, buffer.String(), "Random-Float-Device")
+}
+
+func TestExportConfigurationJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	err := ExportConfiguration(exportTestConfiguration(), &buffer, "json")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	exported := ConfigurationStruct{}
+	err = json.Unmarshal(buffer.Bytes(), &exported)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "localhost", exported.Service.Host)
+	assert.Equal(t, 48095, exported.Service.Port)
+	assert.Equal(t, Redacted, exported.Database.Password)
+	assert.Equal(t, "/v1/secret/edgex/appservice/", exported.SecretStore.Path)
+	assert.Equal(t, Redacted, exported.ApplicationSettings["ApiToken"])
+	assert.Equal(t, "http", exported.Clients["CoreData"].Protocol)
+}
+
+func TestExportConfigurationEmptyNotRedacted(t *testing.T) {
+	var buffer bytes.Buffer
+	err := ExportConfiguration(ConfigurationStruct{}, &buffer, "json")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	exported := ConfigurationStruct{}
+	err = json.Unmarshal(buffer.Bytes(), &exported)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "", exported.Database.Password)
+}
+
+func TestExportConfigurationUnsupportedFormat(t *testing.T) {
+	var buffer bytes.Buffer
+	err := ExportConfiguration(exportTestConfiguration(), &buffer, "xml")
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, UnsupportedFormatError{Format: "xml"}, err)
+	assert.Equal(t, 0, buffer.Len())
+}
@@ -17,12 +17,17 @@
 package common
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/internal"
 	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -30,28 +35,239 @@ const (
 	configDirEnv    = "EDGEX_CONF_DIR"
 )
 
-// LoadFromFile loads .toml file for configuration
-func LoadFromFile(profile string, configDir string) (configuration *ConfigurationStruct, err error) {
+// ParseError is returned by LoadFromFile when the configuration file is malformed
+type ParseError struct {
+	FileName string
+	Err      error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("Unable to parse configuration file (%s): %v", e.FileName, e.Err.Error())
+}
+
+// LoadFromFile loads the configuration file, decoding it from YAML when the file name ends in .yaml or .yml, from
+// JSON when it ends in .json and from TOML otherwise. The default configuration file name is used when configFile is empty.
+func LoadFromFile(profile string, configDir string, configFile string) (configuration *ConfigurationStruct, err error) {
+	if len(configFile) == 0 {
+		configFile = internal.ConfigFileName
+	}
 	path := determinePath(configDir)
-	fileName := path + "/" + internal.ConfigFileName //default profile
+	fileName := path + "/" + configFile //default profile
 	if len(profile) > 0 {
-		fileName = path + "/" + profile + "/" + internal.ConfigFileName
+		fileName = path + "/" + profile + "/" + configFile
 	}
 	contents, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		return nil, fmt.Errorf("Could not load configuration file (%s): %v", fileName, err.Error())
 	}
 
-	// Decode the configuration from TOML
 	configuration = &ConfigurationStruct{}
-	err = toml.Unmarshal(contents, configuration)
+	var table map[string]interface{}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		table, err = unmarshalYAML(contents, configuration)
+	case ".json":
+		if err = json.Unmarshal(contents, configuration); err == nil {
+			err = json.Unmarshal(contents, &table)
+		}
+	default:
+		table, err = unmarshalTOML(contents, configuration)
+	}
+	if err == nil {
+		err = addFunctionSectionParameters(configuration, table)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("Unable to parse configuration file (%s): %v", fileName, err.Error())
+		return nil, ParseError{FileName: fileName, Err: err}
 	}
 
 	return configuration, nil
 }
 
+// unmarshalTOML decodes the TOML configuration, returning its table
+func unmarshalTOML(contents []byte, configuration *ConfigurationStruct) (map[string]interface{}, error) {
+	tree, err := toml.LoadBytes(contents)
+	if err != nil {
+		return nil, err
+	}
+	if err := tree.Unmarshal(configuration); err != nil {
+		return nil, err
+	}
+	return tree.ToMap(), nil
+}
+
+// unmarshalYAML decodes the YAML configuration thru a TOML tree, so that the keys of the YAML configuration are
+// the same as those of the TOML configuration, i.e. `Writable` and `LogLevel`, returning its table.
+func unmarshalYAML(contents []byte, configuration *ConfigurationStruct) (map[string]interface{}, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, fmt.Errorf("malformed YAML: %v", err)
+	}
+	if document == nil {
+		// an empty document leaves the configuration empty, as an empty TOML file does
+		return nil, nil
+	}
+
+	values, err := yamlToTOML(document, "")
+	if err != nil {
+		return nil, fmt.Errorf("malformed YAML: %v", err)
+	}
+	table, ok := values.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("malformed YAML: the configuration must be a mapping of sections")
+	}
+
+	tree, err := toml.TreeFromMap(table)
+	if err != nil {
+		return nil, fmt.Errorf("malformed YAML: %v", err)
+	}
+	return table, tree.Unmarshal(configuration)
+}
+
+// addFunctionSectionParameters adds the keys set directly in the sections of the pipeline functions, i.e.
+// `Devices = ["sensor1"]` under `[Writable.Pipeline.Functions.MyFilter]`, to the functions' Parameters. The
+// values of arrays are joined with commas, which is how the functions take lists. Tables other than Parameters
+// and Addressable, and keys also set in Parameters, are rejected rather than ignored.
+func addFunctionSectionParameters(configuration *ConfigurationStruct, table map[string]interface{}) error {
+	writable, _ := lookupTable(table, "Writable")
+	pipeline, _ := lookupTable(writable, "Pipeline")
+	functions, _ := lookupTable(pipeline, "Functions")
+
+	for section, value := range functions {
+		keys, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		function, ok := configuration.Writable.Pipeline.Functions[section]
+		if !ok {
+			continue
+		}
+
+		for key, value := range keys {
+			switch strings.ToLower(key) {
+			case "name", "parameters", "addressable":
+				continue
+			}
+
+			parameter, ok := parameterValue(value)
+			if !ok {
+				return fmt.Errorf("unsupported value for '%s' of pipeline function %s", key, section)
+			}
+			for name := range function.Parameters {
+				if strings.EqualFold(name, key) {
+					return fmt.Errorf("'%s' of pipeline function %s is set both in its section and its Parameters", key, section)
+				}
+			}
+			if function.Parameters == nil {
+				function.Parameters = make(map[string]string)
+			}
+			function.Parameters[key] = parameter
+		}
+		configuration.Writable.Pipeline.Functions[section] = function
+	}
+
+	return nil
+}
+
+// lookupTable returns the table at the key, which is matched regardless of case as when decoding the configuration
+func lookupTable(table map[string]interface{}, key string) (map[string]interface{}, bool) {
+	for name, value := range table {
+		if strings.EqualFold(name, key) {
+			subTable, ok := value.(map[string]interface{})
+			return subTable, ok
+		}
+	}
+	return nil, false
+}
+
+// parameterValue returns the value of a function parameter, joining the values of arrays with commas
+func parameterValue(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case bool, int, int64, float64:
+		return fmt.Sprint(value), true
+	case []string:
+		return strings.Join(value, ","), true
+	case []interface{}:
+		values := make([]string, len(value))
+		for index, item := range value {
+			if _, ok := item.([]interface{}); ok {
+				return "", false
+			}
+			itemValue, ok := parameterValue(item)
+			if !ok {
+				return "", false
+			}
+			values[index] = itemValue
+		}
+		return strings.Join(values, ","), true
+	}
+	return "", false
+}
+
+// yamlToTOML converts the decoded YAML value at key to the values accepted by toml.TreeFromMap, which requires
+// string keys and arrays of a single type.
+func yamlToTOML(value interface{}, key string) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		table := make(map[string]interface{}, len(value))
+		for mapKey, mapValue := range value {
+			name, ok := mapKey.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v of '%s' is not a string", mapKey, key)
+			}
+			if mapValue == nil {
+				// a key without a value is left unset, as it can't be expressed in TOML
+				continue
+			}
+			converted, err := yamlToTOML(mapValue, joinKey(key, name))
+			if err != nil {
+				return nil, err
+			}
+			table[name] = converted
+		}
+		return table, nil
+	case []interface{}:
+		if len(value) == 0 {
+			return []string{}, nil
+		}
+		if _, ok := value[0].(map[interface{}]interface{}); ok {
+			tables := make([]map[string]interface{}, len(value))
+			for index, item := range value {
+				converted, err := yamlToTOML(item, fmt.Sprintf("%s[%d]", key, index))
+				if err != nil {
+					return nil, err
+				}
+				table, ok := converted.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("'%s' mixes mappings and values", key)
+				}
+				tables[index] = table
+			}
+			return tables, nil
+		}
+		items := make([]interface{}, len(value))
+		for index, item := range value {
+			if _, ok := item.(map[interface{}]interface{}); ok {
+				return nil, fmt.Errorf("'%s' mixes mappings and values", key)
+			}
+			items[index] = item
+		}
+		return items, nil
+	case nil:
+		return nil, fmt.Errorf("'%s' has an empty value", key)
+	default:
+		return value, nil
+	}
+}
+
+func joinKey(parent string, key string) string {
+	if len(parent) == 0 {
+		return key
+	}
+	return parent + "." + key
+}
+
 func determinePath(configDir string) string {
 	path := configDir
 
@@ -0,0 +1,266 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tomlConfiguration = `
+[Writable]
+LogLevel = 'INFO'
+  [Writable.Pipeline]
+  ExecutionOrder = "FilterByDeviceName, TransformToXML"
+    [Writable.Pipeline.Functions.FilterByDeviceName]
+      [Writable.Pipeline.Functions.FilterByDeviceName.Parameters]
+      DeviceNames = "Random-Float-Device"
+
+[Service]
+Host = 'localhost'
+Port = 48095
+Timeout = 5000
+
+[Clients]
+  [Clients.CoreData]
+  Protocol = 'http'
+  Host = 'localhost'
+  Port = 48080
+
+[MessageBus]
+Type = 'zero'
+  [MessageBus.Optional]
+  ClientId = "app-service"
+
+[Binding]
+Type = "messagebus"
+SubscribeTopic = "events"
+
+[ApplicationSettings]
+ApplicationName = "simple-filter-xml"
+`
+
+const yamlConfiguration = `
+Writable:
+  LogLevel: INFO
+  Pipeline:
+    ExecutionOrder: FilterByDeviceName, TransformToXML
+    Functions:
+      FilterByDeviceName:
+        Parameters:
+          DeviceNames: Random-Float-Device
+Service:
+  Host: localhost
+  Port: 48095
+  Timeout: 5000
+Clients:
+  CoreData:
+    Protocol: http
+    Host: localhost
+    Port: 48080
+MessageBus:
+  Type: zero
+  Optional:
+    ClientId: app-service
+Binding:
+  Type: messagebus
+  SubscribeTopic: events
+  # a key without a value is left unset
+  PublishTopic:
+ApplicationSettings:
+  ApplicationName: simple-filter-xml
+`
+
+const jsonConfiguration = `{
+  "Writable": {
+    "LogLevel": "INFO",
+    "Pipeline": {
+      "ExecutionOrder": "FilterByDeviceName, TransformToXML",
+      "Functions": {
+        "FilterByDeviceName": {"Parameters": {"DeviceNames": "Random-Float-Device"}}
+      }
+    }
+  },
+  "Service": {"Host": "localhost", "Port": 48095, "Timeout": 5000},
+  "Clients": {"CoreData": {"Protocol": "http", "Host": "localhost", "Port": 48080}},
+  "MessageBus": {"Type": "zero", "Optional": {"ClientId": "app-service"}},
+  "Binding": {"Type": "messagebus", "SubscribeTopic": "events"},
+  "ApplicationSettings": {"ApplicationName": "simple-filter-xml"}
+}`
+
+func writeConfigurationFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "configuration")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)) {
+		t.Fatal()
+	}
+	return dir
+}
+
+func TestLoadFromFileYAML(t *testing.T) {
+	tomlDir := writeConfigurationFile(t, "configuration.toml", tomlConfiguration)
+	defer os.RemoveAll(tomlDir)
+
+	expected, err := LoadFromFile("", tomlDir, "")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	for _, name := range []string{"configuration.yaml", "configuration.yml", "configuration.YAML"} {
+		t.Run(name, func(t *testing.T) {
+			yamlDir := writeConfigurationFile(t, name, yamlConfiguration)
+			defer os.RemoveAll(yamlDir)
+
+			actual, err := LoadFromFile("", yamlDir, name)
+			if !assert.NoError(t, err) {
+				t.Fatal()
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	assert.Equal(t, "INFO", expected.Writable.LogLevel)
+	assert.Equal(t, "Random-Float-Device", expected.Writable.Pipeline.Functions["FilterByDeviceName"].Parameters["DeviceNames"])
+	assert.Equal(t, 48095, expected.Service.Port)
+	assert.Equal(t, 48080, expected.Clients["CoreData"].Port)
+	assert.Equal(t, "app-service", expected.MessageBus.Optional["ClientId"])
+}
+
+func TestLoadFromFileJSON(t *testing.T) {
+	tomlDir := writeConfigurationFile(t, "configuration.toml", tomlConfiguration)
+	defer os.RemoveAll(tomlDir)
+	jsonDir := writeConfigurationFile(t, "configuration.json", jsonConfiguration)
+	defer os.RemoveAll(jsonDir)
+
+	expected, err := LoadFromFile("", tomlDir, "")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	actual, err := LoadFromFile("", jsonDir, "configuration.json")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, expected, actual)
+
+	malformedDir := writeConfigurationFile(t, "configuration.json", `{"Service": {"Port": "48095"}}`)
+	defer os.RemoveAll(malformedDir)
+
+	_, err = LoadFromFile("", malformedDir, "configuration.json")
+	_, ok := err.(ParseError)
+	assert.True(t, ok, "Expected a ParseError")
+}
+
+func TestLoadFromFileFunctionSectionParameters(t *testing.T) {
+	contents := `
+[Writable.Pipeline]
+ExecutionOrder = "MyFilter, MyFunction"
+[Writable.Pipeline.Functions.MyFilter]
+Name = "FilterByDeviceName"
+Devices = ["sensor1", "sensor2"]
+[Writable.Pipeline.Functions.MyFunction]
+Threshold = 10
+Enabled = true
+  [Writable.Pipeline.Functions.MyFunction.Parameters]
+  Unit = "C"
+`
+	dir := writeConfigurationFile(t, "configuration.toml", contents)
+	defer os.RemoveAll(dir)
+
+	configuration, err := LoadFromFile("", dir, "")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	functions := configuration.Writable.Pipeline.Functions
+	assert.Equal(t, "FilterByDeviceName", functions["MyFilter"].Name)
+	assert.Equal(t, map[string]string{"Devices": "sensor1,sensor2"}, functions["MyFilter"].Parameters)
+	assert.Equal(t, map[string]string{"Threshold": "10", "Enabled": "true", "Unit": "C"}, functions["MyFunction"].Parameters)
+
+	for name, contents := range map[string]string{
+		"configuration.yaml": "Writable:\n  Pipeline:\n    Functions:\n      MyFilter:\n        Name: FilterByDeviceName\n        Devices:\n          - sensor1\n          - sensor2\n",
+		"configuration.json": `{"Writable": {"Pipeline": {"Functions": {"MyFilter": {"Name": "FilterByDeviceName", "Devices": ["sensor1", "sensor2"]}}}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeConfigurationFile(t, name, contents)
+			defer os.RemoveAll(dir)
+
+			configuration, err := LoadFromFile("", dir, name)
+			if !assert.NoError(t, err) {
+				t.Fatal()
+			}
+			assert.Equal(t, map[string]string{"Devices": "sensor1,sensor2"}, configuration.Writable.Pipeline.Functions["MyFilter"].Parameters)
+		})
+	}
+}
+
+func TestLoadFromFileFunctionSectionParametersErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{"Set twice", "[Writable.Pipeline.Functions.MyFilter]\nDeviceNames = \"sensor1\"\n  [Writable.Pipeline.Functions.MyFilter.Parameters]\n  devicenames = \"sensor2\"\n",
+			"'DeviceNames' of pipeline function MyFilter is set both in its section and its Parameters"},
+		{"Unknown table", "[Writable.Pipeline.Functions.MyFilter.Options]\nDevices = \"sensor1\"\n",
+			"unsupported value for 'Options' of pipeline function MyFilter"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeConfigurationFile(t, "configuration.toml", test.contents)
+			defer os.RemoveAll(dir)
+
+			_, err := LoadFromFile("", dir, "")
+			_, ok := err.(ParseError)
+			assert.True(t, ok, "Expected a ParseError")
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestLoadFromFileMalformedYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{"Bad indentation", "Service:\n  Host: localhost\n Port: 48095\n", "malformed YAML: yaml: line 2: did not find expected key"},
+		{"Not a mapping", "- Service\n- Clients\n", "malformed YAML: the configuration must be a mapping of sections"},
+		{"Mixed list", "Service:\n  Hosts:\n    - localhost\n    - Host: localhost\n", "malformed YAML: 'Service.Hosts' mixes mappings and values"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeConfigurationFile(t, "configuration.yaml", test.contents)
+			defer os.RemoveAll(dir)
+
+			_, err := LoadFromFile("", dir, "configuration.yaml")
+			if !assert.Error(t, err) {
+				t.Fatal()
+			}
+			_, ok := err.(ParseError)
+			assert.True(t, ok, "Expected a ParseError")
+			assert.Contains(t, err.Error(), "Unable to parse configuration file")
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
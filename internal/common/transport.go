@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ConfigureTransport applies the connection pooling settings which are set to the transport. The transport isn't
+// changed when the settings are invalid.
+func ConfigureTransport(transport *http.Transport, config RESTClientConfig) error {
+	idleConnTimeout, tlsHandshakeTimeout, err := config.parse()
+	if err != nil {
+		return err
+	}
+
+	if config.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if idleConnTimeout > 0 {
+		transport.IdleConnTimeout = idleConnTimeout
+	}
+	if tlsHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+	}
+	return nil
+}
+
+// Validate returns an error when one of the settings is invalid
+func (config RESTClientConfig) Validate() error {
+	_, _, err := config.parse()
+	return err
+}
+
+// parse returns the IdleConnTimeout and TLSHandshakeTimeout durations, zero when not set
+func (config RESTClientConfig) parse() (time.Duration, time.Duration, error) {
+	if config.MaxIdleConns < 0 || config.MaxIdleConnsPerHost < 0 {
+		return 0, 0, errors.New("RESTClient MaxIdleConns and MaxIdleConnsPerHost must not be negative")
+	}
+	idleConnTimeout, err := parseOptionalDuration("IdleConnTimeout", config.IdleConnTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	tlsHandshakeTimeout, err := parseOptionalDuration("TLSHandshakeTimeout", config.TLSHandshakeTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	return idleConnTimeout, tlsHandshakeTimeout, nil
+}
+
+func parseOptionalDuration(name string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("RESTClient %s '%s' is not a valid duration", name, value)
+	}
+	return duration, nil
+}
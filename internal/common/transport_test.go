@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureTransport(t *testing.T) {
+	transport := &http.Transport{MaxIdleConns: 100, MaxIdleConnsPerHost: 2, IdleConnTimeout: 90 * time.Second, TLSHandshakeTimeout: 10 * time.Second}
+	config := RESTClientConfig{
+		MaxIdleConns:        500,
+		MaxIdleConnsPerHost: 50,
+		IdleConnTimeout:     "30s",
+		TLSHandshakeTimeout: "5s",
+	}
+
+	err := ConfigureTransport(transport, config)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, 500, transport.MaxIdleConns)
+	assert.Equal(t, 50, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 5*time.Second, transport.TLSHandshakeTimeout)
+}
+
+func TestConfigureTransportDefaults(t *testing.T) {
+	transport := &http.Transport{MaxIdleConns: 100, MaxIdleConnsPerHost: 2, IdleConnTimeout: 90 * time.Second, TLSHandshakeTimeout: 10 * time.Second}
+
+	err := ConfigureTransport(transport, RESTClientConfig{MaxIdleConnsPerHost: 20})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 10*time.Second, transport.TLSHandshakeTimeout)
+}
+
+func TestConfigureTransportInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   RESTClientConfig
+		expected string
+	}{
+		{"Negative MaxIdleConns", RESTClientConfig{MaxIdleConns: -1}, "RESTClient MaxIdleConns and MaxIdleConnsPerHost must not be negative"},
+		{"Invalid IdleConnTimeout", RESTClientConfig{IdleConnTimeout: "bogus"}, "RESTClient IdleConnTimeout 'bogus' is not a valid duration"},
+		{"Negative TLSHandshakeTimeout", RESTClientConfig{TLSHandshakeTimeout: "-1s"}, "RESTClient TLSHandshakeTimeout '-1s' is not a valid duration"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &http.Transport{MaxIdleConns: 100}
+			config := test.config
+			config.MaxIdleConnsPerHost = 10
+			err := ConfigureTransport(transport, config)
+			assert.EqualError(t, err, test.expected)
+			assert.EqualError(t, config.Validate(), test.expected)
+			assert.Equal(t, 100, transport.MaxIdleConns)
+			assert.Equal(t, 0, transport.MaxIdleConnsPerHost, "expected the transport not to be changed")
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package config
 
 import (
+	"fmt"
 	"net/url"
 	"os"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 const (
 	envKeyRegistryUrl = "edgex_registry"
 	envKeyServiceUrl  = "edgex_service"
+	envKeyPrefix      = "EDGEX_"
 )
 
 // environment is receiver that holds environment variables and encapsulates toml.Tree-based configuration field
@@ -96,3 +98,96 @@ func (e *environment) OverrideFromEnvironment(tree *toml.Tree) *toml.Tree {
 	}
 	return tree
 }
+
+// OverlayFromEnvironment method replaces values in the toml.Tree for the environment variables with the EDGEX_
+// prefix, i.e. EDGEX_WRITABLE_LOGLEVEL for the LogLevel key of the Writable section. The section and key names
+// are matched ignoring case, and the values are parsed as the type of the values they replace. Environment
+// variables which don't match an existing key are ignored.
+func (e *environment) OverlayFromEnvironment(tree *toml.Tree) (*toml.Tree, error) {
+	for k, v := range e.env {
+		if !strings.HasPrefix(k, envKeyPrefix) {
+			continue
+		}
+		path := findPath(tree, strings.Split(strings.TrimPrefix(k, envKeyPrefix), "_"))
+		if path == nil {
+			continue
+		}
+
+		value, err := parseAs(v.(string), tree.GetPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for environment variable %s: %v", k, err)
+		}
+		tree.SetPath(path, value)
+	}
+	return tree, nil
+}
+
+// OverlayConfiguration method replaces the values of the configuration for the environment variables with the
+// EDGEX_ prefix, as OverlayFromEnvironment does for a toml.Tree.
+func (e *environment) OverlayConfiguration(configuration *common.ConfigurationStruct) error {
+	if !e.hasPrefixedKeys() {
+		return nil
+	}
+
+	contents, err := toml.Marshal(*configuration)
+	if err != nil {
+		return err
+	}
+	tree, err := toml.LoadBytes(contents)
+	if err != nil {
+		return err
+	}
+
+	tree, err = e.OverlayFromEnvironment(tree)
+	if err != nil {
+		return err
+	}
+	return tree.Unmarshal(configuration)
+}
+
+// hasPrefixedKeys returns whether any environment variable has the EDGEX_ prefix
+func (e *environment) hasPrefixedKeys() bool {
+	for k := range e.env {
+		if strings.HasPrefix(k, envKeyPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPath returns the path of the key in the tree matching the parts of an environment variable name, or nil when
+// there is none. As keys may contain "_", consecutive parts are also tried joined with "_".
+func findPath(tree *toml.Tree, parts []string) []string {
+	for i := 1; i <= len(parts); i++ {
+		name := strings.Join(parts[:i], "_")
+		for _, key := range tree.Keys() {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			if subTree, ok := tree.GetPath([]string{key}).(*toml.Tree); ok {
+				if i < len(parts) {
+					if rest := findPath(subTree, parts[i:]); rest != nil {
+						return append([]string{key}, rest...)
+					}
+				}
+			} else if i == len(parts) {
+				return []string{key}
+			}
+		}
+	}
+	return nil
+}
+
+// parseAs parses the environment variable's value as the type of the current value
+func parseAs(value string, current interface{}) (interface{}, error) {
+	switch current.(type) {
+	case int64:
+		return strconv.ParseInt(value, 10, 64)
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	case bool:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
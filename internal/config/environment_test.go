@@ -162,3 +162,99 @@ func TestNoEnvVariableDoesNotUpdateRegistryInfo(t *testing.T) {
 	assert.Equal(t, registryInfo.Port, defaultPortValue)
 	assert.Equal(t, registryInfo.Type, defaultTypeValue)
 }
+
+func TestOverlayFromEnvironment(t *testing.T) {
+	tree, err := toml.Load(`
+Name = "app"
+[Writable]
+LogLevel = "INFO"
+  [Writable.StoreAndForward]
+  Enabled = false
+  MaxRetryCount = 10
+[ApplicationSettings]
+Device_Names = "Random-Float-Device"
+`)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	sut := newSUT(t, map[string]string{
+		"EDGEX_NAME":                                   "other",
+		"EDGEX_WRITABLE_LOGLEVEL":                      "DEBUG",
+		"EDGEX_WRITABLE_STOREANDFORWARD_ENABLED":       "true",
+		"EDGEX_WRITABLE_STOREANDFORWARD_MAXRETRYCOUNT": "3",
+		"EDGEX_APPLICATIONSETTINGS_DEVICE_NAMES":       "Random-Integer-Device",
+		"EDGEX_WRITABLE_UNKNOWN":                       "ignored",
+		"Writable_LogLevel":                            "TRACE",
+	})
+
+	result, err := sut.OverlayFromEnvironment(tree)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	assert.Equal(t, "other", result.Get("Name"))
+	assert.Equal(t, "DEBUG", result.Get("Writable.LogLevel"))
+	assert.Equal(t, true, result.Get("Writable.StoreAndForward.Enabled"))
+	assert.Equal(t, int64(3), result.Get("Writable.StoreAndForward.MaxRetryCount"))
+	assert.Equal(t, "Random-Integer-Device", result.GetPath([]string{"ApplicationSettings", "Device_Names"}))
+	assert.False(t, result.Has("Writable.UNKNOWN"))
+}
+
+func TestOverlayFromEnvironmentInvalidValue(t *testing.T) {
+	tree, err := toml.Load("[Service]\nPort = 48095\n")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	sut := newSUT(t, map[string]string{"EDGEX_SERVICE_PORT": "not-a-port"})
+
+	_, err = sut.OverlayFromEnvironment(tree)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+	assert.Contains(t, err.Error(), "EDGEX_SERVICE_PORT")
+}
+
+func TestOverlayConfiguration(t *testing.T) {
+	configuration := common.ConfigurationStruct{
+		Writable: common.WritableInfo{LogLevel: "INFO"},
+		Service:  common.ServiceInfo{Host: "localhost", Port: 48095},
+		Clients: map[string]common.ClientInfo{
+			"CoreData": {Protocol: "http", Host: "localhost", Port: 48080},
+		},
+		ApplicationSettings: map[string]string{"ApplicationName": "app"},
+	}
+	expected := configuration
+	expected.Writable.LogLevel = "DEBUG"
+	expected.Service.Port = 48100
+	expected.Clients = map[string]common.ClientInfo{
+		"CoreData": {Protocol: "http", Host: "edgex-core-data", Port: 48080},
+	}
+	// the configuration is decoded from TOML, which has empty rather than nil tables
+	expected.Writable.Pipeline.Functions = map[string]common.PipelineFunction{}
+	expected.MessageBus.Optional = map[string]string{}
+
+	sut := newSUT(t, map[string]string{
+		"EDGEX_WRITABLE_LOGLEVEL":     "DEBUG",
+		"EDGEX_SERVICE_PORT":          "48100",
+		"EDGEX_CLIENTS_COREDATA_HOST": "edgex-core-data",
+	})
+
+	err := sut.OverlayConfiguration(&configuration)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, expected, configuration)
+}
+
+func TestOverlayConfigurationNoPrefixedEnvironment(t *testing.T) {
+	configuration := common.ConfigurationStruct{Service: common.ServiceInfo{Port: 48095}}
+	expected := configuration
+
+	sut := newSUT(t, map[string]string{"Service_Port": "48100"})
+
+	err := sut.OverlayConfiguration(&configuration)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, expected, configuration)
+}
@@ -17,14 +17,21 @@
 package internal
 
 const (
-	BootTimeoutDefault   = 30000
-	ClientMonitorDefault = 15000
-	ConfigFileName       = "configuration.toml"
-	ConfigRegistryStem   = "edgex/appservices/1.0/"
-	WritableKey          = "/Writable"
-	ApiTriggerRoute      = "/api/v1/trigger"
-	LogDurationKey       = "duration"
-	DatabaseName         = "application-service"
+	BootTimeoutDefault        = 30000
+	ClientMonitorDefault      = 15000
+	ConfigFileName            = "configuration.toml"
+	ConfigRegistryStem        = "edgex/appservices/1.0/"
+	ServiceConfigRegistryStem = "edgex/appconfig/"
+	WritableKey               = "/Writable"
+	ApiTriggerRoute           = "/api/v1/trigger"
+	ApiProfilingRoute         = "/debug/pprof/"
+	ApiLogLevelRoute          = "/api/v1/loglevel"
+	ApiMetricsResetRoute      = "/api/v1/metrics/reset"
+	ApiStoreForwardQueueRoute = "/api/v1/storeforward/queue"
+	ApiConfigExportRoute      = "/api/v1/config/export"
+	ApiPrometheusMetricsRoute = "/api/v1/metrics/prometheus"
+	LogDurationKey            = "duration"
+	DatabaseName              = "application-service"
 )
 
 // SDKVersion indicates the version of the SDK - will be overwritten by build
@@ -0,0 +1,167 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// logging provides a logging client which writes machine-readable structured logs.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/go-kit/kit/log"
+)
+
+// Formats
+const (
+	JSON   = "json"
+	Logfmt = "logfmt"
+)
+
+// Structured log fields
+const (
+	LevelKey         = "level"
+	TimestampKey     = "timestamp"
+	ServiceKey       = "service"
+	CorrelationIDKey = "correlation_id"
+	MessageKey       = "message"
+)
+
+var logLevels = []string{models.TraceLog, models.DebugLog, models.InfoLog, models.WarnLog, models.ErrorLog}
+
+type structuredLogger struct {
+	logger   log.Logger
+	logLevel string
+	mutex    sync.RWMutex
+}
+
+// NewClient creates a logging client which writes each log entry to the writer as a JSON object or a logfmt line,
+// with the level, timestamp, service, correlation_id and message fields followed by the other key/value pairs
+// passed to the logging methods.
+func NewClient(serviceName string, format string, logLevel string, writer io.Writer) (logger.LoggingClient, error) {
+	var rootLogger log.Logger
+	switch format {
+	case JSON:
+		rootLogger = log.NewJSONLogger(log.NewSyncWriter(writer))
+	case Logfmt:
+		rootLogger = log.NewLogfmtLogger(log.NewSyncWriter(writer))
+	default:
+		return nil, fmt.Errorf("unsupported log format '%s', must be %s or %s", format, JSON, Logfmt)
+	}
+
+	if !logger.IsValidLogLevel(logLevel) {
+		logLevel = models.InfoLog
+	}
+
+	return &structuredLogger{
+		logger:   log.With(rootLogger, TimestampKey, log.DefaultTimestampUTC, ServiceKey, serviceName),
+		logLevel: logLevel,
+	}, nil
+}
+
+// NewWriter returns a writer to stdout and, when a log file is specified, to the end of the file
+func NewWriter(logFile string) (io.Writer, error) {
+	if logFile == "" {
+		return os.Stdout, nil
+	}
+
+	if dir := filepath.Dir(logFile); dir != "" {
+		if err := os.MkdirAll(dir, 0766); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.MultiWriter(os.Stdout, file), nil
+}
+
+func (lc *structuredLogger) log(logLevel string, msg string, args ...interface{}) {
+	lc.mutex.RLock()
+	minimum := lc.logLevel
+	lc.mutex.RUnlock()
+	if levelIndex(logLevel) < levelIndex(minimum) {
+		return
+	}
+
+	if len(args)%2 == 1 {
+		// add an empty string to keep k/v pairs correct
+		args = append(args, "")
+	}
+
+	keyvals := make([]interface{}, 0, len(args)+4)
+	keyvals = append(keyvals, LevelKey, logLevel)
+	for index := 0; index < len(args); index += 2 {
+		key := args[index]
+		if key == clients.CorrelationHeader {
+			key = CorrelationIDKey
+		}
+		keyvals = append(keyvals, key, args[index+1])
+	}
+	keyvals = append(keyvals, MessageKey, msg)
+
+	if err := lc.logger.Log(keyvals...); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write log entry: %s\n", err.Error())
+	}
+}
+
+func levelIndex(logLevel string) int {
+	for index, name := range logLevels {
+		if name == logLevel {
+			return index
+		}
+	}
+	return len(logLevels)
+}
+
+func (lc *structuredLogger) SetLogLevel(logLevel string) error {
+	if !logger.IsValidLogLevel(logLevel) {
+		return types.ErrNotFound{}
+	}
+
+	lc.mutex.Lock()
+	lc.logLevel = logLevel
+	lc.mutex.Unlock()
+	return nil
+}
+
+func (lc *structuredLogger) Trace(msg string, args ...interface{}) {
+	lc.log(models.TraceLog, msg, args...)
+}
+
+func (lc *structuredLogger) Debug(msg string, args ...interface{}) {
+	lc.log(models.DebugLog, msg, args...)
+}
+
+func (lc *structuredLogger) Info(msg string, args ...interface{}) {
+	lc.log(models.InfoLog, msg, args...)
+}
+
+func (lc *structuredLogger) Warn(msg string, args ...interface{}) {
+	lc.log(models.WarnLog, msg, args...)
+}
+
+func (lc *structuredLogger) Error(msg string, args ...interface{}) {
+	lc.log(models.ErrorLog, msg, args...)
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestJSON(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	lc, err := NewClient("myService", JSON, models.DebugLog, buffer)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	lc.Info("Data exported", "Transport", "HTTP", clients.CorrelationHeader, "123-234")
+
+	entry := make(map[string]interface{})
+	if !assert.NoError(t, json.Unmarshal(buffer.Bytes(), &entry)) {
+		t.Fatal()
+	}
+	assert.Equal(t, models.InfoLog, entry[LevelKey])
+	assert.Equal(t, "myService", entry[ServiceKey])
+	assert.Equal(t, "123-234", entry[CorrelationIDKey])
+	assert.Equal(t, "Data exported", entry[MessageKey])
+	assert.Equal(t, "HTTP", entry["Transport"])
+	assert.NotEmpty(t, entry[TimestampKey])
+}
+
+func TestLogfmt(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	lc, err := NewClient("myService", Logfmt, models.DebugLog, buffer)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	lc.Error("Export failed", clients.CorrelationHeader, "123-234", "odd")
+
+	line := buffer.String()
+	assert.True(t, strings.HasPrefix(line, "timestamp="), line)
+	assert.Contains(t, line, "service=myService level=ERROR correlation_id=123-234 odd= message=\"Export failed\"")
+}
+
+func TestLogLevel(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	lc, err := NewClient("myService", JSON, "bogus", buffer)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	lc.Debug("not logged at the default INFO level")
+	assert.Equal(t, 0, buffer.Len())
+
+	assert.NoError(t, lc.SetLogLevel(models.TraceLog))
+	lc.Trace("logged")
+	assert.Contains(t, buffer.String(), "logged")
+
+	assert.Error(t, lc.SetLogLevel("bogus"))
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewClient("myService", "xml", models.InfoLog, &bytes.Buffer{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+
+// queuedMessage is a message received by the message bus trigger waiting to be processed
+type queuedMessage struct {
+	envelope types.MessageEnvelope
+	process  func(envelope types.MessageEnvelope)
+}
+
+// SetMaxConcurrentExecutions limits the number of pipeline invocations running concurrently to max, using a
+// semaphore acquired by the triggers with AcquireExecution. Must be set before the triggers are initialized.
+func (gr *GolangRuntime) SetMaxConcurrentExecutions(max int) {
+	gr.executions = make(chan struct{}, max)
+}
+
+// AcquireExecution blocks until a pipeline invocation can start, when the number of concurrent invocations is
+// limited. DispatchMessage acquires it before processing each received message, so that no further messages are
+// received from the subscription while the limit is reached. Each call must be followed by a call to ReleaseExecution.
+func (gr *GolangRuntime) AcquireExecution() {
+	if gr.executions != nil {
+		gr.executions <- struct{}{}
+	}
+}
+
+// ReleaseExecution releases the execution acquired by AcquireExecution once the pipeline invocation completes
+func (gr *GolangRuntime) ReleaseExecution() {
+	if gr.executions != nil {
+		<-gr.executions
+	}
+}
+
+// SetMessageQueueDepth queues up to depth messages received from the message bus between the subscription and the
+// goroutines processing them. While the queue is full, DispatchMessage blocks, so that no further messages are
+// received from the subscription. Must be set before the triggers are initialized.
+func (gr *GolangRuntime) SetMessageQueueDepth(depth int) {
+	gr.queue = make(chan queuedMessage, depth)
+	go func() {
+		for message := range gr.queue {
+			gr.AcquireExecution()
+			go func(message queuedMessage) {
+				defer gr.ReleaseExecution()
+				message.process(message.envelope)
+			}(message)
+		}
+	}()
+}
+
+// DispatchMessage processes the received message with process in its own goroutine, once an execution has been
+// acquired. The message is queued when the message queue is set, blocking while the queue is full.
+func (gr *GolangRuntime) DispatchMessage(envelope types.MessageEnvelope, process func(envelope types.MessageEnvelope)) {
+	if gr.queue != nil {
+		gr.queue <- queuedMessage{envelope: envelope, process: process}
+		return
+	}
+
+	gr.AcquireExecution()
+	go func() {
+		defer gr.ReleaseExecution()
+		process(envelope)
+	}()
+}
+
+// MessageQueueLength returns the number of messages waiting in the message queue
+func (gr *GolangRuntime) MessageQueueLength() int {
+	return len(gr.queue)
+}
@@ -0,0 +1,149 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxConcurrentExecutions(t *testing.T) {
+	runtime := GolangRuntime{}
+	runtime.SetMaxConcurrentExecutions(2)
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		runtime.AcquireExecution()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer runtime.ReleaseExecution()
+			current := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxRunning)
+}
+
+func TestAcquireExecutionBlocksWhenFull(t *testing.T) {
+	runtime := GolangRuntime{}
+	runtime.SetMaxConcurrentExecutions(1)
+	runtime.AcquireExecution()
+
+	acquired := make(chan bool)
+	go func() {
+		runtime.AcquireExecution()
+		acquired <- true
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected AcquireExecution to block while the limit is reached")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	runtime.ReleaseExecution()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected AcquireExecution to return once released")
+	}
+}
+
+func TestAcquireExecutionUnlimited(t *testing.T) {
+	runtime := GolangRuntime{}
+	for i := 0; i < 100; i++ {
+		runtime.AcquireExecution()
+	}
+	for i := 0; i < 100; i++ {
+		runtime.ReleaseExecution()
+	}
+}
+
+func TestDispatchMessage(t *testing.T) {
+	runtime := GolangRuntime{}
+
+	processed := make(chan string)
+	runtime.DispatchMessage(types.MessageEnvelope{CorrelationID: "123"}, func(envelope types.MessageEnvelope) {
+		processed <- envelope.CorrelationID
+	})
+
+	select {
+	case correlationID := <-processed:
+		assert.Equal(t, "123", correlationID)
+	case <-time.After(time.Second):
+		t.Fatal("expected the message to be processed")
+	}
+}
+
+func TestDispatchMessageQueue(t *testing.T) {
+	runtime := GolangRuntime{}
+	runtime.SetMaxConcurrentExecutions(1)
+	runtime.SetMessageQueueDepth(2)
+
+	release := make(chan struct{})
+	processed := make(chan string, 5)
+	process := func(envelope types.MessageEnvelope) {
+		<-release
+		processed <- envelope.CorrelationID
+	}
+
+	// The first message is being processed and the second waits for an execution, so the next two are queued
+	runtime.DispatchMessage(types.MessageEnvelope{CorrelationID: "1"}, process)
+	runtime.DispatchMessage(types.MessageEnvelope{CorrelationID: "2"}, process)
+	for runtime.MessageQueueLength() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	runtime.DispatchMessage(types.MessageEnvelope{CorrelationID: "3"}, process)
+	runtime.DispatchMessage(types.MessageEnvelope{CorrelationID: "4"}, process)
+	assert.Equal(t, 2, runtime.MessageQueueLength())
+	assert.Equal(t, 2, runtime.GetMetrics().MessageQueueDepth)
+
+	dispatched := make(chan bool)
+	go func() {
+		runtime.DispatchMessage(types.MessageEnvelope{CorrelationID: "5"}, process)
+		dispatched <- true
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("expected DispatchMessage to block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-dispatched
+	var correlationIDs []string
+	for i := 0; i < 5; i++ {
+		correlationIDs = append(correlationIDs, <-processed)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, correlationIDs)
+}
@@ -0,0 +1,102 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	syscontext "context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrShuttingDown is the error of the messages received once the runtime has started draining
+var ErrShuttingDown = errors.New("Service is shutting down, message not processed")
+
+// drain tracks the running pipeline invocations so that shutdown can wait for them to complete
+type drain struct {
+	mutex    sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+	context  syscontext.Context
+	cancel   syscontext.CancelFunc
+}
+
+// start registers a pipeline invocation and returns the context cancelled when the drain times out, or false
+// when the runtime is draining. The registration and the check are under the same lock as Drain's, so no
+// invocation is added to the wait group once waiting for it has started.
+func (d *drain) start() (syscontext.Context, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.draining {
+		return nil, false
+	}
+	if d.context == nil {
+		d.context, d.cancel = syscontext.WithCancel(syscontext.Background())
+	}
+	d.inFlight.Add(1)
+	return d.context, true
+}
+
+func (d *drain) done() {
+	d.inFlight.Done()
+}
+
+// Drain stops the runtime from processing new messages, which fail with ErrShuttingDown, and waits up to the
+// timeout for the running pipeline invocations to complete. When the timeout expires, the contexts of the
+// invocations still running are cancelled and false is returned.
+func (gr *GolangRuntime) Drain(timeout time.Duration) bool {
+	d := &gr.drain
+	d.mutex.Lock()
+	d.draining = true
+	cancel := d.cancel
+	d.mutex.Unlock()
+
+	completed := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(completed)
+	}()
+
+	select {
+	case <-completed:
+		return true
+	case <-time.After(timeout):
+		if cancel != nil {
+			cancel()
+		}
+		return false
+	}
+}
+
+// withDrainContext returns the request context of an invocation, which is also cancelled when the drain context
+// is, along with the function releasing it. The drain context is used when there's no request context.
+func withDrainContext(requestContext syscontext.Context, drainContext syscontext.Context) (syscontext.Context, syscontext.CancelFunc) {
+	if requestContext == nil {
+		return drainContext, func() {}
+	}
+
+	ctx, cancel := syscontext.WithCancel(requestContext)
+	go func() {
+		select {
+		case <-drainContext.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
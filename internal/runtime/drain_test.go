@@ -0,0 +1,102 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// startBlockingPipeline processes a message thru a pipeline which blocks until release is closed or its context is
+// cancelled. Once the pipeline has started, returns the channels receiving its result and whether it was cancelled
+func startBlockingPipeline(runtime *GolangRuntime, release chan struct{}) (chan *MessageError, chan bool) {
+	started := make(chan bool)
+	cancelled := make(chan bool, 1)
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			close(started)
+			select {
+			case <-release:
+				cancelled <- false
+			case <-edgexcontext.RequestContext.Done():
+				cancelled <- true
+			}
+			return false, nil
+		},
+	})
+
+	result := make(chan *MessageError, 1)
+	go func() {
+		envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte(`"data"`), ContentType: clients.ContentTypeJSON}
+		result <- runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, envelope)
+	}()
+	<-started
+	return result, cancelled
+}
+
+func TestDrain(t *testing.T) {
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	release := make(chan struct{})
+	result, cancelled := startBlockingPipeline(&runtime, release)
+
+	drained := make(chan bool)
+	go func() { drained <- runtime.Drain(time.Second) }()
+
+	// Wait for the drain to start so that the message below is received while draining
+	for {
+		runtime.drain.mutex.Lock()
+		draining := runtime.drain.draining
+		runtime.drain.mutex.Unlock()
+		if draining {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	messageError := runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, types.MessageEnvelope{Payload: []byte(`"data"`)})
+	if !assert.NotNil(t, messageError) {
+		t.Fatal()
+	}
+	assert.Equal(t, ErrShuttingDown, messageError.Err)
+	assert.Equal(t, http.StatusServiceUnavailable, messageError.ErrorCode)
+
+	close(release)
+	assert.True(t, <-drained, "expected the running pipeline to complete")
+	assert.Nil(t, <-result)
+	assert.False(t, <-cancelled)
+}
+
+func TestDrainTimeout(t *testing.T) {
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	result, cancelled := startBlockingPipeline(&runtime, make(chan struct{}))
+
+	assert.False(t, runtime.Drain(10*time.Millisecond), "expected the drain to time out")
+	assert.True(t, <-cancelled, "expected the context of the running pipeline to be cancelled")
+	assert.Nil(t, <-result)
+}
+
+func TestDrainNothingRunning(t *testing.T) {
+	runtime := GolangRuntime{}
+	assert.True(t, runtime.Drain(time.Second))
+}
@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are the statistics of the messages processed by the runtime
+type Metrics struct {
+	EventsReceived           uint64
+	EventsProcessed          uint64
+	EventsFailed             uint64
+	AveragePipelineLatencyMs float64
+	MessageQueueDepth        int
+}
+
+// counters are only accessed atomically. ResetMetrics swaps in a new set, so every counter is reset at once.
+type counters struct {
+	received     uint64
+	processed    uint64
+	failed       uint64
+	latencyNanos uint64
+}
+
+// countMessage counts the message as received, then as processed or failed depending on the result of process,
+// and accumulates the time taken to process it.
+// A message being processed when the metrics are reset is only counted in the discarded counters.
+func (gr *GolangRuntime) countMessage(process func() *MessageError) *MessageError {
+	current := gr.currentCounters()
+	atomic.AddUint64(&current.received, 1)
+	start := time.Now()
+
+	messageError := process()
+
+	atomic.AddUint64(&current.latencyNanos, uint64(time.Since(start)))
+	if messageError != nil {
+		atomic.AddUint64(&current.failed, 1)
+	} else {
+		atomic.AddUint64(&current.processed, 1)
+	}
+
+	return messageError
+}
+
+// ResetMetrics atomically zeros the counters and the accumulated processing time by replacing them
+func (gr *GolangRuntime) ResetMetrics() {
+	gr.counters.Store(&counters{})
+}
+
+// currentCounters returns the counters, creating them on first use
+func (gr *GolangRuntime) currentCounters() *counters {
+	if current, ok := gr.counters.Load().(*counters); ok {
+		return current
+	}
+	gr.counters.CompareAndSwap(nil, &counters{})
+	return gr.counters.Load().(*counters)
+}
+
+// GetMetrics returns a snapshot of the statistics of the messages processed so far
+func (gr *GolangRuntime) GetMetrics() Metrics {
+	current := gr.currentCounters()
+	metrics := Metrics{
+		EventsReceived:    atomic.LoadUint64(&current.received),
+		EventsProcessed:   atomic.LoadUint64(&current.processed),
+		EventsFailed:      atomic.LoadUint64(&current.failed),
+		MessageQueueDepth: gr.MessageQueueLength(),
+	}
+
+	if completed := metrics.EventsProcessed + metrics.EventsFailed; completed > 0 {
+		latency := time.Duration(atomic.LoadUint64(&current.latencyNanos) / completed)
+		metrics.AveragePipelineLatencyMs = float64(latency) / float64(time.Millisecond)
+	}
+
+	return metrics
+}
@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// autoRestart recovers the panics of the pipelines, pausing for the cooldown after each one
+type autoRestart struct {
+	maxRestarts int
+	cooldown    time.Duration
+	shutdown    func(err error)
+	mutex       sync.Mutex
+	consecutive int
+	restartedAt time.Time
+}
+
+// EnableAutoRestart recovers the panics of the pipelines instead of letting them crash the service. After each
+// panic, the stack trace is logged and the goroutine which processed the message sleeps for the cooldown before
+// the message fails, up to maxRestarts times. A panic occurring within the cooldown of the previous restart is
+// consecutive, and once there have been more than maxRestarts consecutive panics, shutdown is called instead.
+// Must be enabled before the triggers are initialized.
+func (gr *GolangRuntime) EnableAutoRestart(maxRestarts int, cooldown time.Duration, shutdown func(err error)) {
+	gr.autoRestart = &autoRestart{maxRestarts: maxRestarts, cooldown: cooldown, shutdown: shutdown}
+}
+
+// recoverPanic recovers the panic of the pipeline, if any, and sets messageError to the error resulting from it
+func (restart *autoRestart) recoverPanic(logger logger.LoggingClient, correlationID string, messageError **MessageError) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	err := fmt.Errorf("Pipeline panicked: %v", recovered)
+	*messageError = &MessageError{Err: err, ErrorCode: http.StatusInternalServerError}
+	logger.Error(err.Error(), "stack", string(debug.Stack()), clients.CorrelationHeader, correlationID)
+
+	restart.mutex.Lock()
+	if restart.consecutive > 0 && time.Since(restart.restartedAt) <= restart.cooldown {
+		restart.consecutive++
+	} else {
+		restart.consecutive = 1
+	}
+	consecutive := restart.consecutive
+	restart.mutex.Unlock()
+
+	if consecutive > restart.maxRestarts {
+		restart.shutdown(fmt.Errorf("Pipeline panicked %d consecutive times", consecutive))
+		return
+	}
+
+	logger.Warn(fmt.Sprintf("Restarting message processing in %s, restart %d of %d", restart.cooldown, consecutive, restart.maxRestarts))
+	time.Sleep(restart.cooldown)
+
+	restart.mutex.Lock()
+	restart.restartedAt = time.Now()
+	restart.mutex.Unlock()
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+func newPanickingRuntime() *GolangRuntime {
+	runtime := &GolangRuntime{TargetType: &[]byte{}}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			panic("pipeline function failed")
+		},
+	})
+	return runtime
+}
+
+func TestAutoRestart(t *testing.T) {
+	runtime := newPanickingRuntime()
+	var shutdownErr error
+	runtime.EnableAutoRestart(2, 10*time.Millisecond, func(err error) { shutdownErr = err })
+
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte(`"data"`)}
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		messageError := runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, envelope)
+		if !assert.NotNil(t, messageError) {
+			t.Fatal()
+		}
+		assert.EqualError(t, messageError.Err, "Pipeline panicked: pipeline function failed")
+		assert.Equal(t, http.StatusInternalServerError, messageError.ErrorCode)
+		assert.True(t, time.Since(start) >= 10*time.Millisecond, "expected the processing to pause for the cooldown")
+		assert.NoError(t, shutdownErr)
+	}
+
+	messageError := runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, envelope)
+	assert.NotNil(t, messageError)
+	assert.EqualError(t, shutdownErr, "Pipeline panicked 3 consecutive times")
+}
+
+func TestAutoRestartNotConsecutive(t *testing.T) {
+	runtime := newPanickingRuntime()
+	var shutdownErr error
+	runtime.EnableAutoRestart(1, time.Millisecond, func(err error) { shutdownErr = err })
+
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte(`"data"`)}
+	for i := 0; i < 3; i++ {
+		runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, envelope)
+		// Panics occurring after the cooldown of the previous restart aren't consecutive
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.NoError(t, shutdownErr)
+}
+
+func TestAutoRestartNotEnabled(t *testing.T) {
+	runtime := newPanickingRuntime()
+	envelope := types.MessageEnvelope{CorrelationID: "123", Payload: []byte(`"data"`)}
+
+	assert.Panics(t, func() {
+		runtime.ProcessMessage(&appcontext.Context{LoggingClient: lc}, envelope)
+	})
+}
@@ -23,9 +23,13 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
 	"github.com/ugorji/go/codec"
@@ -35,20 +39,170 @@ const unmarshalErrorMessage = "Unable to unmarshal message payload as %s"
 
 // GolangRuntime represents the golang runtime environment
 type GolangRuntime struct {
-	TargetType    interface{}
+	// counters holds the *counters of the messages processed since the runtime was created or last reset
+	counters   atomic.Value
+	TargetType interface{}
+	// StoreForward stores the data of failed exports for later retry when its StoreClient is set
+	StoreForward StoreForward
+	// ErrorHandler, when set, is called in its own goroutine with the data a pipeline function received
+	// whenever that function stops the pipeline with an error
+	ErrorHandler  ErrorHandler
 	transforms    []appcontext.AppFunction
+	names         []string
+	pipelines     []FunctionPipeline
+	middleware    []Middleware
 	isBusyCopying sync.Mutex
+	drain         drain
+	executions    chan struct{}
+	queue         chan queuedMessage
+	autoRestart   *autoRestart
+}
+
+// ErrorHandler handles the error returned by the pipeline function at stageIndex, with the stage name fn, for the
+// data it received
+type ErrorHandler func(fn string, stageIndex int, err error, data interface{})
+
+// MessageProcessor processes a received message thru a functions pipeline
+type MessageProcessor func(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error
+
+// Middleware wraps the MessageProcessor of the next middleware, or of the functions pipeline for the last one.
+type Middleware func(next MessageProcessor) MessageProcessor
+
+// FunctionPipeline is a functions pipeline that is executed for the messages received on the topics
+// matching its TopicFilter, in addition to the default functions pipeline, or instead of the default
+// functions pipeline for the HTTP requests posted to its Path below the trigger route.
+type FunctionPipeline struct {
+	Id          string
+	TopicFilter string
+	Path        string
+	Transforms  []appcontext.AppFunction
 }
 
 type MessageError struct {
 	Err       error
 	ErrorCode int
+	// Stored is true when the data of the pipeline function which failed was stored for later retry
+	Stored bool
 }
 
 // ProcessMessage sends the contents of the message thru the functions pipeline
 func (gr *GolangRuntime) ProcessMessage(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) *MessageError {
+	// Make copy of transform functions to avoid disruption of pipeline when updating the pipeline from registry
+	gr.isBusyCopying.Lock()
+	transforms := make([]appcontext.AppFunction, len(gr.transforms))
+	copy(transforms, gr.transforms)
+	names := StageNames(gr.names, len(transforms))
+	gr.isBusyCopying.Unlock()
+
+	return gr.processMessage(edgexcontext, envelope, transforms, names, true)
+}
+
+// ProcessMessageForPipeline sends the contents of the message thru the functions pipeline with the specified id
+func (gr *GolangRuntime) ProcessMessageForPipeline(edgexcontext *appcontext.Context, envelope types.MessageEnvelope, id string) *MessageError {
+	var transforms []appcontext.AppFunction
+
+	gr.isBusyCopying.Lock()
+	for _, pipeline := range gr.pipelines {
+		if pipeline.Id == id {
+			transforms = make([]appcontext.AppFunction, len(pipeline.Transforms))
+			copy(transforms, pipeline.Transforms)
+			break
+		}
+	}
+	gr.isBusyCopying.Unlock()
+
+	if transforms == nil {
+		err := fmt.Errorf("function pipeline '%s' not found", id)
+		edgexcontext.LoggingClient.Error(err.Error())
+		return &MessageError{Err: err, ErrorCode: http.StatusInternalServerError}
+	}
+
+	return gr.processMessage(edgexcontext, envelope, transforms, StageNames(nil, len(transforms)), false)
+}
+
+// processMessage runs the message thru the middleware, the first one added being the outermost, before the
+// functions pipeline is executed. The retry data of a failed function is stored when storeForward is true.
+// A correlation ID is generated for messages received without one. The names are the stage names of the functions.
+// When auto restart is enabled, a panic of the pipeline is recovered and returned as a MessageError.
+func (gr *GolangRuntime) processMessage(edgexcontext *appcontext.Context, envelope types.MessageEnvelope, transforms []appcontext.AppFunction, names []string, storeForward bool) (messageError *MessageError) {
+	if envelope.CorrelationID == "" {
+		envelope.CorrelationID = common.NewCorrelationID()
+	}
+
+	drainContext, ok := gr.drain.start()
+	if !ok {
+		return &MessageError{Err: ErrShuttingDown, ErrorCode: http.StatusServiceUnavailable}
+	}
+	if gr.autoRestart != nil {
+		// Deferred before the drain is done, so that the panic is recovered once the invocation no longer runs
+		defer gr.autoRestart.recoverPanic(edgexcontext.LoggingClient, envelope.CorrelationID, &messageError)
+	}
+	defer gr.drain.done()
+
+	originalContext := edgexcontext.RequestContext
+	requestContext, cancel := withDrainContext(originalContext, drainContext)
+	edgexcontext.RequestContext = requestContext
+	defer func() {
+		cancel()
+		edgexcontext.RequestContext = originalContext
+	}()
+
+	span := tracing.StartRemoteSpan("message receive", tracing.ParseTraceParent(edgexcontext.TraceParent))
+	span.SetAttribute(clients.CorrelationHeader, envelope.CorrelationID)
+	span.SetAttribute(clients.ContentType, envelope.ContentType)
+	if span != nil {
+		edgexcontext.RequestContext = tracing.ContextWithSpan(edgexcontext.RequestContext, span)
+	}
+
+	messageError = gr.runMiddleware(edgexcontext, envelope, transforms, names, storeForward)
+	if messageError != nil {
+		span.SetError(messageError.Err)
+	}
+	span.Finish()
+	return messageError
+}
+
+func (gr *GolangRuntime) runMiddleware(edgexcontext *appcontext.Context, envelope types.MessageEnvelope, transforms []appcontext.AppFunction, names []string, storeForward bool) *MessageError {
+	gr.isBusyCopying.Lock()
+	middleware := make([]Middleware, len(gr.middleware))
+	copy(middleware, gr.middleware)
+	gr.isBusyCopying.Unlock()
 
-	edgexcontext.LoggingClient.Debug("Processing message: " + strconv.Itoa(len(gr.transforms)) + " Transforms")
+	if len(middleware) == 0 {
+		return gr.countMessage(func() *MessageError {
+			return gr.executePipeline(edgexcontext, envelope, transforms, names, storeForward)
+		})
+	}
+
+	var messageError *MessageError
+	processor := MessageProcessor(func(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error {
+		messageError = gr.executePipeline(edgexcontext, envelope, transforms, names, storeForward)
+		if messageError != nil {
+			return messageError.Err
+		}
+		return nil
+	})
+	for index := len(middleware) - 1; index >= 0; index-- {
+		processor = middleware[index](processor)
+	}
+
+	return gr.countMessage(func() *MessageError {
+		err := processor(edgexcontext, envelope)
+		if err == nil {
+			return nil
+		}
+		if messageError != nil && err == messageError.Err {
+			return messageError
+		}
+
+		edgexcontext.LoggingClient.Error("Trigger middleware resulted in error", "error", err.Error(), clients.CorrelationHeader, envelope.CorrelationID)
+		return &MessageError{Err: err, ErrorCode: http.StatusInternalServerError}
+	})
+}
+
+func (gr *GolangRuntime) executePipeline(edgexcontext *appcontext.Context, envelope types.MessageEnvelope, transforms []appcontext.AppFunction, names []string, storeForward bool) *MessageError {
+
+	edgexcontext.LoggingClient.Debug("Processing message: " + strconv.Itoa(len(transforms)) + " Transforms")
 
 	if gr.TargetType == nil {
 		gr.TargetType = &models.Event{}
@@ -115,39 +269,170 @@ func (gr *GolangRuntime) ProcessMessage(edgexcontext *appcontext.Context, envelo
 	// dereference to pointer to the object
 	target = reflect.ValueOf(target).Elem().Interface()
 
-	var result interface{}
-	var continuePipeline = true
+	return gr.executeTransforms(edgexcontext, envelope.CorrelationID, transforms, names, 0, storeForward, target, contentType)
+}
 
-	// Make copy of transform functions to avoid disruption of pipeline when updating the pipeline from registry
+// ExecuteFromPosition runs the data thru the functions of the default functions pipeline starting at the
+// specified position, i.e. to replay data which was stored by a function at that position.
+func (gr *GolangRuntime) ExecuteFromPosition(edgexcontext *appcontext.Context, data interface{}, position int) *MessageError {
 	gr.isBusyCopying.Lock()
-	transforms := make([]appcontext.AppFunction, len(gr.transforms))
-	copy(transforms, gr.transforms)
+	var transforms []appcontext.AppFunction
+	var names []string
+	if position >= 0 && position < len(gr.transforms) {
+		transforms = make([]appcontext.AppFunction, len(gr.transforms)-position)
+		copy(transforms, gr.transforms[position:])
+		names = StageNames(gr.names, len(gr.transforms))[position:]
+	}
 	gr.isBusyCopying.Unlock()
 
+	if transforms == nil {
+		err := fmt.Errorf("pipeline position %d is not in the functions pipeline", position)
+		edgexcontext.LoggingClient.Error(err.Error())
+		return &MessageError{Err: err, ErrorCode: http.StatusInternalServerError}
+	}
+
+	span := tracing.StartSpan("pipeline replay", tracing.SpanFromContext(edgexcontext.RequestContext))
+	span.SetAttribute(clients.CorrelationHeader, edgexcontext.CorrelationID)
+	span.SetAttribute("position", strconv.Itoa(position))
+	if span != nil {
+		originalContext := edgexcontext.RequestContext
+		edgexcontext.RequestContext = tracing.ContextWithSpan(originalContext, span)
+		defer func() { edgexcontext.RequestContext = originalContext }()
+	}
+
+	messageError := gr.executeTransforms(edgexcontext, edgexcontext.CorrelationID, transforms, names, position, false, data)
+	if messageError != nil {
+		span.SetError(messageError.Err)
+	}
+	span.Finish()
+	return messageError
+}
+
+// executeTransforms calls each function with the result of the previous function, the first function being
+// called with the specified params. When storeForward is true, the retry data set by a function which results
+// in error is stored for later retry. The names are the stage names of the functions, used in the logs and traces,
+// and position is the index in the pipeline of the first function.
+func (gr *GolangRuntime) executeTransforms(edgexcontext *appcontext.Context, correlationID string, transforms []appcontext.AppFunction, names []string, position int, storeForward bool, params ...interface{}) *MessageError {
+	var result interface{}
+	var continuePipeline = true
+
+	pipelineContext := edgexcontext.RequestContext
+	pipelineSpan := tracing.SpanFromContext(pipelineContext)
+	defer func() { edgexcontext.RequestContext = pipelineContext }()
+
 	for index, trxFunc := range transforms {
+		stageIndex := position + index
+		var span *tracing.Span
+		if pipelineSpan != nil {
+			span = tracing.StartSpan(fmt.Sprintf("pipeline function #%d", stageIndex), pipelineSpan)
+			span.SetAttribute("function", functionName(trxFunc))
+			span.SetAttribute("stage", names[index])
+			edgexcontext.RequestContext = tracing.ContextWithSpan(pipelineContext, span)
+		}
+
+		var input interface{}
 		if result != nil {
+			input = result
 			continuePipeline, result = trxFunc(edgexcontext, result)
 		} else {
-			continuePipeline, result = trxFunc(edgexcontext, target, contentType)
+			if len(params) > 0 {
+				input = params[0]
+			}
+			continuePipeline, result = trxFunc(edgexcontext, params...)
 		}
 		if continuePipeline != true {
 			if result != nil {
 				if err, ok := result.(error); ok {
-					edgexcontext.LoggingClient.Error(fmt.Sprintf("Pipeline function #%d resulted in error", index),
-						"error", err.Error(), clients.CorrelationHeader, envelope.CorrelationID)
-					return &MessageError{Err: err, ErrorCode: http.StatusUnprocessableEntity}
+					span.SetError(err)
+					edgexcontext.LoggingClient.Error(fmt.Sprintf("Pipeline function #%d (%s) resulted in error", stageIndex, names[index]),
+						"error", err.Error(), clients.CorrelationHeader, correlationID)
+					stored := false
+					if storeForward {
+						stored = gr.storeForLaterRetry(edgexcontext, correlationID, err, index, transforms)
+					}
+					if gr.ErrorHandler != nil {
+						go gr.handleError(edgexcontext.LoggingClient, names[index], stageIndex, err, input)
+					}
+					span.Finish()
+					return &MessageError{Err: err, ErrorCode: http.StatusUnprocessableEntity, Stored: stored}
 				}
 			}
+			span.Finish()
 			break
 		}
+		span.Finish()
 	}
 
 	return nil
 }
 
+// handleError calls the ErrorHandler, recovering from its panic so that it doesn't crash the service
+func (gr *GolangRuntime) handleError(loggingClient logger.LoggingClient, name string, stageIndex int, err error, data interface{}) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			loggingClient.Error(fmt.Sprintf("Error handler of pipeline function #%d (%s) panicked: %v", stageIndex, name, recovered))
+		}
+	}()
+
+	gr.ErrorHandler(name, stageIndex, err, data)
+}
+
+// AddMiddleware is thread safe to add middleware which wraps the processing of every received message
+func (gr *GolangRuntime) AddMiddleware(middleware Middleware) {
+	gr.isBusyCopying.Lock()
+	gr.middleware = append(gr.middleware, middleware)
+	gr.isBusyCopying.Unlock()
+}
+
 // SetTransforms is thread safe to set transforms
 func (gr *GolangRuntime) SetTransforms(transforms []appcontext.AppFunction) {
 	gr.isBusyCopying.Lock()
 	gr.transforms = transforms
 	gr.isBusyCopying.Unlock()
 }
+
+// SetFunctionNames is thread safe to set the names of the functions of the default functions pipeline, in the
+// order of the transforms. An empty name is replaced by the stage name of the function, see StageNames.
+func (gr *GolangRuntime) SetFunctionNames(names []string) {
+	gr.isBusyCopying.Lock()
+	gr.names = names
+	gr.isBusyCopying.Unlock()
+}
+
+// StageNames returns the names of count pipeline functions, using "stage_<index>" for the functions without a name
+func StageNames(names []string, count int) []string {
+	stages := make([]string, count)
+	for index := range stages {
+		if index < len(names) && names[index] != "" {
+			stages[index] = names[index]
+		} else {
+			stages[index] = fmt.Sprintf("stage_%d", index)
+		}
+	}
+	return stages
+}
+
+// AddFunctionPipeline is thread safe to add a functions pipeline. The pipeline id must be unique.
+func (gr *GolangRuntime) AddFunctionPipeline(pipeline FunctionPipeline) error {
+	gr.isBusyCopying.Lock()
+	defer gr.isBusyCopying.Unlock()
+
+	for _, existing := range gr.pipelines {
+		if existing.Id == pipeline.Id {
+			return fmt.Errorf("function pipeline '%s' already exists", pipeline.Id)
+		}
+	}
+
+	gr.pipelines = append(gr.pipelines, pipeline)
+	return nil
+}
+
+// GetFunctionPipelines is thread safe to get a copy of the functions pipelines that have been added
+func (gr *GolangRuntime) GetFunctionPipelines() []FunctionPipeline {
+	gr.isBusyCopying.Lock()
+	defer gr.isBusyCopying.Unlock()
+
+	pipelines := make([]FunctionPipeline, len(gr.pipelines))
+	copy(pipelines, gr.pipelines)
+	return pipelines
+}
@@ -17,16 +17,23 @@ package runtime
 
 import (
 	"bytes"
+	syscontext "context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/ugorji/go/codec"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
 	"github.com/edgexfoundry/app-functions-sdk-go/pkg/transforms"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
@@ -273,6 +280,58 @@ func TestProcessMessageTransformError(t *testing.T) {
 	assert.Equal(t, err.ErrorCode, expectedErrorCode)
 }
 
+func TestProcessMessageErrorHandler(t *testing.T) {
+	eventIn := models.Event{
+		Device: devID1,
+	}
+	eventInBytes, _ := json.Marshal(eventIn)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       eventInBytes,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+	expectedErr := errors.New("export failed")
+
+	type handled struct {
+		fn         string
+		stageIndex int
+		err        error
+		data       interface{}
+	}
+	handledErrors := make(chan handled, 1)
+
+	transform1 := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, "Transform1Result"
+	}
+	transform2 := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return false, expectedErr
+	}
+	runtime := GolangRuntime{
+		ErrorHandler: func(fn string, stageIndex int, err error, data interface{}) {
+			handledErrors <- handled{fn: fn, stageIndex: stageIndex, err: err, data: data}
+		},
+	}
+	runtime.SetTransforms([]appcontext.AppFunction{transform1, transform2})
+
+	result := runtime.ProcessMessage(context, envelope)
+	if !assert.NotNil(t, result, "Expected an error") {
+		t.Fatal()
+	}
+
+	select {
+	case h := <-handledErrors:
+		assert.Equal(t, "stage_1", h.fn)
+		assert.Equal(t, 1, h.stageIndex)
+		assert.Equal(t, expectedErr, h.err)
+		assert.Equal(t, "Transform1Result", h.data)
+	case <-time.After(time.Second):
+		t.Fatal("error handler was not called")
+	}
+}
+
 func TestProcessMessageJSON(t *testing.T) {
 	// Event from device 1
 	expectedEventID := "1234"
@@ -473,3 +532,487 @@ func TestProcessMessageTargetType(t *testing.T) {
 		assert.Equal(t, currentTest.ExpectedOutputData, context.OutputData, fmt.Sprintf("'%s' test failed", currentTest.Name))
 	}
 }
+
+func TestAddFunctionPipeline(t *testing.T) {
+	runtime := GolangRuntime{}
+
+	err := runtime.AddFunctionPipeline(FunctionPipeline{Id: "pipeline1", TopicFilter: "edgex/events/#"})
+	assert.NoError(t, err)
+	err = runtime.AddFunctionPipeline(FunctionPipeline{Id: "pipeline2", TopicFilter: "edgex/events/#"})
+	assert.NoError(t, err)
+	err = runtime.AddFunctionPipeline(FunctionPipeline{Id: "pipeline1", TopicFilter: "edgex/other"})
+	assert.Error(t, err, "expected error for duplicate pipeline id")
+
+	pipelines := runtime.GetFunctionPipelines()
+	assert.Equal(t, 2, len(pipelines))
+	assert.Equal(t, "pipeline1", pipelines[0].Id)
+	assert.Equal(t, "pipeline2", pipelines[1].Id)
+}
+
+func TestProcessMessageForPipeline(t *testing.T) {
+	eventIn := models.Event{
+		Device: devID1,
+	}
+	eventInBytes, _ := json.Marshal(eventIn)
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       eventInBytes,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+
+	defaultCalled := false
+	pipelineCalled := false
+	runtime := GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			defaultCalled = true
+			return true, nil
+		},
+	})
+	_ = runtime.AddFunctionPipeline(FunctionPipeline{
+		Id:          "pipeline1",
+		TopicFilter: "edgex/events/#",
+		Transforms: []appcontext.AppFunction{
+			func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+				pipelineCalled = true
+				return true, nil
+			},
+		},
+	})
+
+	result := runtime.ProcessMessageForPipeline(context, envelope, "pipeline1")
+	assert.Nil(t, result)
+	assert.True(t, pipelineCalled, "expected pipeline function to be called")
+	assert.False(t, defaultCalled, "expected default pipeline function not to be called")
+
+	result = runtime.ProcessMessageForPipeline(context, envelope, "bogus")
+	assert.NotNil(t, result, "expected error for unknown pipeline")
+	assert.Equal(t, http.StatusInternalServerError, result.ErrorCode)
+}
+
+func TestProcessMessageMiddleware(t *testing.T) {
+	eventInBytes, _ := json.Marshal(models.Event{Device: devID1})
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       eventInBytes,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+
+	var order []string
+	runtime := GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			order = append(order, "pipeline")
+			return false, errors.New("pipeline failed")
+		},
+	})
+	for _, name := range []string{"first", "second"} {
+		name := name
+		runtime.AddMiddleware(func(next MessageProcessor) MessageProcessor {
+			return func(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error {
+				order = append(order, name)
+				return next(edgexcontext, envelope)
+			}
+		})
+	}
+
+	result := runtime.ProcessMessage(context, envelope)
+	assert.Equal(t, []string{"first", "second", "pipeline"}, order)
+	if !assert.NotNil(t, result) {
+		t.Fatal()
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, result.ErrorCode, "expected pipeline error to be returned as is")
+}
+
+func TestProcessMessageMiddlewareRejects(t *testing.T) {
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+
+	called := false
+	runtime := GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			called = true
+			return true, nil
+		},
+	})
+	runtime.AddMiddleware(func(next MessageProcessor) MessageProcessor {
+		return func(edgexcontext *appcontext.Context, envelope types.MessageEnvelope) error {
+			return errors.New("unauthorized")
+		}
+	})
+
+	result := runtime.ProcessMessage(context, types.MessageEnvelope{ContentType: clients.ContentTypeJSON})
+	assert.False(t, called, "expected pipeline not to be called")
+	if !assert.NotNil(t, result) {
+		t.Fatal()
+	}
+	assert.Equal(t, "unauthorized", result.Err.Error())
+	assert.Equal(t, http.StatusInternalServerError, result.ErrorCode)
+}
+
+func TestExecuteFromPosition(t *testing.T) {
+	context := &appcontext.Context{
+		CorrelationID: "123-234-345-456",
+		LoggingClient: lc,
+	}
+
+	var called []int
+	runtime := GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			called = append(called, 0)
+			return true, params[0]
+		},
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			called = append(called, 1)
+			assert.Equal(t, "stored data", params[0])
+			return true, params[0]
+		},
+	})
+
+	result := runtime.ExecuteFromPosition(context, "stored data", 1)
+	assert.Nil(t, result)
+	assert.Equal(t, []int{1}, called, "expected only the functions from the position to be called")
+
+	result = runtime.ExecuteFromPosition(context, "stored data", 2)
+	if !assert.NotNil(t, result, "expected error for position outside of the pipeline") {
+		t.Fatal()
+	}
+	assert.Equal(t, http.StatusInternalServerError, result.ErrorCode)
+}
+
+func TestExecuteFromPositionErrorHandler(t *testing.T) {
+	context := &appcontext.Context{
+		CorrelationID: "123-234-345-456",
+		LoggingClient: lc,
+	}
+	expectedErr := errors.New("export failed")
+
+	handledErrors := make(chan int, 2)
+	runtime := GolangRuntime{
+		ErrorHandler: func(fn string, stageIndex int, err error, data interface{}) {
+			assert.Equal(t, "export", fn)
+			handledErrors <- stageIndex
+			// a panic of the handler must not crash the service
+			panic("handler failed")
+		},
+	}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return true, params[0]
+		},
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return false, expectedErr
+		},
+	})
+	runtime.SetFunctionNames([]string{"", "export"})
+
+	result := runtime.ExecuteFromPosition(context, "stored data", 1)
+	if !assert.NotNil(t, result, "Expected an error") {
+		t.Fatal()
+	}
+
+	select {
+	case stageIndex := <-handledErrors:
+		assert.Equal(t, 1, stageIndex, "expected the index of the function in the pipeline")
+	case <-time.After(time.Second):
+		t.Fatal("error handler was not called")
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	eventInBytes, _ := json.Marshal(models.Event{Device: devID1})
+	envelope := types.MessageEnvelope{
+		CorrelationID: "123-234-345-456",
+		Payload:       eventInBytes,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+
+	fail := false
+	runtime := GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			if fail {
+				return false, errors.New("pipeline failed")
+			}
+			return true, nil
+		},
+	})
+
+	assert.Equal(t, Metrics{}, runtime.GetMetrics())
+
+	runtime.ProcessMessage(context, envelope)
+	runtime.ProcessMessage(context, envelope)
+	fail = true
+	runtime.ProcessMessage(context, envelope)
+
+	metrics := runtime.GetMetrics()
+	assert.Equal(t, uint64(3), metrics.EventsReceived)
+	assert.Equal(t, uint64(2), metrics.EventsProcessed)
+	assert.Equal(t, uint64(1), metrics.EventsFailed)
+	assert.True(t, metrics.AveragePipelineLatencyMs > 0, "Expected average latency to be recorded")
+}
+
+func TestResetMetrics(t *testing.T) {
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return true, nil
+		},
+	})
+
+	runtime.ProcessMessage(context, types.MessageEnvelope{Payload: []byte("data")})
+	assert.Equal(t, uint64(1), runtime.GetMetrics().EventsReceived)
+
+	runtime.ResetMetrics()
+	assert.Equal(t, Metrics{}, runtime.GetMetrics())
+}
+
+func TestResetMetricsConcurrent(t *testing.T) {
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			return true, nil
+		},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			runtime.ProcessMessage(context, types.MessageEnvelope{Payload: []byte("data")})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		runtime.ResetMetrics()
+		metrics := runtime.GetMetrics()
+		assert.True(t, metrics.EventsProcessed+metrics.EventsFailed <= metrics.EventsReceived,
+			"Expected no more completed than received messages")
+	}
+	<-done
+}
+
+func TestProcessMessageStoreForward(t *testing.T) {
+	connectionError := errors.New("connection refused")
+	validationError := errors.New("invalid payload")
+
+	tests := []struct {
+		name           string
+		err            error
+		persistOnError func(err error) bool
+		expectStored   bool
+	}{
+		{"All errors stored", validationError, nil, true},
+		{"Persisted error stored", connectionError, func(err error) bool { return err == connectionError }, true},
+		{"Other error not stored", validationError, func(err error) bool { return err == connectionError }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storeClient := &mocks.StoreClient{}
+			storeClient.On("Store", mock.Anything).Return("id", nil)
+
+			context := &appcontext.Context{
+				LoggingClient: lc,
+			}
+			runtime := GolangRuntime{
+				TargetType: &[]byte{},
+				StoreForward: StoreForward{
+					StoreClient:    storeClient,
+					ServiceKey:     "myService",
+					PersistOnError: test.persistOnError,
+				},
+			}
+			runtime.SetTransforms([]appcontext.AppFunction{
+				func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+					return true, params[0]
+				},
+				func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+					edgexcontext.SetRetryData([]byte("retry data"))
+					return false, test.err
+				},
+			})
+
+			envelope := types.MessageEnvelope{CorrelationID: "123-234-345-456", Payload: []byte("data")}
+			result := runtime.ProcessMessage(context, envelope)
+			if !assert.NotNil(t, result) {
+				t.Fatal()
+			}
+			assert.Equal(t, test.expectStored, result.Stored)
+
+			if !test.expectStored {
+				storeClient.AssertNotCalled(t, "Store", mock.Anything)
+				return
+			}
+
+			storeClient.AssertNumberOfCalls(t, "Store", 1)
+			stored := storeClient.Calls[0].Arguments.Get(0).(contracts.StoredObject)
+			assert.Equal(t, "myService", stored.AppServiceKey)
+			assert.Equal(t, []byte("retry data"), stored.Payload)
+			assert.Equal(t, 1, stored.PipelinePosition)
+			assert.Equal(t, "123-234-345-456", stored.CorrelationID)
+			assert.NotEmpty(t, stored.Version)
+		})
+	}
+}
+
+// contextStoreClient records the contexts its objects are stored with
+type contextStoreClient struct {
+	*mocks.StoreClient
+	contexts []syscontext.Context
+}
+
+func (client *contextStoreClient) StoreContext(ctx syscontext.Context, o contracts.StoredObject) (string, error) {
+	client.contexts = append(client.contexts, ctx)
+	return "id", nil
+}
+
+func (client *contextStoreClient) UpdateContext(ctx syscontext.Context, o contracts.StoredObject) error {
+	return nil
+}
+
+func TestProcessMessageStoreForwardContext(t *testing.T) {
+	storeClient := &contextStoreClient{StoreClient: &mocks.StoreClient{}}
+
+	context := &appcontext.Context{
+		LoggingClient: lc,
+	}
+	runtime := GolangRuntime{
+		TargetType:   &[]byte{},
+		StoreForward: StoreForward{StoreClient: storeClient, ServiceKey: "myService"},
+	}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			edgexcontext.SetRetryData([]byte("retry data"))
+			return false, errors.New("export failed")
+		},
+	})
+
+	result := runtime.ProcessMessage(context, types.MessageEnvelope{CorrelationID: "123", Payload: []byte("data")})
+	if !assert.NotNil(t, result) {
+		t.Fatal()
+	}
+	assert.True(t, result.Stored)
+	if !assert.Len(t, storeClient.contexts, 1) {
+		t.Fatal()
+	}
+	assert.NotNil(t, storeClient.contexts[0], "the pipeline's context must be used")
+	storeClient.AssertNotCalled(t, "Store", mock.Anything)
+}
+
+type recordingExporter struct {
+	spans []*tracing.Span
+}
+
+func (exporter *recordingExporter) Export(spans []*tracing.Span) error {
+	exporter.spans = append(exporter.spans, spans...)
+	return nil
+}
+
+func TestProcessMessageTracing(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer("myService", 0, exporter, nil)
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	storeClient := &mocks.StoreClient{}
+	storeClient.On("Store", mock.Anything).Return("id", nil)
+
+	context := &appcontext.Context{
+		LoggingClient: lc,
+		TraceParent:   "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	runtime := GolangRuntime{
+		TargetType:   &[]byte{},
+		StoreForward: StoreForward{StoreClient: storeClient, ServiceKey: "myService"},
+	}
+	var functionSpan *tracing.Span
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			functionSpan = tracing.SpanFromContext(edgexcontext.RequestContext)
+			return true, params[0]
+		},
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			edgexcontext.SetRetryData([]byte("retry data"))
+			return false, errors.New("export failed")
+		},
+	})
+
+	envelope := types.MessageEnvelope{CorrelationID: "123-234-345-456", Payload: []byte("data")}
+	result := runtime.ProcessMessage(context, envelope)
+	if !assert.NotNil(t, result) {
+		t.Fatal()
+	}
+	tracer.Flush()
+
+	names := make(map[string]*tracing.Span)
+	for _, span := range exporter.spans {
+		names[span.Name] = span
+	}
+	if !assert.Len(t, names, 4) {
+		t.Fatal()
+	}
+	receive := names["message receive"]
+	if !assert.NotNil(t, receive) {
+		t.Fatal()
+	}
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", fmt.Sprintf("%x", receive.TraceID))
+	assert.Equal(t, "b7ad6b7169203331", fmt.Sprintf("%x", receive.ParentID))
+	assert.Equal(t, "export failed", receive.Error)
+
+	assert.Equal(t, names["pipeline function #0"], functionSpan)
+	assert.Equal(t, receive.SpanID, names["pipeline function #0"].ParentID)
+	assert.Equal(t, receive.SpanID, names["pipeline function #1"].ParentID)
+	assert.Equal(t, "export failed", names["pipeline function #1"].Error)
+	assert.Equal(t, names["pipeline function #1"].SpanID, names["store for later retry"].ParentID)
+}
+
+func TestProcessMessageGeneratesCorrelationID(t *testing.T) {
+	common.SetCorrelationIDGenerator(func() string { return "generated-id" })
+	defer common.SetCorrelationIDGenerator(nil)
+
+	var correlationID string
+	runtime := GolangRuntime{TargetType: &[]byte{}}
+	runtime.SetTransforms([]appcontext.AppFunction{
+		func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+			correlationID = edgexcontext.CorrelationID
+			return false, nil
+		},
+	})
+
+	context := &appcontext.Context{LoggingClient: lc}
+	result := runtime.ProcessMessage(context, types.MessageEnvelope{Payload: []byte("data")})
+	assert.Nil(t, result)
+	assert.Equal(t, "generated-id", correlationID)
+
+	result = runtime.ProcessMessage(context, types.MessageEnvelope{CorrelationID: "received-id", Payload: []byte("data")})
+	assert.Nil(t, result)
+	assert.Equal(t, "received-id", correlationID, "Expected the received correlation ID to be kept")
+}
+
+func TestStageNames(t *testing.T) {
+	assert.Equal(t, []string{"stage_0", "stage_1"}, StageNames(nil, 2))
+	assert.Equal(t, []string{"filter", "stage_1", "stage_2"}, StageNames([]string{"filter", ""}, 3))
+	assert.Equal(t, []string{"filter"}, StageNames([]string{"filter", "export"}, 1))
+	assert.Empty(t, StageNames(nil, 0))
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package runtime
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	goruntime "runtime"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+)
+
+// StoreForward configures storing the retry data of the pipeline functions which result in error, so the data
+// can be exported later.
+type StoreForward struct {
+	// StoreClient stores the retry data, storing is disabled when nil
+	StoreClient interfaces.StoreClient
+	// ServiceKey is the AppServiceKey of the stored objects
+	ServiceKey string
+	// PersistOnError decides whether the data is stored for the error returned by the pipeline function. All
+	// errors are stored when nil.
+	PersistOnError func(err error) bool
+}
+
+// storeForLaterRetry stores the context's retry data, if any, so the pipeline can be resumed at the position of
+// the function which failed. It returns whether the data was stored.
+func (gr *GolangRuntime) storeForLaterRetry(edgexcontext *appcontext.Context, correlationID string, err error, position int, transforms []appcontext.AppFunction) bool {
+	storeForward := gr.StoreForward
+	if storeForward.StoreClient == nil || len(edgexcontext.RetryData) == 0 {
+		return false
+	}
+	if storeForward.PersistOnError != nil && !storeForward.PersistOnError(err) {
+		edgexcontext.LoggingClient.Debug("Not storing data for later retry, the error is not persisted",
+			"error", err.Error(), clients.CorrelationHeader, correlationID)
+		return false
+	}
+
+	span := tracing.StartSpan("store for later retry", tracing.SpanFromContext(edgexcontext.RequestContext))
+	defer span.Finish()
+
+	object, objectErr := contracts.NewStoredObject(storeForward.ServiceKey, correlationID, edgexcontext.RetryData,
+		position, pipelineVersion(transforms))
+	if objectErr != nil {
+		span.SetError(objectErr)
+		edgexcontext.LoggingClient.Error(fmt.Sprintf("Unable to store data for later retry: %s", objectErr.Error()),
+			clients.CorrelationHeader, correlationID)
+		return false
+	}
+	object.EventID = edgexcontext.EventID
+	object.EventChecksum = edgexcontext.EventChecksum
+
+	if _, storeErr := storeObject(edgexcontext, storeForward.StoreClient, object); storeErr != nil {
+		span.SetError(storeErr)
+		edgexcontext.LoggingClient.Error(fmt.Sprintf("Unable to store data for later retry: %s", storeErr.Error()),
+			clients.CorrelationHeader, correlationID)
+		return false
+	}
+
+	edgexcontext.LoggingClient.Trace("Stored data for later retry", clients.CorrelationHeader, correlationID)
+	return true
+}
+
+// storeObject stores the object with the pipeline's context when the StoreClient supports it, so that a write
+// waiting on the StoreClient, i.e. for a rate limit, is abandoned along with the pipeline.
+func storeObject(edgexcontext *appcontext.Context, client interfaces.StoreClient, object contracts.StoredObject) (string, error) {
+	if contextClient, ok := client.(interfaces.ContextStoreClient); ok && edgexcontext.RequestContext != nil {
+		return contextClient.StoreContext(edgexcontext.RequestContext, object)
+	}
+
+	return client.Store(object)
+}
+
+// pipelineVersion is a hash of the names of the pipeline functions, used to know if the pipeline has changed
+// since the data was stored.
+func pipelineVersion(transforms []appcontext.AppFunction) string {
+	hash := sha1.New()
+	for _, transform := range transforms {
+		hash.Write([]byte(functionName(transform)))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// functionName returns the qualified name of the pipeline function, empty if unknown
+func functionName(transform appcontext.AppFunction) string {
+	if function := goruntime.FuncForPC(reflect.ValueOf(transform).Pointer()); function != nil {
+		return function.Name()
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package jwt provides the validation of JSON Web Tokens signed with the keys of a JSON Web Key Set.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRefreshInterval is how often the keys are fetched again from the JWKS URL to pick up rotated keys
+	DefaultRefreshInterval = time.Hour
+	// DefaultMinRefreshInterval is the minimum time between fetches when a token is signed with an unknown key
+	DefaultMinRefreshInterval = time.Minute
+)
+
+// jsonWebKey is a key of a JSON Web Key Set, as defined by RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet caches the public keys of the JSON Web Key Set at a URL, fetching them again every RefreshInterval, or
+// when a token is signed with an unknown key, so that rotated keys are picked up.
+type KeySet struct {
+	URL string
+	// RefreshInterval is how often the keys are fetched. Defaults to DefaultRefreshInterval.
+	RefreshInterval time.Duration
+	// MinRefreshInterval limits how often the keys are fetched for unknown keys. Defaults to DefaultMinRefreshInterval.
+	MinRefreshInterval time.Duration
+	client             http.Client
+	mutex              sync.Mutex
+	keys               map[string]crypto.PublicKey
+	fetchedAt          time.Time
+	// fetching is closed when the fetch in progress completes, with its error in fetchErr
+	fetching chan struct{}
+	fetchErr error
+}
+
+// NewKeySet returns the key set of the JWKS at the URL. The keys are fetched on first use.
+func NewKeySet(url string, timeout time.Duration) *KeySet {
+	return &KeySet{
+		URL:                url,
+		RefreshInterval:    DefaultRefreshInterval,
+		MinRefreshInterval: DefaultMinRefreshInterval,
+		client:             http.Client{Timeout: timeout},
+	}
+}
+
+// Key returns the public key with the key ID. When kid is empty, the key set must have a single key.
+func (keySet *KeySet) Key(kid string) (crypto.PublicKey, error) {
+	keySet.mutex.Lock()
+	keys := keySet.keys
+	sinceFetch := time.Since(keySet.fetchedAt)
+	keySet.mutex.Unlock()
+
+	refreshed := false
+	if keys == nil || sinceFetch >= keySet.RefreshInterval {
+		var err error
+		if keys, err = keySet.refresh(); err != nil && keys == nil {
+			return nil, err
+		}
+		refreshed = err == nil
+	}
+
+	key, err := lookup(keys, kid)
+	if err == nil || refreshed || sinceFetch < keySet.MinRefreshInterval {
+		return key, err
+	}
+
+	// The key may have been rotated since the keys were fetched
+	if keys, err = keySet.refresh(); err != nil {
+		return nil, err
+	}
+	return lookup(keys, kid)
+}
+
+// refresh fetches the keys, or waits for the fetch already in progress, and returns the current keys. The mutex
+// isn't held during the request so that the tokens signed with cached keys are validated meanwhile.
+func (keySet *KeySet) refresh() (map[string]crypto.PublicKey, error) {
+	keySet.mutex.Lock()
+	if fetching := keySet.fetching; fetching != nil {
+		keySet.mutex.Unlock()
+		<-fetching
+
+		keySet.mutex.Lock()
+		defer keySet.mutex.Unlock()
+		return keySet.keys, keySet.fetchErr
+	}
+	fetching := make(chan struct{})
+	keySet.fetching = fetching
+	keySet.fetchedAt = time.Now()
+	keySet.mutex.Unlock()
+
+	keys, err := keySet.fetch()
+
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	// The keys are kept when the fetch fails
+	if err == nil {
+		keySet.keys = keys
+	}
+	keySet.fetchErr = err
+	keySet.fetching = nil
+	close(fetching)
+	return keySet.keys, err
+}
+
+func lookup(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if kid == "" {
+		if len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, errors.New("token has no key ID and the key set doesn't have a single key")
+	}
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("key '%s' not found in the key set", kid)
+	}
+	return key, nil
+}
+
+// fetch returns the keys fetched from the URL
+func (keySet *KeySet) fetch() (map[string]crypto.PublicKey, error) {
+	response, err := keySet.client.Get(keySet.URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch JWKS: %s", err.Error())
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch JWKS: %s", err.Error())
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch JWKS: status %d", response.StatusCode)
+	}
+
+	jwks := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.Unmarshal(body, &jwks); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal JWKS: %s", err.Error())
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped, tokens signed with them are rejected as signed with an unknown key
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+// publicKey returns the RSA or EC public key of the JWK
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type '%s'", jwk.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,241 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	// The hash functions of the supported algorithms are registered by importing them
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ErrMissingToken is returned when the request has no bearer token
+var ErrMissingToken = errors.New("Missing bearer token")
+
+// ErrInsufficientScope is returned for a valid token which lacks one of the required scopes
+type ErrInsufficientScope struct {
+	Scope string
+}
+
+func (e ErrInsufficientScope) Error() string {
+	return fmt.Sprintf("Token lacks the required scope '%s'", e.Scope)
+}
+
+// Validator validates the JSON Web Tokens signed with the keys of its KeySet, and that they have the
+// RequiredScopes in their scope claim
+type Validator struct {
+	KeySet *KeySet
+	// Issuer is the required iss claim, which isn't checked when empty
+	Issuer string
+	// Audience must be the aud claim or one of its values, which isn't checked when empty
+	Audience       string
+	RequiredScopes []string
+}
+
+// Authenticate validates the bearer token of the request's Authorization header, returning the HTTP status code
+// for the request: 401 Unauthorized when the token is missing, invalid or expired, 403 Forbidden when the token
+// lacks a required scope and 200 OK when it is valid.
+func (validator *Validator) Authenticate(r *http.Request) (int, error) {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) <= len("Bearer ") || !strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return http.StatusUnauthorized, ErrMissingToken
+	}
+
+	_, err := validator.Validate(strings.TrimSpace(authorization[len("Bearer "):]))
+	if err != nil {
+		if _, ok := err.(ErrInsufficientScope); ok {
+			return http.StatusForbidden, err
+		}
+		return http.StatusUnauthorized, err
+	}
+	return http.StatusOK, nil
+}
+
+// Validate verifies the signature of the token, that it has an expiration and isn't expired or not yet valid, that
+// it has the issuer, audience and required scopes, and returns its claims. RSA (RS256, RS384, RS512, PS256, PS384, PS512) and ECDSA (ES256,
+// ES384, ES512) signatures are supported.
+func (validator *Validator) Validate(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("Invalid token: malformed")
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("Invalid token: unable to decode header: %s", err.Error())
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid token: unable to decode signature: %s", err.Error())
+	}
+
+	key, err := validator.KeySet.Key(header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid token: %s", err.Error())
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("Invalid token: %s", err.Error())
+	}
+
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("Invalid token: unable to decode claims: %s", err.Error())
+	}
+
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("Invalid token: no expiration")
+	}
+	if !now.Before(time.Unix(int64(exp), 0)) {
+		return nil, errors.New("Token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("Token not valid yet")
+	}
+
+	if validator.Issuer != "" && claims["iss"] != validator.Issuer {
+		return nil, fmt.Errorf("Invalid token: issuer isn't '%s'", validator.Issuer)
+	}
+	if validator.Audience != "" && !hasAudience(claims, validator.Audience) {
+		return nil, fmt.Errorf("Invalid token: audience isn't '%s'", validator.Audience)
+	}
+
+	scopes := tokenScopes(claims)
+	for _, required := range validator.RequiredScopes {
+		if !scopes[required] {
+			return nil, ErrInsufficientScope{Scope: required}
+		}
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// verifySignature verifies the signature of the signing input with the key, which must be of the algorithm's type
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	if len(alg) != len("RS256") {
+		return fmt.Errorf("unsupported algorithm '%s'", alg)
+	}
+
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm '%s'", alg)
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an RSA key for algorithm '%s'", alg)
+		}
+		var err error
+		if alg[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		if err != nil {
+			return errors.New("signature verification failed")
+		}
+		return nil
+
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an EC key for algorithm '%s'", alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("signature verification failed")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported algorithm '%s'", alg)
+}
+
+// hasAudience returns whether the aud claim, a string or an array of strings, has the audience
+func hasAudience(claims map[string]interface{}, audience string) bool {
+	switch claim := claims["aud"].(type) {
+	case string:
+		return claim == audience
+	case []interface{}:
+		for _, value := range claim {
+			if value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenScopes returns the scopes of the space-separated scope claim, or of the scp claim used by some providers
+func tokenScopes(claims map[string]interface{}) map[string]bool {
+	scopes := make(map[string]bool)
+	for _, name := range []string{"scope", "scp"} {
+		switch claim := claims[name].(type) {
+		case string:
+			for _, scope := range strings.Fields(claim) {
+				scopes[scope] = true
+			}
+		case []interface{}:
+			for _, scope := range claim {
+				if value, ok := scope.(string); ok {
+					scopes[value] = true
+				}
+			}
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,240 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+var ecKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+func encodeSegment(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign returns a token with the claims signed with the RSA key using RS256, or the EC key using ES256
+func sign(t *testing.T, alg string, kid string, claims map[string]interface{}) string {
+	signingInput := encodeSegment(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeSegment(claims)
+	hasher := crypto.SHA256.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(padded(r.Bytes(), 32), padded(s.Bytes(), 32)...)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// padded left pads the big-endian value with zeros to size bytes
+func padded(value []byte, size int) []byte {
+	return append(make([]byte, size-len(value)), value...)
+}
+
+// newJWKSServer serves the keys with the key IDs, "rsa" for the RSA key and "ec" for the EC key
+func newJWKSServer(fetches *int32, kids *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(fetches, 1)
+		keys := []map[string]string{}
+		for _, kid := range *kids {
+			switch kid {
+			case "rsa":
+				keys = append(keys, map[string]string{"kty": "RSA", "kid": kid, "use": "sig",
+					"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+					"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())})
+			case "ec":
+				keys = append(keys, map[string]string{"kty": "EC", "kid": kid, "crv": "P-256",
+					"x": base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+					"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes())})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+}
+
+func TestValidate(t *testing.T) {
+	var fetches int32
+	ts := newJWKSServer(&fetches, &[]string{"rsa", "ec"})
+	defer ts.Close()
+
+	validator := Validator{KeySet: NewKeySet(ts.URL, time.Second), Issuer: "issuer", Audience: "edgex", RequiredScopes: []string{"trigger"}}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	claims, err := validator.Validate(sign(t, "RS256", "rsa", map[string]interface{}{"sub": "device", "iss": "issuer", "aud": "edgex", "exp": exp, "scope": "read trigger"}))
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "device", claims["sub"])
+
+	_, err = validator.Validate(sign(t, "ES256", "ec", map[string]interface{}{"iss": "issuer", "aud": []string{"other", "edgex"}, "exp": exp, "scp": []string{"trigger"}}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches), "expected the keys to be cached")
+}
+
+func TestValidateErrors(t *testing.T) {
+	var fetches int32
+	ts := newJWKSServer(&fetches, &[]string{"rsa"})
+	defer ts.Close()
+
+	validator := Validator{KeySet: NewKeySet(ts.URL, time.Second), Issuer: "issuer", Audience: "edgex", RequiredScopes: []string{"trigger"}}
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := map[string]interface{}{"iss": "issuer", "aud": "edgex", "exp": exp, "scope": "trigger"}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{"Malformed", "not-a-token", "Invalid token: malformed"},
+		{"No expiration", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "issuer", "aud": "edgex", "scope": "trigger"}), "Invalid token: no expiration"},
+		{"Expired", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "issuer", "aud": "edgex", "exp": time.Now().Add(-time.Minute).Unix(), "scope": "trigger"}), "Token expired"},
+		{"Not valid yet", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "issuer", "aud": "edgex", "exp": exp, "nbf": time.Now().Add(time.Minute).Unix(), "scope": "trigger"}), "Token not valid yet"},
+		{"Wrong issuer", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "other", "aud": "edgex", "exp": exp, "scope": "trigger"}), "Invalid token: issuer isn't 'issuer'"},
+		{"Missing audience", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "issuer", "exp": exp, "scope": "trigger"}), "Invalid token: audience isn't 'edgex'"},
+		{"Wrong audience", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "issuer", "aud": []string{"other"}, "exp": exp, "scope": "trigger"}), "Invalid token: audience isn't 'edgex'"},
+		{"Missing scope", sign(t, "RS256", "rsa", map[string]interface{}{"iss": "issuer", "aud": "edgex", "exp": exp, "scope": "read"}), "Token lacks the required scope 'trigger'"},
+		{"Unknown key", sign(t, "ES256", "ec", valid), "Invalid token: key 'ec' not found in the key set"},
+		{"Wrong key type", sign(t, "ES256", "rsa", valid), "Invalid token: key is not an EC key for algorithm 'ES256'"},
+		{"Unsupported algorithm", encodeSegment(map[string]string{"alg": "none", "kid": "rsa"}) + "." + encodeSegment(valid) + ".", "Invalid token: unsupported algorithm 'none'"},
+		{"Tampered", sign(t, "RS256", "rsa", valid)[:10] + "x" + sign(t, "RS256", "rsa", valid)[11:], ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := validator.Validate(test.token)
+			if test.expected == "" {
+				assert.Error(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	var fetches int32
+	kids := []string{"rsa"}
+	ts := newJWKSServer(&fetches, &kids)
+	defer ts.Close()
+
+	keySet := NewKeySet(ts.URL, time.Second)
+	keySet.MinRefreshInterval = 0
+	validator := Validator{KeySet: keySet}
+	claims := map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()}
+
+	_, err := validator.Validate(sign(t, "RS256", "rsa", claims))
+	assert.NoError(t, err)
+
+	// The EC key is rotated in, and is picked up when a token is signed with it
+	kids = []string{"ec"}
+	_, err = validator.Validate(sign(t, "ES256", "ec", claims))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestKeySetFetchDoesNotBlockCachedKeys(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	jwks := newJWKSServer(&fetches, &[]string{"rsa"})
+	defer jwks.Close()
+	// The fetches after the first one block until released
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fetches) > 0 {
+			<-release
+		}
+		jwks.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	keySet := NewKeySet(ts.URL, 5*time.Second)
+	keySet.MinRefreshInterval = 0
+	_, err := keySet.Key("rsa")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	unknown := make(chan error)
+	go func() {
+		_, err := keySet.Key("unknown")
+		unknown <- err
+	}()
+
+	// The cached key is returned while the keys are fetched for the unknown key
+	time.Sleep(100 * time.Millisecond)
+	_, err = keySet.Key("rsa")
+	assert.NoError(t, err)
+
+	close(release)
+	assert.EqualError(t, <-unknown, "key 'unknown' not found in the key set")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestAuthenticate(t *testing.T) {
+	var fetches int32
+	ts := newJWKSServer(&fetches, &[]string{"rsa"})
+	defer ts.Close()
+
+	validator := Validator{KeySet: NewKeySet(ts.URL, time.Second), RequiredScopes: []string{"trigger"}}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/trigger", nil)
+	status, err := validator.Authenticate(request)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, ErrMissingToken, err)
+
+	request.Header.Set("Authorization", "Bearer "+sign(t, "RS256", "rsa", map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}))
+	status, _ = validator.Authenticate(request)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	request.Header.Set("Authorization", "Bearer "+sign(t, "RS256", "rsa", map[string]interface{}{"exp": exp, "scope": "read"}))
+	status, err = validator.Authenticate(request)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, ErrInsufficientScope{Scope: "trigger"}, err)
+
+	request.Header.Set("Authorization", "bearer "+sign(t, "RS256", "rsa", map[string]interface{}{"exp": exp, "scope": "trigger"}))
+	status, err = validator.Authenticate(request)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, err)
+}
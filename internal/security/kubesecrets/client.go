@@ -0,0 +1,321 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// kubesecrets provides a secret store client which stores the secrets as Kubernetes Secrets.
+package kubesecrets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+const (
+	// secretLabel is set on the Secrets created by the client, so they can be listed without the other Secrets
+	// of the namespace
+	secretLabel = "app-functions-sdk.edgexfoundry.org/secret"
+	// pathAnnotation holds the path of the secrets stored in a Secret, since its name can't be converted back
+	pathAnnotation = "app-functions-sdk.edgexfoundry.org/path"
+	// maxSecretNameLength is the maximum length of a DNS subdomain, less the hash suffix of secretName
+	maxSecretNameLength = 253 - 9
+)
+
+// ErrUnavailable is returned by ListSecrets when the Kubernetes API server can't be reached
+var ErrUnavailable = errors.New("unable to connect to the Kubernetes API server")
+
+// Client reads and writes secrets as Kubernetes Secrets, using the Kubernetes REST API. The secrets at a path are
+// stored in a Secret of the namespace whose name is derived from the path by secretName. The path is kept in the
+// Secret's pathAnnotation and the Secret is labeled with secretLabel.
+type Client struct {
+	baseURL    string
+	token      string
+	tokenFile  *tokenFile
+	namespace  string
+	httpClient *http.Client
+}
+
+// secret is the subset of the Kubernetes Secret resource used by the client
+type secret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   secretMetadata    `json:"metadata"`
+	Data       map[string][]byte `json:"data"`
+}
+
+type secretMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NewClient creates a client authenticated with the kubeconfig file set as the SecretStore's KubeConfig, or
+// with the service account of the pod when running in a cluster. The SecretStore's Path is the namespace of the
+// Secrets, which defaults to the namespace of the kubeconfig's current context or of the pod. A token read from a
+// file, i.e. the pod's service account token, is read again when the file changes so rotated tokens are used.
+func NewClient(config common.SecretStoreInfo) (*Client, error) {
+	var conn *connection
+	var err error
+	if config.KubeConfig != "" {
+		conn, err = loadKubeConfig(config.KubeConfig)
+	} else {
+		conn, err = loadInCluster()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := strings.Trim(config.Path, "/")
+	if namespace == "" {
+		namespace = conn.namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(conn.server, "/"),
+		token:      conn.token,
+		tokenFile:  conn.tokenFile,
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: time.Duration(config.Timeout) * time.Millisecond, Transport: conn.transport},
+	}, nil
+}
+
+// GetSecret returns the value of the specified key from the secrets at the specified path.
+func (c *Client) GetSecret(path string, key string) (string, error) {
+	secrets, err := c.GetSecrets(path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("no secret found for key '%s' at path '%s'", key, path)
+	}
+	return value, nil
+}
+
+// GetSecrets returns all the secrets at the specified path.
+func (c *Client) GetSecrets(path string) (map[string]string, error) {
+	response, err := c.do(http.MethodGet, c.secretURL(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no secrets found at path '%s'", path)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get secrets at path '%s': %s", path, response.Status)
+	}
+
+	var result secret
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to decode secrets at path '%s': %v", path, err)
+	}
+	if result.Metadata.Annotations[pathAnnotation] != secretPath(path) {
+		return nil, fmt.Errorf("no secrets found at path '%s'", path)
+	}
+
+	secrets := make(map[string]string, len(result.Data))
+	for key, value := range result.Data {
+		secrets[key] = string(value)
+	}
+	return secrets, nil
+}
+
+// StoreSecret stores the specified secrets at the specified path, replacing the data of the existing Secret or
+// creating the Secret.
+func (c *Client) StoreSecret(path string, secrets map[string]string) error {
+	if len(secrets) == 0 {
+		return errors.New("no secrets provided")
+	}
+
+	resource := secret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: secretMetadata{
+			Name:        secretName(path),
+			Namespace:   c.namespace,
+			Labels:      map[string]string{secretLabel: "true"},
+			Annotations: map[string]string{pathAnnotation: secretPath(path)},
+		},
+		Data: make(map[string][]byte, len(secrets)),
+	}
+	for key, value := range secrets {
+		resource.Data[key] = []byte(value)
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.do(http.MethodPut, c.secretURL(path), data)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		response, err = c.do(http.MethodPost, c.secretsURL(), data)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unable to store secrets at path '%s': %s", path, response.Status)
+	}
+	return nil
+}
+
+// ListSecrets returns the names of the secrets and sub-paths, which end with '/', at the specified path.
+// ErrUnavailable is returned when the Kubernetes API server can't be reached.
+func (c *Client) ListSecrets(path string) ([]string, error) {
+	response, err := c.do(http.MethodGet, c.secretsURL()+"?labelSelector="+url.QueryEscape(secretLabel+"=true"), nil)
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to list secrets at path '%s': %s", path, response.Status)
+	}
+
+	result := struct {
+		Items []secret `json:"items"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to decode secrets list at path '%s': %v", path, err)
+	}
+
+	prefix := secretPath(path)
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	found := make(map[string]bool)
+	keys := []string{}
+	for _, item := range result.Items {
+		itemPath, ok := item.Metadata.Annotations[pathAnnotation]
+		if !ok || !strings.HasPrefix(itemPath, prefix) || itemPath == prefix {
+			continue
+		}
+
+		key := strings.TrimPrefix(itemPath, prefix)
+		if index := strings.Index(key, "/"); index >= 0 {
+			key = key[:index] + "/"
+		}
+		if !found[key] {
+			found[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// HealthCheck checks that the Kubernetes API server is ready using its readyz endpoint.
+// ErrUnavailable is returned when the Kubernetes API server can't be reached.
+func (c *Client) HealthCheck() error {
+	response, err := c.do(http.MethodGet, c.baseURL+"/readyz", nil)
+	if err != nil {
+		return ErrUnavailable
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("Kubernetes API server is not ready: %s", response.Status)
+	}
+	return nil
+}
+
+func (c *Client) secretsURL() string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/secrets", c.baseURL, url.PathEscape(c.namespace))
+}
+
+func (c *Client) secretURL(path string) string {
+	return c.secretsURL() + "/" + url.PathEscape(secretName(path))
+}
+
+func (c *Client) do(method string, url string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	token := c.token
+	if c.tokenFile != nil {
+		if token, err = c.tokenFile.get(); err != nil {
+			return nil, fmt.Errorf("could not read token: %v", err)
+		}
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(request)
+}
+
+// secretPath returns the path without its leading and trailing '/'
+func secretPath(path string) string {
+	return strings.Trim(path, "/")
+}
+
+// secretName converts the path to the name of its Secret, which must be a DNS-1123 subdomain. The path is
+// lowercased with the characters other than letters and digits replaced by '-', i.e. 'myservice-mqtt' for
+// 'myService/mqtt', followed by the first 8 hex digits of the SHA-256 of the path, so paths which only differ
+// by case or punctuation, such as 'a/b' and 'a.b', are stored in different Secrets.
+func secretName(path string) string {
+	path = secretPath(path)
+
+	sanitized := strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, path), "-")
+	if len(sanitized) > maxSecretNameLength {
+		sanitized = strings.TrimRight(sanitized[:maxSecretNameLength], "-")
+	}
+	if sanitized == "" {
+		sanitized = "secret"
+	}
+
+	hash := sha256.Sum256([]byte(path))
+	return sanitized + "-" + hex.EncodeToString(hash[:4])
+}
@@ -0,0 +1,316 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package kubesecrets
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+const testToken = "test-token"
+
+// fakeAPIServer serves the Secrets of the edgex namespace
+type fakeAPIServer struct {
+	mutex   sync.Mutex
+	secrets map[string]secret
+	// token is the accepted bearer token, testToken when empty
+	token string
+}
+
+func (server *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	token := server.token
+	if token == "" {
+		token = testToken
+	}
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Path == "/readyz" {
+		_, _ = w.Write([]byte("ok"))
+		return
+	}
+
+	const secretsPath = "/api/v1/namespaces/edgex/secrets"
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, secretsPath), "/")
+	existing, exists := server.secrets[name]
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		list := struct {
+			Items []secret `json:"items"`
+		}{}
+		selector := strings.SplitN(r.URL.Query().Get("labelSelector"), "=", 2)
+		for _, item := range server.secrets {
+			if len(selector) == 2 && item.Metadata.Labels[selector[0]] != selector[1] {
+				continue
+			}
+			list.Items = append(list.Items, item)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodGet && exists:
+		_ = json.NewEncoder(w).Encode(existing)
+	case r.Method == http.MethodPut && exists, r.Method == http.MethodPost && name == "":
+		var item secret
+		_ = json.NewDecoder(r.Body).Decode(&item)
+		server.secrets[item.Metadata.Name] = item
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// storedSecret returns the Secret stored by the client for the path
+func storedSecret(path string, data map[string][]byte) secret {
+	return secret{
+		Metadata: secretMetadata{
+			Name:        secretName(path),
+			Labels:      map[string]string{secretLabel: "true"},
+			Annotations: map[string]string{pathAnnotation: path},
+		},
+		Data: data,
+	}
+}
+
+func newTestClient(t *testing.T, server *fakeAPIServer) (*Client, func()) {
+	ts := httptest.NewServer(server)
+
+	kubeconfig, err := ioutil.TempFile("", "kubeconfig")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	_, _ = kubeconfig.WriteString(`
+apiVersion: v1
+kind: Config
+current-context: edgex
+clusters:
+- name: local
+  cluster:
+    server: ` + ts.URL + `
+users:
+- name: edgex
+  user:
+    token: ` + testToken + `
+contexts:
+- name: edgex
+  context:
+    cluster: local
+    user: edgex
+    namespace: edgex
+`)
+	_ = kubeconfig.Close()
+
+	client, err := NewClient(common.SecretStoreInfo{KubeConfig: kubeconfig.Name(), Timeout: 5000})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	return client, func() {
+		ts.Close()
+		_ = os.Remove(kubeconfig.Name())
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	server := &fakeAPIServer{secrets: map[string]secret{
+		secretName("myService/mqtt"): storedSecret("myService/mqtt", map[string][]byte{"username": []byte("edgex")}),
+	}}
+	client, cleanup := newTestClient(t, server)
+	defer cleanup()
+
+	value, err := client.GetSecret("/myService/mqtt", "username")
+	assert.NoError(t, err)
+	assert.Equal(t, "edgex", value)
+
+	_, err = client.GetSecret("myService/mqtt", "password")
+	assert.Error(t, err, "expected error for missing key")
+
+	_, err = client.GetSecret("bogus", "username")
+	assert.Error(t, err, "expected error for missing path")
+
+	// a Secret of another path with the same name
+	server.secrets[secretName("myService/mqtt")] = storedSecret("other/mqtt", map[string][]byte{"username": []byte("other")})
+	_, err = client.GetSecret("myService/mqtt", "username")
+	assert.Error(t, err, "expected error for Secret of another path")
+}
+
+func TestStoreSecret(t *testing.T) {
+	server := &fakeAPIServer{secrets: map[string]secret{}}
+	client, cleanup := newTestClient(t, server)
+	defer cleanup()
+
+	err := client.StoreSecret("/myService/mqtt", map[string]string{"username": "edgex"})
+	assert.NoError(t, err)
+	stored := server.secrets[secretName("myService/mqtt")]
+	assert.Equal(t, []byte("edgex"), stored.Data["username"], "expected Secret to be created")
+	assert.Equal(t, "myService/mqtt", stored.Metadata.Annotations[pathAnnotation])
+	assert.Equal(t, "true", stored.Metadata.Labels[secretLabel])
+
+	err = client.StoreSecret("myService/mqtt", map[string]string{"username": "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("admin"), server.secrets[secretName("myService/mqtt")].Data["username"], "expected Secret to be updated")
+
+	value, err := client.GetSecret("myService/mqtt", "username")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", value)
+
+	err = client.StoreSecret("myService/mqtt", nil)
+	assert.Error(t, err, "expected error for no secrets")
+}
+
+func TestListSecrets(t *testing.T) {
+	server := &fakeAPIServer{secrets: map[string]secret{}}
+	for _, path := range []string{"myService/mqtt", "myService/cloud/aws", "myService/cloud/azure", "myService/my.secret", "other/mqtt"} {
+		server.secrets[secretName(path)] = storedSecret(path, nil)
+	}
+	// a Secret which isn't stored by the client
+	server.secrets["myservice"] = secret{Metadata: secretMetadata{Name: "myservice"}}
+	client, cleanup := newTestClient(t, server)
+
+	keys, err := client.ListSecrets("myService/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cloud/", "mqtt", "my.secret"}, keys)
+
+	keys, err = client.ListSecrets("myService/cloud")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aws", "azure"}, keys)
+
+	keys, err = client.ListSecrets("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"myService/", "other/"}, keys)
+
+	cleanup()
+	_, err = client.ListSecrets("myService")
+	assert.Equal(t, ErrUnavailable, err)
+}
+
+func TestSecretName(t *testing.T) {
+	dnsSubdomain := regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+	paths := []string{"myService/mqtt", "myservice/mqtt", "myservice.mqtt", "myservice_mqtt", "myservice-mqtt", "/", "_", strings.Repeat("a/", 200)}
+	names := make(map[string]string)
+	for _, path := range paths {
+		name := secretName(path)
+		assert.True(t, dnsSubdomain.MatchString(name), "expected '%s' to be a DNS-1123 subdomain", name)
+		assert.True(t, len(name) <= 253, "expected '%s' to be at most 253 characters", name)
+		if other, ok := names[name]; ok {
+			t.Errorf("paths '%s' and '%s' have the same Secret name '%s'", other, path, name)
+		}
+		names[name] = path
+	}
+
+	assert.Equal(t, secretName("myService/mqtt"), secretName("/myService/mqtt/"))
+	assert.True(t, strings.HasPrefix(secretName("myService/mqtt"), "myservice-mqtt-"))
+}
+
+func TestHealthCheck(t *testing.T) {
+	client, cleanup := newTestClient(t, &fakeAPIServer{secrets: map[string]secret{}})
+
+	assert.NoError(t, client.HealthCheck())
+
+	cleanup()
+	assert.Equal(t, ErrUnavailable, client.HealthCheck())
+}
+
+func TestNewClientInvalidKubeConfig(t *testing.T) {
+	_, err := NewClient(common.SecretStoreInfo{KubeConfig: "bogus"})
+	assert.Error(t, err, "expected error for missing kubeconfig")
+
+	kubeconfig, _ := ioutil.TempFile("", "kubeconfig")
+	defer os.Remove(kubeconfig.Name())
+	_, _ = kubeconfig.WriteString("current-context: missing\n")
+	_ = kubeconfig.Close()
+
+	_, err = NewClient(common.SecretStoreInfo{KubeConfig: kubeconfig.Name()})
+	assert.Error(t, err, "expected error for missing current context")
+}
+
+func TestNewClientNotInCluster(t *testing.T) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		t.Skip("running in a Kubernetes cluster")
+	}
+
+	_, err := NewClient(common.SecretStoreInfo{})
+	assert.Error(t, err, "expected error when not running in a cluster")
+}
+
+func TestNewClientInClusterRotatedToken(t *testing.T) {
+	server := &fakeAPIServer{secrets: map[string]secret{
+		secretName("myService/mqtt"): storedSecret("myService/mqtt", map[string][]byte{"username": []byte("edgex")}),
+	}, token: "first"}
+	ts := httptest.NewTLSServer(server)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "serviceaccount")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	defer os.RemoveAll(dir)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	_ = ioutil.WriteFile(filepath.Join(dir, "ca.crt"), ca, 0600)
+	_ = ioutil.WriteFile(filepath.Join(dir, "namespace"), []byte("edgex"), 0600)
+	tokenPath := filepath.Join(dir, "token")
+	_ = ioutil.WriteFile(tokenPath, []byte("first"), 0600)
+
+	original := serviceAccountDir
+	serviceAccountDir = dir
+	defer func() { serviceAccountDir = original }()
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(ts.URL, "https://"))
+	t.Setenv("KUBERNETES_SERVICE_HOST", host)
+	t.Setenv("KUBERNETES_SERVICE_PORT", port)
+
+	client, err := NewClient(common.SecretStoreInfo{Timeout: 5000})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	_, err = client.GetSecret("myService/mqtt", "username")
+	assert.NoError(t, err)
+
+	// the kubelet rotates the token
+	server.mutex.Lock()
+	server.token = "second"
+	server.mutex.Unlock()
+	_ = ioutil.WriteFile(tokenPath, []byte("second"), 0600)
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(tokenPath, later, later)
+
+	value, err := client.GetSecret("myService/mqtt", "username")
+	assert.NoError(t, err, "expected the rotated token to be used")
+	assert.Equal(t, "edgex", value)
+}
@@ -0,0 +1,265 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package kubesecrets
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Locations of the service account credentials mounted in to a pod
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// connection holds the Kubernetes API server to connect to and the credentials to authenticate with
+type connection struct {
+	server string
+	token  string
+	// tokenFile is set instead of the token when the token is read from a file, which may be rotated
+	tokenFile *tokenFile
+	namespace string
+	transport http.RoundTripper
+}
+
+// tokenFile reads the bearer token from a file again whenever the file changes, since the kubelet rotates the
+// projected service account tokens of the pods, which expire.
+type tokenFile struct {
+	path string
+
+	mutex   sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func newTokenFile(path string) (*tokenFile, error) {
+	file := &tokenFile{path: path}
+	if _, err := file.get(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// get returns the token, read again from the file when the file changed since it was read
+func (f *tokenFile) get() (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+	if f.token != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.token, nil
+	}
+
+	contents, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.token = strings.TrimSpace(string(contents))
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return f.token, nil
+}
+
+// kubeConfig is the subset of the kubeconfig file used to connect to the current context's cluster
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// loadInCluster connects with the service account of the pod the service is running in
+func loadInCluster() (*connection, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster and no kubeconfig is configured")
+	}
+
+	token, err := newTokenFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read service account token: %v", err)
+	}
+	caData, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read service account CA certificate: %v", err)
+	}
+	namespace, _ := ioutil.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+
+	tlsConfig, err := newTLSConfig(caData, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection{
+		server:    "https://" + net.JoinHostPort(host, port),
+		tokenFile: token,
+		namespace: strings.TrimSpace(string(namespace)),
+		transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+// loadKubeConfig connects to the cluster of the kubeconfig's current context, with the context's user
+func loadKubeConfig(path string) (*connection, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read kubeconfig (%s): %v", path, err)
+	}
+
+	var config kubeConfig
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("could not parse kubeconfig (%s): %v", path, err)
+	}
+
+	conn := &connection{}
+	var clusterName, userName string
+	for _, context := range config.Contexts {
+		if context.Name == config.CurrentContext {
+			clusterName = context.Context.Cluster
+			userName = context.Context.User
+			conn.namespace = context.Context.Namespace
+		}
+	}
+	if clusterName == "" {
+		return nil, fmt.Errorf("current context '%s' not found in kubeconfig (%s)", config.CurrentContext, path)
+	}
+
+	// Relative file names in the kubeconfig are relative to the kubeconfig's directory
+	dir := filepath.Dir(path)
+	readData := func(data string, file string) ([]byte, error) {
+		if data != "" {
+			return base64.StdEncoding.DecodeString(data)
+		}
+		if file == "" {
+			return nil, nil
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		return ioutil.ReadFile(file)
+	}
+
+	tlsConfig := &tls.Config{}
+	clusterFound := false
+	for _, cluster := range config.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+		clusterFound = true
+		conn.server = cluster.Cluster.Server
+
+		caData, err := readData(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate of cluster '%s': %v", clusterName, err)
+		}
+		if tlsConfig, err = newTLSConfig(caData, cluster.Cluster.InsecureSkipTLSVerify); err != nil {
+			return nil, err
+		}
+	}
+	if !clusterFound || conn.server == "" {
+		return nil, fmt.Errorf("cluster '%s' not found in kubeconfig (%s)", clusterName, path)
+	}
+
+	for _, user := range config.Users {
+		if user.Name != userName {
+			continue
+		}
+
+		conn.token = strings.TrimSpace(user.User.Token)
+		if conn.token == "" && user.User.TokenFile != "" {
+			file := user.User.TokenFile
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(dir, file)
+			}
+			if conn.tokenFile, err = newTokenFile(file); err != nil {
+				return nil, fmt.Errorf("could not read token of user '%s': %v", userName, err)
+			}
+		}
+
+		certData, err := readData(user.User.ClientCertificateData, user.User.ClientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client certificate of user '%s': %v", userName, err)
+		}
+		keyData, err := readData(user.User.ClientKeyData, user.User.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client key of user '%s': %v", userName, err)
+		}
+		if len(certData) > 0 {
+			certificate, err := tls.X509KeyPair(certData, keyData)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate of user '%s': %v", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+	}
+
+	conn.transport = &http.Transport{TLSClientConfig: tlsConfig}
+	return conn, nil
+}
+
+// newTLSConfig trusts the CA certificates, or the system's CAs when there are none
+func newTLSConfig(caData []byte, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if len(caData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, errors.New("invalid CA certificate for the Kubernetes API server")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// security provides access to the secret store used by the SDK and pipeline functions.
+package security
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/kubesecrets"
+)
+
+const (
+	// Secret store providers
+	Vault       = "vault"
+	KubeSecrets = "kubesecrets"
+)
+
+var (
+	ErrUnsupportedSecretStore = errors.New("unsupported secret store type")
+	ErrSecretStoreUnavailable = errors.New("unable to connect to the secret store")
+)
+
+// SecretStoreClient establishes the contract required to read and write secrets from a secret store.
+type SecretStoreClient interface {
+	// GetSecret returns the value of the specified key from the secrets at the specified path.
+	GetSecret(path string, key string) (string, error)
+	// GetSecrets returns all the secrets at the specified path.
+	GetSecrets(path string) (map[string]string, error)
+
+	// StoreSecret stores the specified secrets at the specified path.
+	StoreSecret(path string, secrets map[string]string) error
+	// ListSecrets returns the names of the secrets and sub-paths, which end with '/', at the specified path.
+	// ErrSecretStoreUnavailable is returned when the secret store can't be reached.
+	ListSecrets(path string) ([]string, error)
+	// HealthCheck checks that the secret store can be reached and is ready to serve secrets, without reading any.
+	// ErrSecretStoreUnavailable is returned when the secret store can't be reached.
+	HealthCheck() error
+}
+
+// NewSecretStoreClient provides a factory for building a SecretStoreClient
+func NewSecretStoreClient(config common.SecretStoreInfo) (SecretStoreClient, error) {
+	switch strings.ToLower(config.Type) {
+	case Vault:
+		return newVaultClient(config)
+	case KubeSecrets:
+		client, err := kubesecrets.NewClient(config)
+		if err != nil {
+			return nil, err
+		}
+		return kubeSecretsClient{client}, nil
+	default:
+		return nil, ErrUnsupportedSecretStore
+	}
+}
+
+// kubeSecretsClient returns the SecretStoreClient errors for the errors of the Kubernetes Secrets client
+type kubeSecretsClient struct {
+	*kubesecrets.Client
+}
+
+func (c kubeSecretsClient) ListSecrets(path string) ([]string, error) {
+	keys, err := c.Client.ListSecrets(path)
+	if err == kubesecrets.ErrUnavailable {
+		return nil, ErrSecretStoreUnavailable
+	}
+	return keys, err
+}
+
+func (c kubeSecretsClient) HealthCheck() error {
+	err := c.Client.HealthCheck()
+	if err == kubesecrets.ErrUnavailable {
+		return ErrSecretStoreUnavailable
+	}
+	return err
+}
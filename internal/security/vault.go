@@ -0,0 +1,235 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+const vaultTokenHeader = "X-Vault-Token"
+
+// vaultClient is a SecretStoreClient for the Vault KV (version 1) secrets engine
+type vaultClient struct {
+	baseURL    string
+	healthURL  string
+	token      string
+	httpClient *http.Client
+}
+
+func newVaultClient(config common.SecretStoreInfo) (*vaultClient, error) {
+	token, err := readToken(config.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	protocol := config.Protocol
+	if protocol == "" {
+		protocol = "https"
+	}
+
+	return &vaultClient{
+		baseURL:    fmt.Sprintf("%s://%s:%d/v1/%s", protocol, config.Host, config.Port, strings.Trim(config.Path, "/")),
+		healthURL:  fmt.Sprintf("%s://%s:%d/v1/sys/health", protocol, config.Host, config.Port),
+		token:      token,
+		httpClient: &http.Client{Timeout: time.Duration(config.Timeout) * time.Millisecond},
+	}, nil
+}
+
+// GetSecret returns the value of the specified key from the secrets at the specified path.
+func (c *vaultClient) GetSecret(path string, key string) (string, error) {
+	secrets, err := c.GetSecrets(path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("no secret found for key '%s' at path '%s'", key, path)
+	}
+	return value, nil
+}
+
+// GetSecrets returns all the secrets at the specified path.
+func (c *vaultClient) GetSecrets(path string) (map[string]string, error) {
+	request, err := http.NewRequest(http.MethodGet, c.url(path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no secrets found at path '%s'", path)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get secrets at path '%s': %s", path, response.Status)
+	}
+
+	result := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to decode secrets at path '%s': %v", path, err)
+	}
+
+	secrets := make(map[string]string, len(result.Data))
+	for key, value := range result.Data {
+		if s, ok := value.(string); ok {
+			secrets[key] = s
+		} else {
+			secrets[key] = fmt.Sprint(value)
+		}
+	}
+	return secrets, nil
+}
+
+// StoreSecret stores the specified secrets at the specified path.
+func (c *vaultClient) StoreSecret(path string, secrets map[string]string) error {
+	if len(secrets) == 0 {
+		return errors.New("no secrets provided")
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, c.url(path), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unable to store secrets at path '%s': %s", path, response.Status)
+	}
+
+	return nil
+}
+
+// ListSecrets returns the names of the secrets and sub-paths, which end with '/', at the specified path.
+// ErrSecretStoreUnavailable is returned when the secret store can't be reached.
+func (c *vaultClient) ListSecrets(path string) ([]string, error) {
+	request, err := http.NewRequest("LIST", c.url(path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.do(request)
+	if err != nil {
+		return nil, ErrSecretStoreUnavailable
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return []string{}, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to list secrets at path '%s': %s", path, response.Status)
+	}
+
+	result := struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to decode secrets list at path '%s': %v", path, err)
+	}
+
+	return result.Data.Keys, nil
+}
+
+// HealthCheck checks that Vault is initialized and unsealed using its health endpoint, which doesn't require
+// any policy. A standby node is healthy as it forwards the requests to the active node.
+// ErrSecretStoreUnavailable is returned when Vault can't be reached.
+func (c *vaultClient) HealthCheck() error {
+	response, err := c.httpClient.Get(c.healthURL)
+	if err != nil {
+		return ErrSecretStoreUnavailable
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusTooManyRequests, 472, 473:
+		// active, standby, disaster recovery secondary and performance standby nodes
+		return nil
+	case http.StatusNotImplemented:
+		return errors.New("Vault is not initialized")
+	case http.StatusServiceUnavailable:
+		return errors.New("Vault is sealed")
+	default:
+		return fmt.Errorf("unable to check Vault health: %s", response.Status)
+	}
+}
+
+func (c *vaultClient) url(path string) string {
+	return c.baseURL + "/" + strings.Trim(path, "/")
+}
+
+func (c *vaultClient) do(request *http.Request) (*http.Response, error) {
+	request.Header.Set(vaultTokenHeader, c.token)
+	return c.httpClient.Do(request)
+}
+
+// readToken reads the Vault access token from the token file, which is either the JSON
+// response created by the security setup (i.e. {"auth":{"client_token":"..."}}) or the plain token.
+func readToken(tokenFile string) (string, error) {
+	if tokenFile == "" {
+		return "", errors.New("secret store token file is not configured")
+	}
+
+	contents, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("could not read secret store token file (%s): %v", tokenFile, err)
+	}
+
+	tokenResponse := struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}{}
+	if err := json.Unmarshal(contents, &tokenResponse); err == nil && tokenResponse.Auth.ClientToken != "" {
+		return tokenResponse.Auth.ClientToken, nil
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("secret store token file (%s) is empty", tokenFile)
+	}
+
+	return token, nil
+}
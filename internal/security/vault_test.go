@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package security
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+)
+
+const testToken = "test-token"
+
+func newTestVaultClient(t *testing.T, handler http.HandlerFunc) (SecretStoreClient, func()) {
+	server := httptest.NewServer(handler)
+
+	tokenFile, err := ioutil.TempFile("", "token")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	_, _ = tokenFile.WriteString(`{"auth":{"client_token":"` + testToken + `"}}`)
+	_ = tokenFile.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	client, err := NewSecretStoreClient(common.SecretStoreInfo{
+		Type:      "Vault",
+		Host:      serverURL.Hostname(),
+		Port:      port,
+		Path:      "/secret/edgex/",
+		Protocol:  "http",
+		TokenFile: tokenFile.Name(),
+		Timeout:   5000,
+	})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	return client, func() {
+		server.Close()
+		_ = os.Remove(tokenFile.Name())
+	}
+}
+
+func TestNewSecretStoreClientUnsupported(t *testing.T) {
+	_, err := NewSecretStoreClient(common.SecretStoreInfo{Type: "bogus"})
+	assert.Equal(t, ErrUnsupportedSecretStore, err)
+}
+
+func TestNewSecretStoreClientNoTokenFile(t *testing.T) {
+	_, err := NewSecretStoreClient(common.SecretStoreInfo{Type: Vault})
+	assert.Error(t, err)
+}
+
+func TestGetSecret(t *testing.T) {
+	client, cleanup := newTestVaultClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, testToken, r.Header.Get(vaultTokenHeader))
+		if r.URL.Path != "/v1/secret/edgex/mqtt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"username":"edgex","port":1883}}`))
+	})
+	defer cleanup()
+
+	value, err := client.GetSecret("mqtt", "username")
+	assert.NoError(t, err)
+	assert.Equal(t, "edgex", value)
+
+	value, err = client.GetSecret("/mqtt", "port")
+	assert.NoError(t, err)
+	assert.Equal(t, "1883", value)
+
+	_, err = client.GetSecret("mqtt", "password")
+	assert.Error(t, err, "expected error for missing key")
+
+	_, err = client.GetSecret("bogus", "username")
+	assert.Error(t, err, "expected error for missing path")
+}
+
+func TestGetSecrets(t *testing.T) {
+	client, cleanup := newTestVaultClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/edgex/mqtt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"username":"edgex","port":1883}}`))
+	})
+	defer cleanup()
+
+	secrets, err := client.GetSecrets("mqtt")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "edgex", "port": "1883"}, secrets)
+
+	_, err = client.GetSecrets("bogus")
+	assert.Error(t, err, "expected error for missing path")
+}
+
+func TestStoreSecret(t *testing.T) {
+	var received map[string]string
+	client, cleanup := newTestVaultClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/secret/edgex/mqtt", r.URL.Path)
+		assert.Equal(t, testToken, r.Header.Get(vaultTokenHeader))
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer cleanup()
+
+	secrets := map[string]string{"username": "edgex", "password": "secret"}
+	err := client.StoreSecret("mqtt", secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, secrets, received)
+
+	err = client.StoreSecret("mqtt", nil)
+	assert.Error(t, err, "expected error for no secrets")
+}
+
+func TestListSecrets(t *testing.T) {
+	client, cleanup := newTestVaultClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "LIST", r.Method)
+		assert.Equal(t, testToken, r.Header.Get(vaultTokenHeader))
+		if r.URL.Path != "/v1/secret/edgex/myService" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"keys":["mqtt","cloud/"]}}`))
+	})
+
+	keys, err := client.ListSecrets("myService/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mqtt", "cloud/"}, keys)
+
+	keys, err = client.ListSecrets("bogus")
+	assert.NoError(t, err)
+	assert.Empty(t, keys, "expected no secrets for missing path")
+
+	cleanup()
+	_, err = client.ListSecrets("myService")
+	assert.Equal(t, ErrSecretStoreUnavailable, err)
+}
+
+func TestHealthCheck(t *testing.T) {
+	status := http.StatusOK
+	client, cleanup := newTestVaultClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/sys/health", r.URL.Path)
+		w.WriteHeader(status)
+	})
+
+	assert.NoError(t, client.HealthCheck())
+
+	status = http.StatusTooManyRequests
+	assert.NoError(t, client.HealthCheck(), "expected standby node to be healthy")
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, client.HealthCheck(), "Vault is sealed")
+
+	cleanup()
+	assert.Equal(t, ErrSecretStoreUnavailable, client.HealthCheck())
+}
+
+func TestReadTokenPlainText(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "token")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	defer os.Remove(tokenFile.Name())
+	_, _ = tokenFile.WriteString(testToken + "\n")
+	_ = tokenFile.Close()
+
+	token, err := readToken(tokenFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, testToken, token)
+}
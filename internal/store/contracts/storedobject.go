@@ -17,10 +17,37 @@ package contracts
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrPayloadTooLarge is returned by ValidateContract when the payload exceeds the maximum allowed size.
+var ErrPayloadTooLarge = errors.New("invalid contract, payload exceeds the maximum allowed size")
+
+// ErrRevisionConflict is returned by Update when the object was modified since it was retrieved.
+var ErrRevisionConflict = errors.New("revision conflict, object was modified since it was retrieved")
+
+// ErrObjectNotFound is returned by GetByID when there is no object with the ID in the store.
+var ErrObjectNotFound = errors.New("object not found")
+
+// MaxAppServiceKeyLength is the maximum number of characters allowed in an AppServiceKey.
+const MaxAppServiceKeyLength = 255
+
+// ValidationError describes a field of a StoredObject which failed validation.
+type ValidationError struct {
+	// Field is the name of the field which failed validation
+	Field string
+
+	// Reason describes why the field is invalid
+	Reason string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid contract, %s %s", e.Field, e.Reason)
+}
+
 // StoredObject is the atomic and most abstract description of what is collected by the export store system.
 type StoredObject struct {
 	// ID uniquely identifies this StoredObject
@@ -32,6 +59,10 @@ type StoredObject struct {
 	// Payload is the data to be exported
 	Payload []byte
 
+	// PayloadEncoding is how the Payload is encoded in the store, e.g. the compression algorithm. Empty when the
+	// Payload is stored as is.
+	PayloadEncoding string
+
 	// RetryCount is how many times this has tried to be exported
 	RetryCount int
 
@@ -49,22 +80,57 @@ type StoredObject struct {
 
 	// EventChecksum is used to identify CBOR encoded data from the core services and mark it as pushed.
 	EventChecksum string
+
+	// LastModifiedAt is when this was last stored or updated, in milliseconds since the epoch. Set by the store.
+	LastModifiedAt int64
+
+	// Revision is set to 1 when stored and incremented on each update by the store. An update only succeeds
+	// when the revision is the stored one, so concurrent updates of the same object can't overwrite each other.
+	Revision int
+
+	// ExpiresAt is when this expires, in seconds since the epoch. Expired objects are no longer retrieved from the
+	// store. Zero means this never expires.
+	ExpiresAt int64
 }
 
-// NewStoredObject creates a new instance of StoredObject and is the preferred way to create one.
-func NewStoredObject(appServiceKey string, payload []byte, pipelinePosition int,
-	version string) StoredObject {
-	return StoredObject{
+// NewStoredObject creates a new instance of StoredObject with a generated ID and is the preferred way to create one.
+// The object is validated by ValidateContract(false), so it is ready to be stored unless an error is returned.
+func NewStoredObject(appServiceKey string, correlationID string, payload []byte, pipelinePosition int,
+	version string) (StoredObject, error) {
+	object := StoredObject{
+		ID:               uuid.New().String(),
 		AppServiceKey:    appServiceKey,
 		Payload:          payload,
 		RetryCount:       0,
 		PipelinePosition: pipelinePosition,
 		Version:          version,
+		CorrelationID:    correlationID,
+	}
+
+	if err := object.ValidateContract(false); err != nil {
+		return StoredObject{}, err
 	}
+	return object, nil
 }
 
-// ValidateContract ensures that the required fields are present on the object.
-func (o *StoredObject) ValidateContract(IDRequired bool) error {
+// Clone returns a deep copy of the object, whose Payload can be modified without modifying the object's payload.
+func (o StoredObject) Clone() StoredObject {
+	clone := o
+	if o.Payload != nil {
+		clone.Payload = make([]byte, len(o.Payload))
+		copy(clone.Payload, o.Payload)
+	}
+	return clone
+}
+
+// IsExpired returns whether the object's ExpiresAt has passed. Objects without an ExpiresAt never expire.
+func (o StoredObject) IsExpired() bool {
+	return time.Now().Unix() > o.ExpiresAt && o.ExpiresAt != 0
+}
+
+// ValidateContract ensures that the required fields are present on the object. An optional maxPayloadBytes
+// limits the size of the payload, a value of zero or less disables the check.
+func (o *StoredObject) ValidateContract(IDRequired bool, maxPayloadBytes ...int) error {
 	if IDRequired {
 		if o.ID == "" {
 			return errors.New("invalid contract, ID cannot be empty")
@@ -85,12 +151,42 @@ func (o *StoredObject) ValidateContract(IDRequired bool) error {
 	if o.AppServiceKey == "" {
 		return errors.New("invalid contract, app service key cannot be empty")
 	}
+	if err := validateAppServiceKey(o.AppServiceKey); err != nil {
+		return err
+	}
 	if len(o.Payload) == 0 {
 		return errors.New("invalid contract, payload cannot be empty")
 	}
+	if len(maxPayloadBytes) > 0 && maxPayloadBytes[0] > 0 && len(o.Payload) > maxPayloadBytes[0] {
+		return ErrPayloadTooLarge
+	}
 	if o.Version == "" {
 		return errors.New("invalid contract, version cannot be empty")
 	}
 
 	return nil
 }
+
+// validateAppServiceKey restricts the AppServiceKey to characters which are safe to use in a store key namespace.
+func validateAppServiceKey(key string) error {
+	if len(key) > MaxAppServiceKeyLength {
+		return ValidationError{
+			Field:  "app service key",
+			Reason: fmt.Sprintf("exceeds the maximum length of %d characters", MaxAppServiceKeyLength),
+		}
+	}
+
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+			continue
+		default:
+			return ValidationError{
+				Field:  "app service key",
+				Reason: fmt.Sprintf("contains invalid character %q", c),
+			}
+		}
+	}
+
+	return nil
+}
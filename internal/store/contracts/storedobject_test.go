@@ -0,0 +1,162 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package contracts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContractAppServiceKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		appServiceKey string
+		expectedError string
+	}{
+		{"Valid", "app-service_1.0", ""},
+		{"Colon", "store:key", `invalid contract, app service key contains invalid character ':'`},
+		{"Space", "my key", `invalid contract, app service key contains invalid character ' '`},
+		{"Non ASCII", "clé", `invalid contract, app service key contains invalid character 'é'`},
+		{"Max Length", strings.Repeat("a", MaxAppServiceKeyLength), ""},
+		{"Too Long", strings.Repeat("a", MaxAppServiceKeyLength+1), "invalid contract, app service key exceeds the maximum length of 255 characters"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object := StoredObject{AppServiceKey: test.appServiceKey, Payload: []byte("payload"), PipelinePosition: 1, Version: "version"}
+			err := object.ValidateContract(false)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if !assert.Error(t, err) {
+				t.Fatal()
+			}
+			assert.IsType(t, ValidationError{}, err)
+			assert.Equal(t, test.expectedError, err.Error())
+		})
+	}
+}
+
+func TestNewStoredObject(t *testing.T) {
+	object, err := NewStoredObject("key", "correlation", []byte("payload"), 1, "version")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	_, err = uuid.Parse(object.ID)
+	assert.NoError(t, err, "ID should be a generated UUID")
+	assert.Equal(t, "key", object.AppServiceKey)
+	assert.Equal(t, "correlation", object.CorrelationID)
+	assert.Equal(t, []byte("payload"), object.Payload)
+	assert.Equal(t, 1, object.PipelinePosition)
+	assert.Equal(t, "version", object.Version)
+	assert.Equal(t, 0, object.RetryCount)
+
+	other, err := NewStoredObject("key", "correlation", []byte("payload"), 1, "version")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.NotEqual(t, object.ID, other.ID)
+}
+
+func TestNewStoredObjectInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		appServiceKey string
+		payload       []byte
+		version       string
+		expectedError string
+	}{
+		{"No App Service Key", "", []byte("payload"), "version", "invalid contract, app service key cannot be empty"},
+		{"Invalid App Service Key", "store:key", []byte("payload"), "version", `invalid contract, app service key contains invalid character ':'`},
+		{"No Payload", "key", nil, "version", "invalid contract, payload cannot be empty"},
+		{"No Version", "key", []byte("payload"), "", "invalid contract, version cannot be empty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object, err := NewStoredObject(test.appServiceKey, "", test.payload, 1, test.version)
+			if !assert.Error(t, err) {
+				t.Fatal()
+			}
+			assert.Equal(t, test.expectedError, err.Error())
+			assert.Equal(t, StoredObject{}, object)
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	object, err := NewStoredObject("key", "correlation", []byte("payload"), 1, "version")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	object.EventID = "event"
+	object.Revision = 2
+
+	clone := object.Clone()
+	assert.Equal(t, object, clone)
+
+	clone.Payload[0] = 'P'
+	clone.Payload = append(clone.Payload, '!')
+	assert.Equal(t, []byte("payload"), object.Payload, "original payload should be unchanged")
+	assert.Equal(t, []byte("Payload!"), clone.Payload)
+}
+
+func TestCloneNoPayload(t *testing.T) {
+	object := StoredObject{ID: "id", AppServiceKey: "key"}
+
+	clone := object.Clone()
+	assert.Equal(t, object, clone)
+	assert.Nil(t, clone.Payload)
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		expiresAt int64
+		expected  bool
+	}{
+		{"Never Expires", 0, false},
+		{"Expired", now - 60, true},
+		{"Not Expired", now + 60, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object := StoredObject{ExpiresAt: test.expiresAt}
+			assert.Equal(t, test.expected, object.IsExpired())
+		})
+	}
+}
+
+func TestValidateContractMaxPayloadBytes(t *testing.T) {
+	object, err := NewStoredObject("key", "", []byte("payload"), 1, "version")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	assert.NoError(t, object.ValidateContract(false))
+	assert.NoError(t, object.ValidateContract(false, 0))
+	assert.NoError(t, object.ValidateContract(false, len(object.Payload)))
+	assert.Equal(t, ErrPayloadTooLarge, object.ValidateContract(false, len(object.Payload)-1))
+}
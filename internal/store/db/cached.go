@@ -0,0 +1,125 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+)
+
+type cachedStoreClient struct {
+	interfaces.StoreClient
+	ttl time.Duration
+	now func() time.Time
+
+	mutex sync.Mutex
+	cache map[string]cachedObjects
+	// generations counts the invalidations per AppServiceKey so a RetrieveFromStore which raced with a mutation
+	// does not cache what it read before the mutation
+	generations map[string]uint64
+}
+
+type cachedObjects struct {
+	objects []contracts.StoredObject
+	expires time.Time
+}
+
+// NewCachedStoreClient wraps a StoreClient so that the result of RetrieveFromStore is cached per AppServiceKey for
+// the ttl. The cached result for an AppServiceKey is invalidated by any Store, Update or RemoveFromStore for that key.
+// An Update which moves an object to another AppServiceKey invalidates both keys.
+func NewCachedStoreClient(inner interfaces.StoreClient, ttl time.Duration) interfaces.StoreClient {
+	return &cachedStoreClient{
+		StoreClient: inner,
+		ttl:         ttl,
+		now:         time.Now,
+		cache:       make(map[string]cachedObjects),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Store persists a stored object using the wrapped StoreClient and invalidates the cache for its AppServiceKey.
+func (c *cachedStoreClient) Store(o contracts.StoredObject) (string, error) {
+	defer c.invalidate(o.AppServiceKey)
+	return c.StoreClient.Store(o)
+}
+
+// RetrieveFromStore gets the objects for the AppServiceKey from the cache, or from the wrapped StoreClient when
+// not cached or expired.
+func (c *cachedStoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.StoredObject, error) {
+	c.mutex.Lock()
+	if cached, ok := c.cache[appServiceKey]; ok {
+		if c.now().Before(cached.expires) {
+			c.mutex.Unlock()
+			return copyObjects(cached.objects), nil
+		}
+		delete(c.cache, appServiceKey)
+	}
+	generation := c.generations[appServiceKey]
+	c.mutex.Unlock()
+
+	objects, err := c.StoreClient.RetrieveFromStore(appServiceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	if c.generations[appServiceKey] == generation {
+		c.cache[appServiceKey] = cachedObjects{objects: copyObjects(objects), expires: c.now().Add(c.ttl)}
+	}
+	c.mutex.Unlock()
+
+	return objects, nil
+}
+
+// Update replaces a stored object using the wrapped StoreClient and invalidates the cache for its AppServiceKey,
+// and for its previous AppServiceKey when the update moves it.
+func (c *cachedStoreClient) Update(o contracts.StoredObject) error {
+	if previous, err := c.StoreClient.GetByID(o.ID); err == nil && previous.AppServiceKey != o.AppServiceKey {
+		defer c.invalidate(previous.AppServiceKey)
+	}
+
+	defer c.invalidate(o.AppServiceKey)
+	return c.StoreClient.Update(o)
+}
+
+// RemoveFromStore removes a stored object using the wrapped StoreClient and invalidates the cache for its
+// AppServiceKey.
+func (c *cachedStoreClient) RemoveFromStore(o contracts.StoredObject) error {
+	defer c.invalidate(o.AppServiceKey)
+	return c.StoreClient.RemoveFromStore(o)
+}
+
+// invalidate drops the cached objects for the AppServiceKey and keeps any RetrieveFromStore in flight from caching.
+func (c *cachedStoreClient) invalidate(appServiceKey string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.cache, appServiceKey)
+	c.generations[appServiceKey]++
+}
+
+// copyObjects copies the slice so callers modifying the returned objects do not modify the cache.
+func copyObjects(objects []contracts.StoredObject) []contracts.StoredObject {
+	if objects == nil {
+		return nil
+	}
+
+	copied := make([]contracts.StoredObject, len(objects))
+	copy(copied, objects)
+	return copied
+}
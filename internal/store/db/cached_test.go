@@ -0,0 +1,126 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCachedStoreClient(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+	other, _ := contracts.NewStoredObject("other", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{object}, nil)
+	inner.On("RetrieveFromStore", "other").Return([]contracts.StoredObject{other}, nil)
+	inner.On("GetByID", object.ID).Return(object, nil)
+	inner.On("Update", object).Return(nil)
+	inner.On("Store", object).Return("id", nil)
+	inner.On("RemoveFromStore", object).Return(nil)
+
+	current := time.Now()
+	client := NewCachedStoreClient(inner, time.Minute)
+	client.(*cachedStoreClient).now = func() time.Time { return current }
+
+	objects, err := client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []contracts.StoredObject{object}, objects)
+
+	// modifying the result must not modify the cache
+	objects[0].RetryCount = 10
+	objects, _ = client.RetrieveFromStore("key")
+	assert.Equal(t, 0, objects[0].RetryCount)
+	inner.AssertNumberOfCalls(t, "RetrieveFromStore", 1)
+
+	_, _ = client.RetrieveFromStore("other")
+	inner.AssertNumberOfCalls(t, "RetrieveFromStore", 2)
+
+	// each mutation invalidates only its own key
+	for _, mutation := range []struct {
+		mutate func()
+		calls  int
+	}{
+		// the lookup of the previous key, the mutation and the reload of the invalidated key
+		{func() { _ = client.Update(object) }, 3},
+		// the mutation and the reload of the invalidated key
+		{func() { _, _ = client.Store(object) }, 2},
+		{func() { _ = client.RemoveFromStore(object) }, 2},
+	} {
+		calls := len(inner.Calls)
+		mutation.mutate()
+		_, _ = client.RetrieveFromStore("key")
+		_, _ = client.RetrieveFromStore("other")
+		assert.Len(t, inner.Calls, calls+mutation.calls)
+	}
+
+	// expired entries are reloaded
+	current = current.Add(time.Minute)
+	_, _ = client.RetrieveFromStore("other")
+	inner.AssertNumberOfCalls(t, "RetrieveFromStore", 6)
+}
+
+func TestCachedStoreClientUpdateMovesKey(t *testing.T) {
+	previous, _ := contracts.NewStoredObject("old", "", []byte("payload"), 1, "version")
+	previous.ID = "id"
+	moved := previous
+	moved.AppServiceKey = "new"
+
+	inner := &mocks.StoreClient{}
+	inner.On("RetrieveFromStore", "old").Return([]contracts.StoredObject{previous}, nil)
+	inner.On("RetrieveFromStore", "new").Return([]contracts.StoredObject{}, nil)
+	inner.On("GetByID", "id").Return(previous, nil)
+	inner.On("Update", moved).Return(nil)
+
+	client := NewCachedStoreClient(inner, time.Minute)
+	_, _ = client.RetrieveFromStore("old")
+	_, _ = client.RetrieveFromStore("new")
+
+	assert.NoError(t, client.Update(moved))
+
+	// both the previous and the new key are reloaded
+	_, _ = client.RetrieveFromStore("old")
+	_, _ = client.RetrieveFromStore("new")
+	inner.AssertNumberOfCalls(t, "RetrieveFromStore", 4)
+}
+
+func TestCachedStoreClientRetrieveRacingMutation(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	client := NewCachedStoreClient(inner, time.Minute)
+
+	// the object is stored while the retrieve is reading from the data store
+	inner.On("Store", object).Return("id", nil)
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{}, nil).Run(func(mock.Arguments) {
+		_, _ = client.Store(object)
+	}).Once()
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{object}, nil).Once()
+
+	objects, err := client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+
+	// the stale result was not cached
+	objects, err = client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []contracts.StoredObject{object}, objects)
+}
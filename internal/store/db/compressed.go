@@ -0,0 +1,187 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+)
+
+// CompressionGzip is the compression algorithm of the CompressedStoreClient, recorded as the PayloadEncoding of
+// the objects it stores
+const CompressionGzip = "gzip"
+
+type codec struct {
+	compress func(data []byte) ([]byte, error)
+	// decompress returns an error once the decompressed data exceeds maxBytes
+	decompress func(data []byte, maxBytes int) ([]byte, error)
+}
+
+var codecs = map[string]codec{
+	CompressionGzip: {compress: gzipCompress, decompress: gzipDecompress},
+}
+
+type compressedStoreClient struct {
+	interfaces.StoreClient
+	algorithm       string
+	maxPayloadBytes int
+}
+
+// NewCompressedStoreClient wraps a StoreClient so that payloads are compressed with the algorithm, which must be
+// gzip, before being stored. The algorithm is recorded as the PayloadEncoding of the stored objects and payloads are
+// decompressed according to their PayloadEncoding, so objects stored uncompressed are still returned as is and
+// compression can be enabled on an existing store. Store and Update return an error when the algorithm is unknown.
+// Payloads are limited to DefaultMaxPayloadBytes, before compression and once decompressed.
+func NewCompressedStoreClient(inner interfaces.StoreClient, algorithm string) interfaces.StoreClient {
+	return NewCompressedStoreClientWithLimit(inner, algorithm, DefaultMaxPayloadBytes)
+}
+
+// NewCompressedStoreClientWithLimit is NewCompressedStoreClient with the payloads limited to maxPayloadBytes, i.e.
+// the MaxPayloadBytes of the store's DatabaseInfo. Store and Update return contracts.ErrPayloadTooLarge for larger
+// payloads, and payloads which decompress to more than maxPayloadBytes are returned as an error, so a small
+// compressed payload can't expand without bound.
+func NewCompressedStoreClientWithLimit(inner interfaces.StoreClient, algorithm string, maxPayloadBytes int) interfaces.StoreClient {
+	if maxPayloadBytes <= 0 {
+		maxPayloadBytes = DefaultMaxPayloadBytes
+	}
+	return &compressedStoreClient{StoreClient: inner, algorithm: algorithm, maxPayloadBytes: maxPayloadBytes}
+}
+
+// Store compresses the payload and persists the stored object using the wrapped StoreClient.
+func (c *compressedStoreClient) Store(o contracts.StoredObject) (string, error) {
+	o, err := c.compress(o)
+	if err != nil {
+		return "", err
+	}
+
+	return c.StoreClient.Store(o)
+}
+
+// RetrieveFromStore gets objects using the wrapped StoreClient and decompresses their payloads.
+func (c *compressedStoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.StoredObject, error) {
+	objects, err := c.StoreClient.RetrieveFromStore(appServiceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range objects {
+		if objects[index], err = c.decompress(objects[index]); err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// GetByID gets the object using the wrapped StoreClient and decompresses its payload.
+func (c *compressedStoreClient) GetByID(id string) (contracts.StoredObject, error) {
+	object, err := c.StoreClient.GetByID(id)
+	if err != nil {
+		return contracts.StoredObject{}, err
+	}
+
+	if object, err = c.decompress(object); err != nil {
+		return contracts.StoredObject{}, err
+	}
+	return object, nil
+}
+
+// Update compresses the payload and updates the stored object using the wrapped StoreClient.
+func (c *compressedStoreClient) Update(o contracts.StoredObject) error {
+	o, err := c.compress(o)
+	if err != nil {
+		return err
+	}
+
+	return c.StoreClient.Update(o)
+}
+
+func (c *compressedStoreClient) compress(o contracts.StoredObject) (contracts.StoredObject, error) {
+	codec, ok := codecs[c.algorithm]
+	if !ok {
+		return contracts.StoredObject{}, fmt.Errorf("unknown compression algorithm '%s'", c.algorithm)
+	}
+	if len(o.Payload) > c.maxPayloadBytes {
+		return contracts.StoredObject{}, contracts.ErrPayloadTooLarge
+	}
+
+	payload, err := codec.compress(o.Payload)
+	if err != nil {
+		return contracts.StoredObject{}, fmt.Errorf("unable to compress payload with %s: %s", c.algorithm, err.Error())
+	}
+	o.Payload = payload
+	o.PayloadEncoding = c.algorithm
+
+	return o, nil
+}
+
+// decompress returns the object with its payload decompressed according to its PayloadEncoding.
+func (c *compressedStoreClient) decompress(o contracts.StoredObject) (contracts.StoredObject, error) {
+	if o.PayloadEncoding == "" {
+		return o, nil
+	}
+
+	codec, ok := codecs[o.PayloadEncoding]
+	if !ok {
+		return contracts.StoredObject{}, fmt.Errorf("unknown payload encoding '%s' of stored object %s", o.PayloadEncoding, o.ID)
+	}
+
+	payload, err := codec.decompress(o.Payload, c.maxPayloadBytes)
+	if err != nil {
+		return contracts.StoredObject{}, fmt.Errorf("unable to decompress payload of stored object %s: %s", o.ID, err.Error())
+	}
+	o.Payload = payload
+	o.PayloadEncoding = ""
+
+	return o, nil
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte, maxBytes int) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// One more byte than allowed is read to tell a payload of maxBytes from a larger one
+	decompressed, err := ioutil.ReadAll(io.LimitReader(reader, int64(maxBytes)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxBytes {
+		return nil, fmt.Errorf("decompressed payload exceeds the maximum of %d bytes", maxBytes)
+	}
+	return decompressed, nil
+}
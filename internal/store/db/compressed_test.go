@@ -0,0 +1,204 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const edgexEvent = `{"id":"5d5bf3a4-b3c2-4e3c-8b9b-3f4e1c4f7a1b","pushed":0,"device":"Random-Float-Device","created":1571992880924,` +
+	`"modified":0,"origin":1571992880920530745,"readings":[{"id":"8b2b4a0a-0b8e-4b41-9b2c-4a2c5d6e7f80","pushed":0,` +
+	`"created":1571992880924,"origin":1571992880919531388,"modified":0,"device":"Random-Float-Device","name":"Float32",` +
+	`"value":"qllQPw=="},{"id":"9c3c5b1b-1c9f-4c52-ac3d-5b3d6e7f8091","pushed":0,"created":1571992880924,` +
+	`"origin":1571992880919920478,"modified":0,"device":"Random-Float-Device","name":"Float64","value":"P+YM71Cid9I="}]}`
+
+func TestCompressedStoreClient(t *testing.T) {
+	payload := []byte(edgexEvent)
+	// an uncompressed payload, stored before compression was enabled, which happens to start with the gzip magic
+	legacy := []byte{0x1f, 0x8b, 'n', 'o', 't', ' ', 'g', 'z', 'i', 'p'}
+
+	object, _ := contracts.NewStoredObject("key", "", payload, 1, "version")
+
+	var stored contracts.StoredObject
+	inner := &mocks.StoreClient{}
+	inner.On("Store", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(contracts.StoredObject)
+	}).Return("id", nil)
+	inner.On("RetrieveFromStore", "key").Return(func(string) []contracts.StoredObject {
+		return []contracts.StoredObject{stored, {ID: "legacy", Payload: legacy}}
+	}, nil)
+
+	client := NewCompressedStoreClient(inner, CompressionGzip)
+
+	_, err := client.Store(object)
+	assert.NoError(t, err)
+	assert.True(t, len(stored.Payload) < len(payload))
+	assert.Equal(t, CompressionGzip, stored.PayloadEncoding)
+
+	objects, err := client.RetrieveFromStore("key")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, payload, objects[0].Payload)
+	assert.Empty(t, objects[0].PayloadEncoding)
+	assert.Equal(t, legacy, objects[1].Payload)
+}
+
+func TestCompressedStoreClientGetByID(t *testing.T) {
+	payload := []byte(edgexEvent)
+	object, _ := contracts.NewStoredObject("key", "", payload, 1, "version")
+
+	var stored contracts.StoredObject
+	inner := &mocks.StoreClient{}
+	inner.On("Store", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(contracts.StoredObject)
+	}).Return("id", nil)
+	inner.On("GetByID", object.ID).Return(func(string) contracts.StoredObject { return stored }, nil)
+	inner.On("GetByID", "missing").Return(contracts.StoredObject{}, contracts.ErrObjectNotFound)
+
+	client := NewCompressedStoreClient(inner, CompressionGzip)
+
+	_, err := client.Store(object)
+	assert.NoError(t, err)
+
+	actual, err := client.GetByID(object.ID)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, payload, actual.Payload)
+
+	_, err = client.GetByID("missing")
+	assert.Equal(t, contracts.ErrObjectNotFound, err)
+}
+
+func TestCompressedStoreClientUnknownPayloadEncoding(t *testing.T) {
+	inner := &mocks.StoreClient{}
+	inner.On("GetByID", "unknown").Return(contracts.StoredObject{ID: "unknown", Payload: []byte(edgexEvent), PayloadEncoding: "zstd"}, nil)
+
+	client := NewCompressedStoreClient(inner, CompressionGzip)
+
+	_, err := client.GetByID("unknown")
+	assert.EqualError(t, err, "unknown payload encoding 'zstd' of stored object unknown")
+}
+
+func TestCompressedStoreClientMaxPayloadBytes(t *testing.T) {
+	const maxPayloadBytes = 1000
+	// a payload which compresses to a few bytes but decompresses to far more than the limit
+	bomb, err := gzipCompress(make([]byte, 100*maxPayloadBytes))
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	inner := &mocks.StoreClient{}
+	inner.On("GetByID", "bomb").Return(contracts.StoredObject{ID: "bomb", Payload: bomb, PayloadEncoding: CompressionGzip}, nil)
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{{ID: "bomb", Payload: bomb, PayloadEncoding: CompressionGzip}}, nil)
+
+	client := NewCompressedStoreClientWithLimit(inner, CompressionGzip, maxPayloadBytes)
+
+	_, err = client.GetByID("bomb")
+	assert.EqualError(t, err, "unable to decompress payload of stored object bomb: decompressed payload exceeds the maximum of 1000 bytes")
+	_, err = client.RetrieveFromStore("key")
+	assert.Error(t, err)
+
+	object, _ := contracts.NewStoredObject("key", "", make([]byte, maxPayloadBytes+1), 1, "version")
+	_, err = client.Store(object)
+	assert.Equal(t, contracts.ErrPayloadTooLarge, err)
+	assert.Equal(t, contracts.ErrPayloadTooLarge, client.Update(object))
+	inner.AssertNotCalled(t, "Store", mock.Anything)
+	inner.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestCompressedStoreClientUnknownAlgorithm(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte(edgexEvent), 1, "version")
+	inner := &mocks.StoreClient{}
+
+	client := NewCompressedStoreClient(inner, "bogus")
+
+	_, err := client.Store(object)
+	assert.EqualError(t, err, "unknown compression algorithm 'bogus'")
+	assert.EqualError(t, client.Update(object), "unknown compression algorithm 'bogus'")
+	inner.AssertNotCalled(t, "Store", mock.Anything)
+	inner.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestCodecs(t *testing.T) {
+	random := make([]byte, 200000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	inputs := map[string][]byte{
+		"empty":     {},
+		"short":     []byte("a"),
+		"repeated":  bytes.Repeat([]byte("abcd"), 100),
+		"event":     []byte(edgexEvent),
+		"events":    bytes.Repeat([]byte(edgexEvent), 1000),
+		"zeros":     make([]byte, 300000),
+		"random":    random,
+		"overlap":   append([]byte("xy"), bytes.Repeat([]byte("x"), 70000)...),
+		"short run": []byte("abcabcabcabcabc"),
+	}
+
+	for algorithm, codec := range codecs {
+		for name, input := range inputs {
+			compressed, err := codec.compress(input)
+			if !assert.NoError(t, err, "%s %s", algorithm, name) {
+				continue
+			}
+
+			decompressed, err := codec.decompress(compressed, len(input))
+			if assert.NoError(t, err, "%s %s", algorithm, name) {
+				assert.True(t, bytes.Equal(input, decompressed), "%s %s", algorithm, name)
+			}
+		}
+	}
+}
+
+func TestGzipDecompressLimit(t *testing.T) {
+	compressed, err := gzipCompress(make([]byte, 1000))
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	decompressed, err := gzipDecompress(compressed, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, len(decompressed))
+
+	_, err = gzipDecompress(compressed, 999)
+	assert.EqualError(t, err, "decompressed payload exceeds the maximum of 999 bytes")
+}
+
+var compressedPayload []byte
+
+func BenchmarkCompressEdgeXEvent(b *testing.B) {
+	var compressed []byte
+	var err error
+	for i := 0; i < b.N; i++ {
+		compressed, err = gzipCompress([]byte(edgexEvent))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.SetBytes(int64(len(edgexEvent)))
+	b.Logf("payload reduced from %d to %d bytes (%.0f%%)", len(edgexEvent), len(compressed),
+		100-float64(len(compressed))*100/float64(len(edgexEvent)))
+	compressedPayload = compressed
+}
@@ -15,12 +15,27 @@
 // db provides useful constants, identifiers, and simple types that apply to all implementations of the store
 package db
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 const (
 	// Database providers
 	MongoDB = "mongodb"
 	RedisDB = "redisdb"
+
+	// DefaultMaxPayloadBytes is the payload size limit used when MaxPayloadBytes is not configured
+	DefaultMaxPayloadBytes = 1024 * 1024
+
+	// Defaults used by DatabaseInfo.WithDefaults for the settings that are not configured
+	DefaultHost      = "localhost"
+	DefaultPort      = 6379
+	DefaultTimeout   = 5000
+	DefaultMaxIdle   = 3
+	DefaultBatchSize = 50
 )
 
 var (
@@ -38,4 +53,85 @@ type DatabaseInfo struct {
 	// Redis specific configuration items
 	MaxIdle   int
 	BatchSize int
+
+	// MaxPayloadBytes is the largest payload the store accepts, defaults to DefaultMaxPayloadBytes when not set
+	MaxPayloadBytes int
+}
+
+// WithDefaults returns a copy of the configuration with the defaults set for the Host, Port, Timeout, MaxIdle and
+// BatchSize that are not configured. The default Port is the Redis port.
+func (info DatabaseInfo) WithDefaults() DatabaseInfo {
+	if info.Host == "" {
+		info.Host = DefaultHost
+	}
+	if info.Port == 0 {
+		info.Port = DefaultPort
+	}
+	if info.Timeout == 0 {
+		info.Timeout = DefaultTimeout
+	}
+	if info.MaxIdle == 0 {
+		info.MaxIdle = DefaultMaxIdle
+	}
+	if info.BatchSize == 0 {
+		info.BatchSize = DefaultBatchSize
+	}
+
+	return info
+}
+
+// ValidationErrors lists all the problems found by DatabaseInfo.Validate
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+
+	return "invalid database configuration: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors.
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// Validate checks the settings of the configuration before the database is dialed: the Host must be set, the Port
+// must be between 1 and 65535, the Timeout must not be negative and the Password must not contain null bytes.
+// MaxIdle and BatchSize must be at least 1 for Redis. All the problems found are returned as ValidationErrors.
+func (info DatabaseInfo) Validate() error {
+	var errs ValidationErrors
+
+	if info.Host == "" {
+		errs = append(errs, errors.New("host must be set"))
+	}
+	if info.Port < 1 || info.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port %d must be between 1 and 65535", info.Port))
+	}
+	if info.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout %d must not be negative", info.Timeout))
+	}
+	if info.Type == RedisDB {
+		if info.MaxIdle < 1 {
+			errs = append(errs, fmt.Errorf("MaxIdle %d must be at least 1", info.MaxIdle))
+		}
+		if info.BatchSize < 1 {
+			errs = append(errs, fmt.Errorf("BatchSize %d must be at least 1", info.BatchSize))
+		}
+	}
+	// the password itself is never part of the error
+	if strings.ContainsRune(info.Password, 0) {
+		errs = append(errs, errors.New("password must not contain null bytes"))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// MakeTimestamp returns the current time in milliseconds since the epoch, as used by StoredObject.LastModifiedAt
+func MakeTimestamp() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
 }
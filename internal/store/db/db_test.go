@@ -0,0 +1,109 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validRedisInfo() DatabaseInfo {
+	return DatabaseInfo{
+		Type:      RedisDB,
+		Host:      "localhost",
+		Port:      6379,
+		Timeout:   5000,
+		MaxIdle:   5000,
+		BatchSize: 1337,
+	}
+}
+
+func TestDatabaseInfoValidate(t *testing.T) {
+	assert.NoError(t, validRedisInfo().Validate())
+
+	mongo := DatabaseInfo{Type: MongoDB, Host: "localhost", Port: 27017, Timeout: 5000}
+	assert.NoError(t, mongo.Validate(), "MaxIdle and BatchSize only apply to Redis")
+}
+
+func TestDatabaseInfoValidateErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(info *DatabaseInfo)
+		expectedError string
+	}{
+		{"Empty Host", func(info *DatabaseInfo) { info.Host = "" }, "host must be set"},
+		{"Zero Port", func(info *DatabaseInfo) { info.Port = 0 }, "port 0 must be between 1 and 65535"},
+		{"Port Too Large", func(info *DatabaseInfo) { info.Port = 65536 }, "port 65536 must be between 1 and 65535"},
+		{"Negative Timeout", func(info *DatabaseInfo) { info.Timeout = -1 }, "timeout -1 must not be negative"},
+		{"MaxIdle", func(info *DatabaseInfo) { info.MaxIdle = 0 }, "MaxIdle 0 must be at least 1"},
+		{"BatchSize", func(info *DatabaseInfo) { info.BatchSize = 0 }, "BatchSize 0 must be at least 1"},
+		{"Null Byte Password", func(info *DatabaseInfo) { info.Password = "pass\x00word" }, "password must not contain null bytes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := validRedisInfo()
+			test.modify(&info)
+
+			err := info.Validate()
+			if !assert.Error(t, err) {
+				t.Fatal()
+			}
+			errs, ok := err.(ValidationErrors)
+			if !assert.True(t, ok, "expected ValidationErrors") {
+				t.Fatal()
+			}
+			assert.Len(t, errs, 1)
+			assert.Equal(t, "invalid database configuration: "+test.expectedError, err.Error())
+		})
+	}
+}
+
+func TestDatabaseInfoValidateCollectsErrors(t *testing.T) {
+	info := DatabaseInfo{Type: RedisDB, Password: "\x00"}
+
+	err := info.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !assert.True(t, ok, "expected ValidationErrors") {
+		t.Fatal()
+	}
+	assert.Len(t, errs, 5)
+	assert.NotContains(t, err.Error(), "\x00")
+}
+
+func TestDatabaseInfoWithDefaults(t *testing.T) {
+	info := DatabaseInfo{Type: RedisDB}.WithDefaults()
+
+	assert.Equal(t, DefaultHost, info.Host)
+	assert.Equal(t, DefaultPort, info.Port)
+	assert.Equal(t, DefaultTimeout, info.Timeout)
+	assert.Equal(t, DefaultMaxIdle, info.MaxIdle)
+	assert.Equal(t, DefaultBatchSize, info.BatchSize)
+	assert.NoError(t, info.Validate())
+}
+
+func TestDatabaseInfoWithDefaultsKeepsConfigured(t *testing.T) {
+	configured := validRedisInfo()
+	configured.Host = "redis"
+	configured.Port = 6380
+
+	info := configured.WithDefaults()
+	assert.Equal(t, configured, info)
+
+	invalid := DatabaseInfo{Type: RedisDB, Port: 70000, Timeout: -1}.WithDefaults()
+	assert.Equal(t, 70000, invalid.Port, "invalid values are left for Validate to report")
+	assert.Equal(t, -1, invalid.Timeout)
+}
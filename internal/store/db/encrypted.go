@@ -0,0 +1,198 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// dataKeySize is the size of the AES-256 data encryption key
+const dataKeySize = 32
+
+// KMSClient encrypts and decrypts data encryption keys with a key held by a key management service.
+type KMSClient interface {
+	// Encrypt encrypts the plaintext with the key identified by keyID
+	Encrypt(keyID string, plaintext []byte) ([]byte, error)
+
+	// Decrypt decrypts the ciphertext with the key identified by keyID
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+type encryptedStoreClient struct {
+	interfaces.StoreClient
+	kmsClient     KMSClient
+	keyID         string
+	loggingClient logger.LoggingClient
+}
+
+// NewEncryptedStoreClient wraps a StoreClient so that payloads are encrypted at rest using envelope encryption.
+// Each payload is encrypted with a random AES-256-GCM data encryption key (DEK), the DEK is encrypted with the KMS
+// key identified by keyID, and the payload stored is the encrypted DEK followed by the encrypted data. Objects which
+// fail to decrypt are logged with the loggingClient, when not nil, and skipped by RetrieveFromStore.
+func NewEncryptedStoreClient(
+	inner interfaces.StoreClient,
+	kmsClient KMSClient,
+	keyID string,
+	loggingClient logger.LoggingClient) interfaces.StoreClient {
+	return &encryptedStoreClient{
+		StoreClient:   inner,
+		kmsClient:     kmsClient,
+		keyID:         keyID,
+		loggingClient: loggingClient,
+	}
+}
+
+// Store encrypts the payload and persists the stored object using the wrapped StoreClient.
+func (c *encryptedStoreClient) Store(o contracts.StoredObject) (string, error) {
+	payload, err := c.encrypt(o.Payload)
+	if err != nil {
+		return "", err
+	}
+	o.Payload = payload
+
+	return c.StoreClient.Store(o)
+}
+
+// RetrieveFromStore gets objects using the wrapped StoreClient and decrypts their payloads. Objects which fail to
+// decrypt are skipped so that one bad object does not block the retry of all the others.
+func (c *encryptedStoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.StoredObject, error) {
+	objects, err := c.StoreClient.RetrieveFromStore(appServiceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make([]contracts.StoredObject, 0, len(objects))
+	for _, object := range objects {
+		payload, err := c.decrypt(object.Payload)
+		if err != nil {
+			if c.loggingClient != nil {
+				c.loggingClient.Error(fmt.Sprintf("Unable to decrypt payload of stored object %s, skipping it: %s", object.ID, err.Error()))
+			}
+			continue
+		}
+		object.Payload = payload
+		decrypted = append(decrypted, object)
+	}
+
+	return decrypted, nil
+}
+
+// GetByID gets the object using the wrapped StoreClient and decrypts its payload.
+func (c *encryptedStoreClient) GetByID(id string) (contracts.StoredObject, error) {
+	object, err := c.StoreClient.GetByID(id)
+	if err != nil {
+		return contracts.StoredObject{}, err
+	}
+
+	payload, err := c.decrypt(object.Payload)
+	if err != nil {
+		return contracts.StoredObject{}, fmt.Errorf("unable to decrypt payload of stored object %s: %s", object.ID, err.Error())
+	}
+	object.Payload = payload
+
+	return object, nil
+}
+
+// Update encrypts the payload with a new data encryption key and updates the stored object using the wrapped
+// StoreClient.
+func (c *encryptedStoreClient) Update(o contracts.StoredObject) error {
+	payload, err := c.encrypt(o.Payload)
+	if err != nil {
+		return err
+	}
+	o.Payload = payload
+
+	return c.StoreClient.Update(o)
+}
+
+// encrypt returns the envelope: the length of the encrypted DEK, the encrypted DEK, the nonce and the ciphertext.
+func (c *encryptedStoreClient) encrypt(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := c.kmsClient.Encrypt(c.keyID, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt data encryption key: %s", err.Error())
+	}
+
+	envelope := make([]byte, 4, 4+len(encryptedKey)+len(nonce)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint32(envelope, uint32(len(encryptedKey)))
+	envelope = append(envelope, encryptedKey...)
+	envelope = append(envelope, nonce...)
+
+	return gcm.Seal(envelope, nonce, plaintext, nil), nil
+}
+
+func (c *encryptedStoreClient) decrypt(envelope []byte) ([]byte, error) {
+	if len(envelope) < 4 {
+		return nil, errors.New("payload is not encrypted")
+	}
+
+	keyLength := int(binary.BigEndian.Uint32(envelope))
+	envelope = envelope[4:]
+	if keyLength > len(envelope) {
+		return nil, errors.New("payload is not encrypted")
+	}
+
+	dataKey, err := c.kmsClient.Decrypt(c.keyID, envelope[:keyLength])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt data encryption key: %s", err.Error())
+	}
+	envelope = envelope[keyLength:]
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envelope) < gcm.NonceSize() {
+		return nil, errors.New("payload is not encrypted")
+	}
+
+	nonce := envelope[:gcm.NonceSize()]
+	return gcm.Open(nil, nonce, envelope[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
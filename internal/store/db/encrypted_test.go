@@ -0,0 +1,164 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// xorKMS is a reversible stand in for a key management service
+type xorKMS struct {
+	keys map[string]byte
+}
+
+func (k xorKMS) transform(keyID string, data []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+
+	result := make([]byte, len(data))
+	for index, b := range data {
+		result[index] = b ^ key
+	}
+	return result, nil
+}
+
+func (k xorKMS) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	return k.transform(keyID, plaintext)
+}
+func (k xorKMS) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	return k.transform(keyID, ciphertext)
+}
+
+func TestEncryptedStoreClient(t *testing.T) {
+	payload := []byte(`{"device":"Random-Float-Device"}`)
+	object, _ := contracts.NewStoredObject("key", "", payload, 1, "version")
+
+	var stored contracts.StoredObject
+	inner := &mocks.StoreClient{}
+	inner.On("Store", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(contracts.StoredObject)
+	}).Return("id", nil)
+	inner.On("RetrieveFromStore", "key").Return(func(string) []contracts.StoredObject {
+		return []contracts.StoredObject{stored}
+	}, nil)
+
+	client := NewEncryptedStoreClient(inner, xorKMS{keys: map[string]byte{"kek": 0x5a}}, "kek", nil)
+
+	_, err := client.Store(object)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.False(t, bytes.Contains(stored.Payload, payload), "payload must be encrypted")
+
+	objects, err := client.RetrieveFromStore("key")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, payload, objects[0].Payload)
+	assert.Equal(t, payload, object.Payload, "caller's payload must not be modified")
+}
+
+func TestEncryptedStoreClientGetByID(t *testing.T) {
+	payload := []byte(`{"device":"Random-Float-Device"}`)
+	object, _ := contracts.NewStoredObject("key", "", payload, 1, "version")
+
+	var stored contracts.StoredObject
+	inner := &mocks.StoreClient{}
+	inner.On("Store", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(contracts.StoredObject)
+	}).Return("id", nil)
+	inner.On("GetByID", object.ID).Return(func(string) contracts.StoredObject { return stored }, nil)
+
+	client := NewEncryptedStoreClient(inner, xorKMS{keys: map[string]byte{"kek": 0x5a}}, "kek", nil)
+
+	_, err := client.Store(object)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	actual, err := client.GetByID(object.ID)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, payload, actual.Payload)
+}
+
+func TestEncryptedStoreClientUpdateUsesNewKey(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	var payloads [][]byte
+	inner := &mocks.StoreClient{}
+	inner.On("Update", mock.Anything).Run(func(args mock.Arguments) {
+		payloads = append(payloads, args.Get(0).(contracts.StoredObject).Payload)
+	}).Return(nil)
+
+	client := NewEncryptedStoreClient(inner, xorKMS{keys: map[string]byte{"kek": 0x5a}}, "kek", nil)
+	assert.NoError(t, client.Update(object))
+	assert.NoError(t, client.Update(object))
+	assert.NotEqual(t, payloads[0], payloads[1])
+}
+
+func TestEncryptedStoreClientErrors(t *testing.T) {
+	inner := &mocks.StoreClient{}
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{{ID: "id", Payload: []byte("plain")}}, nil)
+
+	client := NewEncryptedStoreClient(inner, xorKMS{keys: map[string]byte{}}, "missing", nil)
+
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+	_, err := client.Store(object)
+	assert.EqualError(t, err, "unable to encrypt data encryption key: unknown key")
+	inner.AssertNotCalled(t, "Store", mock.Anything)
+
+	// objects which fail to decrypt are skipped
+	objects, err := client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestEncryptedStoreClientSkipsUndecryptable(t *testing.T) {
+	kms := xorKMS{keys: map[string]byte{"kek": 0x5a}}
+	encrypter := &encryptedStoreClient{kmsClient: kms, keyID: "kek"}
+	payload, err := encrypter.encrypt([]byte("payload"))
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	inner := &mocks.StoreClient{}
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{
+		{ID: "bad", Payload: []byte("plain")},
+		{ID: "good", Payload: payload},
+	}, nil)
+
+	client := NewEncryptedStoreClient(inner, kms, "kek", logger.NewClient("app_functions_sdk_go", false, "./test.log", "DEBUG"))
+
+	objects, err := client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+	if !assert.Len(t, objects, 1) {
+		t.Fatal()
+	}
+	assert.Equal(t, "good", objects[0].ID)
+	assert.Equal(t, []byte("payload"), objects[0].Payload)
+}
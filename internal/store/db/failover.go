@@ -0,0 +1,258 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// DefaultRecoveryInterval is how long the FailoverStoreClient uses the secondary before retrying the primary.
+const DefaultRecoveryInterval = time.Minute
+
+// FailoverStoreClient is a StoreClient which routes all operations to a secondary StoreClient after a number of
+// consecutive connectivity errors from the primary StoreClient. Errors of a reachable primary, such as a missing
+// object, a revision conflict or an invalid object, are returned as is and never cause a failover.
+type FailoverStoreClient struct {
+	// RecoveryInterval is how long to wait after a failover before trying the primary again
+	RecoveryInterval time.Duration
+
+	// LoggingClient is used to log failover and recovery events, nothing is logged when nil
+	LoggingClient logger.LoggingClient
+
+	primary           interfaces.StoreClient
+	secondary         interfaces.StoreClient
+	failoverThreshold int
+
+	mutex      sync.Mutex
+	errorCount int
+	failedOver bool
+	failedAt   time.Time
+	now        func() time.Time
+
+	// secondaryObservers maps the handles of the observers registered with the primary to their handles with the
+	// secondary
+	secondaryObservers map[interfaces.ObserverHandle]interfaces.ObserverHandle
+}
+
+// NewFailoverStoreClient returns a FailoverStoreClient which fails over to the secondary StoreClient after
+// failoverThreshold consecutive connectivity errors from the primary StoreClient.
+func NewFailoverStoreClient(primary, secondary interfaces.StoreClient, failoverThreshold int) *FailoverStoreClient {
+	if failoverThreshold < 1 {
+		failoverThreshold = 1
+	}
+
+	return &FailoverStoreClient{
+		RecoveryInterval:  DefaultRecoveryInterval,
+		primary:           primary,
+		secondary:         secondary,
+		failoverThreshold: failoverThreshold,
+		now:               time.Now,
+	}
+}
+
+// Store persists a stored object to the active data store.
+func (c *FailoverStoreClient) Store(o contracts.StoredObject) (string, error) {
+	var id string
+	err := c.do(func(client interfaces.StoreClient) error {
+		var err error
+		id, err = client.Store(o)
+		return err
+	})
+
+	return id, err
+}
+
+// RetrieveFromStore gets objects from the active data store.
+func (c *FailoverStoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.StoredObject, error) {
+	var objects []contracts.StoredObject
+	err := c.do(func(client interfaces.StoreClient) error {
+		var err error
+		objects, err = client.RetrieveFromStore(appServiceKey)
+		return err
+	})
+
+	return objects, err
+}
+
+// GetByID gets the object with the ID from the active data store.
+func (c *FailoverStoreClient) GetByID(id string) (contracts.StoredObject, error) {
+	var object contracts.StoredObject
+	err := c.do(func(client interfaces.StoreClient) error {
+		var err error
+		object, err = client.GetByID(id)
+		return err
+	})
+
+	return object, err
+}
+
+// Count returns the number of objects in the active data store for the AppServiceKey.
+func (c *FailoverStoreClient) Count(appServiceKey string) (int, error) {
+	var count int
+	err := c.do(func(client interfaces.StoreClient) error {
+		var err error
+		count, err = client.Count(appServiceKey)
+		return err
+	})
+
+	return count, err
+}
+
+// Exists returns whether an object with the ID is in the active data store.
+func (c *FailoverStoreClient) Exists(id string) (bool, error) {
+	var exists bool
+	err := c.do(func(client interfaces.StoreClient) error {
+		var err error
+		exists, err = client.Exists(id)
+		return err
+	})
+
+	return exists, err
+}
+
+// Update replaces the data currently in the active data store with the provided data.
+func (c *FailoverStoreClient) Update(o contracts.StoredObject) error {
+	return c.do(func(client interfaces.StoreClient) error {
+		return client.Update(o)
+	})
+}
+
+// RemoveFromStore removes an object from the active data store.
+func (c *FailoverStoreClient) RemoveFromStore(o contracts.StoredObject) error {
+	return c.do(func(client interfaces.StoreClient) error {
+		return client.RemoveFromStore(o)
+	})
+}
+
+// Disconnect ends the connection of both the primary and secondary StoreClients.
+func (c *FailoverStoreClient) Disconnect() error {
+	primaryErr := c.primary.Disconnect()
+	secondaryErr := c.secondary.Disconnect()
+
+	if primaryErr != nil {
+		return primaryErr
+	}
+	return secondaryErr
+}
+
+// RegisterObserver adds an observer to both the primary and secondary StoreClients. The handle of the observer
+// with the primary is returned.
+func (c *FailoverStoreClient) RegisterObserver(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	handle := c.primary.RegisterObserver(obs)
+	secondaryHandle := c.secondary.RegisterObserver(obs)
+	if handle == 0 {
+		return 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.secondaryObservers == nil {
+		c.secondaryObservers = make(map[interfaces.ObserverHandle]interfaces.ObserverHandle)
+	}
+	c.secondaryObservers[handle] = secondaryHandle
+
+	return handle
+}
+
+// UnregisterObserver removes an observer from both the primary and secondary StoreClients.
+func (c *FailoverStoreClient) UnregisterObserver(handle interfaces.ObserverHandle) {
+	c.mutex.Lock()
+	secondaryHandle := c.secondaryObservers[handle]
+	delete(c.secondaryObservers, handle)
+	c.mutex.Unlock()
+
+	c.primary.UnregisterObserver(handle)
+	c.secondary.UnregisterObserver(secondaryHandle)
+}
+
+// do runs the operation against the primary unless failed over, in which case the secondary is used until the
+// RecoveryInterval has passed and the primary is tried again. The operation that triggers a failover is retried
+// on the secondary. Only connectivity errors of the primary count towards the failover threshold.
+func (c *FailoverStoreClient) do(operation func(client interfaces.StoreClient) error) error {
+	c.mutex.Lock()
+	usePrimary := !c.failedOver || c.now().Sub(c.failedAt) >= c.RecoveryInterval
+	c.mutex.Unlock()
+
+	if !usePrimary {
+		return operation(c.secondary)
+	}
+
+	err := operation(c.primary)
+
+	c.mutex.Lock()
+	if err == nil || !isConnectivityError(err) {
+		// the primary answered, even if the answer is an error
+		c.errorCount = 0
+		if c.failedOver {
+			c.failedOver = false
+			if c.LoggingClient != nil {
+				c.LoggingClient.Info("Primary store recovered, switching back from secondary store")
+			}
+		}
+		c.mutex.Unlock()
+		return err
+	}
+
+	c.errorCount++
+	if c.failedOver {
+		// the recovery attempt failed, stay on the secondary for another interval
+		c.failedAt = c.now()
+	} else if c.errorCount >= c.failoverThreshold {
+		c.failedOver = true
+		c.failedAt = c.now()
+		if c.LoggingClient != nil {
+			c.LoggingClient.Warn(fmt.Sprintf("Primary store failed %d consecutive times, failing over to secondary store: %s", c.errorCount, err.Error()))
+		}
+	}
+	failedOver := c.failedOver
+	c.mutex.Unlock()
+
+	if failedOver {
+		return operation(c.secondary)
+	}
+	return err
+}
+
+// isConnectivityError reports whether the error means the data store could not be reached, as opposed to the data
+// store rejecting the operation.
+func isConnectivityError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Mongo labels the errors of lost connections
+	var labeled interface{ HasErrorLabel(label string) bool }
+	if errors.As(err, &labeled) && labeled.HasErrorLabel("NetworkError") {
+		return true
+	}
+
+	// the Mongo server selection and Redis pool errors only keep the message
+	message := err.Error()
+	return strings.Contains(message, "server selection timeout") || strings.Contains(message, "connection pool exhausted")
+}
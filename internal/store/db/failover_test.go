@@ -0,0 +1,197 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverStoreClient(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+	failure := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	primary := &mocks.StoreClient{}
+	primary.On("Store", object).Return("", failure).Times(3)
+	primary.On("Store", object).Return("primary", nil).Once()
+	secondary := &mocks.StoreClient{}
+	secondary.On("Store", object).Return("secondary", nil)
+
+	current := time.Now()
+	client := NewFailoverStoreClient(primary, secondary, 2)
+	client.LoggingClient = logger.NewClient("app_functions_sdk_go", false, "./test.log", "DEBUG")
+	client.now = func() time.Time { return current }
+
+	// first error is returned as is
+	_, err := client.Store(object)
+	assert.Equal(t, failure, err)
+
+	// second consecutive error fails over and retries on the secondary
+	id, err := client.Store(object)
+	assert.NoError(t, err)
+	assert.Equal(t, "secondary", id)
+
+	// the primary is not used until the recovery interval has passed
+	id, _ = client.Store(object)
+	assert.Equal(t, "secondary", id)
+	primary.AssertNumberOfCalls(t, "Store", 2)
+
+	// failed recovery attempt stays on the secondary
+	current = current.Add(DefaultRecoveryInterval)
+	id, _ = client.Store(object)
+	assert.Equal(t, "secondary", id)
+	id, _ = client.Store(object)
+	assert.Equal(t, "secondary", id)
+	primary.AssertNumberOfCalls(t, "Store", 3)
+
+	// successful recovery attempt switches back to the primary
+	current = current.Add(DefaultRecoveryInterval)
+	id, err = client.Store(object)
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", id)
+	assert.False(t, client.failedOver)
+	assert.Equal(t, 0, client.errorCount)
+}
+
+func TestFailoverStoreClientResetsErrorCount(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+	failure := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	primary := &mocks.StoreClient{}
+	primary.On("Update", object).Return(failure).Once()
+	primary.On("Update", object).Return(nil).Once()
+	primary.On("Update", object).Return(failure).Once()
+	secondary := &mocks.StoreClient{}
+
+	client := NewFailoverStoreClient(primary, secondary, 2)
+
+	assert.Equal(t, failure, client.Update(object))
+	assert.NoError(t, client.Update(object))
+	assert.Equal(t, failure, client.Update(object))
+	assert.False(t, client.failedOver)
+	secondary.AssertNotCalled(t, "Update", object)
+}
+
+func TestFailoverStoreClientDisconnect(t *testing.T) {
+	failure := errors.New("failed")
+
+	primary := &mocks.StoreClient{}
+	primary.On("Disconnect").Return(nil)
+	secondary := &mocks.StoreClient{}
+	secondary.On("Disconnect").Return(failure)
+
+	client := NewFailoverStoreClient(primary, secondary, 1)
+	assert.Equal(t, failure, client.Disconnect())
+	primary.AssertExpectations(t)
+}
+
+func TestFailoverStoreClientObservers(t *testing.T) {
+	observer := &mocks.StoredObjectObserver{}
+
+	primary := &mocks.StoreClient{}
+	primary.On("RegisterObserver", observer).Return(interfaces.ObserverHandle(3))
+	primary.On("UnregisterObserver", interfaces.ObserverHandle(3)).Return()
+	secondary := &mocks.StoreClient{}
+	secondary.On("RegisterObserver", observer).Return(interfaces.ObserverHandle(7))
+	secondary.On("UnregisterObserver", interfaces.ObserverHandle(7)).Return()
+
+	client := NewFailoverStoreClient(primary, secondary, 1)
+	handle := client.RegisterObserver(observer)
+	assert.Equal(t, interfaces.ObserverHandle(3), handle)
+
+	client.UnregisterObserver(handle)
+	primary.AssertExpectations(t)
+	secondary.AssertExpectations(t)
+}
+
+func TestFailoverStoreClientExists(t *testing.T) {
+	failure := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	primary := &mocks.StoreClient{}
+	primary.On("Exists", "id").Return(false, failure)
+	secondary := &mocks.StoreClient{}
+	secondary.On("Exists", "id").Return(true, nil)
+
+	client := NewFailoverStoreClient(primary, secondary, 1)
+
+	exists, err := client.Exists("id")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.True(t, client.failedOver)
+}
+
+func TestFailoverStoreClientGetByIDNotFound(t *testing.T) {
+	primary := &mocks.StoreClient{}
+	primary.On("GetByID", "missing").Return(contracts.StoredObject{}, contracts.ErrObjectNotFound)
+	secondary := &mocks.StoreClient{}
+
+	client := NewFailoverStoreClient(primary, secondary, 1)
+
+	_, err := client.GetByID("missing")
+	assert.Equal(t, contracts.ErrObjectNotFound, err)
+	assert.False(t, client.failedOver, "a missing object must not fail over")
+	assert.Equal(t, 0, client.errorCount)
+	secondary.AssertNotCalled(t, "GetByID", "missing")
+}
+
+func TestFailoverStoreClientRejectedOperations(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"revision conflict", contracts.ErrRevisionConflict},
+		{"payload too large", contracts.ErrPayloadTooLarge},
+		{"invalid object", errors.New("invalid contract, app service key cannot be empty")},
+		{"missing id", errors.New("no ID provided")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary := &mocks.StoreClient{}
+			primary.On("Update", object).Return(test.err)
+			secondary := &mocks.StoreClient{}
+
+			client := NewFailoverStoreClient(primary, secondary, 1)
+
+			assert.Equal(t, test.err, client.Update(object))
+			assert.Equal(t, test.err, client.Update(object))
+			assert.False(t, client.failedOver, "a rejected operation must not fail over")
+			assert.Equal(t, 0, client.errorCount)
+			secondary.AssertNotCalled(t, "Update", object)
+		})
+	}
+}
+
+func TestIsConnectivityError(t *testing.T) {
+	assert.True(t, isConnectivityError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}))
+	assert.True(t, isConnectivityError(fmt.Errorf("Could not dial Redis: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})))
+	assert.True(t, isConnectivityError(io.EOF))
+	assert.True(t, isConnectivityError(errors.New("server selection timeout, current topology: {}")))
+	assert.False(t, isConnectivityError(contracts.ErrObjectNotFound))
+	assert.False(t, isConnectivityError(errors.New("object exists in database")))
+}
@@ -3,6 +3,7 @@
 package mocks
 
 import contracts "github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+import interfaces "github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
 
 import mock "github.com/stretchr/testify/mock"
 
@@ -11,6 +12,27 @@ type StoreClient struct {
 	mock.Mock
 }
 
+// Count provides a mock function with given fields: appServiceKey
+func (_m *StoreClient) Count(appServiceKey string) (int, error) {
+	ret := _m.Called(appServiceKey)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(appServiceKey)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(appServiceKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Disconnect provides a mock function with given fields:
 func (_m *StoreClient) Disconnect() error {
 	ret := _m.Called()
@@ -25,6 +47,62 @@ func (_m *StoreClient) Disconnect() error {
 	return r0
 }
 
+// RegisterObserver provides a mock function with given fields: obs
+func (_m *StoreClient) RegisterObserver(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	ret := _m.Called(obs)
+
+	var r0 interfaces.ObserverHandle
+	if rf, ok := ret.Get(0).(func(interfaces.StoredObjectObserver) interfaces.ObserverHandle); ok {
+		r0 = rf(obs)
+	} else {
+		r0 = ret.Get(0).(interfaces.ObserverHandle)
+	}
+
+	return r0
+}
+
+// Exists provides a mock function with given fields: id
+func (_m *StoreClient) Exists(id string) (bool, error) {
+	ret := _m.Called(id)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: id
+func (_m *StoreClient) GetByID(id string) (contracts.StoredObject, error) {
+	ret := _m.Called(id)
+
+	var r0 contracts.StoredObject
+	if rf, ok := ret.Get(0).(func(string) contracts.StoredObject); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(contracts.StoredObject)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RemoveFromStore provides a mock function with given fields: o
 func (_m *StoreClient) RemoveFromStore(o contracts.StoredObject) error {
 	ret := _m.Called(o)
@@ -83,6 +161,11 @@ func (_m *StoreClient) Store(o contracts.StoredObject) (string, error) {
 	return r0, r1
 }
 
+// UnregisterObserver provides a mock function with given fields: handle
+func (_m *StoreClient) UnregisterObserver(handle interfaces.ObserverHandle) {
+	_m.Called(handle)
+}
+
 // Update provides a mock function with given fields: o
 func (_m *StoreClient) Update(o contracts.StoredObject) error {
 	ret := _m.Called(o)
@@ -16,6 +16,8 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
 )
 
@@ -27,7 +29,19 @@ type StoreClient interface {
 	// RetrieveFromStore gets an object from the data store.
 	RetrieveFromStore(appServiceKey string) (objects []contracts.StoredObject, err error)
 
-	// Update replaces the data currently in the store with the provided data.
+	// GetByID gets the object with the ID from the data store. Returns contracts.ErrObjectNotFound when there is
+	// no object with the ID.
+	GetByID(id string) (contracts.StoredObject, error)
+
+	// Count returns the number of objects in the data store for the AppServiceKey.
+	Count(appServiceKey string) (int, error)
+
+	// Exists returns whether an object with the ID is in the data store, without retrieving it. An ID which isn't
+	// in the data store is not an error.
+	Exists(id string) (bool, error)
+
+	// Update replaces the data currently in the store with the provided data. Returns
+	// contracts.ErrRevisionConflict when the object's revision is no longer the stored one.
 	Update(o contracts.StoredObject) error
 
 	// RemoveFromStore removes an object from the data store.
@@ -35,4 +49,37 @@ type StoreClient interface {
 
 	// Disconnect ends the connection.
 	Disconnect() error
+
+	// RegisterObserver adds an observer which is notified after each successful mutation. The returned handle
+	// is used to unregister the observer.
+	RegisterObserver(obs StoredObjectObserver) ObserverHandle
+
+	// UnregisterObserver removes a previously registered observer.
+	UnregisterObserver(handle ObserverHandle)
+}
+
+// ContextStoreClient is implemented by the StoreClients whose writes can wait, i.e. for a rate limit, in which
+// case the writes are abandoned when the context is done. Store and Forward uses it with the pipeline's context.
+type ContextStoreClient interface {
+	// StoreContext persists a stored object to the data store and returns the assigned UUID.
+	StoreContext(ctx context.Context, o contracts.StoredObject) (id string, err error)
+
+	// UpdateContext replaces the data currently in the store with the provided data.
+	UpdateContext(ctx context.Context, o contracts.StoredObject) error
+}
+
+// ObserverHandle identifies a registered StoredObjectObserver. The zero handle is never returned for a registered
+// observer.
+type ObserverHandle uint64
+
+// StoredObjectObserver is notified synchronously after a StoredObject has been successfully mutated in the store.
+type StoredObjectObserver interface {
+	// OnStore is called after the object has been persisted.
+	OnStore(o contracts.StoredObject)
+
+	// OnUpdate is called after the object has been updated.
+	OnUpdate(o contracts.StoredObject)
+
+	// OnRemove is called after the object has been removed.
+	OnRemove(o contracts.StoredObject)
 }
@@ -0,0 +1,192 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+)
+
+// Label names used by the MeteredStoreClient metrics
+const (
+	OperationLabel     = "operation"
+	ErrorLabel         = "error"
+	AppServiceKeyLabel = "app_service_key"
+)
+
+// StoreMetrics holds the metrics recorded by a MeteredStoreClient. Any metric left nil is discarded.
+type StoreMetrics struct {
+	// Latency observes the duration of each operation in seconds, labeled by OperationLabel
+	Latency metrics.Histogram
+
+	// Errors counts failed operations, labeled by OperationLabel and ErrorLabel
+	Errors metrics.Counter
+
+	// Count is the number of objects in the store, labeled by AppServiceKeyLabel. It is set from the wrapped
+	// StoreClient's Count after each mutation, so it is accurate after a restart or a failed operation.
+	Count metrics.Gauge
+}
+
+type meteredStoreClient struct {
+	inner   interfaces.StoreClient
+	metrics StoreMetrics
+	// counted is whether the Count gauge is recorded, the wrapped StoreClient isn't counted otherwise
+	counted bool
+}
+
+// NewMeteredStoreClient wraps a StoreClient so that the latency, errors and stored object count of its operations
+// are recorded. It takes go-kit metrics instead of a prometheus.Registerer since client_golang isn't a dependency;
+// go-kit's metrics/prometheus package provides metrics registered with Prometheus.
+func NewMeteredStoreClient(inner interfaces.StoreClient, storeMetrics StoreMetrics) interfaces.StoreClient {
+	if storeMetrics.Latency == nil {
+		storeMetrics.Latency = discard.NewHistogram()
+	}
+	if storeMetrics.Errors == nil {
+		storeMetrics.Errors = discard.NewCounter()
+	}
+	counted := storeMetrics.Count != nil
+	if !counted {
+		storeMetrics.Count = discard.NewGauge()
+	}
+
+	return &meteredStoreClient{inner: inner, metrics: storeMetrics, counted: counted}
+}
+
+// Store persists a stored object using the wrapped StoreClient.
+func (c *meteredStoreClient) Store(o contracts.StoredObject) (string, error) {
+	begin := time.Now()
+	id, err := c.inner.Store(o)
+	c.record("Store", begin, err)
+	c.updateCount(o.AppServiceKey)
+
+	return id, err
+}
+
+// RetrieveFromStore gets objects using the wrapped StoreClient.
+func (c *meteredStoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.StoredObject, error) {
+	begin := time.Now()
+	objects, err := c.inner.RetrieveFromStore(appServiceKey)
+	c.record("RetrieveFromStore", begin, err)
+
+	return objects, err
+}
+
+// GetByID gets an object using the wrapped StoreClient.
+func (c *meteredStoreClient) GetByID(id string) (contracts.StoredObject, error) {
+	begin := time.Now()
+	object, err := c.inner.GetByID(id)
+	c.record("GetByID", begin, err)
+
+	return object, err
+}
+
+// Count returns the number of stored objects using the wrapped StoreClient.
+func (c *meteredStoreClient) Count(appServiceKey string) (int, error) {
+	begin := time.Now()
+	count, err := c.inner.Count(appServiceKey)
+	c.record("Count", begin, err)
+
+	return count, err
+}
+
+// Exists checks whether an object is stored using the wrapped StoreClient.
+func (c *meteredStoreClient) Exists(id string) (bool, error) {
+	begin := time.Now()
+	exists, err := c.inner.Exists(id)
+	c.record("Exists", begin, err)
+
+	return exists, err
+}
+
+// Update replaces a stored object using the wrapped StoreClient.
+func (c *meteredStoreClient) Update(o contracts.StoredObject) error {
+	begin := time.Now()
+	err := c.inner.Update(o)
+	c.record("Update", begin, err)
+	c.updateCount(o.AppServiceKey)
+
+	return err
+}
+
+// RemoveFromStore removes a stored object using the wrapped StoreClient.
+func (c *meteredStoreClient) RemoveFromStore(o contracts.StoredObject) error {
+	begin := time.Now()
+	err := c.inner.RemoveFromStore(o)
+	c.record("RemoveFromStore", begin, err)
+	c.updateCount(o.AppServiceKey)
+
+	return err
+}
+
+// updateCount sets the Count gauge to the number of objects for the AppServiceKey in the wrapped StoreClient. The
+// gauge is left unchanged when the objects can't be counted.
+func (c *meteredStoreClient) updateCount(appServiceKey string) {
+	if !c.counted {
+		return
+	}
+
+	count, err := c.inner.Count(appServiceKey)
+	if err != nil {
+		return
+	}
+	c.metrics.Count.With(AppServiceKeyLabel, appServiceKey).Set(float64(count))
+}
+
+// Disconnect ends the connection of the wrapped StoreClient.
+func (c *meteredStoreClient) Disconnect() error {
+	return c.inner.Disconnect()
+}
+
+// RegisterObserver adds an observer to the wrapped StoreClient.
+func (c *meteredStoreClient) RegisterObserver(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	return c.inner.RegisterObserver(obs)
+}
+
+// UnregisterObserver removes an observer from the wrapped StoreClient.
+func (c *meteredStoreClient) UnregisterObserver(handle interfaces.ObserverHandle) {
+	c.inner.UnregisterObserver(handle)
+}
+
+func (c *meteredStoreClient) record(operation string, begin time.Time, err error) {
+	c.metrics.Latency.With(OperationLabel, operation).Observe(time.Since(begin).Seconds())
+	if err != nil {
+		c.metrics.Errors.With(OperationLabel, operation, ErrorLabel, errorType(err)).Add(1)
+	}
+}
+
+// errorType classifies an error into a low cardinality label value.
+func errorType(err error) string {
+	switch err.(type) {
+	case contracts.ValidationError:
+		return "validation"
+	}
+
+	switch err {
+	case contracts.ErrPayloadTooLarge:
+		return "payload_too_large"
+	case contracts.ErrObjectNotFound:
+		return "not_found"
+	case context.DeadlineExceeded:
+		return "timeout"
+	default:
+		return "store"
+	}
+}
@@ -0,0 +1,176 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMetric records the values of a metric keyed by its label values
+type fakeMetric struct {
+	labels []string
+	values map[string]float64
+}
+
+func newFakeMetric() *fakeMetric {
+	return &fakeMetric{values: make(map[string]float64)}
+}
+
+func (f *fakeMetric) key() string {
+	key := ""
+	for _, label := range f.labels {
+		key += label + "|"
+	}
+	return key
+}
+
+func (f *fakeMetric) with(labelValues ...string) *fakeMetric {
+	return &fakeMetric{labels: append(append([]string{}, f.labels...), labelValues...), values: f.values}
+}
+
+func (f *fakeMetric) With(labelValues ...string) metrics.Counter { return f.with(labelValues...) }
+func (f *fakeMetric) Add(delta float64)                          { f.values[f.key()] += delta }
+
+type fakeHistogram struct{ *fakeMetric }
+
+func (f fakeHistogram) With(labelValues ...string) metrics.Histogram {
+	return fakeHistogram{f.with(labelValues...)}
+}
+func (f fakeHistogram) Observe(value float64) { f.values[f.key()]++ }
+
+type fakeGauge struct{ *fakeMetric }
+
+func (f fakeGauge) With(labelValues ...string) metrics.Gauge {
+	return fakeGauge{f.with(labelValues...)}
+}
+func (f fakeGauge) Set(value float64) { f.values[f.key()] = value }
+
+func TestMeteredStoreClient(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	inner.On("Store", object).Return("id", nil)
+	inner.On("Update", object).Return(contracts.ErrPayloadTooLarge)
+	inner.On("RemoveFromStore", object).Return(errors.New("failed"))
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{object}, nil)
+	inner.On("Count", "key").Return(5, nil).Once()
+	inner.On("Count", "key").Return(6, nil)
+
+	latency := fakeHistogram{newFakeMetric()}
+	errorCount := newFakeMetric()
+	count := fakeGauge{newFakeMetric()}
+
+	client := NewMeteredStoreClient(inner, StoreMetrics{Latency: latency, Errors: errorCount, Count: count})
+
+	_, err := client.Store(object)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"app_service_key|key|": 5}, count.values, "set from the stored objects")
+	_, err = client.Store(object)
+	assert.NoError(t, err)
+	assert.Error(t, client.Update(object))
+	assert.Error(t, client.RemoveFromStore(object))
+	_, err = client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]float64{
+		"operation|Store|":             2,
+		"operation|Update|":            1,
+		"operation|RemoveFromStore|":   1,
+		"operation|RetrieveFromStore|": 1,
+	}, latency.values)
+	assert.Equal(t, map[string]float64{
+		"operation|Update|error|payload_too_large|": 1,
+		"operation|RemoveFromStore|error|store|":    1,
+	}, errorCount.values)
+	assert.Equal(t, map[string]float64{"app_service_key|key|": 6}, count.values)
+	inner.AssertNumberOfCalls(t, "Count", 4)
+}
+
+func TestMeteredStoreClientCountError(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	inner.On("Store", object).Return("id", nil)
+	inner.On("Count", "key").Return(0, errors.New("unavailable"))
+
+	count := fakeGauge{newFakeMetric()}
+	client := NewMeteredStoreClient(inner, StoreMetrics{Count: count})
+
+	_, err := client.Store(object)
+	assert.NoError(t, err)
+	assert.Empty(t, count.values, "the gauge is left unchanged when the objects can't be counted")
+}
+
+func TestMeteredStoreClientNoMetrics(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	inner.On("Store", object).Return("id", nil)
+
+	client := NewMeteredStoreClient(inner, StoreMetrics{})
+	id, err := client.Store(object)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", id)
+}
+
+func TestMeteredStoreClientExists(t *testing.T) {
+	inner := &mocks.StoreClient{}
+	inner.On("Exists", "id").Return(true, nil)
+	inner.On("Exists", "missing").Return(false, nil)
+
+	latency := fakeHistogram{newFakeMetric()}
+	errorCount := newFakeMetric()
+
+	client := NewMeteredStoreClient(inner, StoreMetrics{Latency: latency, Errors: errorCount})
+
+	exists, err := client.Exists("id")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = client.Exists("missing")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Equal(t, map[string]float64{"operation|Exists|": 2}, latency.values)
+	assert.Empty(t, errorCount.values)
+}
+
+func TestMeteredStoreClientGetByID(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	inner.On("GetByID", object.ID).Return(object, nil)
+	inner.On("GetByID", "missing").Return(contracts.StoredObject{}, contracts.ErrObjectNotFound)
+
+	latency := fakeHistogram{newFakeMetric()}
+	errorCount := newFakeMetric()
+
+	client := NewMeteredStoreClient(inner, StoreMetrics{Latency: latency, Errors: errorCount})
+
+	actual, err := client.GetByID(object.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, object, actual)
+	_, err = client.GetByID("missing")
+	assert.Equal(t, contracts.ErrObjectNotFound, err)
+
+	assert.Equal(t, map[string]float64{"operation|GetByID|": 2}, latency.values)
+	assert.Equal(t, map[string]float64{"operation|GetByID|error|not_found|": 1}, errorCount.values)
+}
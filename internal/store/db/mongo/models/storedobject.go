@@ -38,6 +38,9 @@ type StoredObject struct {
 	// Payload is the data to be exported
 	Payload []byte `bson:"payload"`
 
+	// PayloadEncoding is how the Payload is encoded, empty when it is stored as is.
+	PayloadEncoding string `bson:"payloadEncoding"`
+
 	// RetryCount is how many times this has tried to be exported
 	RetryCount int `bson:"retryCount"`
 
@@ -55,43 +58,67 @@ type StoredObject struct {
 
 	// EventChecksum is used to identify CBOR encoded data from the core services and mark it as pushed.
 	EventChecksum string `bson:"eventChecksum"`
+
+	// LastModifiedAt is when this was last stored or updated, in milliseconds since the epoch.
+	LastModifiedAt int64 `bson:"lastModifiedAt"`
+
+	// Revision is incremented on each update, used for optimistic concurrency.
+	Revision int `bson:"revision"`
+
+	// ExpiresAt is when this expires, in seconds since the epoch. Zero means this never expires.
+	ExpiresAt int64 `bson:"expiresAt"`
 }
 
-// FromContract builds a model object out of the supplied contract.
+// FromContract builds a model object out of the supplied contract. The contract's ID is validated and formatted as
+// a standard UUID, and a new UUID is generated when it is empty, so use it for the objects being added to the store.
 func (o *StoredObject) FromContract(c contracts.StoredObject) error {
-	var err error
-	o.UUID, err = GetUUID(c.ID)
+	id, err := GetUUID(c.ID)
 	if err != nil {
 		return err
 	}
 
+	o.FromContractWithID(c)
+	o.UUID = id
+
+	return nil
+}
+
+// FromContractWithID builds a model object out of the supplied contract, using the contract's ID verbatim as the
+// UUID without generating a new one. Use it for the objects already in the store, i.e. to update them, whose ID
+// must stay stable.
+func (o *StoredObject) FromContractWithID(c contracts.StoredObject) {
+	o.UUID = c.ID
 	o.AppServiceKey = c.AppServiceKey
 	o.Payload = c.Payload
+	o.PayloadEncoding = c.PayloadEncoding
 	o.RetryCount = c.RetryCount
 	o.PipelinePosition = c.PipelinePosition
 	o.Version = c.Version
 	o.CorrelationID = c.CorrelationID
 	o.EventID = c.EventID
 	o.EventChecksum = c.EventChecksum
-
-	return nil
+	o.LastModifiedAt = c.LastModifiedAt
+	o.Revision = c.Revision
+	o.ExpiresAt = c.ExpiresAt
 }
 
 // ToContract builds a contract out of the supplied model.
 func (o StoredObject) ToContract() contracts.StoredObject {
-	contract := contracts.NewStoredObject(
-		o.AppServiceKey,
-		o.Payload,
-		o.PipelinePosition,
-		o.Version)
-
-	contract.ID = ToContractId(o.ObjectID, o.UUID)
-	contract.RetryCount = o.RetryCount
-	contract.CorrelationID = o.CorrelationID
-	contract.EventID = o.EventID
-	contract.EventChecksum = o.EventChecksum
-
-	return contract
+	return contracts.StoredObject{
+		ID:               ToContractId(o.ObjectID, o.UUID),
+		AppServiceKey:    o.AppServiceKey,
+		Payload:          o.Payload,
+		PayloadEncoding:  o.PayloadEncoding,
+		RetryCount:       o.RetryCount,
+		PipelinePosition: o.PipelinePosition,
+		Version:          o.Version,
+		CorrelationID:    o.CorrelationID,
+		EventID:          o.EventID,
+		EventChecksum:    o.EventChecksum,
+		LastModifiedAt:   o.LastModifiedAt,
+		Revision:         o.Revision,
+		ExpiresAt:        o.ExpiresAt,
+	}
 }
 
 // GetUUID validates that the provided ID is a valid UUID, and returns it in the standard format.
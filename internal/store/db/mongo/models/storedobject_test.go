@@ -140,6 +140,63 @@ func TestFromContract(t *testing.T) {
 	}
 }
 
+func TestFromContractNoID(t *testing.T) {
+	actual := StoredObject{}
+	contract := TestContractUUID
+	contract.ID = TestUUIDNil
+	if err := actual.FromContract(contract); err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+
+	if _, err := uuid.Parse(actual.UUID); err != nil {
+		t.Fatalf("Expected a generated UUID, got '%s'", actual.UUID)
+	}
+}
+
+func TestFromContractWithID(t *testing.T) {
+	tests := []struct {
+		testName       string
+		fromContract   contracts.StoredObject
+		expectedResult StoredObject
+	}{
+		{
+			"UUID",
+			TestContractUUID,
+			TestModelUUID,
+		},
+		{
+			"No ID",
+			contracts.StoredObject{
+				AppServiceKey:    TestAppServiceKey,
+				Payload:          TestPayload,
+				RetryCount:       TestRetryCount,
+				PipelinePosition: TestPipelinePosition,
+				Version:          TestVersion,
+				CorrelationID:    TestCorrelationID,
+				EventID:          TestEventID,
+				EventChecksum:    TestEventChecksum,
+			},
+			TestModelNoID,
+		},
+		{
+			"Uppercase UUID",
+			contracts.StoredObject{ID: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8"},
+			StoredObject{UUID: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(tt *testing.T) {
+			actual := StoredObject{}
+			actual.FromContractWithID(test.fromContract)
+
+			if !reflect.DeepEqual(actual, test.expectedResult) {
+				t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", test.expectedResult, actual)
+			}
+		})
+	}
+}
+
 func TestToContract(t *testing.T) {
 	tests := []struct {
 		testName       string
@@ -37,17 +37,19 @@ import (
 
 // Client provides a wrapper for Mongo's Client type
 type Client struct {
-	Timeout time.Duration
-	Client  *mongo.Database
+	Timeout         time.Duration
+	Client          *mongo.Database
+	MaxPayloadBytes int
 	// unexported Client for Disconnect
-	client *mongo.Client
+	client    *mongo.Client
+	observers *db.Observers
 }
 
 const mongoCollection = "store"
 
 // Store persists a stored object to the data store.
 func (c Client) Store(o contracts.StoredObject) (string, error) {
-	err := o.ValidateContract(false)
+	err := o.ValidateContract(false, c.MaxPayloadBytes)
 	if err != nil {
 		return "", err
 	}
@@ -60,6 +62,8 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 		return "", err
 	}
 	var doc bson.M
+	o.LastModifiedAt = db.MakeTimestamp()
+	o.Revision = 1
 
 	// determine if this object already exists in the DB
 	filter := bson.M{"uuid": uuid}
@@ -77,12 +81,16 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 		"uuid":             uuid,
 		"appServiceKey":    o.AppServiceKey,
 		"payload":          o.Payload,
+		"payloadEncoding":  o.PayloadEncoding,
 		"retryCount":       o.RetryCount,
 		"pipelinePosition": o.PipelinePosition,
 		"version":          o.Version,
 		"correlationID":    o.CorrelationID,
 		"eventID":          o.EventID,
 		"eventChecksum":    o.EventChecksum,
+		"lastModifiedAt":   o.LastModifiedAt,
+		"revision":         o.Revision,
+		"expiresAt":        o.ExpiresAt,
 	}
 
 	_, err = c.Client.Collection(mongoCollection).InsertOne(ctx, doc)
@@ -90,10 +98,13 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 		return "", err
 	}
 
+	o.ID = uuid
+	c.observers.NotifyStore(o)
+
 	return uuid, nil
 }
 
-// RetrieveFromStore gets an object from the data store.
+// RetrieveFromStore gets an object from the data store. Expired objects are not returned.
 func (c Client) RetrieveFromStore(appServiceKey string) (objects []contracts.StoredObject, err error) {
 	// do not satisfy requests for a blank ASK, this will return ALL objects with ANY ASK
 	if appServiceKey == "" {
@@ -129,15 +140,82 @@ func (c Client) RetrieveFromStore(appServiceKey string) (objects []contracts.Sto
 	}
 
 	for _, model := range modelSlice {
-		objects = append(objects, model.ToContract())
+		object := model.ToContract()
+		// the expired objects are left out even though they haven't been removed yet
+		if object.IsExpired() {
+			continue
+		}
+		objects = append(objects, object)
 	}
 
 	return objects, nil
 }
 
-// Update replaces the data currently in the store with the provided data.
+// Count returns the number of objects in the data store for the AppServiceKey.
+func (c Client) Count(appServiceKey string) (int, error) {
+	// do not satisfy requests for a blank ASK, this would count ALL objects with ANY ASK
+	if appServiceKey == "" {
+		return 0, errors.New("no AppServiceKey provided")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	count, err := c.Client.Collection(mongoCollection).CountDocuments(ctx, bson.M{"appServiceKey": appServiceKey})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// GetByID gets the object with the ID from the data store. Expired objects are not returned.
+func (c Client) GetByID(id string) (contracts.StoredObject, error) {
+	// do not satisfy requests for a blank ID, this would match the documents stored without a UUID
+	if id == "" {
+		return contracts.StoredObject{}, errors.New("no ID provided")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	var model models.StoredObject
+	err := c.Client.Collection(mongoCollection).FindOne(ctx, bson.M{"uuid": id}).Decode(&model)
+	if err == mongo.ErrNoDocuments {
+		return contracts.StoredObject{}, contracts.ErrObjectNotFound
+	} else if err != nil {
+		return contracts.StoredObject{}, err
+	}
+
+	object := model.ToContract()
+	if object.IsExpired() {
+		return contracts.StoredObject{}, contracts.ErrObjectNotFound
+	}
+	return object, nil
+}
+
+// Exists returns whether an object with the ID is in the data store.
+func (c Client) Exists(id string) (bool, error) {
+	// do not satisfy requests for a blank ID, this would match the documents stored without a UUID
+	if id == "" {
+		return false, errors.New("no ID provided")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	count, err := c.Client.Collection(mongoCollection).CountDocuments(ctx, bson.M{"uuid": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Update replaces the data currently in the store with the provided data. The document is only replaced when
+// its revision is the object's revision, ErrRevisionConflict is returned otherwise.
 func (c Client) Update(o contracts.StoredObject) error {
-	err := o.ValidateContract(true)
+	err := o.ValidateContract(true, c.MaxPayloadBytes)
 	if err != nil {
 		return err
 	}
@@ -145,27 +223,51 @@ func (c Client) Update(o contracts.StoredObject) error {
 	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
 	defer cancel()
 
+	// documents stored without a revision are at revision 0
+	var revision interface{} = o.Revision
+	if o.Revision == 0 {
+		revision = bson.M{"$in": bson.A{0, nil}}
+	}
 	filter := bson.D{
 		primitive.E{Key: "uuid", Value: o.ID},
 		primitive.E{Key: "appServiceKey", Value: o.AppServiceKey},
+		primitive.E{Key: "revision", Value: revision},
 	}
 
+	o.Revision++
+	o.LastModifiedAt = db.MakeTimestamp()
 	update := bson.M{"$set": bson.M{
 		"uuid":             o.ID,
 		"appServiceKey":    o.AppServiceKey,
 		"payload":          o.Payload,
+		"payloadEncoding":  o.PayloadEncoding,
 		"retryCount":       o.RetryCount,
 		"pipelinePosition": o.PipelinePosition,
 		"version":          o.Version,
 		"correlationID":    o.CorrelationID,
 		"eventID":          o.EventID,
 		"eventChecksum":    o.EventChecksum,
+		"lastModifiedAt":   o.LastModifiedAt,
+		"revision":         o.Revision,
+		"expiresAt":        o.ExpiresAt,
 	}}
 
-	_, err = c.Client.Collection(mongoCollection).UpdateOne(ctx, filter, update)
+	result, err := c.Client.Collection(mongoCollection).UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		count, err := c.Client.Collection(mongoCollection).CountDocuments(ctx, filter[:2])
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("object does not exist in database")
+		}
+		return contracts.ErrRevisionConflict
+	}
+
+	c.observers.NotifyUpdate(o)
 
 	return nil
 }
@@ -190,6 +292,8 @@ func (c Client) RemoveFromStore(o contracts.StoredObject) error {
 		return err
 	}
 
+	c.observers.NotifyRemove(o)
+
 	return nil
 }
 
@@ -200,6 +304,16 @@ func (c Client) Disconnect() error {
 	return c.client.Disconnect(ctx)
 }
 
+// RegisterObserver adds an observer which is notified after each successful mutation.
+func (c Client) RegisterObserver(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	return c.observers.Register(obs)
+}
+
+// UnregisterObserver removes a previously registered observer.
+func (c Client) UnregisterObserver(handle interfaces.ObserverHandle) {
+	c.observers.Unregister(handle)
+}
+
 // NewClient provides a factory for building a StoreClient
 func NewClient(config db.DatabaseInfo) (client interfaces.StoreClient, err error) {
 	var uri string
@@ -210,6 +324,11 @@ func NewClient(config db.DatabaseInfo) (client interfaces.StoreClient, err error
 		uri = fmt.Sprintf("mongodb://%s:%s@%s:%s", config.Username, config.Password, config.Host, strconv.Itoa(config.Port))
 	}
 
+	maxPayloadBytes := config.MaxPayloadBytes
+	if maxPayloadBytes == 0 {
+		maxPayloadBytes = db.DefaultMaxPayloadBytes
+	}
+
 	timeout := time.Duration(config.Timeout) * time.Millisecond
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -248,6 +367,6 @@ func NewClient(config db.DatabaseInfo) (client interfaces.StoreClient, err error
 			return nil, ctx.Err()
 		}
 	case <-notify:
-		return Client{timeout, mongoDatabase, mongoClient}, nil
+		return Client{timeout, mongoDatabase, maxPayloadBytes, mongoClient, &db.Observers{}}, nil
 	}
 }
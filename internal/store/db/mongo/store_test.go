@@ -296,6 +296,33 @@ func TestClient_RetrieveFromStore(t *testing.T) {
 	}
 }
 
+func TestClient_Count(t *testing.T) {
+	UUIDAppServiceKey := uuid.New().String()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+
+	for i := 0; i < 2; i++ {
+		object := TestContractBase
+		object.ID = uuid.New().String()
+		object.AppServiceKey = UUIDAppServiceKey
+		if _, err := client.Store(object); err != nil {
+			t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+		}
+	}
+
+	count, err := client.Count(UUIDAppServiceKey)
+	if err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+	if count != 2 {
+		t.Fatalf("Count doesn't match expected.\nExpected: %v\nActual: %v\n", 2, count)
+	}
+
+	if _, err := client.Count(""); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
 func TestClient_Update(t *testing.T) {
 	TestContractValid := TestContractBase
 	TestContractValid.AppServiceKey = uuid.New().String()
@@ -305,6 +332,7 @@ func TestClient_Update(t *testing.T) {
 
 	// add the objects we're going to update in the database now so we have a known state
 	TestContractValid.ID, _ = client.Store(TestContractValid)
+	TestContractValid.Revision = 1
 
 	tests := []struct {
 		name          string
@@ -356,8 +384,12 @@ func TestClient_Update(t *testing.T) {
 					t.Fatal("No objects retrieved from store")
 				}
 
-				if !reflect.DeepEqual(actual[0], test.expectedVal) {
-					t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", test.expectedVal, actual[0])
+				// the store increments the revision and sets the modification time
+				expected := test.expectedVal
+				expected.Revision++
+				expected.LastModifiedAt = actual[0].LastModifiedAt
+				if !reflect.DeepEqual(actual[0], expected) {
+					t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", expected, actual[0])
 				}
 			}
 		})
@@ -369,6 +401,25 @@ func TestClient_Update(t *testing.T) {
 	}
 }
 
+func TestClient_UpdateRevisionConflict(t *testing.T) {
+	TestContractValid := TestContractBase
+	TestContractValid.AppServiceKey = uuid.New().String()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+
+	TestContractValid.ID, _ = client.Store(TestContractValid)
+	// both updaters retrieved the object at revision 1
+	TestContractValid.Revision = 1
+
+	if err := client.Update(TestContractValid); err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+
+	if err := client.Update(TestContractValid); err != contracts.ErrRevisionConflict {
+		t.Fatalf("Expected a revision conflict, got: %v", err)
+	}
+}
+
 func TestClient_RemoveFromStore(t *testing.T) {
 	TestContractValid := TestContractBase
 	TestContractValid.AppServiceKey = uuid.New().String()
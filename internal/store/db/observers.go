@@ -0,0 +1,104 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+)
+
+// Observers is a thread-safe list of StoredObjectObservers shared by the store implementations. The observers are
+// identified by the handle returned by Register, so observers of any type, comparable or not, can be unregistered.
+type Observers struct {
+	mutex      sync.RWMutex
+	observers  []registeredObserver
+	lastHandle interfaces.ObserverHandle
+}
+
+type registeredObserver struct {
+	handle   interfaces.ObserverHandle
+	observer interfaces.StoredObjectObserver
+}
+
+// Register adds an observer to the list and returns its handle. The zero handle is returned, and nothing is
+// registered, for a nil observer or a nil list.
+func (o *Observers) Register(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	if o == nil || obs == nil {
+		return 0
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.lastHandle++
+	o.observers = append(o.observers, registeredObserver{handle: o.lastHandle, observer: obs})
+	return o.lastHandle
+}
+
+// Unregister removes the observer with the handle from the list.
+func (o *Observers) Unregister(handle interfaces.ObserverHandle) {
+	if o == nil || handle == 0 {
+		return
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for index, registered := range o.observers {
+		if registered.handle == handle {
+			o.observers = append(o.observers[:index], o.observers[index+1:]...)
+			return
+		}
+	}
+}
+
+// NotifyStore calls OnStore for every registered observer.
+func (o *Observers) NotifyStore(object contracts.StoredObject) {
+	for _, obs := range o.snapshot() {
+		obs.OnStore(object)
+	}
+}
+
+// NotifyUpdate calls OnUpdate for every registered observer.
+func (o *Observers) NotifyUpdate(object contracts.StoredObject) {
+	for _, obs := range o.snapshot() {
+		obs.OnUpdate(object)
+	}
+}
+
+// NotifyRemove calls OnRemove for every registered observer.
+func (o *Observers) NotifyRemove(object contracts.StoredObject) {
+	for _, obs := range o.snapshot() {
+		obs.OnRemove(object)
+	}
+}
+
+// snapshot copies the list so observers can register or unregister from within a callback without deadlocking.
+func (o *Observers) snapshot() []interfaces.StoredObjectObserver {
+	if o == nil {
+		return nil
+	}
+
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	observers := make([]interfaces.StoredObjectObserver, len(o.observers))
+	for index, registered := range o.observers {
+		observers[index] = registered.observer
+	}
+	return observers
+}
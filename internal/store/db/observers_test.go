@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestObserversNotify(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	first := &mocks.StoredObjectObserver{}
+	first.On("OnStore", object).Return()
+	first.On("OnUpdate", object).Return()
+	first.On("OnRemove", object).Return()
+	second := &mocks.StoredObjectObserver{}
+	second.On("OnStore", object).Return()
+
+	observers := &Observers{}
+	observers.Register(first)
+	handle := observers.Register(second)
+
+	observers.NotifyStore(object)
+	second.AssertNumberOfCalls(t, "OnStore", 1)
+
+	observers.Unregister(handle)
+	observers.NotifyUpdate(object)
+	observers.NotifyRemove(object)
+
+	first.AssertExpectations(t)
+	second.AssertNotCalled(t, "OnUpdate", mock.Anything)
+	second.AssertNotCalled(t, "OnRemove", mock.Anything)
+}
+
+func TestObserversUnregisterUnknown(t *testing.T) {
+	observers := &Observers{}
+	handle := observers.Register(&mocks.StoredObjectObserver{})
+
+	observers.Unregister(handle + 1)
+	observers.Unregister(0)
+	assert.Len(t, observers.observers, 1)
+
+	assert.Equal(t, interfaces.ObserverHandle(0), observers.Register(nil))
+	assert.Len(t, observers.observers, 1)
+}
+
+// sliceObserver isn't comparable, so it can only be unregistered by its handle
+type sliceObserver struct {
+	stored []contracts.StoredObject
+}
+
+func (s sliceObserver) OnStore(contracts.StoredObject)  {}
+func (s sliceObserver) OnUpdate(contracts.StoredObject) {}
+func (s sliceObserver) OnRemove(contracts.StoredObject) {}
+
+func TestObserversNotComparable(t *testing.T) {
+	observers := &Observers{}
+	first := observers.Register(sliceObserver{})
+	second := observers.Register(sliceObserver{})
+	assert.NotEqual(t, first, second)
+
+	assert.NotPanics(t, func() { observers.Unregister(second) })
+	assert.Len(t, observers.observers, 1)
+	assert.Equal(t, first, observers.observers[0].handle)
+}
+
+func TestObserversNil(t *testing.T) {
+	var observers *Observers
+	assert.NotPanics(t, func() { observers.NotifyStore(contracts.StoredObject{}) })
+	assert.NotPanics(t, func() {
+		handle := observers.Register(&mocks.StoredObjectObserver{})
+		assert.Equal(t, interfaces.ObserverHandle(0), handle)
+		observers.Unregister(handle)
+	})
+}
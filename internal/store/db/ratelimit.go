@@ -0,0 +1,201 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+)
+
+// DefaultRateLimitWaitTimeout is how long a throttled write waits for a token before giving up, when the write
+// isn't given a context.
+const DefaultRateLimitWaitTimeout = 5 * time.Second
+
+// RateLimitedStoreClient is a StoreClient which limits the rate of the Store and Update operations of the wrapped
+// StoreClient. The other operations aren't throttled.
+type RateLimitedStoreClient struct {
+	// WaitTimeout is how long Store and Update wait for a token. StoreContext and UpdateContext wait until their
+	// context is done instead.
+	WaitTimeout time.Duration
+
+	inner   interfaces.StoreClient
+	limiter *tokenBucket
+}
+
+// NewRateLimitedStoreClient wraps a StoreClient so that Store and Update are limited to rps operations per second.
+// RetrieveFromStore and RemoveFromStore are not throttled. A rps of zero or less disables the limit.
+func NewRateLimitedStoreClient(inner interfaces.StoreClient, rps float64) *RateLimitedStoreClient {
+	return &RateLimitedStoreClient{
+		WaitTimeout: DefaultRateLimitWaitTimeout,
+		inner:       inner,
+		limiter:     newTokenBucket(rps),
+	}
+}
+
+// Store waits up to the WaitTimeout for a token and then persists the stored object using the wrapped
+// StoreClient. context.DeadlineExceeded is returned when no token is available in time.
+func (c *RateLimitedStoreClient) Store(o contracts.StoredObject) (string, error) {
+	ctx, cancel := c.waitContext()
+	defer cancel()
+
+	return c.StoreContext(ctx, o)
+}
+
+// StoreContext waits for a token until the context is done and then persists the stored object using the
+// wrapped StoreClient. The context's error is returned when no token is available in time.
+func (c *RateLimitedStoreClient) StoreContext(ctx context.Context, o contracts.StoredObject) (string, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return "", err
+	}
+
+	return c.inner.Store(o)
+}
+
+// Update waits up to the WaitTimeout for a token and then updates the stored object using the wrapped
+// StoreClient. context.DeadlineExceeded is returned when no token is available in time.
+func (c *RateLimitedStoreClient) Update(o contracts.StoredObject) error {
+	ctx, cancel := c.waitContext()
+	defer cancel()
+
+	return c.UpdateContext(ctx, o)
+}
+
+// UpdateContext waits for a token until the context is done and then updates the stored object using the
+// wrapped StoreClient. The context's error is returned when no token is available in time.
+func (c *RateLimitedStoreClient) UpdateContext(ctx context.Context, o contracts.StoredObject) error {
+	if err := c.limiter.wait(ctx); err != nil {
+		return err
+	}
+
+	return c.inner.Update(o)
+}
+
+// RetrieveFromStore gets the objects from the wrapped StoreClient.
+func (c *RateLimitedStoreClient) RetrieveFromStore(appServiceKey string) ([]contracts.StoredObject, error) {
+	return c.inner.RetrieveFromStore(appServiceKey)
+}
+
+// GetByID gets the object with the ID from the wrapped StoreClient.
+func (c *RateLimitedStoreClient) GetByID(id string) (contracts.StoredObject, error) {
+	return c.inner.GetByID(id)
+}
+
+// Count returns the number of objects in the wrapped StoreClient.
+func (c *RateLimitedStoreClient) Count(appServiceKey string) (int, error) {
+	return c.inner.Count(appServiceKey)
+}
+
+// Exists returns whether the object with the ID is in the wrapped StoreClient.
+func (c *RateLimitedStoreClient) Exists(id string) (bool, error) {
+	return c.inner.Exists(id)
+}
+
+// RemoveFromStore removes the object from the wrapped StoreClient.
+func (c *RateLimitedStoreClient) RemoveFromStore(o contracts.StoredObject) error {
+	return c.inner.RemoveFromStore(o)
+}
+
+// Disconnect ends the connection of the wrapped StoreClient.
+func (c *RateLimitedStoreClient) Disconnect() error {
+	return c.inner.Disconnect()
+}
+
+// RegisterObserver adds an observer to the wrapped StoreClient.
+func (c *RateLimitedStoreClient) RegisterObserver(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	return c.inner.RegisterObserver(obs)
+}
+
+// UnregisterObserver removes an observer from the wrapped StoreClient.
+func (c *RateLimitedStoreClient) UnregisterObserver(handle interfaces.ObserverHandle) {
+	c.inner.UnregisterObserver(handle)
+}
+
+func (c *RateLimitedStoreClient) waitContext() (context.Context, context.CancelFunc) {
+	timeout := c.WaitTimeout
+	if timeout <= 0 {
+		timeout = DefaultRateLimitWaitTimeout
+	}
+
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// tokenBucket is a minimal token bucket limiter which allows a burst of up to one second's worth of tokens.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := math.Max(1, math.Ceil(rate))
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+	}
+}
+
+// wait blocks until a token is available. context.DeadlineExceeded is returned without waiting when the token
+// would not be available before the context's deadline.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b.rate <= 0 {
+		return nil
+	}
+
+	b.mutex.Lock()
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.mutex.Unlock()
+		return nil
+	}
+
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		b.mutex.Unlock()
+		return context.DeadlineExceeded
+	}
+
+	// reserve the token so concurrent callers queue up behind this one
+	b.tokens--
+	b.mutex.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mutex.Lock()
+		b.tokens++
+		b.mutex.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedStoreClient(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+
+	inner := &mocks.StoreClient{}
+	inner.On("Store", object).Return("id", nil)
+	inner.On("Update", object).Return(nil)
+	inner.On("RemoveFromStore", object).Return(nil)
+	inner.On("RetrieveFromStore", "key").Return([]contracts.StoredObject{object}, nil)
+
+	client := NewRateLimitedStoreClient(inner, 1)
+
+	id, err := client.Store(object)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", id)
+
+	// the only token has been consumed and the next one arrives after the wait timeout
+	client.WaitTimeout = 10 * time.Millisecond
+	assert.Equal(t, context.DeadlineExceeded, client.Update(object))
+	inner.AssertNotCalled(t, "Update", object)
+
+	// writes given a context wait until it is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.StoreContext(ctx, object)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, context.Canceled, client.UpdateContext(ctx, object))
+	inner.AssertNumberOfCalls(t, "Store", 1)
+
+	// reads and removes are never throttled
+	assert.NoError(t, client.RemoveFromStore(object))
+	objects, err := client.RetrieveFromStore("key")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestRateLimitedStoreClientDelegates(t *testing.T) {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+	observer := &mocks.StoredObjectObserver{}
+
+	inner := &mocks.StoreClient{}
+	inner.On("GetByID", object.ID).Return(object, nil)
+	inner.On("Count", "key").Return(1, nil)
+	inner.On("Exists", object.ID).Return(true, nil)
+	inner.On("Disconnect").Return(nil)
+	inner.On("RegisterObserver", observer).Return(interfaces.ObserverHandle(1))
+	inner.On("UnregisterObserver", interfaces.ObserverHandle(1)).Return()
+
+	var client interfaces.StoreClient = NewRateLimitedStoreClient(inner, 1)
+	_, isContextClient := client.(interfaces.ContextStoreClient)
+	assert.True(t, isContextClient)
+
+	retrieved, err := client.GetByID(object.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, object, retrieved)
+	count, _ := client.Count("key")
+	assert.Equal(t, 1, count)
+	exists, _ := client.Exists(object.ID)
+	assert.True(t, exists)
+	client.UnregisterObserver(client.RegisterObserver(observer))
+	assert.NoError(t, client.Disconnect())
+	inner.AssertExpectations(t)
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	current := time.Now()
+	bucket := newTokenBucket(2)
+	bucket.now = func() time.Time { return current }
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	// burst of two tokens
+	assert.NoError(t, bucket.wait(ctx))
+	assert.NoError(t, bucket.wait(ctx))
+	assert.Equal(t, context.DeadlineExceeded, bucket.wait(ctx))
+
+	// one token is refilled after half a second
+	current = current.Add(500 * time.Millisecond)
+	assert.NoError(t, bucket.wait(ctx))
+	assert.Equal(t, context.DeadlineExceeded, bucket.wait(ctx))
+}
+
+func TestTokenBucketWaitsForToken(t *testing.T) {
+	bucket := newTokenBucket(100)
+	bucket.tokens = 0
+
+	start := time.Now()
+	assert.NoError(t, bucket.wait(context.Background()))
+	assert.True(t, time.Since(start) >= 5*time.Millisecond)
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	bucket := newTokenBucket(0)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, bucket.wait(context.Background()))
+	}
+}
@@ -31,6 +31,9 @@ type StoredObject struct {
 	// Payload is the data to be exported
 	Payload []byte `json:"payload"`
 
+	// PayloadEncoding is how the Payload is encoded, empty when it is stored as is.
+	PayloadEncoding string `json:"payloadEncoding"`
+
 	// RetryCount is how many times this has tried to be exported
 	RetryCount int `json:"retryCount"`
 
@@ -48,6 +51,15 @@ type StoredObject struct {
 
 	// EventChecksum is used to identify CBOR encoded data from the core services and mark it as pushed.
 	EventChecksum string `json:"eventChecksum"`
+
+	// LastModifiedAt is when this was last stored or updated, in milliseconds since the epoch.
+	LastModifiedAt int64 `json:"lastModifiedAt"`
+
+	// Revision is incremented on each update, used for optimistic concurrency.
+	Revision int `json:"revision"`
+
+	// ExpiresAt is when this expires, in seconds since the epoch. Zero means this never expires.
+	ExpiresAt int64 `json:"expiresAt"`
 }
 
 // ToContract builds a contract out of the supplied model.
@@ -56,12 +68,16 @@ func (o StoredObject) ToContract() contracts.StoredObject {
 		ID:               o.ID,
 		AppServiceKey:    o.AppServiceKey,
 		Payload:          o.Payload,
+		PayloadEncoding:  o.PayloadEncoding,
 		RetryCount:       o.RetryCount,
 		PipelinePosition: o.PipelinePosition,
 		Version:          o.Version,
 		CorrelationID:    o.CorrelationID,
 		EventID:          o.EventID,
 		EventChecksum:    o.EventChecksum,
+		LastModifiedAt:   o.LastModifiedAt,
+		Revision:         o.Revision,
+		ExpiresAt:        o.ExpiresAt,
 	}
 }
 
@@ -70,12 +86,16 @@ func (o *StoredObject) FromContract(c contracts.StoredObject) {
 	o.ID = c.ID
 	o.AppServiceKey = c.AppServiceKey
 	o.Payload = c.Payload
+	o.PayloadEncoding = c.PayloadEncoding
 	o.RetryCount = c.RetryCount
 	o.PipelinePosition = c.PipelinePosition
 	o.Version = c.Version
 	o.CorrelationID = c.CorrelationID
 	o.EventID = c.EventID
 	o.EventChecksum = c.EventChecksum
+	o.LastModifiedAt = c.LastModifiedAt
+	o.Revision = c.Revision
+	o.ExpiresAt = c.ExpiresAt
 }
 
 // MarshalJSON returns the object as a JSON encoded byte array.
@@ -84,16 +104,23 @@ func (o StoredObject) MarshalJSON() ([]byte, error) {
 		ID               *string `json:"id,omitempty"`
 		AppServiceKey    *string `json:"appServiceKey,omitempty"`
 		Payload          []byte  `json:"payload,omitempty"`
+		PayloadEncoding  *string `json:"payloadEncoding,omitempty"`
 		RetryCount       int     `json:"retryCount,omitempty"`
 		PipelinePosition int     `json:"pipelinePosition,omitempty"`
 		Version          *string `json:"version,omitempty"`
 		CorrelationID    *string `json:"correlationID,omitempty"`
 		EventID          *string `json:"eventID,omitempty"`
 		EventChecksum    *string `json:"eventChecksum,omitempty"`
+		LastModifiedAt   int64   `json:"lastModifiedAt,omitempty"`
+		Revision         int     `json:"revision,omitempty"`
+		ExpiresAt        int64   `json:"expiresAt,omitempty"`
 	}{
 		Payload:          o.Payload,
 		RetryCount:       o.RetryCount,
 		PipelinePosition: o.PipelinePosition,
+		LastModifiedAt:   o.LastModifiedAt,
+		Revision:         o.Revision,
+		ExpiresAt:        o.ExpiresAt,
 	}
 
 	// Empty strings are null
@@ -115,6 +142,9 @@ func (o StoredObject) MarshalJSON() ([]byte, error) {
 	if o.EventChecksum != "" {
 		test.EventChecksum = &o.EventChecksum
 	}
+	if o.PayloadEncoding != "" {
+		test.PayloadEncoding = &o.PayloadEncoding
+	}
 
 	return json.Marshal(test)
 }
@@ -125,12 +155,16 @@ func (o *StoredObject) UnmarshalJSON(data []byte) error {
 		ID               *string `json:"id"`
 		AppServiceKey    *string `json:"appServiceKey"`
 		Payload          []byte  `json:"payload"`
+		PayloadEncoding  *string `json:"payloadEncoding"`
 		RetryCount       int     `json:"retryCount"`
 		PipelinePosition int     `json:"pipelinePosition"`
 		Version          *string `json:"version"`
 		CorrelationID    *string `json:"correlationID"`
 		EventID          *string `json:"eventID"`
 		EventChecksum    *string `json:"eventChecksum"`
+		LastModifiedAt   int64   `json:"lastModifiedAt"`
+		Revision         int     `json:"revision"`
+		ExpiresAt        int64   `json:"expiresAt"`
 	})
 
 	// Error with unmarshaling
@@ -157,10 +191,16 @@ func (o *StoredObject) UnmarshalJSON(data []byte) error {
 	if alias.EventChecksum != nil {
 		o.EventChecksum = *alias.EventChecksum
 	}
+	if alias.PayloadEncoding != nil {
+		o.PayloadEncoding = *alias.PayloadEncoding
+	}
 
 	o.Payload = alias.Payload
 	o.RetryCount = alias.RetryCount
 	o.PipelinePosition = alias.PipelinePosition
+	o.LastModifiedAt = alias.LastModifiedAt
+	o.Revision = alias.Revision
+	o.ExpiresAt = alias.ExpiresAt
 
 	return nil
 }
@@ -145,6 +145,54 @@ func TestStoredObject_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestStoredObject_ExpiresAtJSON(t *testing.T) {
+	expected := TestModelValid
+	expected.ExpiresAt = 1577836800
+
+	data, err := expected.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Unexpectedly encountered error: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"expiresAt":1577836800`)) {
+		t.Fatalf("Expected the expiresAt field in %s", data)
+	}
+
+	actual := new(StoredObject)
+	if err := actual.UnmarshalJSON(data); err != nil {
+		t.Fatalf("Unexpectedly encountered error: %v", err)
+	}
+	if !reflect.DeepEqual(*actual, expected) {
+		t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", expected, *actual)
+	}
+	if actual.ToContract().ExpiresAt != expected.ExpiresAt {
+		t.Fatal("Expected ExpiresAt in the contract")
+	}
+}
+
+func TestStoredObject_PayloadEncodingJSON(t *testing.T) {
+	expected := TestModelValid
+	expected.PayloadEncoding = "zstd"
+
+	data, err := expected.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Unexpectedly encountered error: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"payloadEncoding":"zstd"`)) {
+		t.Fatalf("Expected the payloadEncoding field in %s", data)
+	}
+
+	actual := new(StoredObject)
+	if err := actual.UnmarshalJSON(data); err != nil {
+		t.Fatalf("Unexpectedly encountered error: %v", err)
+	}
+	if !reflect.DeepEqual(*actual, expected) {
+		t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", expected, *actual)
+	}
+	if actual.ToContract().PayloadEncoding != expected.PayloadEncoding {
+		t.Fatal("Expected PayloadEncoding in the contract")
+	}
+}
+
 func TestStoredObject_UnmarshalJSON(t *testing.T) {
 	type args struct {
 		data []byte
@@ -0,0 +1,245 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package redis
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/redis/models"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineRetrieve(t *testing.T) {
+	object := newTestContract()
+	removed := newTestContract()
+
+	conn := &fakeConn{
+		replies: map[string]interface{}{
+			"SMEMBERS": []interface{}{[]byte(object.ID), []byte(removed.ID)},
+			"EXEC":     []interface{}{[]interface{}{storedJSON(t, object), nil}},
+		},
+	}
+	client := newFakeClient(conn)
+	client.LoggingClient = logger.NewClient("app_functions_sdk_go", false, "./test.log", "DEBUG")
+
+	objects, err := client.PipelineRetrieve(object.AppServiceKey)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, []contracts.StoredObject{object}, objects, "expected object with missing key to be skipped")
+	assert.Equal(t, []string{"WATCH", "SMEMBERS", "WATCH", "MULTI", "MGET", "EXEC"}, conn.commands)
+}
+
+func TestPipelineRetrieveRetriesAbortedTransaction(t *testing.T) {
+	object := newTestContract()
+
+	attempts := 0
+	conn := &fakeConn{
+		replies: map[string]interface{}{
+			"SMEMBERS": []interface{}{[]byte(object.ID)},
+			"EXEC": func() interface{} {
+				attempts++
+				switch attempts {
+				case 1:
+					// a watched key was modified
+					return nil
+				case 2:
+					return errors.New("EXECABORT Transaction discarded because of previous errors.")
+				default:
+					return []interface{}{[]interface{}{storedJSON(t, object)}}
+				}
+			},
+		},
+	}
+
+	objects, err := newFakeClient(conn).PipelineRetrieve(object.AppServiceKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []contracts.StoredObject{object}, objects)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestPipelineRetrieveGivesUp(t *testing.T) {
+	attempts := 0
+	conn := &fakeConn{
+		replies: map[string]interface{}{
+			"SMEMBERS": []interface{}{[]byte("id")},
+			"EXEC": func() interface{} {
+				attempts++
+				return nil
+			},
+		},
+	}
+
+	_, err := newFakeClient(conn).PipelineRetrieve("key")
+	assert.Error(t, err)
+	assert.Equal(t, maxPipelineRetrieveAttempts, attempts)
+}
+
+func TestPipelineRetrieveEmpty(t *testing.T) {
+	conn := &fakeConn{
+		replies: map[string]interface{}{"SMEMBERS": []interface{}{}},
+	}
+
+	objects, err := newFakeClient(conn).PipelineRetrieve("key")
+	assert.NoError(t, err)
+	assert.Nil(t, objects)
+	assert.Equal(t, []string{"WATCH", "SMEMBERS", "UNWATCH"}, conn.commands)
+
+	_, err = newFakeClient(conn).PipelineRetrieve("")
+	assert.Error(t, err, "expected error for blank AppServiceKey")
+}
+
+func TestRetrieveFromStoreRemovesDanglingMembers(t *testing.T) {
+	object := newTestContract()
+	deleted := newTestContract()
+
+	conn := &fakeConn{
+		replies: map[string]interface{}{
+			"SMEMBERS": []interface{}{[]byte(deleted.ID), []byte(object.ID)},
+			"MGET":     []interface{}{nil, storedJSON(t, object)},
+			"SREM":     errors.New("SREM failed"),
+		},
+	}
+	client := newFakeClient(conn)
+	client.LoggingClient = logger.NewClient("app_functions_sdk_go", false, "./test.log", "DEBUG")
+
+	objects, err := client.RetrieveFromStore(object.AppServiceKey)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, []contracts.StoredObject{object}, objects)
+	assert.Equal(t, []string{"SMEMBERS", "MGET", "SREM"}, conn.commands)
+}
+
+// fakeScriptServer emulates the update script against a single stored object, serializing the scripts like Redis
+type fakeScriptServer struct {
+	stored []byte
+	mutex  sync.Mutex
+}
+
+// eval expects the arguments of EVALSHA: the sha, the number of keys, the keys and the script's arguments
+func (server *fakeScriptServer) eval(args ...interface{}) interface{} {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var current models.StoredObject
+	if err := current.UnmarshalJSON(server.stored); err != nil {
+		return err
+	}
+	if int64(current.Revision) != int64(args[4].(int)) {
+		return int64(0)
+	}
+	server.stored = args[5].([]byte)
+	return int64(1)
+}
+
+func TestUpdate(t *testing.T) {
+	object := newTestContract()
+	object.Revision = 3
+
+	server := &fakeScriptServer{stored: storedJSON(t, object)}
+	conn := &fakeConn{replies: map[string]interface{}{"EVALSHA": server.eval}}
+
+	err := newFakeClient(conn).Update(object)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, []string{"EVALSHA"}, conn.commands)
+
+	var stored models.StoredObject
+	if !assert.NoError(t, stored.UnmarshalJSON(server.stored)) {
+		t.Fatal()
+	}
+	assert.Equal(t, 4, stored.Revision)
+	assert.NotZero(t, stored.LastModifiedAt)
+
+	err = newFakeClient(conn).Update(object)
+	assert.Equal(t, contracts.ErrRevisionConflict, err, "expected a conflict for the previous revision")
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	object := newTestContract()
+	object.Revision = 1
+	server := &fakeScriptServer{stored: storedJSON(t, object)}
+
+	const updaters = 10
+	results := make(chan error, updaters)
+	var wait sync.WaitGroup
+	for index := 0; index < updaters; index++ {
+		wait.Add(1)
+		go func(retryCount int) {
+			defer wait.Done()
+			// each updater retrieved the object at revision 1 and changes it
+			update := object
+			update.RetryCount = retryCount
+			conn := &fakeConn{replies: map[string]interface{}{"EVALSHA": server.eval}}
+			results <- newFakeClient(conn).Update(update)
+		}(index)
+	}
+	wait.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, contracts.ErrRevisionConflict, err)
+	}
+	assert.Equal(t, 1, succeeded, "expected only one of the concurrent updates to succeed")
+
+	var stored models.StoredObject
+	if !assert.NoError(t, stored.UnmarshalJSON(server.stored)) {
+		t.Fatal()
+	}
+	assert.Equal(t, 2, stored.Revision)
+}
+
+func TestUpdateMissingObject(t *testing.T) {
+	conn := &fakeConn{
+		replies: map[string]interface{}{"EVALSHA": errors.New("object does not exist in database")},
+	}
+
+	err := newFakeClient(conn).Update(newTestContract())
+	assert.EqualError(t, err, "object does not exist in database")
+}
+
+func TestCount(t *testing.T) {
+	conn := &fakeConn{
+		replies: map[string]interface{}{
+			"SCARD": func(args ...interface{}) interface{} {
+				if args[0] != redisCollection+":key" {
+					return errors.New("unexpected key")
+				}
+				return int64(3)
+			},
+		},
+	}
+
+	count, err := newFakeClient(conn).Count("key")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, 3, count)
+
+	_, err = newFakeClient(conn).Count("")
+	assert.Error(t, err)
+}
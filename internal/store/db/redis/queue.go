@@ -0,0 +1,60 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package redis
+
+import (
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// SendErrors aggregates the errors returned while queuing the commands of a transaction.
+type SendErrors []error
+
+func (e SendErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+
+	return "unable to queue redis commands: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors.
+func (e SendErrors) Unwrap() []error {
+	return e
+}
+
+// commandQueue queues commands on a connection and collects the errors returned by Send.
+type commandQueue struct {
+	conn   redis.Conn
+	errors SendErrors
+}
+
+func (q *commandQueue) send(commandName string, args ...interface{}) {
+	if err := q.conn.Send(commandName, args...); err != nil {
+		q.errors = append(q.errors, err)
+	}
+}
+
+// err returns the aggregate of the Send errors, or nil when all the commands were queued. The transaction is
+// discarded when the connection is returned to the pool.
+func (q *commandQueue) err() error {
+	if len(q.errors) == 0 {
+		return nil
+	}
+
+	return q.errors
+}
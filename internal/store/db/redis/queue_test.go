@@ -0,0 +1,154 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces/mocks"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/redis/models"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConn is a redis.Conn which replies to Do with canned replies, or the result of calling a reply func with or
+// without the command's arguments, and fails Send for the configured commands
+type fakeConn struct {
+	replies    map[string]interface{}
+	sendErrors map[string]error
+	commands   []string
+}
+
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Err() error   { return nil }
+func (c *fakeConn) Flush() error { return nil }
+
+func (c *fakeConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if commandName == "" {
+		return nil, nil
+	}
+
+	c.commands = append(c.commands, commandName)
+	reply := c.replies[commandName]
+	switch replyFunc := reply.(type) {
+	case func() interface{}:
+		reply = replyFunc()
+	case func(args ...interface{}) interface{}:
+		reply = replyFunc(args...)
+	}
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *fakeConn) Send(commandName string, args ...interface{}) error {
+	c.commands = append(c.commands, commandName)
+	return c.sendErrors[commandName]
+}
+
+func (c *fakeConn) Receive() (interface{}, error) { return nil, nil }
+
+func newFakeClient(conn *fakeConn) Client {
+	return Client{
+		Pool: &redis.Pool{
+			Dial: func() (redis.Conn, error) { return conn, nil },
+		},
+		observers: &db.Observers{},
+	}
+}
+
+func newTestContract() contracts.StoredObject {
+	object, _ := contracts.NewStoredObject("key", "", []byte("payload"), 1, "version")
+	object.ID = uuid.New().String()
+	return object
+}
+
+func storedJSON(t *testing.T, object contracts.StoredObject) []byte {
+	var model models.StoredObject
+	model.FromContract(object)
+	json, err := model.MarshalJSON()
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	return json
+}
+
+func TestStoreSendErrors(t *testing.T) {
+	setFailure := errors.New("SET failed")
+	saddFailure := errors.New("SADD failed")
+	conn := &fakeConn{
+		replies:    map[string]interface{}{"EXISTS": int64(0)},
+		sendErrors: map[string]error{"SET": setFailure, "SADD": saddFailure},
+	}
+
+	_, err := newFakeClient(conn).Store(newTestContract())
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+
+	unwrapper, ok := err.(interface{ Unwrap() []error })
+	if !assert.True(t, ok, "expected aggregate error") {
+		t.Fatal()
+	}
+	assert.Equal(t, []error{setFailure, saddFailure}, unwrapper.Unwrap())
+	assert.Equal(t, "unable to queue redis commands: SET failed; SADD failed", err.Error())
+	assert.NotContains(t, conn.commands, "EXEC")
+}
+
+func TestRemoveFromStoreSendErrors(t *testing.T) {
+	failure := errors.New("MULTI failed")
+	conn := &fakeConn{
+		sendErrors: map[string]error{"MULTI": failure},
+	}
+
+	err := newFakeClient(conn).RemoveFromStore(newTestContract())
+	assert.Equal(t, SendErrors{failure}, err)
+	assert.NotContains(t, conn.commands, "EXEC")
+}
+
+func TestRemoveFromStoreNoSendErrors(t *testing.T) {
+	conn := &fakeConn{
+		replies: map[string]interface{}{"EXEC": []interface{}{int64(1), int64(1)}},
+	}
+
+	err := newFakeClient(conn).RemoveFromStore(newTestContract())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MULTI", "UNLINK", "SREM", "EXEC"}, conn.commands)
+}
+
+func TestNewClientInvalidConfig(t *testing.T) {
+	client, err := NewClient(db.DatabaseInfo{Type: db.RedisDB, Port: 70000, Timeout: -1})
+
+	assert.Nil(t, client)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+	assert.IsType(t, db.ValidationErrors{}, err)
+}
+
+func TestZeroValueClientObservers(t *testing.T) {
+	client := Client{}
+
+	assert.NotPanics(t, func() {
+		handle := client.RegisterObserver(&mocks.StoredObjectObserver{})
+		client.UnregisterObserver(handle)
+	})
+}
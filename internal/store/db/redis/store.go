@@ -18,6 +18,7 @@ package redis
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db/redis/models"
 
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/gomodule/redigo/redis"
 )
 
@@ -34,15 +36,47 @@ var once sync.Once
 
 const redisCollection = "store"
 
+// maxPipelineRetrieveAttempts is how many times PipelineRetrieve retries when the snapshot is modified concurrently
+const maxPipelineRetrieveAttempts = 3
+
+// updateScript replaces the object only when its stored revision is the expected one, moving the object to the
+// set of its new AppServiceKey when the key has changed. Objects stored without a revision are at revision 0.
+// KEYS[1] is the object's key and KEYS[2] the set of its new AppServiceKey. ARGV[1] is the expected revision,
+// ARGV[2] the new JSON, ARGV[3] the prefix of the sets and ARGV[4] the new AppServiceKey. Replies 1 when the object
+// is updated and 0 on a revision conflict.
+var updateScript = redis.NewScript(2, `
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return redis.error_reply('object does not exist in database')
+end
+
+local object = cjson.decode(current)
+if (tonumber(object.revision) or 0) ~= tonumber(ARGV[1]) then
+	return 0
+end
+
+redis.call('SET', KEYS[1], ARGV[2])
+if object.appServiceKey ~= ARGV[4] then
+	if type(object.appServiceKey) == 'string' then
+		redis.call('SREM', ARGV[3] .. object.appServiceKey, KEYS[1])
+	end
+	redis.call('SADD', KEYS[2], KEYS[1])
+end
+return 1
+`)
+
 // Client provides an implementation for the Client interface for Redis
 type Client struct {
-	Pool      *redis.Pool // A thread-safe pool of connections to Redis
-	BatchSize int
+	Pool            *redis.Pool // A thread-safe pool of connections to Redis
+	BatchSize       int
+	MaxPayloadBytes int
+	LoggingClient   logger.LoggingClient // Optional, used to log warnings about inconsistent data
+	observers       *db.Observers
 }
 
 // Store persists a stored object to the data store.
 func (c Client) Store(o contracts.StoredObject) (string, error) {
-	err := o.ValidateContract(false)
+	err := o.ValidateContract(false, c.MaxPayloadBytes)
 	if err != nil {
 		return "", err
 	}
@@ -57,6 +91,9 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 		return "", errors.New("object exists in database")
 	}
 
+	o.LastModifiedAt = db.MakeTimestamp()
+	o.Revision = 1
+
 	var model models.StoredObject
 	model.FromContract(o)
 
@@ -65,11 +102,15 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 		return "", err
 	}
 
-	_ = conn.Send("MULTI")
+	queue := commandQueue{conn: conn}
+	queue.send("MULTI")
 	// store the object's representation
-	_ = conn.Send("SET", model.ID, json)
+	queue.send("SET", model.ID, json)
 	// store the association with this ASK
-	_ = conn.Send("SADD", redisCollection+":"+model.AppServiceKey, model.ID)
+	queue.send("SADD", redisCollection+":"+model.AppServiceKey, model.ID)
+	if err = queue.err(); err != nil {
+		return "", err
+	}
 
 	_, err = conn.Do("EXEC")
 	if err != nil {
@@ -80,10 +121,12 @@ func (c Client) Store(o contracts.StoredObject) (string, error) {
 		return "", errors.New("no ID produced")
 	}
 
+	c.observers.NotifyStore(o)
+
 	return model.ID, nil
 }
 
-// RetrieveFromStore gets an object from the data store.
+// RetrieveFromStore gets an object from the data store. Expired objects are not returned.
 func (c Client) RetrieveFromStore(appServiceKey string) (objects []contracts.StoredObject, err error) {
 	// do not satisfy requests for a blank ASK
 	if appServiceKey == "" {
@@ -109,48 +152,198 @@ func (c Client) RetrieveFromStore(appServiceKey string) (objects []contracts.Sto
 
 	var model models.StoredObject
 
-	for _, bytes := range values {
+	for index, bytes := range values {
+		// the object's key was deleted outside of the SDK, remove the dangling member from the ASK's set
+		if bytes == nil {
+			c.logWarn(fmt.Sprintf("Stored object %s of AppServiceKey %s no longer exists, removing it from the store", ids[index], appServiceKey))
+			if _, err = conn.Do("SREM", redisCollection+":"+appServiceKey, ids[index]); err != nil {
+				c.logWarn(fmt.Sprintf("Unable to remove stored object %s of AppServiceKey %s: %s", ids[index], appServiceKey, err.Error()))
+			}
+			continue
+		}
+
 		err = model.UnmarshalJSON(bytes)
 		if err != nil {
 			return nil, err
 		}
-		objects = append(objects, model.ToContract())
+		object := model.ToContract()
+		// the expiry is checked by the SDK, so an object is left out as soon as it expires on the SDK's clock
+		if object.IsExpired() {
+			continue
+		}
+		objects = append(objects, object)
 	}
 
 	return objects, nil
 }
 
-// Update replaces the data currently in the store with the provided data.
-func (c Client) Update(o contracts.StoredObject) error {
-	err := o.ValidateContract(true)
-	if err != nil {
-		return err
+// Count returns the number of objects in the data store for the AppServiceKey.
+func (c Client) Count(appServiceKey string) (int, error) {
+	// do not satisfy requests for a blank ASK
+	if appServiceKey == "" {
+		return 0, errors.New("no AppServiceKey provided")
 	}
 
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	// retrieve the current value
-	result, err := redis.String(conn.Do("GET", o.ID))
+	return redis.Int(conn.Do("SCARD", redisCollection+":"+appServiceKey))
+}
+
+// GetByID gets the object with the ID from the data store. Expired objects are not returned.
+func (c Client) GetByID(id string) (contracts.StoredObject, error) {
+	// an empty key can't hold an object
+	if id == "" {
+		return contracts.StoredObject{}, errors.New("no ID provided")
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	bytes, err := redis.Bytes(conn.Do("GET", id))
+	if err == redis.ErrNil {
+		return contracts.StoredObject{}, contracts.ErrObjectNotFound
+	} else if err != nil {
+		return contracts.StoredObject{}, err
+	}
+
+	var model models.StoredObject
+	if err = model.UnmarshalJSON(bytes); err != nil {
+		return contracts.StoredObject{}, err
+	}
+
+	object := model.ToContract()
+	if object.IsExpired() {
+		return contracts.StoredObject{}, contracts.ErrObjectNotFound
+	}
+	return object, nil
+}
+
+// Exists returns whether an object with the ID is in the data store.
+func (c Client) Exists(id string) (bool, error) {
+	// an empty key can't hold an object
+	if id == "" {
+		return false, errors.New("no ID provided")
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	return redis.Bool(conn.Do("EXISTS", id))
+}
+
+// PipelineRetrieve gets the objects for the AppServiceKey like RetrieveFromStore, but reads the set of IDs and the
+// objects as a consistent snapshot using WATCH/MULTI/EXEC. The read is retried when the snapshot is modified
+// concurrently, and objects whose key no longer exists or which are expired are skipped.
+func (c Client) PipelineRetrieve(appServiceKey string) ([]contracts.StoredObject, error) {
+	// do not satisfy requests for a blank ASK
+	if appServiceKey == "" {
+		return nil, errors.New("no AppServiceKey provided")
+	}
+
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	for attempt := 1; ; attempt++ {
+		objects, err := c.pipelineRetrieve(conn, appServiceKey)
+		if err == nil {
+			return objects, nil
+		}
+		if !isTransactionAborted(err) || attempt >= maxPipelineRetrieveAttempts {
+			return nil, err
+		}
+	}
+}
+
+func (c Client) pipelineRetrieve(conn redis.Conn, appServiceKey string) ([]contracts.StoredObject, error) {
+	setKey := redisCollection + ":" + appServiceKey
+
+	if _, err := conn.Do("WATCH", setKey); err != nil {
+		return nil, err
+	}
+
+	ids, err := redis.Values(conn.Do("SMEMBERS", setKey))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	if len(ids) == 0 {
+		_, err = conn.Do("UNWATCH")
+		return nil, err
+	}
+
+	// also watch the objects so that they can not be removed before they are read
+	if _, err = conn.Do("WATCH", ids...); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Do("MULTI"); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Do("MGET", ids...); err != nil {
+		return nil, err
+	}
+
+	results, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := redis.ByteSlices(results[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []contracts.StoredObject
 	var model models.StoredObject
 
-	err = model.UnmarshalJSON([]byte(result))
+	for index, bytes := range values {
+		if bytes == nil {
+			c.logWarn(fmt.Sprintf("Stored object %s of AppServiceKey %s no longer exists, skipping", ids[index], appServiceKey))
+			continue
+		}
+
+		err = model.UnmarshalJSON(bytes)
+		if err != nil {
+			return nil, err
+		}
+		object := model.ToContract()
+		// the expiry is checked by the SDK, so an object is left out as soon as it expires on the SDK's clock
+		if object.IsExpired() {
+			continue
+		}
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// isTransactionAborted determines if the transaction was aborted, either because a watched key was modified, in
+// which case EXEC replies nil, or because a queued command failed.
+func isTransactionAborted(err error) bool {
+	return err == redis.ErrNil || strings.HasPrefix(err.Error(), "EXECABORT")
+}
+
+func (c Client) logWarn(msg string) {
+	if c.LoggingClient != nil {
+		c.LoggingClient.Warn(msg)
+	}
+}
+
+// Update replaces the data currently in the store with the provided data. The read, compare and write of the
+// revision are done atomically by a Lua script, ErrRevisionConflict is returned when the stored revision isn't
+// the object's revision.
+func (c Client) Update(o contracts.StoredObject) error {
+	err := o.ValidateContract(true, c.MaxPayloadBytes)
 	if err != nil {
 		return err
 	}
-	current := model.ToContract()
 
-	_ = conn.Send("MULTI")
+	conn := c.Pool.Get()
+	defer conn.Close()
 
-	// ASK has changed, update the ASK registry
-	if o.AppServiceKey != current.AppServiceKey {
-		_ = conn.Send("SREM", redisCollection+":"+current.AppServiceKey, current.ID)
-		_ = conn.Send("SADD", redisCollection+":"+o.AppServiceKey, o.ID)
-	}
+	expectedRevision := o.Revision
+	o.Revision++
+	o.LastModifiedAt = db.MakeTimestamp()
 
 	var update models.StoredObject
 	update.FromContract(o)
@@ -159,12 +352,16 @@ func (c Client) Update(o contracts.StoredObject) error {
 		return err
 	}
 
-	_ = conn.Send("SET", update.ID, json)
-
-	_, err = conn.Do("EXEC")
+	updated, err := redis.Bool(updateScript.Do(conn, update.ID, redisCollection+":"+update.AppServiceKey,
+		expectedRevision, json, redisCollection+":", update.AppServiceKey))
 	if err != nil {
 		return err
 	}
+	if !updated {
+		return contracts.ErrRevisionConflict
+	}
+
+	c.observers.NotifyUpdate(o)
 
 	return nil
 }
@@ -179,11 +376,15 @@ func (c Client) RemoveFromStore(o contracts.StoredObject) error {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	_ = conn.Send("MULTI")
+	queue := commandQueue{conn: conn}
+	queue.send("MULTI")
 	// remove the object's representation
-	_ = conn.Send("UNLINK", o.ID)
+	queue.send("UNLINK", o.ID)
 	// remove the association with the ASK
-	_ = conn.Send("SREM", redisCollection+":"+o.AppServiceKey, o.ID)
+	queue.send("SREM", redisCollection+":"+o.AppServiceKey, o.ID)
+	if err = queue.err(); err != nil {
+		return err
+	}
 
 	res, err := redis.Values(conn.Do("EXEC"))
 	if err != nil {
@@ -194,6 +395,8 @@ func (c Client) RemoveFromStore(o contracts.StoredObject) error {
 		return errors.New("could not remove object from store")
 	}
 
+	c.observers.NotifyRemove(o)
+
 	return nil
 }
 
@@ -202,8 +405,24 @@ func (c Client) Disconnect() error {
 	return c.Pool.Close()
 }
 
-// NewClient provides a factory for building a StoreClient
+// RegisterObserver adds an observer which is notified after each successful mutation.
+func (c Client) RegisterObserver(obs interfaces.StoredObjectObserver) interfaces.ObserverHandle {
+	return c.observers.Register(obs)
+}
+
+// UnregisterObserver removes a previously registered observer.
+func (c Client) UnregisterObserver(handle interfaces.ObserverHandle) {
+	c.observers.Unregister(handle)
+}
+
+// NewClient provides a factory for building a StoreClient. The defaults are set for the settings that are not
+// configured and the configuration is validated before Redis is dialed.
 func NewClient(config db.DatabaseInfo) (interfaces.StoreClient, error) {
+	config = config.WithDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	once.Do(func() {
 		connectionString := fmt.Sprintf("%s:%d", config.Host, config.Port)
 		opts := []redis.DialOption{
@@ -216,10 +435,16 @@ func NewClient(config db.DatabaseInfo) (interfaces.StoreClient, error) {
 				"tcp", connectionString, opts...,
 			)
 			if err != nil {
-				return nil, fmt.Errorf("Could not dial Redis: %s", err)
+				return nil, fmt.Errorf("Could not dial Redis: %w", err)
 			}
 			return conn, nil
 		}
+
+		maxPayloadBytes := config.MaxPayloadBytes
+		if maxPayloadBytes == 0 {
+			maxPayloadBytes = db.DefaultMaxPayloadBytes
+		}
+
 		currClient = &Client{
 			Pool: &redis.Pool{
 				IdleTimeout: time.Duration(config.Timeout) * time.Millisecond,
@@ -233,7 +458,9 @@ func NewClient(config db.DatabaseInfo) (interfaces.StoreClient, error) {
 				MaxIdle: config.MaxIdle,
 				Dial:    dialFunc,
 			},
-			BatchSize: config.BatchSize,
+			BatchSize:       config.BatchSize,
+			MaxPayloadBytes: maxPayloadBytes,
+			observers:       &db.Observers{},
 		}
 	})
 
@@ -23,6 +23,7 @@ package redis
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/contracts"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/store/db"
@@ -274,6 +275,136 @@ func TestClient_RetrieveFromStore(t *testing.T) {
 	}
 }
 
+func TestClient_RetrieveFromStoreExpired(t *testing.T) {
+	UUIDAppServiceKey := uuid.New().String()
+
+	expired := TestContractBase
+	expired.ID = uuid.New().String()
+	expired.AppServiceKey = UUIDAppServiceKey
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+
+	unexpired := TestContractBase
+	unexpired.ID = uuid.New().String()
+	unexpired.AppServiceKey = UUIDAppServiceKey
+	unexpired.ExpiresAt = time.Now().Add(time.Hour).Unix()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+	for _, object := range []contracts.StoredObject{expired, unexpired} {
+		if _, err := client.Store(object); err != nil {
+			t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+		}
+	}
+
+	actual, err := client.RetrieveFromStore(UUIDAppServiceKey)
+	if err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+	if len(actual) != 1 || actual[0].ID != unexpired.ID {
+		t.Fatalf("Expected only the unexpired object %s, got %v", unexpired.ID, actual)
+	}
+}
+
+func TestClient_GetByID(t *testing.T) {
+	stored := TestContractBase
+	stored.ID = uuid.New().String()
+	stored.AppServiceKey = uuid.New().String()
+
+	expired := stored
+	expired.ID = uuid.New().String()
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+	for _, object := range []contracts.StoredObject{stored, expired} {
+		if _, err := client.Store(object); err != nil {
+			t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+		}
+	}
+
+	actual, err := client.GetByID(stored.ID)
+	if err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+	if actual.ID != stored.ID || actual.AppServiceKey != stored.AppServiceKey || actual.Revision != 1 {
+		t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", stored, actual)
+	}
+
+	for _, id := range []string{uuid.New().String(), expired.ID} {
+		if _, err = client.GetByID(id); err != contracts.ErrObjectNotFound {
+			t.Fatalf("Expected ErrObjectNotFound for %s, got %v", id, err)
+		}
+	}
+
+	if _, err = client.GetByID(""); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestClient_Exists(t *testing.T) {
+	stored := TestContractBase
+	stored.ID = uuid.New().String()
+	stored.AppServiceKey = uuid.New().String()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+	if _, err := client.Store(stored); err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+
+	tests := []struct {
+		name          string
+		id            string
+		expected      bool
+		expectedError bool
+	}{
+		{"Exists", stored.ID, true, false},
+		{"Does not exist", uuid.New().String(), false, false},
+		{"No ID", "", false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := client.Exists(test.id)
+
+			if test.expectedError && err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if !test.expectedError && err != nil {
+				t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+			}
+
+			if actual != test.expected {
+				t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClient_Count(t *testing.T) {
+	UUIDAppServiceKey := uuid.New().String()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+
+	for i := 0; i < 2; i++ {
+		object := TestContractBase
+		object.ID = uuid.New().String()
+		object.AppServiceKey = UUIDAppServiceKey
+		if _, err := client.Store(object); err != nil {
+			t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+		}
+	}
+
+	count, err := client.Count(UUIDAppServiceKey)
+	if err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+	if count != 2 {
+		t.Fatalf("Count doesn't match expected.\nExpected: %v\nActual: %v\n", 2, count)
+	}
+
+	if _, err := client.Count(""); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
 func TestClient_Update(t *testing.T) {
 	TestContractValid := TestContractBase
 	TestContractValid.AppServiceKey = uuid.New().String()
@@ -283,6 +414,7 @@ func TestClient_Update(t *testing.T) {
 
 	// add the objects we're going to update in the database now so we have a known state
 	TestContractValid.ID, _ = client.Store(TestContractValid)
+	TestContractValid.Revision = 1
 
 	tests := []struct {
 		name          string
@@ -334,14 +466,37 @@ func TestClient_Update(t *testing.T) {
 					t.Fatal("No objects retrieved from store")
 				}
 
-				if !reflect.DeepEqual(actual[0], test.expectedVal) {
-					t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", test.expectedVal, actual[0])
+				// the store increments the revision and sets the modification time
+				expected := test.expectedVal
+				expected.Revision++
+				expected.LastModifiedAt = actual[0].LastModifiedAt
+				if !reflect.DeepEqual(actual[0], expected) {
+					t.Fatalf("Return value doesn't match expected.\nExpected: %v\nActual: %v\n", expected, actual[0])
 				}
 			}
 		})
 	}
 }
 
+func TestClient_UpdateRevisionConflict(t *testing.T) {
+	TestContractValid := TestContractBase
+	TestContractValid.AppServiceKey = uuid.New().String()
+
+	client, _ := NewClient(TestValidNoAuthConfig)
+
+	TestContractValid.ID, _ = client.Store(TestContractValid)
+	// both updaters retrieved the object at revision 1
+	TestContractValid.Revision = 1
+
+	if err := client.Update(TestContractValid); err != nil {
+		t.Fatalf("Unexpectedly encountered error: %s", err.Error())
+	}
+
+	if err := client.Update(TestContractValid); err != contracts.ErrRevisionConflict {
+		t.Fatalf("Expected a revision conflict, got: %v", err)
+	}
+}
+
 func TestClient_RemoveFromStore(t *testing.T) {
 	TestContractValid := TestContractBase
 	TestContractValid.AppServiceKey = uuid.New().String()
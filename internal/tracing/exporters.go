@@ -0,0 +1,213 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Exporters
+const (
+	OTLP   = "otlp"
+	Jaeger = "jaeger"
+	Zipkin = "zipkin"
+)
+
+// NewExporter creates the exporter of the specified type which sends the spans to the endpoint, i.e.
+// http://localhost:4318/v1/traces for OTLP or http://localhost:9411/api/v2/spans for Zipkin. Jaeger ingests
+// OTLP, so the Jaeger exporter sends OTLP to the collector's OTLP endpoint.
+func NewExporter(exporterType string, endpoint string, serviceName string) (Exporter, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("no endpoint specified for the %s exporter", exporterType)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch exporterType {
+	case OTLP, Jaeger:
+		return &otlpExporter{endpoint: endpoint, serviceName: serviceName, client: client}, nil
+	case Zipkin:
+		return &zipkinExporter{endpoint: endpoint, serviceName: serviceName, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter '%s', must be one of %s, %s or %s", exporterType, OTLP, Jaeger, Zipkin)
+	}
+}
+
+// otlpExporter sends the spans using the OTLP/HTTP JSON encoding
+type otlpExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+func (exporter *otlpExporter) Export(spans []*Span) error {
+	otlpSpans := make([]otlpSpan, len(spans))
+	for index, span := range spans {
+		span.mutex.Lock()
+		otlpSpans[index] = otlpSpan{
+			TraceID:           hex.EncodeToString(span.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			ParentSpanID:      parentID(span),
+			Name:              span.Name,
+			Kind:              1, // internal
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.Error != "" {
+			otlpSpans[index].Status.Code = 2 // error
+			otlpSpans[index].Status.Message = span.Error
+		}
+		span.mutex.Unlock()
+	}
+
+	request := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]string{"service.name": exporter.serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "app-functions-sdk-go"},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+
+	return send(exporter.client, exporter.endpoint, request)
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	result := make([]otlpAttribute, 0, len(attributes))
+	for _, key := range sortedKeys(attributes) {
+		attribute := otlpAttribute{Key: key}
+		attribute.Value.StringValue = attributes[key]
+		result = append(result, attribute)
+	}
+	return result
+}
+
+// zipkinExporter sends the spans using the Zipkin v2 JSON API
+type zipkinExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint map[string]string `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+func (exporter *zipkinExporter) Export(spans []*Span) error {
+	zipkinSpans := make([]zipkinSpan, len(spans))
+	for index, span := range spans {
+		span.mutex.Lock()
+		tags := make(map[string]string, len(span.Attributes)+1)
+		for key, value := range span.Attributes {
+			tags[key] = value
+		}
+		if span.Error != "" {
+			tags["error"] = span.Error
+		}
+		zipkinSpans[index] = zipkinSpan{
+			TraceID:       hex.EncodeToString(span.TraceID[:]),
+			ID:            hex.EncodeToString(span.SpanID[:]),
+			ParentID:      parentID(span),
+			Name:          span.Name,
+			Timestamp:     span.Start.UnixNano() / int64(time.Microsecond),
+			Duration:      int64(span.End.Sub(span.Start) / time.Microsecond),
+			LocalEndpoint: map[string]string{"serviceName": exporter.serviceName},
+			Tags:          tags,
+		}
+		span.mutex.Unlock()
+	}
+
+	return send(exporter.client, exporter.endpoint, zipkinSpans)
+}
+
+func parentID(span *Span) string {
+	if span.ParentID == [8]byte{} {
+		return ""
+	}
+	return hex.EncodeToString(span.ParentID[:])
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// send posts the data as JSON to the endpoint
+func send(client *http.Client, endpoint string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to export spans: %v", err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unable to export spans: %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// tracing records the spans of the processing of messages and exports them to a distributed tracing backend,
+// propagating the trace context with the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceParentHeader is the W3C Trace Context header used to propagate the trace context
+const TraceParentHeader = "traceparent"
+
+// Span records an operation of a trace. All methods of a nil Span are no-ops, so the Span returned when tracing
+// is disabled or the trace isn't sampled can be used without checks.
+type Span struct {
+	tracer     *Tracer
+	TraceID    [16]byte
+	SpanID     [8]byte
+	ParentID   [8]byte
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Error      string
+	mutex      sync.Mutex
+}
+
+// SpanContext identifies a span of a trace received from another service
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// Exporter sends the ended spans to a tracing backend
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer starts the spans of the sampled traces and exports them in batches
+type Tracer struct {
+	serviceName  string
+	samplingRate float64
+	exporter     Exporter
+	spans        chan *Span
+	flush        chan chan struct{}
+	onError      func(err error)
+	stopped      chan struct{}
+	stopOnce     sync.Once
+}
+
+var (
+	globalTracer *Tracer
+	globalMutex  sync.RWMutex
+)
+
+const (
+	batchSize     = 100
+	batchInterval = time.Second
+)
+
+// NewTracer creates a tracer which samples the root spans at the sampling rate, between 0 and 1, and exports the
+// spans with the exporter. Export errors are passed to onError.
+func NewTracer(serviceName string, samplingRate float64, exporter Exporter, onError func(err error)) *Tracer {
+	tracer := &Tracer{
+		serviceName:  serviceName,
+		samplingRate: samplingRate,
+		exporter:     exporter,
+		spans:        make(chan *Span, batchSize*10),
+		flush:        make(chan chan struct{}),
+		onError:      onError,
+		stopped:      make(chan struct{}),
+	}
+	go tracer.export()
+	return tracer
+}
+
+// SetTracer sets the global tracer used by StartSpan, tracing is disabled when nil. The previous global tracer is
+// shut down.
+func SetTracer(tracer *Tracer) {
+	globalMutex.Lock()
+	previous := globalTracer
+	globalTracer = tracer
+	globalMutex.Unlock()
+
+	if previous != nil && previous != tracer {
+		previous.Shutdown()
+	}
+}
+
+// ServiceName returns the name of the service the spans are recorded for
+func (tracer *Tracer) ServiceName() string {
+	return tracer.serviceName
+}
+
+// StartSpan starts a child span of the parent with the global tracer, or a root span when the parent is nil.
+// Nil is returned when tracing is disabled or the trace isn't sampled.
+func StartSpan(name string, parent *Span) *Span {
+	if parent != nil {
+		return parent.tracer.start(name, parent.TraceID, parent.SpanID)
+	}
+
+	globalMutex.RLock()
+	tracer := globalTracer
+	globalMutex.RUnlock()
+	if tracer == nil || !tracer.sample() {
+		return nil
+	}
+
+	var traceID [16]byte
+	randomBytes(traceID[:])
+	return tracer.start(name, traceID, [8]byte{})
+}
+
+// StartRemoteSpan starts a child span of the span received from another service, honoring its sampling decision.
+// A root span is started when the remote span context is nil.
+func StartRemoteSpan(name string, remote *SpanContext) *Span {
+	if remote == nil {
+		return StartSpan(name, nil)
+	}
+
+	globalMutex.RLock()
+	tracer := globalTracer
+	globalMutex.RUnlock()
+	if tracer == nil || !remote.Sampled {
+		return nil
+	}
+
+	return tracer.start(name, remote.TraceID, remote.SpanID)
+}
+
+func (tracer *Tracer) start(name string, traceID [16]byte, parentID [8]byte) *Span {
+	span := &Span{
+		tracer:     tracer,
+		TraceID:    traceID,
+		ParentID:   parentID,
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: make(map[string]string),
+	}
+	randomBytes(span.SpanID[:])
+	return span
+}
+
+func (tracer *Tracer) sample() bool {
+	if tracer.samplingRate >= 1 {
+		return true
+	}
+	if tracer.samplingRate <= 0 {
+		return false
+	}
+
+	var value [8]byte
+	randomBytes(value[:])
+	return float64(binary.BigEndian.Uint64(value[:])>>11)/float64(1<<53) < tracer.samplingRate
+}
+
+// export sends the ended spans in batches of up to batchSize, at least every batchInterval
+func (tracer *Tracer) export() {
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := tracer.exporter.Export(batch); err != nil && tracer.onError != nil {
+			tracer.onError(err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case span := <-tracer.spans:
+			batch = append(batch, span)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case done := <-tracer.flush:
+			for len(tracer.spans) > 0 {
+				batch = append(batch, <-tracer.spans)
+			}
+			flush()
+			close(done)
+		case <-tracer.stopped:
+			return
+		}
+	}
+}
+
+// Flush exports the ended spans which haven't been exported yet. Nothing is exported once the tracer is shut down.
+func (tracer *Tracer) Flush() {
+	done := make(chan struct{})
+	select {
+	case tracer.flush <- done:
+		<-done
+	case <-tracer.stopped:
+	}
+}
+
+// Shutdown exports the ended spans which haven't been exported yet and stops the export, i.e. before the service
+// exits. The spans finished after the shutdown are dropped.
+func (tracer *Tracer) Shutdown() {
+	tracer.stopOnce.Do(func() {
+		tracer.Flush()
+		close(tracer.stopped)
+	})
+}
+
+// SetAttribute records an attribute of the operation
+func (span *Span) SetAttribute(key string, value string) {
+	if span == nil {
+		return
+	}
+	span.mutex.Lock()
+	span.Attributes[key] = value
+	span.mutex.Unlock()
+}
+
+// SetError records that the operation failed with the error
+func (span *Span) SetError(err error) {
+	if span == nil || err == nil {
+		return
+	}
+	span.mutex.Lock()
+	span.Error = err.Error()
+	span.mutex.Unlock()
+}
+
+// Finish ends the span and queues it to be exported. The span is dropped if the export queue is full.
+func (span *Span) Finish() {
+	if span == nil {
+		return
+	}
+	span.mutex.Lock()
+	span.End = time.Now()
+	span.mutex.Unlock()
+
+	select {
+	case <-span.tracer.stopped:
+	case span.tracer.spans <- span:
+	default:
+	}
+}
+
+// TraceParent returns the W3C traceparent header value identifying the span, empty for a nil span
+func (span *Span) TraceParent() string {
+	if span == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(span.TraceID[:]), hex.EncodeToString(span.SpanID[:]))
+}
+
+// ParseTraceParent parses the W3C traceparent header value, returning nil when it is empty or invalid
+func ParseTraceParent(value string) *SpanContext {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return nil
+	}
+
+	var spanContext SpanContext
+	if _, err := hex.Decode(spanContext.TraceID[:], []byte(parts[1])); err != nil || spanContext.TraceID == [16]byte{} {
+		return nil
+	}
+	if _, err := hex.Decode(spanContext.SpanID[:], []byte(parts[2])); err != nil || spanContext.SpanID == [8]byte{} {
+		return nil
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return nil
+	}
+	spanContext.Sampled = flags[0]&1 == 1
+
+	return &spanContext
+}
+
+// spanKey is the key of the span held by a context
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of the parent context holding the span, so the spans of the operations done with
+// the context are its children. A background context is used when the parent is nil.
+func ContextWithSpan(parent context.Context, span *Span) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, spanKey{}, span)
+}
+
+// SpanFromContext returns the span held by the context, nil when the context is nil or holds no span
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func randomBytes(buffer []byte) {
+	_, _ = rand.Read(buffer)
+}
@@ -0,0 +1,256 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingExporter struct {
+	spans []*Span
+	mutex sync.Mutex
+}
+
+func (exporter *recordingExporter) Export(spans []*Span) error {
+	exporter.mutex.Lock()
+	exporter.spans = append(exporter.spans, spans...)
+	exporter.mutex.Unlock()
+	return nil
+}
+
+func TestStartSpanDisabled(t *testing.T) {
+	SetTracer(nil)
+
+	span := StartSpan("test", nil)
+	assert.Nil(t, span)
+
+	// a nil span can be used without checks
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("failed"))
+	span.Finish()
+	assert.Equal(t, "", span.TraceParent())
+}
+
+func TestStartSpan(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer("myService", 1, exporter, nil)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	root := StartSpan("root", nil)
+	if !assert.NotNil(t, root) {
+		t.Fatal()
+	}
+	child := StartSpan("child", root)
+	child.SetAttribute("key", "value")
+	child.SetError(errors.New("failed"))
+	child.Finish()
+	root.Finish()
+	tracer.Flush()
+
+	if !assert.Len(t, exporter.spans, 2) {
+		t.Fatal()
+	}
+	assert.Equal(t, "child", exporter.spans[0].Name)
+	assert.Equal(t, root.TraceID, exporter.spans[0].TraceID)
+	assert.Equal(t, root.SpanID, exporter.spans[0].ParentID)
+	assert.Equal(t, "value", exporter.spans[0].Attributes["key"])
+	assert.Equal(t, "failed", exporter.spans[0].Error)
+	assert.Equal(t, [8]byte{}, exporter.spans[1].ParentID)
+	assert.False(t, exporter.spans[1].End.Before(exporter.spans[1].Start))
+}
+
+func TestTracerShutdown(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer("myService", 1, exporter, nil)
+	SetTracer(tracer)
+
+	StartSpan("pending", nil).Finish()
+
+	// replacing the tracer shuts the previous one down, exporting its pending spans
+	SetTracer(NewTracer("myService", 1, &recordingExporter{}, nil))
+	SetTracer(nil)
+	if !assert.Len(t, exporter.spans, 1) {
+		t.Fatal()
+	}
+	assert.Equal(t, "pending", exporter.spans[0].Name)
+
+	// a shut down tracer drops the spans and doesn't block
+	tracer.start("late", [16]byte{1}, [8]byte{}).Finish()
+	tracer.Flush()
+	tracer.Shutdown()
+	assert.Len(t, exporter.spans, 1)
+}
+
+func TestStartSpanNotSampled(t *testing.T) {
+	SetTracer(NewTracer("myService", 0, &recordingExporter{}, nil))
+	defer SetTracer(nil)
+
+	assert.Nil(t, StartSpan("root", nil))
+}
+
+func TestStartRemoteSpan(t *testing.T) {
+	SetTracer(NewTracer("myService", 0, &recordingExporter{}, nil))
+	defer SetTracer(nil)
+
+	remote := ParseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	span := StartRemoteSpan("receive", remote)
+	if !assert.NotNil(t, span, "sampled remote traces are recorded regardless of sampling rate") {
+		t.Fatal()
+	}
+	assert.Equal(t, remote.TraceID, span.TraceID)
+	assert.Equal(t, remote.SpanID, span.ParentID)
+
+	notSampled := ParseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	assert.Nil(t, StartRemoteSpan("receive", notSampled))
+}
+
+func TestTraceParent(t *testing.T) {
+	SetTracer(NewTracer("myService", 1, &recordingExporter{}, nil))
+	defer SetTracer(nil)
+
+	span := StartSpan("root", nil)
+	spanContext := ParseTraceParent(span.TraceParent())
+	if !assert.NotNil(t, spanContext) {
+		t.Fatal()
+	}
+	assert.Equal(t, span.TraceID, spanContext.TraceID)
+	assert.Equal(t, span.SpanID, spanContext.SpanID)
+	assert.True(t, spanContext.Sampled)
+}
+
+func TestContextWithSpan(t *testing.T) {
+	SetTracer(NewTracer("myService", 1, &recordingExporter{}, nil))
+	defer SetTracer(nil)
+
+	assert.Nil(t, SpanFromContext(nil))
+	assert.Nil(t, SpanFromContext(context.Background()))
+
+	span := StartSpan("root", nil)
+	ctx := ContextWithSpan(nil, span)
+	assert.Equal(t, span, SpanFromContext(ctx))
+
+	child := StartSpan("child", SpanFromContext(ctx))
+	assert.Equal(t, span, SpanFromContext(ctx), "Expected the parent context to be unchanged")
+	assert.Equal(t, child, SpanFromContext(ContextWithSpan(ctx, child)))
+}
+
+func TestParseTraceParentInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"garbage",
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01",
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b716920333-01",
+		"00-zzf7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+
+	for _, test := range tests {
+		assert.Nil(t, ParseTraceParent(test), test)
+	}
+}
+
+func TestNewExporterInvalid(t *testing.T) {
+	_, err := NewExporter("bogus", "http://localhost:4318/v1/traces", "myService")
+	assert.Error(t, err)
+
+	_, err = NewExporter(OTLP, "", "myService")
+	assert.Error(t, err)
+}
+
+func exportTo(t *testing.T, exporterType string, status int) (map[string]interface{}, []interface{}, error) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ = ioutil.ReadAll(request.Body)
+		writer.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(exporterType, server.URL, "myService")
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	tracer := NewTracer("myService", 1, exporter, nil)
+	root := tracer.start("root", [16]byte{1}, [8]byte{})
+	child := tracer.start("child", root.TraceID, root.SpanID)
+	child.SetAttribute("key", "value")
+	child.SetError(errors.New("failed"))
+	err = exporter.Export([]*Span{root, child})
+
+	var object map[string]interface{}
+	var array []interface{}
+	if exporterType == Zipkin {
+		_ = json.Unmarshal(body, &array)
+	} else {
+		_ = json.Unmarshal(body, &object)
+	}
+	return object, array, err
+}
+
+func TestOTLPExporter(t *testing.T) {
+	request, _, err := exportTo(t, OTLP, http.StatusOK)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	resourceSpans := request["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resource := resourceSpans["resource"].(map[string]interface{})
+	assert.Equal(t, "service.name", resource["attributes"].([]interface{})[0].(map[string]interface{})["key"])
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if !assert.Len(t, spans, 2) {
+		t.Fatal()
+	}
+	root := spans[0].(map[string]interface{})
+	child := spans[1].(map[string]interface{})
+	assert.Equal(t, "01000000000000000000000000000000", root["traceId"])
+	assert.Nil(t, root["parentSpanId"])
+	assert.Equal(t, root["spanId"], child["parentSpanId"])
+	assert.Equal(t, "failed", child["status"].(map[string]interface{})["message"])
+}
+
+func TestZipkinExporter(t *testing.T) {
+	_, spans, err := exportTo(t, Zipkin, http.StatusAccepted)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	if !assert.Len(t, spans, 2) {
+		t.Fatal()
+	}
+	root := spans[0].(map[string]interface{})
+	child := spans[1].(map[string]interface{})
+	assert.Equal(t, "myService", root["localEndpoint"].(map[string]interface{})["serviceName"])
+	assert.Equal(t, root["id"], child["parentId"])
+	assert.Equal(t, "value", child["tags"].(map[string]interface{})["key"])
+	assert.Equal(t, "failed", child["tags"].(map[string]interface{})["error"])
+}
+
+func TestExporterError(t *testing.T) {
+	_, _, err := exportTo(t, OTLP, http.StatusServiceUnavailable)
+	assert.Error(t, err)
+}
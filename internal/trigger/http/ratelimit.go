@@ -0,0 +1,114 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package http
+
+import (
+	"container/list"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of the requests of each client IP with a token bucket, refilled with rps tokens per
+// second up to burst tokens. The buckets of the least recently seen clients are evicted once there are more than
+// maxClients of them.
+type RateLimiter struct {
+	rps        float64
+	burst      int
+	maxClients int
+	clients    sync.Map
+	mutex      sync.Mutex
+	recent     *list.List
+}
+
+// tokenBucket holds the tokens left for a client and its element in the recently seen clients
+type tokenBucket struct {
+	mutex   sync.Mutex
+	tokens  float64
+	updated time.Time
+	element *list.Element
+}
+
+// NewRateLimiter returns a RateLimiter allowing rps requests per second to each client IP, with bursts of up to
+// burst requests, and keeping the buckets of at most maxClients clients
+func NewRateLimiter(rps float64, burst int, maxClients int) *RateLimiter {
+	return &RateLimiter{
+		rps:        rps,
+		burst:      burst,
+		maxClients: maxClients,
+		recent:     list.New(),
+	}
+}
+
+// Allow takes a token from the bucket of the request's client IP, returning false along with the time until the
+// next token is available when the bucket is empty
+func (limiter *RateLimiter) Allow(r *http.Request) (bool, time.Duration) {
+	bucket := limiter.bucket(clientIP(r))
+	now := time.Now()
+
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+
+	bucket.tokens = math.Min(float64(limiter.burst), bucket.tokens+now.Sub(bucket.updated).Seconds()*limiter.rps)
+	bucket.updated = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - bucket.tokens) / limiter.rps * float64(time.Second))
+}
+
+// bucket returns the bucket of the client, marking it as the most recently seen client and evicting the least
+// recently seen clients beyond maxClients
+func (limiter *RateLimiter) bucket(client string) *tokenBucket {
+	value, found := limiter.clients.Load(client)
+	if !found {
+		value, _ = limiter.clients.LoadOrStore(client, &tokenBucket{
+			tokens:  float64(limiter.burst),
+			updated: time.Now(),
+		})
+	}
+	bucket := value.(*tokenBucket)
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if bucket.element != nil {
+		limiter.recent.MoveToFront(bucket.element)
+		return bucket
+	}
+
+	bucket.element = limiter.recent.PushFront(client)
+	for limiter.recent.Len() > limiter.maxClients {
+		oldest := limiter.recent.Back()
+		limiter.recent.Remove(oldest)
+		limiter.clients.Delete(oldest.Value)
+	}
+	return bucket
+}
+
+// clientIP returns the IP of the client which sent the request
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newClientRequest(remoteAddr string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/trigger", nil)
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	limiter := NewRateLimiter(1, 3, 10)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow(newClientRequest("10.0.0.1:5000"))
+		assert.True(t, allowed, "request %d should be allowed", i)
+	}
+
+	allowed, retryAfter := limiter.Allow(newClientRequest("10.0.0.1:5001"))
+	assert.False(t, allowed)
+	assert.True(t, retryAfter > 0 && retryAfter <= time.Second, "unexpected retry after %s", retryAfter)
+}
+
+func TestRateLimiterPerClient(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, 10)
+
+	allowed, _ := limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	assert.False(t, allowed)
+
+	allowed, _ = limiter.Allow(newClientRequest("10.0.0.2:5000"))
+	assert.True(t, allowed, "other clients should have their own limit")
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	limiter := NewRateLimiter(100, 1, 10)
+
+	allowed, _ := limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	assert.False(t, allowed)
+
+	time.Sleep(20 * time.Millisecond)
+	allowed, _ = limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	assert.True(t, allowed, "bucket should have been refilled")
+}
+
+func TestRateLimiterEvictsLeastRecentClients(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, 2)
+
+	limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	limiter.Allow(newClientRequest("10.0.0.2:5000"))
+	limiter.Allow(newClientRequest("10.0.0.1:5000"))
+	limiter.Allow(newClientRequest("10.0.0.3:5000"))
+
+	_, found := limiter.clients.Load("10.0.0.2")
+	assert.False(t, found, "least recently seen client should have been evicted")
+	_, found = limiter.clients.Load("10.0.0.1")
+	assert.True(t, found)
+	_, found = limiter.clients.Load("10.0.0.3")
+	assert.True(t, found)
+	assert.Equal(t, 2, limiter.recent.Len())
+}
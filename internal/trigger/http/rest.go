@@ -19,16 +19,28 @@ package http
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/webserver"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/gorilla/mux"
 )
 
+// RequestAuthenticator authenticates the requests to the trigger routes, returning the HTTP status code of the
+// response and the error for a request which is rejected
+type RequestAuthenticator interface {
+	Authenticate(r *http.Request) (int, error)
+}
+
 // Trigger implements Trigger to support Triggers
 type Trigger struct {
 	Configuration common.ConfigurationStruct
@@ -36,6 +48,13 @@ type Trigger struct {
 	outputData    []byte
 	Webserver     *webserver.WebServer
 	EdgeXClients  common.EdgeXClients
+	SecretStore   security.SecretStoreClient
+	// RequestTimeout is the timeout of the calls made to the EdgeX services by the pipeline's context
+	RequestTimeout time.Duration
+	// Authenticator, when set, authenticates every request to the trigger routes before it is processed
+	Authenticator RequestAuthenticator
+	// RateLimiter, when set, rejects the requests to the trigger routes of the clients exceeding their rate
+	RateLimiter *RateLimiter
 }
 
 // Initialize initializes the Trigger for logging and REST route
@@ -44,12 +63,81 @@ func (trigger *Trigger) Initialize() error {
 
 	logger.Info("Initializing HTTP Trigger")
 	trigger.Webserver.SetupTriggerRoute(trigger.requestHandler)
+	trigger.Webserver.SetupTriggerPathRoute(trigger.pathRequestHandler)
 	logger.Info("HTTP Trigger Initialized")
 
 	return nil
 }
 
 func (trigger *Trigger) requestHandler(writer http.ResponseWriter, r *http.Request) {
+	if !trigger.rateLimit(writer, r) || !trigger.authenticate(writer, r) {
+		return
+	}
+	trigger.handleRequest(writer, r, trigger.Runtime.ProcessMessage)
+}
+
+// pathRequestHandler sends the request thru the functions pipeline added for the path of the request
+func (trigger *Trigger) pathRequestHandler(writer http.ResponseWriter, r *http.Request) {
+	if !trigger.rateLimit(writer, r) || !trigger.authenticate(writer, r) {
+		return
+	}
+	path := mux.Vars(r)[webserver.TriggerPathVar]
+	for _, pipeline := range trigger.Runtime.GetFunctionPipelines() {
+		if pipeline.Path != "" && pipeline.Path == path {
+			trigger.handleRequest(writer, r, func(edgexContext *appcontext.Context, envelope types.MessageEnvelope) *runtime.MessageError {
+				return trigger.Runtime.ProcessMessageForPipeline(edgexContext, envelope, pipeline.Id)
+			})
+			return
+		}
+	}
+
+	r.Body.Close()
+	http.Error(writer, fmt.Sprintf("No functions pipeline for trigger path '%s'", path), http.StatusNotFound)
+}
+
+// rateLimit rejects the request with 429 when its client exceeds the rate allowed by the RateLimiter
+func (trigger *Trigger) rateLimit(writer http.ResponseWriter, r *http.Request) bool {
+	if trigger.RateLimiter == nil {
+		return true
+	}
+
+	allowed, retryAfter := trigger.RateLimiter.Allow(r)
+	if allowed {
+		return true
+	}
+
+	trigger.EdgeXClients.LoggingClient.Debug("Trigger request rate limited", "client", clientIP(r))
+	r.Body.Close()
+	writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(writer, "Too many requests", http.StatusTooManyRequests)
+	return false
+}
+
+// authenticate authenticates the request with the Authenticator, if any, and responds with the error when the
+// request is rejected
+func (trigger *Trigger) authenticate(writer http.ResponseWriter, r *http.Request) bool {
+	if trigger.Authenticator == nil {
+		return true
+	}
+
+	status, err := trigger.Authenticator.Authenticate(r)
+	if err == nil {
+		return true
+	}
+
+	trigger.EdgeXClients.LoggingClient.Debug("Trigger request rejected", "error", err.Error(), "status", status)
+	r.Body.Close()
+	if status == http.StatusUnauthorized {
+		writer.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	http.Error(writer, err.Error(), status)
+	return false
+}
+
+func (trigger *Trigger) handleRequest(writer http.ResponseWriter, r *http.Request, processMessage func(*appcontext.Context, types.MessageEnvelope) *runtime.MessageError) {
+	trigger.Runtime.AcquireExecution()
+	defer trigger.Runtime.ReleaseExecution()
+
 	defer r.Body.Close()
 
 	logger := trigger.EdgeXClients.LoggingClient
@@ -74,6 +162,10 @@ func (trigger *Trigger) requestHandler(writer http.ResponseWriter, r *http.Reque
 		ValueDescriptorClient: trigger.EdgeXClients.ValueDescriptorClient,
 		CommandClient:         trigger.EdgeXClients.CommandClient,
 		NotificationsClient:   trigger.EdgeXClients.NotificationsClient,
+		SecretStoreClient:     trigger.SecretStore,
+		RequestContext:        r.Context(),
+		RequestTimeout:        trigger.RequestTimeout,
+		TraceParent:           r.Header.Get(tracing.TraceParentHeader),
 	}
 
 	logger.Trace("Received message from http", clients.CorrelationHeader, correlationID)
@@ -85,7 +177,7 @@ func (trigger *Trigger) requestHandler(writer http.ResponseWriter, r *http.Reque
 		Payload:       data,
 	}
 
-	messageError := trigger.Runtime.ProcessMessage(edgexContext, envelope)
+	messageError := processMessage(edgexContext, envelope)
 	if messageError != nil {
 		// ProcessMessage logs the error, so no need to log it here.
 		writer.WriteHeader(messageError.ErrorCode)
@@ -93,6 +185,9 @@ func (trigger *Trigger) requestHandler(writer http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if edgexContext.ResponseContentType != "" {
+		writer.Header().Set(clients.ContentType, edgexContext.ResponseContentType)
+	}
 	writer.Write(edgexContext.OutputData)
 
 	if edgexContext.OutputData != nil {
@@ -0,0 +1,44 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// lockedClient serializes the publishes of a MessageClient, as its ZeroMQ publisher socket must not be used by
+// concurrent goroutines
+type lockedClient struct {
+	messaging.MessageClient
+	mutex sync.Mutex
+}
+
+// NewLockedClient returns the client with its Publish serialized, so that it can be shared by the goroutines of the
+// trigger, the pipelines and the SDK
+func NewLockedClient(client messaging.MessageClient) messaging.MessageClient {
+	return &lockedClient{MessageClient: client}
+}
+
+// Publish publishes the message once the publishes of the other goroutines are done
+func (c *lockedClient) Publish(message types.MessageEnvelope, topic string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.MessageClient.Publish(message, topic)
+}
@@ -17,14 +17,18 @@
 package messagebus
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/runtime"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-messaging/messaging"
 	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	zmq "github.com/pebbe/zmq4"
 )
 
 // Trigger implements Trigger to support MessageBusData
@@ -33,7 +37,14 @@ type Trigger struct {
 	Runtime       *runtime.GolangRuntime
 	client        messaging.MessageClient
 	topics        []types.TopicChannel
-	EdgeXClients  common.EdgeXClients
+	// filterContexts are the ZeroMQ contexts of the function pipelines' topic filter subscriptions
+	filterContexts []*zmq.Context
+	EdgeXClients   common.EdgeXClients
+	SecretStore    security.SecretStoreClient
+	// DeadLetterTopic, when set, is the topic the messages which fail to be processed are published to
+	DeadLetterTopic string
+	// RequestTimeout is the timeout of the calls made to the EdgeX services by the pipeline's context
+	RequestTimeout time.Duration
 }
 
 // Initialize ...
@@ -43,14 +54,30 @@ func (trigger *Trigger) Initialize() error {
 
 	logger.Info(fmt.Sprintf("Initializing Message Bus Trigger. Subscribing to topic: %s on port %d , Publish Topic: %s on port %d", trigger.Configuration.Binding.SubscribeTopic, trigger.Configuration.MessageBus.SubscribeHost.Port, trigger.Configuration.Binding.PublishTopic, trigger.Configuration.MessageBus.PublishHost.Port))
 
-	trigger.client, err = messaging.NewMessageClient(trigger.Configuration.MessageBus)
+	client, err := messaging.NewMessageClient(trigger.Configuration.MessageBus)
 	if err != nil {
 		return err
 	}
+	trigger.client = NewLockedClient(client)
 	trigger.topics = []types.TopicChannel{{Topic: trigger.Configuration.Binding.SubscribeTopic, Messages: make(chan types.MessageEnvelope)}}
+
+	// Each function pipeline has its own subscription so that messages received on overlapping topics are
+	// delivered to every pipeline whose topic filter covers the topic.
+	var pipelines []runtime.FunctionPipeline
+	for _, pipeline := range trigger.Runtime.GetFunctionPipelines() {
+		// Pipelines without a topic filter are only executed by the HTTP trigger
+		if pipeline.TopicFilter != "" {
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+
 	messageErrors := make(chan error)
 
-	trigger.client.Subscribe(trigger.topics, messageErrors)
+	err = trigger.client.Subscribe(trigger.topics, messageErrors)
+	if err != nil {
+		return err
+	}
+
 	receiveMessage := true
 	go func() {
 		for receiveMessage {
@@ -58,42 +85,122 @@ func (trigger *Trigger) Initialize() error {
 			case msgErr := <-messageErrors:
 				logger.Error(fmt.Sprintf("Failed to receive ZMQ Message, %v", msgErr))
 			case msgs := <-trigger.topics[0].Messages:
-				go func() {
-					logger.Trace("Received message from bus", "topic", trigger.Configuration.Binding.SubscribeTopic, clients.CorrelationHeader, msgs.CorrelationID)
-
-					edgexContext := &appcontext.Context{
-						CorrelationID:         msgs.CorrelationID,
-						Configuration:         trigger.Configuration,
-						LoggingClient:         trigger.EdgeXClients.LoggingClient,
-						EventClient:           trigger.EdgeXClients.EventClient,
-						ValueDescriptorClient: trigger.EdgeXClients.ValueDescriptorClient,
-						CommandClient:         trigger.EdgeXClients.CommandClient,
-						NotificationsClient:   trigger.EdgeXClients.NotificationsClient,
-					}
-
-					messageError := trigger.Runtime.ProcessMessage(edgexContext, msgs)
-					if messageError != nil {
-						// ProcessMessage logs the error, so no need to log it here.
-						return
-					}
-
-					if edgexContext.OutputData != nil {
-						outputEnvelope := types.MessageEnvelope{
-							CorrelationID: edgexContext.CorrelationID,
-							Payload:       edgexContext.OutputData,
-							ContentType:   clients.ContentTypeJSON,
-						}
-						err := trigger.client.Publish(outputEnvelope, trigger.Configuration.Binding.PublishTopic)
-						if err != nil {
-							logger.Error(fmt.Sprintf("Failed to publish Message to bus, %v", err))
-						}
-
-						logger.Trace("Published message to bus", "topic", trigger.Configuration.Binding.PublishTopic, clients.CorrelationHeader, msgs.CorrelationID)
-					}
-				}()
+				trigger.Runtime.DispatchMessage(msgs, func(msgs types.MessageEnvelope) {
+					trigger.processMessage(msgs, trigger.topics[0].Topic, "")
+				})
 			}
 		}
 	}()
 
+	for _, pipeline := range pipelines {
+		logger.Info(fmt.Sprintf("Subscribing to topic filter: %s for function pipeline %s", pipeline.TopicFilter, pipeline.Id))
+		messages := make(chan receivedMessage)
+		if err = trigger.subscribeTopicFilter(pipeline.TopicFilter, messages, messageErrors); err != nil {
+			return err
+		}
+
+		go func(messages chan receivedMessage, id string) {
+			for message := range messages {
+				topic := message.topic
+				trigger.Runtime.DispatchMessage(message.envelope, func(msgs types.MessageEnvelope) {
+					trigger.processMessage(msgs, topic, id)
+				})
+			}
+		}(messages, pipeline.Id)
+	}
+
 	return nil
 }
+
+// Client returns the connection to the message bus, which is nil until the trigger is initialized. Its
+// publishes are serialized, so it can be used by other goroutines to publish to the bus.
+func (trigger *Trigger) Client() messaging.MessageClient {
+	return trigger.client
+}
+
+// processMessage executes the function pipeline with the specified id, or the default function pipeline
+// when the id is empty, and publishes the output data if any.
+func (trigger *Trigger) processMessage(msgs types.MessageEnvelope, topic string, id string) {
+	logger := trigger.EdgeXClients.LoggingClient
+	logger.Trace("Received message from bus", "topic", topic, clients.CorrelationHeader, msgs.CorrelationID)
+
+	edgexContext := &appcontext.Context{
+		CorrelationID:         msgs.CorrelationID,
+		Configuration:         trigger.Configuration,
+		LoggingClient:         trigger.EdgeXClients.LoggingClient,
+		EventClient:           trigger.EdgeXClients.EventClient,
+		ValueDescriptorClient: trigger.EdgeXClients.ValueDescriptorClient,
+		CommandClient:         trigger.EdgeXClients.CommandClient,
+		NotificationsClient:   trigger.EdgeXClients.NotificationsClient,
+		SecretStoreClient:     trigger.SecretStore,
+		MessageClient:         trigger.client,
+		RequestTimeout:        trigger.RequestTimeout,
+	}
+
+	var messageError *runtime.MessageError
+	if id == "" {
+		messageError = trigger.Runtime.ProcessMessage(edgexContext, msgs)
+	} else {
+		messageError = trigger.Runtime.ProcessMessageForPipeline(edgexContext, msgs, id)
+	}
+	if messageError != nil {
+		if messageError.Err == runtime.ErrShuttingDown {
+			logger.Warn("Message received while shutting down was not processed", "topic", topic, clients.CorrelationHeader, msgs.CorrelationID)
+			return
+		}
+		// ProcessMessage logs the error, so no need to log it here.
+		if trigger.DeadLetterTopic != "" && !messageError.Stored {
+			trigger.publishDeadLetter(msgs, topic, messageError.Err)
+		}
+		return
+	}
+
+	if edgexContext.OutputData != nil {
+		contentType := edgexContext.ResponseContentType
+		if contentType == "" {
+			contentType = clients.ContentTypeJSON
+		}
+		outputEnvelope := types.MessageEnvelope{
+			CorrelationID: edgexContext.CorrelationID,
+			Payload:       edgexContext.OutputData,
+			ContentType:   contentType,
+		}
+		err := trigger.client.Publish(outputEnvelope, trigger.Configuration.Binding.PublishTopic)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to publish Message to bus, %v", err))
+		}
+
+		logger.Trace("Published message to bus", "topic", trigger.Configuration.Binding.PublishTopic, clients.CorrelationHeader, msgs.CorrelationID)
+	}
+}
+
+// publishDeadLetter publishes the message which failed to be processed, along with the error, to the dead-letter
+// topic so that it isn't lost.
+func (trigger *Trigger) publishDeadLetter(msgs types.MessageEnvelope, topic string, err error) {
+	logger := trigger.EdgeXClients.LoggingClient
+
+	deadLetter := common.DeadLetterEnvelope{
+		MessageEnvelope: msgs,
+		OriginalTopic:   topic,
+		FailedAt:        time.Now(),
+		ErrorMessage:    err.Error(),
+		Attempts:        1,
+	}
+	payload, err := json.Marshal(deadLetter)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to marshal dead letter message, %v", err), clients.CorrelationHeader, msgs.CorrelationID)
+		return
+	}
+
+	envelope := types.MessageEnvelope{
+		CorrelationID: msgs.CorrelationID,
+		Payload:       payload,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	if err := trigger.client.Publish(envelope, trigger.DeadLetterTopic); err != nil {
+		logger.Error(fmt.Sprintf("Failed to publish dead letter message to bus, %v", err), clients.CorrelationHeader, msgs.CorrelationID)
+		return
+	}
+
+	logger.Trace("Published dead letter message to bus", "topic", trigger.DeadLetterTopic, clients.CorrelationHeader, msgs.CorrelationID)
+}
@@ -18,6 +18,8 @@ package messagebus
 
 import (
 	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -273,3 +275,189 @@ func TestInitializeAndProcessEventWithOutput(t *testing.T) {
 		}
 	}
 }
+
+type publishedMessage struct {
+	envelope types.MessageEnvelope
+	topic    string
+}
+
+// fakeClient records the published messages, so processMessage can be tested without a message bus
+type fakeClient struct {
+	messaging.MessageClient
+	published []publishedMessage
+}
+
+func (client *fakeClient) Publish(message types.MessageEnvelope, topic string) error {
+	client.published = append(client.published, publishedMessage{envelope: message, topic: topic})
+	return nil
+}
+
+func TestProcessMessageDeadLetter(t *testing.T) {
+	expectedErr := errors.New("export failed")
+	transform1 := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return false, expectedErr
+	}
+
+	runtime := &runtime.GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{transform1})
+
+	client := &fakeClient{}
+	trigger := Trigger{
+		Runtime:         runtime,
+		EdgeXClients:    common.EdgeXClients{LoggingClient: logClient},
+		DeadLetterTopic: "deadletter",
+		client:          client,
+	}
+
+	eventInBytes, _ := json.Marshal(models.Event{Device: "LivingRoomThermostat"})
+	message := types.MessageEnvelope{
+		CorrelationID: "123",
+		Payload:       eventInBytes,
+		ContentType:   clients.ContentTypeJSON,
+	}
+	before := time.Now()
+	trigger.processMessage(message, "events", "")
+
+	if !assert.Len(t, client.published, 1) {
+		t.Fatal()
+	}
+	published := client.published[0]
+	assert.Equal(t, "deadletter", published.topic)
+	assert.Equal(t, "123", published.envelope.CorrelationID)
+	assert.Equal(t, clients.ContentTypeJSON, published.envelope.ContentType)
+
+	var deadLetter common.DeadLetterEnvelope
+	if !assert.NoError(t, json.Unmarshal(published.envelope.Payload, &deadLetter)) {
+		t.Fatal()
+	}
+	assert.Equal(t, message, deadLetter.MessageEnvelope)
+	assert.Equal(t, "events", deadLetter.OriginalTopic)
+	assert.Equal(t, expectedErr.Error(), deadLetter.ErrorMessage)
+	assert.Equal(t, 1, deadLetter.Attempts)
+	assert.False(t, deadLetter.FailedAt.Before(before))
+}
+
+func TestProcessMessageNoDeadLetterTopic(t *testing.T) {
+	transform1 := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return false, errors.New("export failed")
+	}
+
+	runtime := &runtime.GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{transform1})
+
+	client := &fakeClient{}
+	trigger := Trigger{
+		Runtime:      runtime,
+		EdgeXClients: common.EdgeXClients{LoggingClient: logClient},
+		client:       client,
+	}
+
+	eventInBytes, _ := json.Marshal(models.Event{Device: "LivingRoomThermostat"})
+	trigger.processMessage(types.MessageEnvelope{Payload: eventInBytes, ContentType: clients.ContentTypeJSON}, "events", "")
+
+	assert.Empty(t, client.published)
+}
+
+func TestProcessMessageResponseContentType(t *testing.T) {
+	transform1 := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		edgexcontext.ResponseContentType = clients.ContentTypeCBOR
+		edgexcontext.Complete([]byte{0xa0})
+		return false, nil
+	}
+
+	runtime := &runtime.GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{transform1})
+
+	client := &fakeClient{}
+	trigger := Trigger{
+		Configuration: common.ConfigurationStruct{Binding: common.BindingInfo{PublishTopic: "output"}},
+		Runtime:       runtime,
+		EdgeXClients:  common.EdgeXClients{LoggingClient: logClient},
+		client:        client,
+	}
+
+	eventInBytes, _ := json.Marshal(models.Event{Device: "LivingRoomThermostat"})
+	trigger.processMessage(types.MessageEnvelope{Payload: eventInBytes, ContentType: clients.ContentTypeJSON}, "events", "")
+
+	if !assert.Len(t, client.published, 1) {
+		t.Fatal()
+	}
+	assert.Equal(t, "output", client.published[0].topic)
+	assert.Equal(t, clients.ContentTypeCBOR, client.published[0].envelope.ContentType)
+	assert.Equal(t, []byte{0xa0}, client.published[0].envelope.Payload)
+}
+
+func TestProcessMessageRequestTimeout(t *testing.T) {
+	var requestTimeout time.Duration
+	transform1 := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		requestTimeout = edgexcontext.RequestTimeout
+		return false, nil
+	}
+
+	runtime := &runtime.GolangRuntime{}
+	runtime.SetTransforms([]appcontext.AppFunction{transform1})
+
+	trigger := Trigger{
+		Runtime:        runtime,
+		EdgeXClients:   common.EdgeXClients{LoggingClient: logClient},
+		RequestTimeout: 5 * time.Second,
+		client:         &fakeClient{},
+	}
+
+	eventInBytes, _ := json.Marshal(models.Event{Device: "LivingRoomThermostat"})
+	trigger.processMessage(types.MessageEnvelope{Payload: eventInBytes, ContentType: clients.ContentTypeJSON}, "events", "")
+	assert.Equal(t, 5*time.Second, requestTimeout)
+}
+
+func TestLockedClient(t *testing.T) {
+	client := &fakeClient{}
+	locked := NewLockedClient(client)
+
+	// the fake client isn't safe for concurrent use, so the race detector reports publishes which aren't serialized
+	var wait sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			_ = locked.Publish(types.MessageEnvelope{}, "events")
+		}()
+	}
+	wait.Wait()
+
+	assert.Len(t, client.published, 10)
+}
+
+func TestSubscribeTopicFilterClose(t *testing.T) {
+	config := common.ConfigurationStruct{
+		MessageBus: types.MessageBusConfig{
+			Type: "zero",
+			SubscribeHost: types.HostInfo{
+				Host:     "localhost",
+				Port:     5570,
+				Protocol: "tcp",
+			},
+		},
+	}
+	trigger := Trigger{Configuration: config, EdgeXClients: common.EdgeXClients{LoggingClient: logClient}}
+
+	messages := make(chan receivedMessage)
+	if !assert.NoError(t, trigger.subscribeTopicFilter("events/#", messages, make(chan error))) {
+		t.Fatal()
+	}
+	assert.NoError(t, trigger.Close())
+
+	select {
+	case _, ok := <-messages:
+		assert.False(t, ok, "Expected the messages channel to be closed")
+	case <-time.After(time.Second):
+		t.Fatal("Expected the subscription to be closed")
+	}
+}
+
+func TestSubscribeTopicFilterUnsupportedMessageBus(t *testing.T) {
+	trigger := Trigger{Configuration: common.ConfigurationStruct{MessageBus: types.MessageBusConfig{Type: "mqtt"}}}
+
+	err := trigger.subscribeTopicFilter("events/#", make(chan receivedMessage), make(chan error))
+	assert.EqualError(t, err, "topic filters are only supported by the 'zero' message bus")
+	assert.NoError(t, trigger.Close())
+}
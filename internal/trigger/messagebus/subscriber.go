@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	"github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	zmq "github.com/pebbe/zmq4"
+)
+
+// receivedMessage is a message received on a topic matching the topic filter of a function pipeline
+type receivedMessage struct {
+	topic    string
+	envelope types.MessageEnvelope
+}
+
+// subscribeTopicFilter subscribes to the topics matching the topic filter and sends the messages received to the
+// messages channel, which is closed once the subscription is closed by Close. The MessageClient doesn't provide the
+// topic a message was received on, and each of its Subscribe calls replaces the previous subscriptions, so the
+// subscription is made on a ZeroMQ socket of its own in order to check each topic against the whole filter. ZeroMQ
+// is the only type of message bus supported by the MessageClient.
+func (trigger *Trigger) subscribeTopicFilter(filter string, messages chan<- receivedMessage, messageErrors chan<- error) error {
+	if !strings.EqualFold(trigger.Configuration.MessageBus.Type, messaging.ZeroMQ) {
+		return fmt.Errorf("topic filters are only supported by the '%s' message bus", messaging.ZeroMQ)
+	}
+
+	context, err := zmq.NewContext()
+	if err != nil {
+		return err
+	}
+	socket, err := context.NewSocket(zmq.SUB)
+	if err != nil {
+		_ = context.Term()
+		return err
+	}
+	if err := trigger.connectTopicFilter(socket, filter); err != nil {
+		_ = socket.Close()
+		_ = context.Term()
+		return err
+	}
+	trigger.filterContexts = append(trigger.filterContexts, context)
+
+	go func() {
+		// Terminating the context interrupts the receive, and waits for the socket to be closed here
+		defer close(messages)
+		defer socket.Close()
+
+		for {
+			frames, err := socket.RecvMessage(0)
+			if err != nil {
+				if zmq.AsErrno(err) == zmq.ETERM || err == zmq.ErrorSocketClosed || err == zmq.ErrorContextClosed {
+					return
+				}
+				messageErrors <- err
+				continue
+			}
+
+			message, matched, err := filterMessage(filter, frames)
+			if err != nil {
+				messageErrors <- err
+				continue
+			}
+			if matched {
+				messages <- message
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (trigger *Trigger) connectTopicFilter(socket *zmq.Socket, filter string) error {
+	// The messages not yet received are dropped when the subscription is closed
+	if err := socket.SetLinger(0); err != nil {
+		return err
+	}
+	if err := socket.Connect(trigger.Configuration.MessageBus.SubscribeHost.GetHostURL()); err != nil {
+		return err
+	}
+	if err := socket.SetSubscribe(subscriptionTopic(filter)); err != nil {
+		return fmt.Errorf("error subscribing to topic, %v", err)
+	}
+	return nil
+}
+
+// Close closes the subscriptions of the function pipelines' topic filters, returning the first error
+func (trigger *Trigger) Close() error {
+	var firstErr error
+	for _, context := range trigger.filterContexts {
+		if err := context.Term(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	trigger.filterContexts = nil
+	return firstErr
+}
+
+// filterMessage decodes the topic and message envelope from the frames received, and reports whether the topic
+// matches the topic filter. The frames received on a prefix subscription may be for topics which don't match the
+// filter, e.g. 'events/a/b/c' for the filter 'events/+/temp'.
+func filterMessage(filter string, frames []string) (receivedMessage, bool, error) {
+	if len(frames) != 2 {
+		return receivedMessage{}, false, fmt.Errorf("expected the topic and the message to be received, but found %d parts", len(frames))
+	}
+
+	message := receivedMessage{topic: frames[0]}
+	if !topicMatches(filter, message.topic) {
+		return message, false, nil
+	}
+	if err := json.Unmarshal([]byte(frames[1]), &message.envelope); err != nil {
+		return message, false, err
+	}
+
+	return message, true, nil
+}
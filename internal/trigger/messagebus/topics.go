@@ -0,0 +1,89 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	topicLevelSeparator   = "/"
+	singleLevelWildcard   = "+"
+	multipleLevelWildcard = "#"
+)
+
+// ValidateTopicFilter checks that the topic filter follows the MQTT rules for wildcards, i.e. '+' must occupy
+// an entire topic level and '#' must occupy the last topic level.
+func ValidateTopicFilter(filter string) error {
+	if filter == "" {
+		return errors.New("topic filter must not be empty")
+	}
+
+	levels := strings.Split(filter, topicLevelSeparator)
+	for index, level := range levels {
+		if strings.Contains(level, multipleLevelWildcard) {
+			if level != multipleLevelWildcard || index != len(levels)-1 {
+				return errors.New("'#' wildcard must occupy the last level of the topic filter")
+			}
+		}
+		if strings.Contains(level, singleLevelWildcard) && level != singleLevelWildcard {
+			return errors.New("'+' wildcard must occupy an entire level of the topic filter")
+		}
+	}
+
+	return nil
+}
+
+// subscriptionTopic returns the topic to subscribe to on the message bus for the topic filter. The message bus
+// matches subscriptions by topic prefix, so this is the portion of the filter before the first wildcard and the
+// topics received are then checked against the whole filter by topicMatches. The separator before a trailing '#'
+// isn't part of the prefix, since 'events/#' matches the parent topic 'events' too.
+func subscriptionTopic(filter string) string {
+	index := strings.IndexAny(filter, singleLevelWildcard+multipleLevelWildcard)
+	if index < 0 {
+		return filter
+	}
+
+	prefix := filter[:index]
+	if filter[index:] == multipleLevelWildcard {
+		prefix = strings.TrimSuffix(prefix, topicLevelSeparator)
+	}
+	return prefix
+}
+
+// topicMatches reports whether the topic matches the topic filter using the MQTT rules, i.e. '+' matches exactly
+// one topic level and '#' matches any number of remaining topic levels, including none. It follows the matching
+// of github.com/eclipse/paho.mqtt.golang, which is unexported there.
+func topicMatches(filter string, topic string) bool {
+	filterLevels := strings.Split(filter, topicLevelSeparator)
+	topicLevels := strings.Split(topic, topicLevelSeparator)
+
+	for index, level := range filterLevels {
+		if level == multipleLevelWildcard {
+			return true
+		}
+		if index >= len(topicLevels) {
+			return false
+		}
+		if level != singleLevelWildcard && level != topicLevels[index] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package messagebus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTopicFilter(t *testing.T) {
+	tests := []struct {
+		filter        string
+		expectedError bool
+	}{
+		{"edgex/events", false},
+		{"edgex/events/device/+/profile1", false},
+		{"edgex/events/#", false},
+		{"#", false},
+		{"+/+", false},
+		{"", true},
+		{"edgex/events/#/profile1", true},
+		{"edgex/events#", true},
+		{"edgex/events/device+/profile1", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			err := ValidateTopicFilter(test.filter)
+			assert.Equal(t, test.expectedError, err != nil)
+		})
+	}
+}
+
+func TestSubscriptionTopic(t *testing.T) {
+	assert.Equal(t, "edgex/events", subscriptionTopic("edgex/events"))
+	assert.Equal(t, "edgex/events/device/", subscriptionTopic("edgex/events/device/+/profile1"))
+	assert.Equal(t, "edgex/events", subscriptionTopic("edgex/events/#"))
+	assert.Equal(t, "", subscriptionTopic("#"))
+}
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		filter   string
+		topic    string
+		expected bool
+	}{
+		{"events", "events", true},
+		{"events", "events2", false},
+		{"events/+/temp", "events/a/temp", true},
+		{"events/+/temp", "events/a/b/c", false},
+		{"events/+/temp", "events/a", false},
+		{"events/+", "events/a/temp", false},
+		{"events/#", "events/a/b/c", true},
+		{"events/#", "events", true},
+		{"events/#", "other/a", false},
+		{"#", "any/topic", true},
+		{"+/+", "a/b", true},
+		{"+/+", "a", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter+" "+test.topic, func(t *testing.T) {
+			assert.Equal(t, test.expected, topicMatches(test.filter, test.topic))
+		})
+	}
+}
+
+func TestFilterMessage(t *testing.T) {
+	payload := `{"CorrelationID":"123","Payload":"aGVsbG8=","ContentType":"application/json"}`
+
+	message, matched, err := filterMessage("events/+/temp", []string{"events/device1/temp", payload})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.True(t, matched)
+	assert.Equal(t, "events/device1/temp", message.topic)
+	assert.Equal(t, "123", message.envelope.CorrelationID)
+	assert.Equal(t, "hello", string(message.envelope.Payload))
+
+	_, matched, err = filterMessage("events/+/temp", []string{"events/a/b/c", payload})
+	assert.NoError(t, err)
+	assert.False(t, matched, "topic with more levels than the filter must not be delivered")
+
+	_, matched, err = filterMessage("events/#", []string{"events", payload})
+	assert.NoError(t, err)
+	assert.True(t, matched, "the parent topic must be delivered for a trailing '#'")
+
+	_, matched, err = filterMessage("events/#", []string{"events2", payload})
+	assert.NoError(t, err)
+	assert.False(t, matched, "the topics sharing the prefix must not be delivered")
+
+	_, _, err = filterMessage("events/#", []string{payload})
+	assert.Error(t, err)
+
+	_, _, err = filterMessage("events/#", []string{"events/a", "not json"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// ConfigureRequestLogging logs the method, path, status code, response size, latency and correlation ID of every
+// request to the webserver with the logger
+func (webserver *WebServer) ConfigureRequestLogging(logger logger.LoggingClient) {
+	webserver.requestLogger = logger
+}
+
+// logRequests logs the requests handled by the next handler once their response is written
+func logRequests(logger logger.LoggingClient, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &accessLogResponseWriter{ResponseWriter: writer, statusCode: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		logger.Info("HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.statusCode,
+			"size", recorder.size,
+			"latency", time.Since(start).String(),
+			clients.CorrelationHeader, r.Header.Get(clients.CorrelationHeader))
+	})
+}
+
+// accessLogResponseWriter records the status code and the size of the response
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	size        int
+	wroteHeader bool
+}
+
+func (writer *accessLogResponseWriter) WriteHeader(statusCode int) {
+	if !writer.wroteHeader {
+		writer.wroteHeader = true
+		writer.statusCode = statusCode
+	}
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *accessLogResponseWriter) Write(data []byte) (int, error) {
+	writer.wroteHeader = true
+	n, err := writer.ResponseWriter.Write(data)
+	writer.size += n
+	return n, err
+}
+
+// Flush sends the response written so far to the client, for the handlers streaming their response
+func (writer *accessLogResponseWriter) Flush() {
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
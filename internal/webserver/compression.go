@@ -0,0 +1,172 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webserver
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressibleContentTypes are the media types of the responses which are compressed
+var compressibleContentTypes = []string{"application/json", "text/plain", "application/xml"}
+
+// ConfigureCompression compresses the responses with gzip, or deflate, when the request's Accept-Encoding header
+// accepts it and the response's Content-Type is application/json, text/plain or application/xml
+func (webserver *WebServer) ConfigureCompression() {
+	webserver.compression = true
+}
+
+// compress compresses the responses of the next handler with the encoding accepted by the request
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(writer, r)
+			return
+		}
+
+		compressWriter := &compressResponseWriter{ResponseWriter: writer, encoding: encoding}
+		defer compressWriter.Close()
+		next.ServeHTTP(compressWriter, r)
+	})
+}
+
+// acceptedEncoding returns the encoding, gzip or deflate, accepted by the Accept-Encoding header, preferring
+// gzip, or an empty string when neither is accepted
+func acceptedEncoding(acceptEncoding string) string {
+	deflate := false
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(coding, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name != "gzip" && name != "deflate" {
+			continue
+		}
+		if rejectedCoding(params[1:]) {
+			continue
+		}
+		if name == "gzip" {
+			return "gzip"
+		}
+		deflate = true
+	}
+	if deflate {
+		return "deflate"
+	}
+	return ""
+}
+
+// rejectedCoding returns whether the parameters of a coding in the Accept-Encoding header set its q-value to 0
+func rejectedCoding(params []string) bool {
+	for _, param := range params {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+		return err == nil && q == 0
+	}
+	return false
+}
+
+// compressResponseWriter compresses the body of the response once its Content-Type is known to be compressible
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	wroteHeader bool
+	compressor  compressor
+}
+
+// compressor is implemented by the gzip and zlib writers
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func (writer *compressResponseWriter) WriteHeader(statusCode int) {
+	if writer.wroteHeader {
+		return
+	}
+	writer.wroteHeader = true
+
+	header := writer.Header()
+	if statusCode != http.StatusNoContent && statusCode != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", writer.encoding)
+		header.Del("Content-Length")
+		if writer.encoding == "gzip" {
+			writer.compressor = gzip.NewWriter(writer.ResponseWriter)
+		} else {
+			writer.compressor = zlib.NewWriter(writer.ResponseWriter)
+		}
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *compressResponseWriter) Write(data []byte) (int, error) {
+	if !writer.wroteHeader {
+		// The Content-Type is sniffed from the first data written, as done by the http package when it's unset
+		if writer.Header().Get("Content-Type") == "" {
+			writer.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	if writer.compressor == nil {
+		return writer.ResponseWriter.Write(data)
+	}
+	return writer.compressor.Write(data)
+}
+
+// Flush sends the data compressed so far to the client, for the handlers streaming their response
+func (writer *compressResponseWriter) Flush() {
+	if writer.compressor != nil {
+		writer.compressor.Flush()
+	}
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close flushes the compressed data remaining to the response
+func (writer *compressResponseWriter) Close() error {
+	if writer.compressor == nil {
+		return nil
+	}
+	return writer.compressor.Close()
+}
+
+// compressible returns whether the media type of the Content-Type is one of the compressibleContentTypes
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, compressibleType := range compressibleContentTypes {
+		if mediaType == compressibleType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webserver
+
+import (
+	"net/http"
+	"strings"
+)
+
+// corsConfig holds the values of the CORS headers set on the responses
+type corsConfig struct {
+	origins []string
+	// anyOrigin is whether all origins are allowed, otherwise the responses vary with the origin of the request
+	anyOrigin bool
+	methods   string
+	headers   string
+}
+
+// ConfigureCORS sets the Access-Control-Allow-Origin, Access-Control-Allow-Methods and Access-Control-Allow-Headers
+// headers on all the responses to the requests from the origin, which is "*" for any origin or a comma separated
+// list of origins, and responds to the OPTIONS preflight requests of all routes. Unless any origin is allowed, all
+// the responses have a Vary: Origin header.
+func (webserver *WebServer) ConfigureCORS(origin string, methods string, headers string) {
+	config := &corsConfig{methods: methods, headers: headers}
+	for _, allowed := range strings.Split(origin, ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" {
+			config.origins = append(config.origins, allowed)
+			config.anyOrigin = config.anyOrigin || allowed == "*"
+		}
+	}
+	webserver.cors = config
+}
+
+// wrap sets the CORS headers on the responses of the next handler, answering the preflight requests itself
+func (cors *corsConfig) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		header := writer.Header()
+		// Caches must not serve a response for an origin to another, including when the origin isn't allowed
+		if !cors.anyOrigin {
+			header.Add("Vary", "Origin")
+		}
+
+		allowedOrigin := cors.allowedOrigin(r.Header.Get("Origin"))
+		if allowedOrigin != "" {
+			header.Set("Access-Control-Allow-Origin", allowedOrigin)
+			header.Set("Access-Control-Allow-Methods", cors.methods)
+			header.Set("Access-Control-Allow-Headers", cors.headers)
+		}
+
+		// The routes only match their own methods, so the preflight requests are answered here
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header for the origin of the request, which
+// is empty when the origin isn't allowed
+func (cors *corsConfig) allowedOrigin(origin string) string {
+	if cors.anyOrigin {
+		return "*"
+	}
+	for _, allowed := range cors.origins {
+		if origin != "" && strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
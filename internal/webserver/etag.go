@@ -0,0 +1,94 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package webserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ConfigureETagCaching sets an ETag header, the SHA-256 hash of the body, on the responses of the GET config and
+// metrics routes, and responds with 304 when the request's If-None-Match header holds the current ETag
+func (webserver *WebServer) ConfigureETagCaching() {
+	webserver.etagCaching = true
+}
+
+// withETag buffers the response of the handler to set its ETag, when ETag caching is configured
+func (webserver *WebServer) withETag(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, r *http.Request) {
+		if !webserver.etagCaching {
+			handler(writer, r)
+			return
+		}
+
+		buffered := &bufferedResponseWriter{header: http.Header{}, statusCode: http.StatusOK}
+		handler(buffered, r)
+
+		for name, values := range buffered.header {
+			writer.Header()[name] = values
+		}
+		if buffered.statusCode != http.StatusOK {
+			writer.WriteHeader(buffered.statusCode)
+			writer.Write(buffered.body.Bytes())
+			return
+		}
+
+		hash := sha256.Sum256(buffered.body.Bytes())
+		etag := `"` + hex.EncodeToString(hash[:]) + `"`
+		writer.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			writer.Header().Del("Content-Type")
+			writer.Header().Del("Content-Length")
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writer.Write(buffered.body.Bytes())
+	}
+}
+
+// etagMatches returns whether one of the ETags of the If-None-Match header, which are compared weakly, is the etag
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds the response written by a handler
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (writer *bufferedResponseWriter) Header() http.Header {
+	return writer.header
+}
+
+func (writer *bufferedResponseWriter) WriteHeader(statusCode int) {
+	writer.statusCode = statusCode
+}
+
+func (writer *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return writer.body.Write(data)
+}
@@ -17,9 +17,14 @@
 package webserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/internal"
@@ -31,11 +36,30 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// TriggerPathVar is the name of the route variable holding the path of the trigger path route
+const TriggerPathVar = "path"
+
+// exportContentTypes are the content types of the configuration export formats
+var exportContentTypes = map[string]string{
+	"toml": "application/toml",
+	"yaml": "application/x-yaml",
+	"json": clients.ContentTypeJSON,
+}
+
+// QueueDepthHeader is the header of the ping response holding the Store and Forward queue depth
+const QueueDepthHeader = "X-StoreForward-Queue-Depth"
+
 // WebServer handles the webserver configuration
 type WebServer struct {
 	Config        *common.ConfigurationStruct
 	LoggingClient logger.LoggingClient
 	router        *mux.Router
+	appMetrics    func() interface{}
+	queueDepth    func() (int, error)
+	cors          *corsConfig
+	compression   bool
+	etagCaching   bool
+	requestLogger logger.LoggingClient
 }
 
 // NewWebserver returns a new instance of *WebServer
@@ -52,6 +76,12 @@ func NewWebServer(config *common.ConfigurationStruct, lc logger.LoggingClient, r
 // Test if the service is working
 func (webserver *WebServer) pingHandler(writer http.ResponseWriter, _ *http.Request) {
 	writer.Header().Set("Content-Type", "text/plain")
+	if webserver.queueDepth != nil {
+		// the health check must not fail because the store can't be reached, so the depth is left out
+		if depth, err := webserver.queueDepth(); err == nil {
+			writer.Header().Set(QueueDepthHeader, strconv.Itoa(depth))
+		}
+	}
 	writer.Write([]byte("pong"))
 }
 
@@ -59,6 +89,30 @@ func (webserver *WebServer) configHandler(writer http.ResponseWriter, _ *http.Re
 	webserver.encode(webserver.Config, writer)
 }
 
+// configExportHandler exports the configuration, with the sensitive settings redacted, in the format of the format
+// query parameter, which defaults to toml
+func (webserver *WebServer) configExportHandler(writer http.ResponseWriter, request *http.Request) {
+	format := request.URL.Query().Get("format")
+	if format == "" {
+		format = "toml"
+	}
+
+	var buffer bytes.Buffer
+	err := common.ExportConfiguration(*webserver.Config, &buffer, format)
+	if err != nil {
+		if _, ok := err.(common.UnsupportedFormatError); ok {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		webserver.LoggingClient.Error("Error exporting the configuration: " + err.Error())
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", exportContentTypes[strings.ToLower(format)])
+	writer.Write(buffer.Bytes())
+}
+
 // Helper function for encoding things for returning from REST calls
 func (webserver *WebServer) encode(data interface{}, writer http.ResponseWriter) {
 	writer.Header().Add("Content-Type", "application/json")
@@ -74,7 +128,15 @@ func (webserver *WebServer) encode(data interface{}, writer http.ResponseWriter)
 }
 
 func (webserver *WebServer) metricsHandler(writer http.ResponseWriter, _ *http.Request) {
-	telem := telemetry.NewSystemUsage()
+	telem := struct {
+		telemetry.SystemUsage
+		Application interface{} `json:",omitempty"`
+	}{
+		SystemUsage: telemetry.NewSystemUsage(),
+	}
+	if webserver.appMetrics != nil {
+		telem.Application = webserver.appMetrics()
+	}
 
 	webserver.encode(telem, writer)
 
@@ -107,25 +169,137 @@ func (webserver *WebServer) ConfigureStandardRoutes() {
 	webserver.router.HandleFunc(clients.ApiPingRoute, webserver.pingHandler).Methods(http.MethodGet)
 
 	// Configuration
-	webserver.router.HandleFunc(clients.ApiConfigRoute, webserver.configHandler).Methods(http.MethodGet)
+	webserver.router.HandleFunc(clients.ApiConfigRoute, webserver.withETag(webserver.configHandler)).Methods(http.MethodGet)
+	webserver.router.HandleFunc(internal.ApiConfigExportRoute, webserver.configExportHandler).Methods(http.MethodGet)
 
 	// Metrics
-	webserver.router.HandleFunc(clients.ApiMetricsRoute, webserver.metricsHandler).Methods(http.MethodGet)
+	webserver.router.HandleFunc(clients.ApiMetricsRoute, webserver.withETag(webserver.metricsHandler)).Methods(http.MethodGet)
 
 	// Version
 	webserver.router.HandleFunc(clients.ApiVersionRoute, webserver.versionHandler).Methods(http.MethodGet)
 }
 
+// ConfigureProfilingRoutes mounts the net/http/pprof handlers under /debug/pprof/
+func (webserver *WebServer) ConfigureProfilingRoutes() {
+	webserver.LoggingClient.Info("Registering profiling routes...")
+
+	webserver.router.HandleFunc(internal.ApiProfilingRoute+"cmdline", pprof.Cmdline)
+	webserver.router.HandleFunc(internal.ApiProfilingRoute+"profile", pprof.Profile)
+	webserver.router.HandleFunc(internal.ApiProfilingRoute+"symbol", pprof.Symbol)
+	webserver.router.HandleFunc(internal.ApiProfilingRoute+"trace", pprof.Trace)
+	// Index also serves the named profiles, i.e. /debug/pprof/heap
+	webserver.router.PathPrefix(internal.ApiProfilingRoute).HandlerFunc(pprof.Index)
+}
+
+// ConfigureLogLevelRoute adds a route to change the log level at runtime using the specified function, which
+// returns an error for an unrecognized log level. The request body is JSON in the form {"level":"DEBUG"}.
+func (webserver *WebServer) ConfigureLogLevelRoute(setLogLevel func(level string) error) {
+	webserver.router.HandleFunc(internal.ApiLogLevelRoute, func(writer http.ResponseWriter, request *http.Request) {
+		defer request.Body.Close()
+
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			http.Error(writer, fmt.Sprintf("Unable to decode request body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		if err := setLogLevel(body.Level); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodPost)
+}
+
+// ConfigureApplicationMetrics sets the function which returns the application's metrics, which are included
+// under Application in the response of the metrics route along with the system usage.
+func (webserver *WebServer) ConfigureApplicationMetrics(getMetrics func() interface{}) {
+	webserver.appMetrics = getMetrics
+}
+
+// ConfigurePrometheusMetricsRoute adds a route which returns the application's metrics written by the specified
+// function, in the Prometheus text exposition format of the content type.
+func (webserver *WebServer) ConfigurePrometheusMetricsRoute(contentType string, writeMetrics func(writer io.Writer) error) {
+	webserver.router.HandleFunc(internal.ApiPrometheusMetricsRoute, func(writer http.ResponseWriter, _ *http.Request) {
+		var buffer bytes.Buffer
+		if err := writeMetrics(&buffer); err != nil {
+			webserver.LoggingClient.Error("Error writing the Prometheus metrics: " + err.Error())
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set(clients.ContentType, contentType)
+		_, _ = writer.Write(buffer.Bytes())
+	}).Methods(http.MethodGet)
+}
+
+// ConfigureMetricsResetRoute adds a route to reset the application's metrics using the specified function.
+// The route responds with 403 unless Writable.MaintenanceMode is enabled.
+func (webserver *WebServer) ConfigureMetricsResetRoute(resetMetrics func()) {
+	webserver.router.HandleFunc(internal.ApiMetricsResetRoute, func(writer http.ResponseWriter, _ *http.Request) {
+		if !webserver.Config.Writable.MaintenanceMode {
+			http.Error(writer, "Metrics can only be reset in MaintenanceMode", http.StatusForbidden)
+			return
+		}
+
+		resetMetrics()
+		writer.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodPost)
+}
+
+// ConfigureStoreForwardQueueRoute adds a route which returns the Store and Forward queue depth using the
+// specified function. The depth is also returned in the QueueDepthHeader of the ping response.
+func (webserver *WebServer) ConfigureStoreForwardQueueRoute(getQueueDepth func() (int, error)) {
+	webserver.queueDepth = getQueueDepth
+	webserver.router.HandleFunc(internal.ApiStoreForwardQueueRoute, func(writer http.ResponseWriter, _ *http.Request) {
+		depth, err := getQueueDepth()
+		if err != nil {
+			webserver.LoggingClient.Error("Unable to get the Store and Forward queue depth: " + err.Error())
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		webserver.encode(struct {
+			QueueDepth int `json:"queue_depth"`
+		}{depth}, writer)
+	}).Methods(http.MethodGet)
+}
+
 // SetupTriggerRoute adds a route to handle trigger pipeline from HTTP request
 func (webserver *WebServer) SetupTriggerRoute(handlerForTrigger func(http.ResponseWriter, *http.Request)) {
 	webserver.router.HandleFunc(internal.ApiTriggerRoute, handlerForTrigger)
 }
 
+// SetupTriggerPathRoute adds a route to handle the HTTP requests posted to the paths below the trigger route. The
+// path below the trigger route is available from the TriggerPathVar route variable.
+func (webserver *WebServer) SetupTriggerPathRoute(handlerForTrigger func(http.ResponseWriter, *http.Request)) {
+	webserver.router.HandleFunc(internal.ApiTriggerRoute+"/{"+TriggerPathVar+":.+}", handlerForTrigger).Methods(http.MethodPost)
+}
+
+// handler returns the handler of the webserver's routes, along with the compression, CORS headers and request
+// logging when configured
+func (webserver *WebServer) handler() http.Handler {
+	var handler http.Handler = webserver.router
+	if webserver.compression {
+		handler = compress(handler)
+	}
+	if webserver.cors != nil {
+		handler = webserver.cors.wrap(handler)
+	}
+	if webserver.requestLogger != nil {
+		handler = logRequests(webserver.requestLogger, handler)
+	}
+	return handler
+}
+
 // StartHTTPServer starts the http server
 func (webserver *WebServer) StartHTTPServer(errChannel chan error) {
 	webserver.LoggingClient.Info(fmt.Sprintf("Starting HTTP Server on port :%d", webserver.Config.Service.Port))
 	go func() {
 		p := fmt.Sprintf(":%d", webserver.Config.Service.Port)
-		errChannel <- http.ListenAndServe(p, http.TimeoutHandler(webserver.router, time.Millisecond*time.Duration(webserver.Config.Service.Timeout), "Request timed out"))
+		errChannel <- http.ListenAndServe(p, http.TimeoutHandler(webserver.handler(), time.Millisecond*time.Duration(webserver.Config.Service.Timeout), "Request timed out"))
 	}()
 }
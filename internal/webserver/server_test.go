@@ -17,13 +17,21 @@
 package webserver
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/internal"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/common"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/logging"
 	"github.com/edgexfoundry/app-functions-sdk-go/internal/telemetry"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
@@ -75,11 +83,45 @@ func TestConfigureAndConfigRoute(t *testing.T) {
 	rr := httptest.NewRecorder()
 	webserver.router.ServeHTTP(rr, req)
 
-	expected := `{"Writable":{"LogLevel":"","Pipeline":{"ExecutionOrder":"","UseTargetTypeOfByteArray":false,"Functions":null},"StoreAndForward":{"Enabled":false,"RetryInterval":0,"MaxRetryCount":0}},"Logging":{"EnableRemote":false,"File":""},"Registry":{"Host":"","Port":0,"Type":""},"Service":{"BootTimeout":0,"CheckInterval":"","ClientMonitor":0,"Host":"","Port":0,"Protocol":"","StartupMsg":"","ReadMaxLimit":0,"Timeout":0},"MessageBus":{"PublishHost":{"Host":"","Port":0,"Protocol":""},"SubscribeHost":{"Host":"","Port":0,"Protocol":""},"Type":"","Optional":null},"Binding":{"Type":"","Name":"","SubscribeTopic":"","PublishTopic":""},"ApplicationSettings":null,"Clients":null,"Database":{"Type":"","Host":"","Port":0,"Timeout":0,"Username":"","Password":"","MaxIdle":0,"BatchSize":0}}` + "\n"
+	expected := `{"Writable":{"LogLevel":"","Pipeline":{"ExecutionOrder":"","UseTargetTypeOfByteArray":false,"Functions":null},"StoreAndForward":{"Enabled":false,"RetryInterval":0,"MaxRetryCount":0,"ReplayRPS":0},"MaintenanceMode":false},"Logging":{"EnableRemote":false,"File":""},"Registry":{"Host":"","Port":0,"Type":""},"Service":{"BootTimeout":0,"CheckInterval":"","ClientMonitor":0,"Host":"","Port":0,"Protocol":"","StartupMsg":"","ReadMaxLimit":0,"Timeout":0,"ProfilingEnabled":false,"DeviceCacheTTL":"","PrewarmDeviceCache":false,"CommandCacheTTL":"","DrainTimeout":""},"MessageBus":{"PublishHost":{"Host":"","Port":0,"Protocol":""},"SubscribeHost":{"Host":"","Port":0,"Protocol":""},"Type":"","Optional":null},"Binding":{"Type":"","Name":"","SubscribeTopic":"","PublishTopic":"","SystemEventsTopic":"","KeyRefreshInterval":""},"ApplicationSettings":null,"Clients":null,"Database":{"Type":"","Host":"","Port":0,"Timeout":0,"Username":"","Password":"","MaxIdle":0,"BatchSize":0,"MaxPayloadBytes":0},"SecretStore":{"Type":"","Host":"","Port":0,"Path":"","Protocol":"","TokenFile":"","Timeout":0,"KubeConfig":""},"RESTClient":{"MaxIdleConns":0,"MaxIdleConnsPerHost":0,"IdleConnTimeout":"","TLSHandshakeTimeout":""}}` + "\n"
 	body := rr.Body.String()
 	assert.Equal(t, expected, body)
 }
 
+func TestConfigureConfigExportRoute(t *testing.T) {
+	configuration := &common.ConfigurationStruct{}
+	configuration.Service.Host = "localhost"
+	configuration.Database.Password = "secret"
+	webserver := NewWebServer(configuration, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, internal.ApiConfigExportRoute+"?format=json", nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, clients.ContentTypeJSON, rr.Header().Get(clients.ContentType))
+	exported := common.ConfigurationStruct{}
+	err := json.Unmarshal(rr.Body.Bytes(), &exported)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "localhost", exported.Service.Host)
+	assert.Equal(t, common.Redacted, exported.Database.Password)
+
+	req, _ = http.NewRequest(http.MethodGet, internal.ApiConfigExportRoute, nil)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/toml", rr.Header().Get(clients.ContentType))
+	assert.Contains(t, rr.Body.String(), "[Service]")
+	assert.NotContains(t, rr.Body.String(), "secret")
+
+	req, _ = http.NewRequest(http.MethodGet, internal.ApiConfigExportRoute+"?format=xml", nil)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
 func TestConfigureAndMetricsRoute(t *testing.T) {
 	webserver := NewWebServer(config, logClient, mux.NewRouter())
 	webserver.ConfigureStandardRoutes()
@@ -101,6 +143,46 @@ func TestConfigureAndMetricsRoute(t *testing.T) {
 	assert.NotNil(t, metrics.CpuBusyAvg, "Expected CpuBusyAvg value of metrics to be not nil")
 }
 
+func TestConfigureApplicationMetrics(t *testing.T) {
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureApplicationMetrics(func() interface{} {
+		return map[string]uint64{"EventsReceived": 5}
+	})
+
+	req, _ := http.NewRequest("GET", clients.ApiMetricsRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+
+	metrics := struct {
+		telemetry.SystemUsage
+		Application map[string]uint64
+	}{}
+	err := json.Unmarshal(rr.Body.Bytes(), &metrics)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.NotZero(t, metrics.Memory.Alloc, "Expected system usage to still be included")
+	assert.Equal(t, uint64(5), metrics.Application["EventsReceived"])
+}
+
+func TestConfigureProfilingRoutes(t *testing.T) {
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureProfilingRoutes()
+
+	req, _ := http.NewRequest("GET", internal.ApiProfilingRoute+"cmdline", nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEmpty(t, rr.Body.String(), "Expected command line to be returned")
+
+	req, _ = http.NewRequest("GET", internal.ApiProfilingRoute+"goroutine?debug=1", nil)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "goroutine profile")
+}
+
 func TestSetupTriggerRoute(t *testing.T) {
 	webserver := NewWebServer(config, logClient, mux.NewRouter())
 
@@ -123,3 +205,370 @@ func TestSetupTriggerRoute(t *testing.T) {
 	assert.False(t, handlerFunctionNotCalled, "expected handler function to be called")
 
 }
+
+func TestConfigureMetricsResetRoute(t *testing.T) {
+	resetCalled := false
+	webserver := NewWebServer(&common.ConfigurationStruct{}, logClient, mux.NewRouter())
+	webserver.ConfigureMetricsResetRoute(func() {
+		resetCalled = true
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, internal.ApiMetricsResetRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.False(t, resetCalled, "expected metrics not to be reset outside of MaintenanceMode")
+
+	webserver.Config.Writable.MaintenanceMode = true
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, resetCalled, "expected metrics to be reset in MaintenanceMode")
+}
+
+func TestConfigurePrometheusMetricsRoute(t *testing.T) {
+	var writeErr error
+	webserver := NewWebServer(&common.ConfigurationStruct{}, logClient, mux.NewRouter())
+	webserver.ConfigurePrometheusMetricsRoute("text/plain; version=0.0.4", func(writer io.Writer) error {
+		_, _ = writer.Write([]byte("uptime_seconds 1\n"))
+		return writeErr
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, internal.ApiPrometheusMetricsRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/plain; version=0.0.4", rr.Header().Get(clients.ContentType))
+	assert.Equal(t, "uptime_seconds 1\n", rr.Body.String())
+
+	writeErr = errors.New("write failed")
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestConfigureStoreForwardQueueRoute(t *testing.T) {
+	depth := 3
+	var depthErr error
+	webserver := NewWebServer(&common.ConfigurationStruct{}, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureStoreForwardQueueRoute(func() (int, error) {
+		return depth, depthErr
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, internal.ApiStoreForwardQueueRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"queue_depth":3}`, rr.Body.String())
+
+	req, _ = http.NewRequest(http.MethodGet, clients.ApiPingRoute, nil)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, "pong", rr.Body.String())
+	assert.Equal(t, "3", rr.Header().Get(QueueDepthHeader))
+
+	depthErr = errors.New("store unavailable")
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, "pong", rr.Body.String())
+	assert.Empty(t, rr.Header().Get(QueueDepthHeader), "expected no depth when the store is unavailable")
+
+	req, _ = http.NewRequest(http.MethodGet, internal.ApiStoreForwardQueueRoute, nil)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestSetupTriggerPathRoute(t *testing.T) {
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+
+	var path string
+	webserver.SetupTriggerPathRoute(func(w http.ResponseWriter, r *http.Request) {
+		path = mux.Vars(r)[TriggerPathVar]
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, internal.ApiTriggerRoute+"/devices/thermostats", nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "devices/thermostats", path)
+
+	req, _ = http.NewRequest(http.MethodGet, internal.ApiTriggerRoute+"/devices", nil)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestConfigureLogLevelRoute(t *testing.T) {
+	var level string
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureLogLevelRoute(func(l string) error {
+		if l != "DEBUG" {
+			return errors.New("invalid log level")
+		}
+		level = l
+		return nil
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"Valid", `{"level":"DEBUG"}`, http.StatusOK},
+		{"Unrecognized Level", `{"level":"VERBOSE"}`, http.StatusBadRequest},
+		{"Bad JSON", `{"level":`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, internal.ApiLogLevelRoute, strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+			webserver.router.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectedStatus, rr.Code)
+		})
+	}
+	assert.Equal(t, "DEBUG", level)
+}
+
+func TestCORSHeaders(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureCORS("*", "GET, POST", "Content-Type")
+
+	req, _ := http.NewRequest("GET", clients.ApiPingRoute, nil)
+	req.Header.Set("Origin", "http://dashboard.local")
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	assert.Equal(t, "pong", rr.Body.String())
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Vary"))
+	assert.Equal(t, "GET, POST", rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", rr.Header().Get("Access-Control-Allow-Headers"))
+}
+
+func TestCORSPreflight(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureCORS("http://dashboard.local, http://other.local", "GET", "Content-Type")
+
+	req, _ := http.NewRequest(http.MethodOptions, clients.ApiPingRoute, nil)
+	req.Header.Set("Origin", "http://dashboard.local")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Body.String())
+	assert.Equal(t, "http://dashboard.local", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", rr.Header().Get("Vary"))
+	assert.Equal(t, "GET", rr.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORSOriginNotAllowed(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureCORS("http://dashboard.local", "GET", "Content-Type")
+
+	req, _ := http.NewRequest("GET", clients.ApiPingRoute, nil)
+	req.Header.Set("Origin", "http://unknown.local")
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	assert.Equal(t, "pong", rr.Body.String())
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Origin", rr.Header().Get("Vary"))
+
+	// The response without an origin varies with the origin too
+	req.Header.Del("Origin")
+	rr = httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+	assert.Equal(t, "Origin", rr.Header().Get("Vary"))
+}
+
+func TestCompressionGzip(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureCompression()
+
+	req, _ := http.NewRequest("GET", clients.ApiPingRoute, nil)
+	req.Header.Set("Accept-Encoding", "deflate, gzip")
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	reader, err := gzip.NewReader(rr.Body)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	body, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(body))
+}
+
+func TestCompressionDeflate(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureCompression()
+
+	req, _ := http.NewRequest("GET", clients.ApiVersionRoute, nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, deflate")
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	assert.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+	reader, err := zlib.NewReader(rr.Body)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	body, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "version")
+}
+
+func TestCompressionSkipped(t *testing.T) {
+
+	router := mux.NewRouter()
+	router.HandleFunc("/image", func(writer http.ResponseWriter, r *http.Request) {
+		writer.Header().Set("Content-Type", "image/png")
+		writer.Write([]byte("image"))
+	})
+	webserver := NewWebServer(config, logClient, router)
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureCompression()
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		expectedBody   string
+	}{
+		{"not accepted", clients.ApiPingRoute, "", "pong"},
+		{"unsupported encoding", clients.ApiPingRoute, "br", "pong"},
+		{"content type", "/image", "gzip", "image"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", test.path, nil)
+			req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			rr := httptest.NewRecorder()
+			webserver.handler().ServeHTTP(rr, req)
+
+			assert.Empty(t, rr.Header().Get("Content-Encoding"))
+			assert.Equal(t, test.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestETagCaching(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureETagCaching()
+
+	req, _ := http.NewRequest("GET", clients.ApiConfigRoute, nil)
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	etag := rr.Header().Get("ETag")
+	if !assert.NotEmpty(t, etag) {
+		t.Fatal()
+	}
+	assert.NotEmpty(t, rr.Body.String())
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Equal(t, etag, rr.Header().Get("ETag"))
+	assert.Empty(t, rr.Body.String())
+
+	req.Header.Set("If-None-Match", `"stale"`)
+	rr = httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEmpty(t, rr.Body.String())
+}
+
+func TestETagCachingNotConfigured(t *testing.T) {
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+
+	req, _ := http.NewRequest("GET", clients.ApiMetricsRoute, nil)
+	req.Header.Set("If-None-Match", "*")
+	rr := httptest.NewRecorder()
+	webserver.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("ETag"))
+}
+
+func TestRequestLogging(t *testing.T) {
+
+	var logged bytes.Buffer
+	requestLogger, err := logging.NewClient("app_functions_sdk_go", logging.JSON, "INFO", &logged)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureStandardRoutes()
+	webserver.ConfigureRequestLogging(requestLogger)
+
+	req, _ := http.NewRequest("GET", clients.ApiPingRoute, nil)
+	req.Header.Set(clients.CorrelationHeader, "123-456")
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	var entry map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(logged.Bytes(), &entry)) {
+		t.Fatal()
+	}
+	assert.Equal(t, "HTTP request", entry[logging.MessageKey])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, clients.ApiPingRoute, entry["path"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Equal(t, float64(len("pong")), entry["size"])
+	assert.NotEmpty(t, entry["latency"])
+	assert.Equal(t, "123-456", entry[logging.CorrelationIDKey])
+}
+
+func TestRequestLoggingStatusCode(t *testing.T) {
+
+	var logged bytes.Buffer
+	requestLogger, err := logging.NewClient("app_functions_sdk_go", logging.JSON, "INFO", &logged)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	webserver := NewWebServer(config, logClient, mux.NewRouter())
+	webserver.ConfigureRequestLogging(requestLogger)
+
+	req, _ := http.NewRequest("GET", "/unknown", nil)
+	rr := httptest.NewRecorder()
+	webserver.handler().ServeHTTP(rr, req)
+
+	var entry map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(logged.Bytes(), &entry)) {
+		t.Fatal()
+	}
+	assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+	assert.Equal(t, "/unknown", entry["path"])
+}
@@ -23,37 +23,69 @@ import (
 	"github.com/edgexfoundry/go-mod-registry/registry"
 )
 
+// defaultMonitorInterval is used to refresh the endpoints when the ClientMonitor interval isn't configured
+const defaultMonitorInterval = 15 * time.Second
+
+// Endpoint resolves the endpoints of the EdgeX services from the Registry. The endpoint of a service is only
+// resolved when the Registry reports the service as healthy. When the Registry is unreachable or the service isn't
+// healthy, the last resolved endpoint is used, or the endpoint from the static configuration, set as the Url of the
+// params, when the endpoint has not been resolved yet.
 type Endpoint struct {
 	RegistryClient *registry.Client
 }
 
+// Monitor sends the service's endpoint to the channel immediately and then every params.Interval milliseconds
 func (e Endpoint) Monitor(params types.EndpointParams, ch chan string) {
+	interval := time.Millisecond * time.Duration(params.Interval)
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+
+	var last string
 	for {
-		url, err := e.buildURL(params)
-		if err != nil {
-			fmt.Fprintln(os.Stdout, err.Error())
-		}
-		ch <- url
-		time.Sleep(time.Millisecond * time.Duration(params.Interval))
+		last = e.resolve(params, last)
+		ch <- last
+		time.Sleep(interval)
 	}
 }
 
+// Fetch returns the service's endpoint
 func (e Endpoint) Fetch(params types.EndpointParams) string {
+	return e.resolve(params, "")
+}
+
+// resolve returns the endpoint from the Registry, or else the last resolved endpoint or the static endpoint
+func (e Endpoint) resolve(params types.EndpointParams, last string) string {
 	url, err := e.buildURL(params)
-	if err != nil {
-		fmt.Fprintln(os.Stdout, err.Error())
+	if err == nil {
+		return url
+	}
+
+	fallback := last
+	if fallback == "" {
+		fallback = params.Url
 	}
-	return url
+	fmt.Fprintf(os.Stdout, "%s, using %s\n", err.Error(), fallback)
+	return fallback
 }
 
 func (e Endpoint) buildURL(params types.EndpointParams) (string, error) {
-	if e.RegistryClient != nil {
-		endpoint, err := (*e.RegistryClient).GetServiceEndpoint(params.ServiceKey)
-		if err != nil {
-			return "", fmt.Errorf("unable to get Service endpoint for %s: %s", params.ServiceKey, err.Error())
-		}
-		return fmt.Sprintf("http://%s:%v%s", endpoint.Host, endpoint.Port, params.Path), nil
-	} else {
+	if e.RegistryClient == nil || *e.RegistryClient == nil {
 		return "", fmt.Errorf("unable to get Service endpoint for %s: Registry client is nil", params.ServiceKey)
 	}
+	client := *e.RegistryClient
+
+	if err := client.IsServiceAvailable(params.ServiceKey); err != nil {
+		return "", fmt.Errorf("unable to get Service endpoint for %s: %s", params.ServiceKey, err.Error())
+	}
+
+	endpoint, err := client.GetServiceEndpoint(params.ServiceKey)
+	if err != nil {
+		return "", fmt.Errorf("unable to get Service endpoint for %s: %s", params.ServiceKey, err.Error())
+	}
+	if endpoint.Host == "" || endpoint.Port == 0 {
+		return "", fmt.Errorf("unable to get Service endpoint for %s: service is not registered", params.ServiceKey)
+	}
+
+	return fmt.Sprintf("http://%s:%v%s", endpoint.Host, endpoint.Port, params.Path), nil
 }
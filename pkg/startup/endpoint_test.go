@@ -0,0 +1,81 @@
+/*******************************************************************************
+ * Copyright (c) 2019 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package startup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	registryTypes "github.com/edgexfoundry/go-mod-registry/pkg/types"
+	"github.com/edgexfoundry/go-mod-registry/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRegistry implements the service discovery methods of registry.Client, the other methods are not used
+type fakeRegistry struct {
+	registry.Client
+	endpoint registryTypes.ServiceEndpoint
+	err      error
+}
+
+func (r *fakeRegistry) IsServiceAvailable(serviceKey string) error {
+	return r.err
+}
+
+func (r *fakeRegistry) GetServiceEndpoint(serviceKey string) (registryTypes.ServiceEndpoint, error) {
+	return r.endpoint, nil
+}
+
+var params = types.EndpointParams{
+	ServiceKey:  "edgex-core-data",
+	Path:        "/api/v1/event",
+	UseRegistry: true,
+	Url:         "http://localhost:48080/api/v1/event",
+	Interval:    1,
+}
+
+func TestFetch(t *testing.T) {
+	var client registry.Client = &fakeRegistry{endpoint: registryTypes.ServiceEndpoint{Host: "core-data", Port: 48080}}
+
+	assert.Equal(t, "http://core-data:48080/api/v1/event", Endpoint{RegistryClient: &client}.Fetch(params))
+}
+
+func TestFetchFallsBackToStaticEndpoint(t *testing.T) {
+	var unavailable registry.Client = &fakeRegistry{err: errors.New("registry unreachable")}
+	assert.Equal(t, params.Url, Endpoint{RegistryClient: &unavailable}.Fetch(params))
+
+	var unregistered registry.Client = &fakeRegistry{}
+	assert.Equal(t, params.Url, Endpoint{RegistryClient: &unregistered}.Fetch(params))
+
+	var noClient registry.Client
+	assert.Equal(t, params.Url, Endpoint{RegistryClient: &noClient}.Fetch(params))
+	assert.Equal(t, params.Url, Endpoint{}.Fetch(params))
+}
+
+func TestMonitorKeepsLastResolvedEndpoint(t *testing.T) {
+	fake := &fakeRegistry{endpoint: registryTypes.ServiceEndpoint{Host: "core-data", Port: 48080}}
+	var client registry.Client = fake
+	endpoint := Endpoint{RegistryClient: &client}
+
+	assert.Equal(t, "http://core-data:48080/api/v1/event", endpoint.resolve(params, ""))
+
+	fake.err = errors.New("registry unreachable")
+	assert.Equal(t, "http://core-data:48080/api/v1/event", endpoint.resolve(params, "http://core-data:48080/api/v1/event"),
+		"Expected the last resolved endpoint while the registry is unreachable")
+
+	ch := make(chan string)
+	go endpoint.Monitor(params, ch)
+	assert.Equal(t, params.Url, <-ch, "Expected the static endpoint before an endpoint is resolved")
+}
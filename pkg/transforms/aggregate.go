@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Stat is a statistic computed by the ReadingAggregator
+type Stat int
+
+const (
+	// Min is the minimum value in the window
+	Min Stat = iota
+	// Max is the maximum value in the window
+	Max
+	// Mean is the arithmetic mean of the values in the window
+	Mean
+	// StdDev is the sample standard deviation of the values in the window
+	StdDev
+	// Percentile90 is the 90th percentile of the values in the window
+	Percentile90
+)
+
+// String returns the name of the statistic, which is used as the suffix of the aggregate reading names
+func (s Stat) String() string {
+	switch s {
+	case Min:
+		return "min"
+	case Max:
+		return "max"
+	case Mean:
+		return "mean"
+	case StdDev:
+		return "stddev"
+	case Percentile90:
+		return "p90"
+	default:
+		return "stat" + strconv.Itoa(int(s))
+	}
+}
+
+// compute returns the statistic of the values, which must not be empty
+func (s Stat) compute(values []float64) float64 {
+	switch s {
+	case Min:
+		sorted := sortedCopy(values)
+		return sorted[0]
+	case Max:
+		sorted := sortedCopy(values)
+		return sorted[len(sorted)-1]
+	case Mean:
+		mean, _ := meanAndStdDev(values)
+		return mean
+	case StdDev:
+		if len(values) < 2 {
+			return 0
+		}
+		// Sample standard deviation, using Bessel's correction
+		_, populationStdDev := meanAndStdDev(values)
+		n := float64(len(values))
+		return populationStdDev * math.Sqrt(n/(n-1))
+	case Percentile90:
+		sorted := sortedCopy(values)
+		index := int(math.Ceil(0.9*float64(len(sorted)))) - 1
+		if index < 0 {
+			index = 0
+		}
+		return sorted[index]
+	default:
+		return math.NaN()
+	}
+}
+
+func sortedCopy(values []float64) []float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// ReadingAggregator aggregates the numeric readings received within a time window by reading name
+type ReadingAggregator struct {
+	WindowDuration time.Duration
+	Stats          []Stat
+	mutex          sync.Mutex
+	windowStart    time.Time
+	values         map[string][]float64
+	devices        map[string]bool
+	now            func() time.Time
+}
+
+// NewReadingAggregator creates, initializes and returns a new instance of ReadingAggregator
+func NewReadingAggregator(windowDuration time.Duration, stats []Stat) *ReadingAggregator {
+	return &ReadingAggregator{
+		WindowDuration: windowDuration,
+		Stats:          stats,
+		values:         make(map[string][]float64),
+		devices:        make(map[string]bool),
+		now:            time.Now,
+	}
+}
+
+// AggregateReadings accumulates the numeric readings of the received Event by reading name. Non-numeric readings are
+// ignored. The pipeline is stopped while the window is open. The first event received after the window closes
+// causes a new Event to be passed to the next function, with a reading named <name>_<stat> for each reading name and
+// statistic of the closed window, and starts the next window with its readings. The aggregate event's device is the
+// device of the accumulated events when they are all from the same device.
+// This function will return an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (aggregator *ReadingAggregator) AggregateReadings(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Event Received")
+	}
+	event, ok := params[0].(models.Event)
+	if !ok {
+		return false, errors.New("type received is not an Event")
+	}
+
+	aggregator.mutex.Lock()
+	defer aggregator.mutex.Unlock()
+
+	now := aggregator.now()
+	var aggregate *models.Event
+	if aggregator.windowStart.IsZero() {
+		aggregator.windowStart = now
+	} else if now.Sub(aggregator.windowStart) >= aggregator.WindowDuration {
+		aggregate = aggregator.closeWindow(aggregator.windowStart.Add(aggregator.WindowDuration))
+		aggregator.windowStart = now
+	}
+
+	for _, reading := range event.Readings {
+		value, err := strconv.ParseFloat(reading.Value, 64)
+		if err != nil {
+			continue
+		}
+		aggregator.values[reading.Name] = append(aggregator.values[reading.Name], value)
+		aggregator.devices[event.Device] = true
+	}
+
+	if aggregate == nil {
+		return false, nil
+	}
+
+	edgexcontext.LoggingClient.Debug("Readings aggregated", "readings", strconv.Itoa(len(aggregate.Readings)))
+	return true, *aggregate
+}
+
+// closeWindow returns the event with the aggregate readings of the window and clears the window.
+// Nil is returned when no numeric readings were received in the window.
+func (aggregator *ReadingAggregator) closeWindow(windowEnd time.Time) *models.Event {
+	defer func() {
+		aggregator.values = make(map[string][]float64)
+		aggregator.devices = make(map[string]bool)
+	}()
+
+	if len(aggregator.values) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(aggregator.values))
+	for name := range aggregator.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	device := ""
+	if len(aggregator.devices) == 1 {
+		for name := range aggregator.devices {
+			device = name
+		}
+	}
+
+	origin := windowEnd.UnixNano()
+	aggregate := &models.Event{Device: device, Origin: origin}
+	for _, name := range names {
+		for _, stat := range aggregator.Stats {
+			aggregate.Readings = append(aggregate.Readings, models.Reading{
+				Device: device,
+				Origin: origin,
+				Name:   name + "_" + stat.String(),
+				Value:  strconv.FormatFloat(stat.compute(aggregator.values[name]), 'f', -1, 64),
+			})
+		}
+	}
+
+	return aggregate
+}
@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateReadings(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	aggregator := NewReadingAggregator(time.Minute, []Stat{Min, Max, Mean, StdDev, Percentile90})
+	aggregator.now = func() time.Time { return now }
+
+	for i, value := range []string{"2", "4", "4", "4", "5", "5", "7", "9", "bogus"} {
+		now = start.Add(time.Duration(i) * time.Second)
+		event := models.Event{
+			Device:   "thermostat1",
+			Readings: []models.Reading{{Name: "temperature", Value: value}, {Name: "mode", Value: "heat"}},
+		}
+		continuePipeline, result := aggregator.AggregateReadings(context, event)
+		assert.False(t, continuePipeline, "Pipeline should stop while the window is open")
+		assert.Nil(t, result)
+	}
+
+	now = start.Add(time.Minute + time.Second)
+	next := models.Event{Device: "thermostat1", Readings: []models.Reading{{Name: "temperature", Value: "100"}}}
+	continuePipeline, result := aggregator.AggregateReadings(context, next)
+	if !assert.True(t, continuePipeline, "Pipeline should continue when the window closes") {
+		t.Fatal()
+	}
+
+	aggregate := result.(models.Event)
+	assert.Equal(t, "thermostat1", aggregate.Device)
+	assert.Equal(t, start.Add(time.Minute).UnixNano(), aggregate.Origin)
+	values := map[string]string{}
+	for _, reading := range aggregate.Readings {
+		values[reading.Name] = reading.Value
+	}
+	assert.Equal(t, map[string]string{
+		"temperature_min":    "2",
+		"temperature_max":    "9",
+		"temperature_mean":   "5",
+		"temperature_stddev": "2.138089935299395",
+		"temperature_p90":    "9",
+	}, values)
+
+	// The event that closed the window is part of the next window
+	assert.Equal(t, []float64{100}, aggregator.values["temperature"])
+}
+
+func TestAggregateReadingsMultipleDevices(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	aggregator := NewReadingAggregator(time.Minute, []Stat{Max})
+	aggregator.now = func() time.Time { return now }
+
+	aggregator.AggregateReadings(context, models.Event{Device: "thermostat1", Readings: []models.Reading{{Name: "temperature", Value: "20"}}})
+	aggregator.AggregateReadings(context, models.Event{Device: "thermostat2", Readings: []models.Reading{{Name: "temperature", Value: "22"}}})
+
+	now = start.Add(time.Minute)
+	continuePipeline, result := aggregator.AggregateReadings(context, models.Event{Device: "thermostat1"})
+	assert.True(t, continuePipeline, "Pipeline should continue when the window closes")
+	aggregate := result.(models.Event)
+	assert.Equal(t, "", aggregate.Device)
+	assert.Equal(t, "temperature_max", aggregate.Readings[0].Name)
+	assert.Equal(t, "22", aggregate.Readings[0].Value)
+}
+
+func TestAggregateReadingsEmptyWindow(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	aggregator := NewReadingAggregator(time.Minute, []Stat{Mean})
+	aggregator.now = func() time.Time { return now }
+
+	aggregator.AggregateReadings(context, models.Event{Readings: []models.Reading{{Name: "mode", Value: "heat"}}})
+	now = start.Add(time.Minute)
+	continuePipeline, result := aggregator.AggregateReadings(context, models.Event{})
+	assert.False(t, continuePipeline, "Pipeline should stop when the window has no numeric readings")
+	assert.Nil(t, result)
+}
+
+func TestAggregateReadingsErrors(t *testing.T) {
+	aggregator := NewReadingAggregator(time.Minute, []Stat{Mean})
+
+	continuePipeline, result := aggregator.AggregateReadings(context)
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+
+	continuePipeline, result = aggregator.AggregateReadings(context, "not an event")
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+}
+
+func TestStatCompute(t *testing.T) {
+	values := []float64{15, 20, 35, 40, 50}
+	assert.Equal(t, 15.0, Min.compute(values))
+	assert.Equal(t, 50.0, Max.compute(values))
+	assert.Equal(t, 32.0, Mean.compute(values))
+	assert.Equal(t, 50.0, Percentile90.compute(values))
+	assert.Equal(t, 0.0, StdDev.compute([]float64{42}))
+	assert.Equal(t, 42.0, Percentile90.compute([]float64{42}))
+	assert.Equal(t, "p90", Percentile90.String())
+}
@@ -0,0 +1,164 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// AnomalyTag is the tag set to true on readings detected as anomalies
+const AnomalyTag = "anomaly"
+
+// TaggedReading is a Reading with tags, such as the AnomalyTag, which the Reading model doesn't support
+type TaggedReading struct {
+	models.Reading
+	Tags map[string]interface{}
+}
+
+// MarshalJSON adds the tags to the JSON of the reading
+func (reading TaggedReading) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(reading.Reading)
+	if err != nil || len(reading.Tags) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["tags"] = reading.Tags
+	return json.Marshal(fields)
+}
+
+// TaggedEvent is an Event whose readings have tags
+type TaggedEvent struct {
+	models.Event
+	Readings []TaggedReading
+}
+
+// MarshalJSON marshals the event with its tagged readings
+func (event TaggedEvent) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(event.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["readings"] = event.Readings
+	return json.Marshal(fields)
+}
+
+// ZScoreAnomalyDetector detects anomalous numeric readings using the Z-score of the reading's value against a sliding
+// window of the previous values of the same device and reading name.
+type ZScoreAnomalyDetector struct {
+	WindowSize int
+	Threshold  float64
+	mutex      sync.Mutex
+	windows    map[string][]float64
+}
+
+// NewZScoreAnomalyDetector creates, initializes and returns a new instance of ZScoreAnomalyDetector
+func NewZScoreAnomalyDetector(windowSize int, threshold float64) *ZScoreAnomalyDetector {
+	return &ZScoreAnomalyDetector{
+		WindowSize: windowSize,
+		Threshold:  threshold,
+		windows:    make(map[string][]float64),
+	}
+}
+
+// DetectAnomalies computes the Z-score of each numeric reading of the Event against the last WindowSize values of
+// the reading and tags the reading with AnomalyTag when the absolute Z-score exceeds the Threshold. Non-numeric
+// readings pass through unchanged. The event is returned as a TaggedEvent to the pipeline. This function will return
+// an error and stop the pipeline if a non-edgex event is received or if no data is received.
+func (detector *ZScoreAnomalyDetector) DetectAnomalies(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Event Received")
+	}
+	event, ok := params[0].(models.Event)
+	if !ok {
+		return false, errors.New("type received is not an Event")
+	}
+
+	tagged := TaggedEvent{Event: event, Readings: make([]TaggedReading, len(event.Readings))}
+	for i, reading := range event.Readings {
+		tagged.Readings[i] = TaggedReading{Reading: reading}
+
+		value, err := strconv.ParseFloat(reading.Value, 64)
+		if err != nil {
+			continue
+		}
+		if detector.addValue(event.Device+"/"+reading.Name, value) {
+			tagged.Readings[i].Tags = map[string]interface{}{AnomalyTag: true}
+			edgexcontext.LoggingClient.Debug("Anomaly detected", "device", event.Device, "reading", reading.Name, "value", reading.Value)
+		}
+	}
+
+	return true, tagged
+}
+
+// addValue returns whether the value is an anomaly for the window with the specified key and then adds the value to
+// the window. Values aren't anomalies until the window holds at least two values.
+func (detector *ZScoreAnomalyDetector) addValue(key string, value float64) bool {
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+
+	window := detector.windows[key]
+	anomaly := false
+	if len(window) >= 2 {
+		mean, stdDev := meanAndStdDev(window)
+		if stdDev > 0 {
+			anomaly = math.Abs(value-mean)/stdDev > detector.Threshold
+		} else {
+			// Any deviation from a window of identical values has an infinite Z-score
+			anomaly = value != mean
+		}
+	}
+
+	window = append(window, value)
+	if len(window) > detector.WindowSize {
+		window = window[len(window)-detector.WindowSize:]
+	}
+	detector.windows[key] = window
+
+	return anomaly
+}
+
+func meanAndStdDev(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	variance /= float64(len(values))
+
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,113 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func anomalyTestEvent(temperature string) models.Event {
+	return models.Event{
+		Device: "thermostat1",
+		Readings: []models.Reading{
+			{Name: "temperature", Value: temperature},
+			{Name: "mode", Value: "heat"},
+		},
+	}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	detector := NewZScoreAnomalyDetector(5, 3)
+
+	for _, value := range []string{"20.0", "21.0", "20.5", "19.5", "20.0"} {
+		continuePipeline, result := detector.DetectAnomalies(context, anomalyTestEvent(value))
+		assert.True(t, continuePipeline, "Pipeline should continue")
+		tagged := result.(TaggedEvent)
+		assert.Nil(t, tagged.Readings[0].Tags, "expected no anomaly for %s", value)
+	}
+
+	continuePipeline, result := detector.DetectAnomalies(context, anomalyTestEvent("35.0"))
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	tagged := result.(TaggedEvent)
+	assert.Equal(t, true, tagged.Readings[0].Tags[AnomalyTag])
+	assert.Nil(t, tagged.Readings[1].Tags, "non-numeric reading should pass through unchanged")
+	assert.Equal(t, "heat", tagged.Readings[1].Value)
+	assert.Equal(t, "thermostat1", tagged.Device)
+
+	// The window is limited to the last WindowSize values
+	assert.Equal(t, 5, len(detector.windows["thermostat1/temperature"]))
+}
+
+func TestDetectAnomaliesJSON(t *testing.T) {
+	detector := NewZScoreAnomalyDetector(3, 1)
+	for _, value := range []string{"1", "2", "1"} {
+		detector.DetectAnomalies(context, anomalyTestEvent(value))
+	}
+	_, result := detector.DetectAnomalies(context, anomalyTestEvent("100"))
+
+	data, err := json.Marshal(result)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	decoded := struct {
+		Device   string `json:"device"`
+		Readings []struct {
+			Name  string                 `json:"name"`
+			Value string                 `json:"value"`
+			Tags  map[string]interface{} `json:"tags"`
+		} `json:"readings"`
+	}{}
+	if !assert.NoError(t, json.Unmarshal(data, &decoded)) {
+		t.Fatal()
+	}
+	assert.Equal(t, "thermostat1", decoded.Device)
+	assert.Equal(t, "100", decoded.Readings[0].Value)
+	assert.Equal(t, true, decoded.Readings[0].Tags[AnomalyTag])
+	assert.Nil(t, decoded.Readings[1].Tags)
+}
+
+func TestDetectAnomaliesConcurrent(t *testing.T) {
+	detector := NewZScoreAnomalyDetector(10, 3)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			detector.DetectAnomalies(context, anomalyTestEvent(strconv.Itoa(20+i%2)))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 10, len(detector.windows["thermostat1/temperature"]))
+}
+
+func TestDetectAnomaliesErrors(t *testing.T) {
+	detector := NewZScoreAnomalyDetector(5, 3)
+
+	continuePipeline, result := detector.DetectAnomalies(context)
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+
+	continuePipeline, result = detector.DetectAnomalies(context, "not an event")
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+}
@@ -0,0 +1,185 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+)
+
+// DefaultAvroSchemaCacheTTL is the duration the schema fetched from the schema registry is cached when the
+// CacheTTL of the AvroEncoder is zero
+const DefaultAvroSchemaCacheTTL = 5 * time.Minute
+
+// avroMagicByte is the first byte of the Confluent wire format, followed by the 4 byte big-endian schema ID
+const avroMagicByte = 0
+
+// AvroEncoder encodes the data it receives with the latest Avro schema of a subject of a Confluent Schema Registry
+type AvroEncoder struct {
+	// SchemaRegistryURL is the base URL of the schema registry, i.e. http://localhost:8081
+	SchemaRegistryURL string
+	// Subject is the subject of the schema in the schema registry, i.e. <topic>-value
+	Subject string
+	// CacheTTL is the duration the schema is cached before it is fetched again. Defaults to DefaultAvroSchemaCacheTTL
+	// when zero.
+	CacheTTL time.Duration
+	mutex    sync.Mutex
+	cached   *avroSubjectSchema
+}
+
+// avroSubjectSchema is the schema of the subject fetched from the schema registry
+type avroSubjectSchema struct {
+	id      int
+	codec   avroCodec
+	fetched time.Time
+}
+
+// avroCodec is the method of goavro.Codec used by the encoder, so that goavro can replace the encoding of
+// avroschema.go once it is a dependency of the SDK
+type avroCodec interface {
+	// BinaryFromNative appends the Avro binary encoding of the datum to buf
+	BinaryFromNative(buf []byte, datum interface{}) ([]byte, error)
+}
+
+// newAvroCodec returns the codec of the JSON Avro schema, as goavro.NewCodec does
+func newAvroCodec(schema string) (avroCodec, error) {
+	parsed, err := parseAvroSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// schemaRegistryResponse is the response of the schema registry for a version of a subject
+type schemaRegistryResponse struct {
+	ID     int    `json:"id"`
+	Schema string `json:"schema"`
+}
+
+// NewAvroEncoder creates, initializes and returns a new instance of AvroEncoder
+func NewAvroEncoder(schemaRegistryURL string, subject string) *AvroEncoder {
+	return &AvroEncoder{
+		SchemaRegistryURL: schemaRegistryURL,
+		Subject:           subject,
+	}
+}
+
+// TransformToAvro encodes the data from the previous function with the latest schema of the subject, and returns
+// the encoded []byte prefixed with the magic byte and the ID of the schema in the Confluent wire format. The data
+// is converted to JSON, or used as is when it is JSON []byte or string, to match the fields of the schema by name.
+// It will return an error and stop the pipeline if no data is received, if the schema can't be fetched or if the
+// data doesn't match the schema.
+func (encoder *AvroEncoder) TransformToAvro(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Data Received")
+	}
+
+	var data []byte
+	switch input := params[0].(type) {
+	case []byte:
+		data = input
+	case string:
+		data = []byte(input)
+	default:
+		var err error
+		if data, err = json.Marshal(input); err != nil {
+			return false, fmt.Errorf("unable to marshal data to JSON: %s", err.Error())
+		}
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return false, fmt.Errorf("unable to unmarshal data from JSON: %s", err.Error())
+	}
+
+	subjectSchema, err := encoder.schema(edgexcontext)
+	if err != nil {
+		return false, err
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming to Avro", "subject", encoder.Subject)
+	encoded := make([]byte, 5, 5+len(data))
+	encoded[0] = avroMagicByte
+	binary.BigEndian.PutUint32(encoded[1:], uint32(subjectSchema.id))
+	encoded, err = subjectSchema.codec.BinaryFromNative(encoded, value)
+	if err != nil {
+		return false, fmt.Errorf("data doesn't match the Avro schema of subject %s: %s", encoder.Subject, err.Error())
+	}
+
+	return true, encoded
+}
+
+// schema returns the cached schema of the subject, fetching it from the schema registry when it has expired
+func (encoder *AvroEncoder) schema(edgexcontext *appcontext.Context) (*avroSubjectSchema, error) {
+	ttl := encoder.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultAvroSchemaCacheTTL
+	}
+
+	encoder.mutex.Lock()
+	defer encoder.mutex.Unlock()
+	if encoder.cached != nil && time.Since(encoder.cached.fetched) < ttl {
+		return encoder.cached, nil
+	}
+
+	schemaURL := strings.TrimSuffix(encoder.SchemaRegistryURL, "/") + "/subjects/" + url.PathEscape(encoder.Subject) + "/versions/latest"
+	request, err := http.NewRequest(http.MethodGet, schemaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	edgexcontext.LoggingClient.Debug("Fetching Avro schema", "subject", encoder.Subject)
+	response, err := http.DefaultClient.Do(request.WithContext(requestContext(edgexcontext)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch Avro schema of subject %s: %s", encoder.Subject, err.Error())
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch Avro schema of subject %s: %s", encoder.Subject, err.Error())
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("unable to fetch Avro schema of subject %s: %d HTTP status code", encoder.Subject, response.StatusCode)
+	}
+
+	result := schemaRegistryResponse{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal schema registry response: %s", err.Error())
+	}
+	codec, err := newAvroCodec(result.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder.cached = &avroSubjectSchema{id: result.ID, codec: codec, fetched: time.Now()}
+	return encoder.cached, nil
+}
@@ -0,0 +1,215 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const avroReadingSchema = `{
+  "type": "record",
+  "name": "Reading",
+  "namespace": "org.edgexfoundry",
+  "fields": [
+    {"name": "name", "type": "string"},
+    {"name": "value", "type": "long"}
+  ]
+}`
+
+const avroEventSchema = `{
+  "type": "record",
+  "name": "Event",
+  "namespace": "org.edgexfoundry",
+  "fields": [
+    {"name": "device", "type": "string"},
+    {"name": "origin", "type": "long"},
+    {"name": "tags", "type": {"type": "map", "values": "string"}, "default": {}},
+    {"name": "readings", "type": {"type": "array", "items": {
+      "type": "record",
+      "name": "Reading",
+      "fields": [
+        {"name": "name", "type": "string"},
+        {"name": "value", "type": ["null", "string"], "default": null}
+      ]
+    }}}
+  ]
+}`
+
+func newTestSchemaRegistry(schema string, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if request.URL.Path != "/subjects/readings-value/versions/latest" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		response, _ := json.Marshal(schemaRegistryResponse{ID: 7, Schema: schema})
+		writer.Write(response)
+	}))
+}
+
+func TestTransformToAvro(t *testing.T) {
+	var requests int32
+	server := newTestSchemaRegistry(avroReadingSchema, &requests)
+	defer server.Close()
+
+	encoder := NewAvroEncoder(server.URL, "readings-value")
+	continuePipeline, result := encoder.TransformToAvro(context, map[string]interface{}{"name": "a", "value": -2})
+	if !assert.True(t, continuePipeline, "%v", result) {
+		t.Fatal()
+	}
+
+	// Magic byte, schema ID 7, string "a" and zig-zag long -2
+	assert.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x07, 0x02, 'a', 0x03}, result)
+
+	continuePipeline, result = encoder.TransformToAvro(context, `{"name": "b", "value": 64}`)
+	if !assert.True(t, continuePipeline, "%v", result) {
+		t.Fatal()
+	}
+	assert.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x07, 0x02, 'b', 0x80, 0x01}, result)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "expected the schema to be cached")
+}
+
+func TestTransformToAvroEvent(t *testing.T) {
+	var requests int32
+	server := newTestSchemaRegistry(avroEventSchema, &requests)
+	defer server.Close()
+
+	event := models.Event{
+		Device:   devID1,
+		Origin:   1,
+		Readings: []models.Reading{{Name: readingName1, Value: "1"}, {Name: "sensor2"}},
+	}
+	continuePipeline, result := NewAvroEncoder(server.URL, "readings-value").TransformToAvro(context, event)
+	if !assert.True(t, continuePipeline, "%v", result) {
+		t.Fatal()
+	}
+
+	expected := []byte{0x00, 0x00, 0x00, 0x00, 0x07}
+	expected = append(expected, 0x06, 'i', 'd', '1', 0x02, 0x00)
+	expected = append(expected, 0x04, 0x0e, 's', 'e', 'n', 's', 'o', 'r', '1', 0x02, 0x02, '1')
+	expected = append(expected, 0x0e, 's', 'e', 'n', 's', 'o', 'r', '2', 0x00, 0x00)
+	assert.Equal(t, expected, result)
+}
+
+func TestTransformToAvroCacheTTL(t *testing.T) {
+	var requests int32
+	server := newTestSchemaRegistry(avroReadingSchema, &requests)
+	defer server.Close()
+
+	encoder := NewAvroEncoder(server.URL, "readings-value")
+	encoder.CacheTTL = time.Nanosecond
+	data := map[string]interface{}{"name": "a", "value": 1}
+
+	continuePipeline, _ := encoder.TransformToAvro(context, data)
+	assert.True(t, continuePipeline)
+	time.Sleep(time.Millisecond)
+	continuePipeline, _ = encoder.TransformToAvro(context, data)
+	assert.True(t, continuePipeline)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "expected the schema to be fetched again")
+}
+
+func TestTransformToAvroErrors(t *testing.T) {
+	var requests int32
+	server := newTestSchemaRegistry(avroReadingSchema, &requests)
+	defer server.Close()
+
+	continuePipeline, result := NewAvroEncoder(server.URL, "readings-value").TransformToAvro(context)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+
+	continuePipeline, result = NewAvroEncoder(server.URL, "unknown").TransformToAvro(context, `{}`)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "unable to fetch Avro schema of subject unknown: 404 HTTP status code")
+
+	continuePipeline, result = NewAvroEncoder(server.URL, "readings-value").TransformToAvro(context, `{"name": "a"}`)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "data doesn't match the Avro schema of subject readings-value: field value of record org.edgexfoundry.Reading is missing")
+
+	continuePipeline, result = NewAvroEncoder(server.URL, "readings-value").TransformToAvro(context, `{"name": "a", "value": 1.5}`)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "data doesn't match the Avro schema of subject readings-value: field value of record org.edgexfoundry.Reading: expected long, got 1.5")
+
+	continuePipeline, result = NewAvroEncoder(server.URL, "readings-value").TransformToAvro(context, "not json")
+	assert.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "unable to unmarshal data from JSON")
+}
+
+func TestAvroSchemaEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		value    string
+		expected []byte
+	}{
+		{"null", `"null"`, `null`, nil},
+		{"boolean", `"boolean"`, `true`, []byte{0x01}},
+		{"int", `"int"`, `-64`, []byte{0x7f}},
+		{"int logical type", `{"type": "int", "logicalType": "date"}`, `1`, []byte{0x02}},
+		{"float", `"float"`, `1`, []byte{0x00, 0x00, 0x80, 0x3f}},
+		{"double", `"double"`, `2`, []byte{0, 0, 0, 0, 0, 0, 0, 0x40}},
+		{"bytes", `"bytes"`, `"ab"`, []byte{0x04, 'a', 'b'}},
+		{"enum", `{"type": "enum", "name": "Kind", "symbols": ["A", "B"]}`, `"B"`, []byte{0x02}},
+		{"fixed", `{"type": "fixed", "name": "Two", "size": 2}`, `"ab"`, []byte{'a', 'b'}},
+		{"empty array", `{"type": "array", "items": "int"}`, `[]`, []byte{0x00}},
+		{"map", `{"type": "map", "values": "int"}`, `{"a": 1}`, []byte{0x02, 0x02, 'a', 0x02, 0x00}},
+		{"union", `["null", "int", "string"]`, `"a"`, []byte{0x04, 0x02, 'a'}},
+		{"named reference", `{"type": "record", "name": "Pair", "fields": [
+			{"name": "first", "type": {"type": "enum", "name": "Side", "symbols": ["L", "R"]}},
+			{"name": "second", "type": "Side"}]}`, `{"first": "R", "second": "L"}`, []byte{0x02, 0x00}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := parseAvroSchema(test.schema)
+			if !assert.NoError(t, err) {
+				t.Fatal()
+			}
+			var value interface{}
+			decoder := json.NewDecoder(strings.NewReader(test.value))
+			decoder.UseNumber()
+			if !assert.NoError(t, decoder.Decode(&value)) {
+				t.Fatal()
+			}
+
+			encoded, err := schema.encode(nil, value)
+			if !assert.NoError(t, err) {
+				t.Fatal()
+			}
+			assert.Equal(t, test.expected, encoded)
+		})
+	}
+}
+
+func TestParseAvroSchemaInvalid(t *testing.T) {
+	_, err := parseAvroSchema(`{"type": "record", "fields": []}`)
+	assert.EqualError(t, err, "invalid Avro schema: record must have a name")
+
+	_, err = parseAvroSchema(`{"type": "record", "name": "A", "fields": [{"name": "b", "type": "Unknown"}]}`)
+	assert.EqualError(t, err, "invalid Avro schema: field b of record A: unknown type Unknown")
+
+	_, err = parseAvroSchema(`not json`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,387 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// avroSchema is a parsed Avro schema, which encodes the JSON values of its type in the Avro binary encoding
+type avroSchema struct {
+	typeName string
+	// fullName is the full name of the named types: record, enum and fixed
+	fullName string
+	fields   []avroField
+	symbols  []string
+	size     int
+	items    *avroSchema
+	values   *avroSchema
+	branches []*avroSchema
+}
+
+type avroField struct {
+	name       string
+	schema     *avroSchema
+	defaultSet bool
+	def        interface{}
+}
+
+// avroParser parses an Avro schema, keeping the named types so that they can be referenced by name
+type avroParser struct {
+	named map[string]*avroSchema
+}
+
+var avroPrimitives = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true, "float": true, "double": true, "bytes": true, "string": true,
+}
+
+// parseAvroSchema parses the JSON Avro schema returned by the schema registry
+func parseAvroSchema(schema string) (*avroSchema, error) {
+	var definition interface{}
+	decoder := json.NewDecoder(strings.NewReader(schema))
+	decoder.UseNumber()
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %s", err.Error())
+	}
+
+	parser := avroParser{named: make(map[string]*avroSchema)}
+	parsed, err := parser.parse(definition, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %s", err.Error())
+	}
+	return parsed, nil
+}
+
+func (parser avroParser) parse(definition interface{}, namespace string) (*avroSchema, error) {
+	switch definition := definition.(type) {
+	case string:
+		if avroPrimitives[definition] {
+			return &avroSchema{typeName: definition}, nil
+		}
+		return parser.lookup(definition, namespace)
+
+	case []interface{}:
+		union := &avroSchema{typeName: "union"}
+		for _, branch := range definition {
+			parsed, err := parser.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			union.branches = append(union.branches, parsed)
+		}
+		return union, nil
+
+	case map[string]interface{}:
+		typeName, _ := definition["type"].(string)
+		switch typeName {
+		case "record", "error", "enum", "fixed":
+			return parser.parseNamed(typeName, definition, namespace)
+		case "array":
+			items, err := parser.parse(definition["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroSchema{typeName: "array", items: items}, nil
+		case "map":
+			values, err := parser.parse(definition["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroSchema{typeName: "map", values: values}, nil
+		default:
+			// Primitive types, possibly with a logicalType, are encoded as their underlying type
+			return parser.parse(definition["type"], namespace)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported schema definition %v", definition)
+}
+
+func (parser avroParser) parseNamed(typeName string, definition map[string]interface{}, namespace string) (*avroSchema, error) {
+	name, _ := definition["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%s must have a name", typeName)
+	}
+	if definedNamespace, ok := definition["namespace"].(string); ok {
+		namespace = definedNamespace
+	}
+	fullName := name
+	if !strings.Contains(name, ".") && namespace != "" {
+		fullName = namespace + "." + name
+	}
+	if index := strings.LastIndex(fullName, "."); index >= 0 {
+		namespace = fullName[:index]
+	}
+
+	schema := &avroSchema{typeName: typeName, fullName: fullName}
+	parser.named[fullName] = schema
+
+	switch typeName {
+	case "enum":
+		symbols, _ := definition["symbols"].([]interface{})
+		for _, symbol := range symbols {
+			schema.symbols = append(schema.symbols, fmt.Sprint(symbol))
+		}
+	case "fixed":
+		number, _ := definition["size"].(json.Number)
+		size, err := number.Int64()
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("fixed %s must have a size", fullName)
+		}
+		schema.size = int(size)
+	default:
+		schema.typeName = "record"
+		fields, _ := definition["fields"].([]interface{})
+		for _, field := range fields {
+			fieldDefinition, ok := field.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid field of record %s", fullName)
+			}
+			fieldName, _ := fieldDefinition["name"].(string)
+			fieldSchema, err := parser.parse(fieldDefinition["type"], namespace)
+			if err != nil {
+				return nil, fmt.Errorf("field %s of record %s: %s", fieldName, fullName, err.Error())
+			}
+			def, defaultSet := fieldDefinition["default"]
+			schema.fields = append(schema.fields, avroField{name: fieldName, schema: fieldSchema, defaultSet: defaultSet, def: def})
+		}
+	}
+
+	return schema, nil
+}
+
+// lookup returns the named type, which must have been defined earlier in the schema as required by the Avro spec
+func (parser avroParser) lookup(name string, namespace string) (*avroSchema, error) {
+	if !strings.Contains(name, ".") && namespace != "" {
+		if schema, ok := parser.named[namespace+"."+name]; ok {
+			return schema, nil
+		}
+	}
+	if schema, ok := parser.named[name]; ok {
+		return schema, nil
+	}
+	return nil, fmt.Errorf("unknown type %s", name)
+}
+
+// BinaryFromNative appends the Avro binary encoding of the JSON value, decoded with UseNumber, to buf
+func (schema *avroSchema) BinaryFromNative(buf []byte, datum interface{}) ([]byte, error) {
+	return schema.encode(buf, datum)
+}
+
+// encode appends the Avro binary encoding of the JSON value, decoded with UseNumber, to data
+func (schema *avroSchema) encode(data []byte, value interface{}) ([]byte, error) {
+	switch schema.typeName {
+	case "null":
+		if value != nil {
+			return nil, fmt.Errorf("expected null, got %v", value)
+		}
+		return data, nil
+
+	case "boolean":
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected boolean, got %v", value)
+		}
+		if boolean {
+			return append(data, 1), nil
+		}
+		return append(data, 0), nil
+
+	case "int", "long":
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %v", schema.typeName, value)
+		}
+		integer, err := number.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("expected %s, got %v", schema.typeName, value)
+		}
+		if schema.typeName == "int" && (integer < math.MinInt32 || integer > math.MaxInt32) {
+			return nil, fmt.Errorf("%d overflows int", integer)
+		}
+		return appendAvroLong(data, integer), nil
+
+	case "float", "double":
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %v", schema.typeName, value)
+		}
+		float, err := number.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("expected %s, got %v", schema.typeName, value)
+		}
+		if schema.typeName == "float" {
+			return appendUint32LE(data, math.Float32bits(float32(float))), nil
+		}
+		var bytes [8]byte
+		binary.LittleEndian.PutUint64(bytes[:], math.Float64bits(float))
+		return append(data, bytes[:]...), nil
+
+	case "bytes", "string":
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %v", schema.typeName, value)
+		}
+		data = appendAvroLong(data, int64(len(text)))
+		return append(data, text...), nil
+
+	case "fixed":
+		text, ok := value.(string)
+		if !ok || len(text) != schema.size {
+			return nil, fmt.Errorf("expected fixed %s of %d bytes, got %v", schema.fullName, schema.size, value)
+		}
+		return append(data, text...), nil
+
+	case "enum":
+		for index, symbol := range schema.symbols {
+			if symbol == value {
+				return appendAvroLong(data, int64(index)), nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not a symbol of enum %s", value, schema.fullName)
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array, got %v", value)
+		}
+		if len(items) > 0 {
+			data = appendAvroLong(data, int64(len(items)))
+			for _, item := range items {
+				var err error
+				if data, err = schema.items.encode(data, item); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(data, 0), nil
+
+	case "map":
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected map, got %v", value)
+		}
+		if len(values) > 0 {
+			data = appendAvroLong(data, int64(len(values)))
+			for key, mapValue := range values {
+				data = appendAvroLong(data, int64(len(key)))
+				data = append(data, key...)
+				var err error
+				if data, err = schema.values.encode(data, mapValue); err != nil {
+					return nil, fmt.Errorf("map value %s: %s", key, err.Error())
+				}
+			}
+		}
+		return append(data, 0), nil
+
+	case "record":
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected record %s, got %v", schema.fullName, value)
+		}
+		for _, field := range schema.fields {
+			fieldValue, set := fields[field.name]
+			if !set {
+				if !field.defaultSet {
+					return nil, fmt.Errorf("field %s of record %s is missing", field.name, schema.fullName)
+				}
+				fieldValue = field.def
+				// The default of a union is the default of its first branch
+				if field.schema.typeName == "union" {
+					encoded, err := field.schema.branches[0].encode(appendAvroLong(data, 0), fieldValue)
+					if err != nil {
+						return nil, fmt.Errorf("field %s of record %s: %s", field.name, schema.fullName, err.Error())
+					}
+					data = encoded
+					continue
+				}
+			}
+			encoded, err := field.schema.encode(data, fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("field %s of record %s: %s", field.name, schema.fullName, err.Error())
+			}
+			data = encoded
+		}
+		return data, nil
+
+	case "union":
+		for index, branch := range schema.branches {
+			if branch.matches(value) {
+				return branch.encode(appendAvroLong(data, int64(index)), value)
+			}
+		}
+		return nil, fmt.Errorf("%v doesn't match any type of the union", value)
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", schema.typeName)
+}
+
+// matches returns whether the JSON value can be encoded by the branch of a union
+func (schema *avroSchema) matches(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return schema.typeName == "null"
+	case bool:
+		return schema.typeName == "boolean"
+	case json.Number:
+		switch schema.typeName {
+		case "int", "long":
+			_, err := value.Int64()
+			return err == nil
+		case "float", "double":
+			return true
+		}
+	case string:
+		switch schema.typeName {
+		case "string", "bytes":
+			return true
+		case "fixed":
+			return len(value) == schema.size
+		case "enum":
+			for _, symbol := range schema.symbols {
+				if symbol == value {
+					return true
+				}
+			}
+		}
+	case []interface{}:
+		return schema.typeName == "array"
+	case map[string]interface{}:
+		return schema.typeName == "record" || schema.typeName == "map"
+	}
+	return false
+}
+
+// appendAvroLong appends the zig-zag encoded variable length int or long
+func appendAvroLong(data []byte, value int64) []byte {
+	encoded := uint64(value<<1) ^ uint64(value>>63)
+	for encoded >= 0x80 {
+		data = append(data, byte(encoded)|0x80)
+		encoded >>= 7
+	}
+	return append(data, byte(encoded))
+}
+
+func appendUint32LE(data []byte, value uint32) []byte {
+	var bytes [4]byte
+	binary.LittleEndian.PutUint32(bytes[:], value)
+	return append(data, bytes[:]...)
+}
@@ -0,0 +1,93 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/ugorji/go/codec"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+)
+
+// CBOREncoder encodes the data it receives as CBOR, which is more compact than JSON for high-frequency data
+type CBOREncoder struct {
+}
+
+// NewCBOREncoder creates, initializes and returns a new instance of CBOREncoder
+func NewCBOREncoder() *CBOREncoder {
+	return &CBOREncoder{}
+}
+
+// TransformToCBOR encodes the received data, i.e. an EdgeX event, as CBOR and returns the CBOR bytes. The
+// ResponseContentType of the context is set to application/cbor. []byte and string data is encoded as a CBOR byte
+// string or text string. It will return an error and stop the pipeline if no data is received or if the data can't
+// be encoded.
+func (encoder *CBOREncoder) TransformToCBOR(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Data Received")
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming to CBOR")
+	var encoded []byte
+	err := codec.NewEncoderBytes(&encoded, &codec.CborHandle{}).Encode(params[0])
+	if err != nil {
+		return false, fmt.Errorf("Error encoding CBOR: %s", err.Error())
+	}
+
+	edgexcontext.ResponseContentType = clients.ContentTypeCBOR
+	return true, encoded
+}
+
+// CBORDecoder decodes CBOR encoded EdgeX events, i.e. when the data is received as []byte
+type CBORDecoder struct {
+}
+
+// NewCBORDecoder creates, initializes and returns a new instance of CBORDecoder
+func NewCBORDecoder() *CBORDecoder {
+	return &CBORDecoder{}
+}
+
+// TransformToEvent decodes the received CBOR bytes to an EdgeX event and returns the event. It will return an
+// error and stop the pipeline if no data is received or if the data isn't a CBOR encoded event.
+func (decoder *CBORDecoder) TransformToEvent(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Data Received")
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming CBOR to Event")
+	var data []byte
+	switch input := params[0].(type) {
+	case []byte:
+		data = input
+	case string:
+		data = []byte(input)
+	default:
+		return false, errors.New("Unexpected type received, expecting CBOR bytes")
+	}
+
+	event := models.Event{}
+	err := codec.NewDecoderBytes(data, &codec.CborHandle{}).Decode(&event)
+	if err != nil {
+		return false, fmt.Errorf("Error decoding CBOR: %s", err.Error())
+	}
+
+	return true, event
+}
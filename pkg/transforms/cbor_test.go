@@ -0,0 +1,99 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+)
+
+func TestTransformToCBOR(t *testing.T) {
+	edgexcontext := &appcontext.Context{LoggingClient: context.LoggingClient}
+	eventIn := models.Event{
+		Device:   devID1,
+		Readings: []models.Reading{{Name: readingName1, Value: readingValue1}},
+	}
+
+	continuePipeline, result := NewCBOREncoder().TransformToCBOR(edgexcontext, eventIn)
+	if !assert.True(t, continuePipeline) {
+		t.Fatal()
+	}
+	assert.Equal(t, clients.ContentTypeCBOR, edgexcontext.ResponseContentType)
+
+	encoded, ok := result.([]byte)
+	if !assert.True(t, ok, "expected CBOR []byte") {
+		t.Fatal()
+	}
+	eventOut := models.Event{}
+	err := codec.NewDecoderBytes(encoded, &codec.CborHandle{}).Decode(&eventOut)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, devID1, eventOut.Device)
+	assert.Equal(t, readingValue1, eventOut.Readings[0].Value)
+}
+
+func TestTransformToCBORNoData(t *testing.T) {
+	continuePipeline, result := NewCBOREncoder().TransformToCBOR(context)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	edgexcontext := &appcontext.Context{LoggingClient: context.LoggingClient}
+	eventIn := models.Event{
+		Device:   devID2,
+		Readings: []models.Reading{{Name: readingName1, Value: readingValue1}},
+	}
+
+	continuePipeline, encoded := NewCBOREncoder().TransformToCBOR(edgexcontext, eventIn)
+	if !assert.True(t, continuePipeline) {
+		t.Fatal()
+	}
+	continuePipeline, result := NewCBORDecoder().TransformToEvent(edgexcontext, encoded)
+	if !assert.True(t, continuePipeline) {
+		t.Fatal()
+	}
+
+	eventOut, ok := result.(models.Event)
+	if !assert.True(t, ok, "expected models.Event") {
+		t.Fatal()
+	}
+	assert.Equal(t, devID2, eventOut.Device)
+	assert.Equal(t, readingName1, eventOut.Readings[0].Name)
+	assert.Equal(t, readingValue1, eventOut.Readings[0].Value)
+}
+
+func TestTransformToEventBadData(t *testing.T) {
+	continuePipeline, result := NewCBORDecoder().TransformToEvent(context, []byte{0xff, 0x01})
+	assert.False(t, continuePipeline)
+	assert.Contains(t, result.(error).Error(), "Error decoding CBOR")
+
+	continuePipeline, result = NewCBORDecoder().TransformToEvent(context, 42)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Unexpected type received, expecting CBOR bytes")
+
+	continuePipeline, result = NewCBORDecoder().TransformToEvent(context)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+}
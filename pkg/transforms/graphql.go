@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/pkg/util"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+)
+
+// GraphQLExporter sends the data from the previous function to a GraphQL endpoint as the variables of a mutation
+type GraphQLExporter struct {
+	Endpoint string
+	Mutation string
+	// VariableMapper maps the data from the previous function to the variables of the mutation. When the export is
+	// retried by Store and Forward the data is received as []byte.
+	VariableMapper func(interface{}) map[string]interface{}
+	// TokenSecretPath is the path in the secret store of the Bearer token used to authenticate with the endpoint.
+	// No Authorization header is sent when empty.
+	TokenSecretPath string
+	// TokenSecretKey is the key of the Bearer token in the secrets at TokenSecretPath
+	TokenSecretKey string
+	PersistOnError bool
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewGraphQLExporter creates, initializes and returns a new instance of GraphQLExporter
+func NewGraphQLExporter(endpoint string, mutation string, variableMapper func(interface{}) map[string]interface{}) *GraphQLExporter {
+	return &GraphQLExporter{
+		Endpoint:       endpoint,
+		Mutation:       mutation,
+		VariableMapper: variableMapper,
+	}
+}
+
+// SetBearerTokenSecret enables Bearer token authentication using the token stored in the secret store at path and key
+func (exporter *GraphQLExporter) SetBearerTokenSecret(path string, key string) {
+	exporter.TokenSecretPath = path
+	exporter.TokenSecretKey = key
+}
+
+// Export will send the mutation, with the variables mapped from the data from the previous function, to the GraphQL
+// endpoint via http POST. If no previous function exists, then the event that triggered the pipeline will be used.
+// The pipeline is stopped when the POST fails or the response contains GraphQL errors, otherwise the data of the
+// response is passed to the next function.
+func (exporter *GraphQLExporter) Export(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	request := graphQLRequest{Query: exporter.Mutation}
+	if exporter.VariableMapper != nil {
+		request.Variables = exporter.VariableMapper(params[0])
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return false, fmt.Errorf("unable to marshal GraphQL request: %s", err.Error())
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, exporter.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	httpRequest.Header.Set(clients.ContentType, clients.ContentTypeJSON)
+
+	if exporter.TokenSecretPath != "" {
+		token, err := edgexcontext.GetSecret(exporter.TokenSecretPath, exporter.TokenSecretKey)
+		if err != nil {
+			exporter.setRetryData(edgexcontext, params[0])
+			return false, err
+		}
+		httpRequest.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	edgexcontext.LoggingClient.Debug("POSTing GraphQL mutation")
+	response, err := http.DefaultClient.Do(httpRequest.WithContext(requestContext(edgexcontext)))
+	if err != nil {
+		exporter.setRetryData(edgexcontext, params[0])
+		return false, err
+	}
+	defer response.Body.Close()
+	edgexcontext.LoggingClient.Debug(fmt.Sprintf("Response: %s", response.Status))
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		exporter.setRetryData(edgexcontext, params[0])
+		return false, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		exporter.setRetryData(edgexcontext, params[0])
+		return false, fmt.Errorf("export failed with %d HTTP status code", response.StatusCode)
+	}
+
+	result := graphQLResponse{}
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		exporter.setRetryData(edgexcontext, params[0])
+		return false, fmt.Errorf("unable to unmarshal GraphQL response: %s", err.Error())
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, graphQLError := range result.Errors {
+			messages[i] = graphQLError.Message
+		}
+		exporter.setRetryData(edgexcontext, params[0])
+		return false, fmt.Errorf("export failed with GraphQL errors: %s", strings.Join(messages, "; "))
+	}
+
+	edgexcontext.LoggingClient.Trace("Data exported", "Transport", "GraphQL", clients.CorrelationHeader, edgexcontext.CorrelationID)
+
+	return true, []byte(result.Data)
+}
+
+// setRetryData stores the data the function received, rather than the GraphQL request, so that the variables are
+// mapped again from the data when the function is retried.
+func (exporter *GraphQLExporter) setRetryData(ctx *appcontext.Context, data interface{}) {
+	if !exporter.PersistOnError {
+		return
+	}
+
+	exportData, err := util.CoerceType(data)
+	if err != nil {
+		ctx.LoggingClient.Error(fmt.Sprintf("Unable to store the data for retry: %s", err.Error()))
+		return
+	}
+	ctx.RetryData = exportData
+}
@@ -0,0 +1,140 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/security/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMutation = `mutation AddReading($device: String!) { addReading(device: $device) { id } }`
+
+func testVariableMapper(data interface{}) map[string]interface{} {
+	return map[string]interface{}{"device": data}
+}
+
+func TestGraphQLExport(t *testing.T) {
+	var received graphQLRequest
+	var authorization string
+	responseBody := `{"data":{"addReading":{"id":"1"}}}`
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("Invalid GraphQL request received: %s", err.Error())
+		}
+		if r.Header.Get("Content-type") != "application/json" {
+			t.Errorf("Unexpected content-type received %s, expected %s", r.Header.Get("Content-type"), "application/json")
+		}
+		w.Write([]byte(responseBody))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	exporter := NewGraphQLExporter(ts.URL, testMutation, testVariableMapper)
+	continuePipeline, result := exporter.Export(context, "device1")
+
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, `{"addReading":{"id":"1"}}`, string(result.([]byte)))
+	assert.Equal(t, testMutation, received.Query)
+	assert.Equal(t, "device1", received.Variables["device"])
+	assert.Equal(t, "", authorization, "No Authorization header expected")
+}
+
+func TestGraphQLExportBearerToken(t *testing.T) {
+	var authorization string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"data":{}}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	secretStore := &mocks.SecretStoreClient{}
+	secretStore.On("GetSecret", "graphql", "token").Return("my-token", nil)
+	secretStore.On("GetSecret", "bogus", "token").Return("", errors.New("secret not found"))
+	edgexcontext := &appcontext.Context{
+		LoggingClient:     context.LoggingClient,
+		SecretStoreClient: secretStore,
+	}
+
+	exporter := NewGraphQLExporter(ts.URL, testMutation, testVariableMapper)
+	exporter.SetBearerTokenSecret("graphql", "token")
+	continuePipeline, _ := exporter.Export(edgexcontext, "device1")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "Bearer my-token", authorization)
+
+	exporter.SetBearerTokenSecret("bogus", "token")
+	continuePipeline, result := exporter.Export(edgexcontext, "device1")
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+}
+
+func TestGraphQLExportErrors(t *testing.T) {
+	tests := []struct {
+		Name         string
+		StatusCode   int
+		ResponseBody string
+		Persist      bool
+	}{
+		{"HTTP error", http.StatusInternalServerError, ``, false},
+		{"HTTP error with persist", http.StatusInternalServerError, ``, true},
+		{"GraphQL error", http.StatusOK, `{"data":null,"errors":[{"message":"device is required"}]}`, true},
+		{"Invalid response", http.StatusOK, `not json`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.StatusCode)
+				w.Write([]byte(test.ResponseBody))
+			}))
+			defer ts.Close()
+
+			context.RetryData = nil
+			exporter := NewGraphQLExporter(ts.URL, testMutation, testVariableMapper)
+			exporter.PersistOnError = test.Persist
+			continuePipeline, result := exporter.Export(context, "device1")
+
+			assert.False(t, continuePipeline, "Pipeline should stop")
+			assert.Error(t, result.(error))
+			assert.Equal(t, test.Persist, context.RetryData != nil)
+			if test.Persist {
+				assert.Equal(t, "device1", string(context.RetryData), "the input data must be stored, not the request")
+			}
+		})
+	}
+	context.RetryData = nil
+}
+
+func TestGraphQLExportNoParameterPassed(t *testing.T) {
+	exporter := NewGraphQLExporter("", testMutation, testVariableMapper)
+	continuePipeline, result := exporter.Export(context)
+
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Equal(t, "No Data Received", result.(error).Error())
+}
@@ -18,14 +18,21 @@ package transforms
 
 import (
 	"bytes"
+	syscontext "context"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/pkg/util"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 )
 
@@ -34,8 +41,33 @@ type HTTPSender struct {
 	URL            string
 	MimeType       string
 	PersistOnError bool
+	Config         HTTPSenderConfig
+	client         *http.Client
 }
 
+// HTTPSenderConfig contains the optional settings of the HTTPSender
+type HTTPSenderConfig struct {
+	// MaxRetries is the number of times the POST is retried after receiving one of the RetryStatusCodes
+	MaxRetries int
+	// RetryStatusCodes are the HTTP status codes for which the POST is retried. Defaults to 429, 503 and 504 when nil.
+	RetryStatusCodes []int
+	// RetryInterval is the duration to back off before the first retry, which doubles for each further retry.
+	// Defaults to 1 second when zero. A Retry-After header in the response is used as the minimum duration.
+	RetryInterval time.Duration
+	// ClientCertPath is the path of the PEM encoded client certificate used for mutual TLS, which is enabled when set.
+	// The client certificate and key are reloaded on SIGHUP.
+	ClientCertPath string
+	// ClientKeyPath is the path of the PEM encoded private key of the client certificate
+	ClientKeyPath string
+	// CACertPath is the optional path of the PEM encoded CA certificate used to verify the server's certificate
+	CACertPath string
+}
+
+// DefaultRetryStatusCodes are the HTTP status codes that are retried when HTTPSenderConfig.RetryStatusCodes is nil
+var DefaultRetryStatusCodes = []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+const defaultRetryInterval = time.Second
+
 // NewHTTPSender creates, initializes and returns a new instance of HTTPSender
 func NewHTTPSender(url string, mimeType string, persistOnError bool) HTTPSender {
 	return HTTPSender{
@@ -45,9 +77,25 @@ func NewHTTPSender(url string, mimeType string, persistOnError bool) HTTPSender
 	}
 }
 
+// NewHTTPSenderWithConfig creates, initializes and returns a new instance of HTTPSender using the optional settings.
+// An error is returned when the mutual TLS certificates can't be loaded.
+func NewHTTPSenderWithConfig(url string, mimeType string, persistOnError bool, config HTTPSenderConfig) (HTTPSender, error) {
+	sender := NewHTTPSender(url, mimeType, persistOnError)
+	sender.Config = config
+
+	client, err := config.newHTTPClient()
+	if err != nil {
+		return HTTPSender{}, err
+	}
+	sender.client = client
+
+	return sender, nil
+}
+
 // HTTPPost will send data from the previous function to the specified Endpoint via http POST.
 // If no previous function exists, then the event that triggered the pipeline will be used.
 // An empty string for the mimetype will default to application/json.
+// The POST is retried, up to the configured MaxRetries, when one of the configured RetryStatusCodes is received.
 func (sender HTTPSender) HTTPPost(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
 	if len(params) < 1 {
 		// We didn't receive a result
@@ -61,35 +109,189 @@ func (sender HTTPSender) HTTPPost(edgexcontext *appcontext.Context, params ...in
 		return false, err
 	}
 
-	edgexcontext.LoggingClient.Debug("POSTing data")
-	response, err := http.Post(sender.URL, sender.MimeType, bytes.NewReader(exportData))
+	return post(edgexcontext, sender.client, sender.URL, sender.MimeType, exportData, exportData, sender.PersistOnError, sender.Config)
+}
+
+// HTTPMultipartSender sends the data from the previous function as a file in a multipart/form-data body
+type HTTPMultipartSender struct {
+	URL            string
+	FieldName      string
+	FileName       string
+	MimeType       string
+	PersistOnError bool
+}
+
+// NewHTTPMultipartSender creates, initializes and returns a new instance of HTTPMultipartSender
+func NewHTTPMultipartSender(url string, fieldName string, filename string, mimeType string) *HTTPMultipartSender {
+	return &HTTPMultipartSender{
+		URL:       url,
+		FieldName: fieldName,
+		FileName:  filename,
+		MimeType:  mimeType,
+	}
+}
+
+// HTTPPost will send data from the previous function to the specified Endpoint via http POST, wrapped in a
+// multipart/form-data body as the file part with the configured field name and filename.
+// If no previous function exists, then the event that triggered the pipeline will be used.
+// An empty string for the mimetype of the file part will default to application/octet-stream.
+func (sender *HTTPMultipartSender) HTTPPost(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+	exportData, err := util.CoerceType(params[0])
 	if err != nil {
-		sender.setRetryData(edgexcontext, exportData)
 		return false, err
 	}
-	defer response.Body.Close()
-	edgexcontext.LoggingClient.Debug(fmt.Sprintf("Response: %s", response.Status))
-	edgexcontext.LoggingClient.Debug(fmt.Sprintf("Sent data: %s", string(exportData)))
-	bodyBytes, errReadingBody := ioutil.ReadAll(response.Body)
-	if errReadingBody != nil {
-		sender.setRetryData(edgexcontext, exportData)
-		return false, errReadingBody
+
+	mimeType := sender.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
 	}
 
-	edgexcontext.LoggingClient.Trace("Data exported", "Transport", "HTTP", clients.CorrelationHeader, edgexcontext.CorrelationID)
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(sender.FieldName), escapeQuotes(sender.FileName)))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return false, err
+	}
+	if _, err = part.Write(exportData); err != nil {
+		return false, err
+	}
+	if err = writer.Close(); err != nil {
+		return false, err
+	}
+
+	return post(edgexcontext, http.DefaultClient, sender.URL, writer.FormDataContentType(), body.Bytes(), exportData, sender.PersistOnError, HTTPSenderConfig{})
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// post sends the body to the url and returns the response body when a 2xx response is received.
+// The export data is set as the context's retry data on failure when persistOnError is true.
+func post(edgexcontext *appcontext.Context, client *http.Client, url string, contentType string, body []byte, exportData []byte, persistOnError bool, config HTTPSenderConfig) (bool, interface{}) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	setRetryData := func() {
+		if persistOnError {
+			edgexcontext.RetryData = exportData
+		}
+	}
+
+	span := tracing.StartSpan("HTTP export", tracing.SpanFromContext(edgexcontext.RequestContext))
+	span.SetAttribute("http.url", url)
+	defer span.Finish()
 
-	// continues the pipeline if we get a 2xx response, stops pipeline if non-2xx response
-	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		sender.setRetryData(edgexcontext, exportData)
-		return false, fmt.Errorf("export failed with %d HTTP status code", response.StatusCode)
+	ctx := requestContext(edgexcontext)
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return false, err
+		}
+		request.Header.Set(clients.ContentType, contentType)
+		if traceParent := span.TraceParent(); traceParent != "" {
+			request.Header.Set(tracing.TraceParentHeader, traceParent)
+		}
+
+		edgexcontext.LoggingClient.Debug("POSTing data")
+		response, err := client.Do(request.WithContext(ctx))
+		if err != nil {
+			span.SetError(err)
+			setRetryData()
+			return false, err
+		}
+		edgexcontext.LoggingClient.Debug(fmt.Sprintf("Response: %s", response.Status))
+		edgexcontext.LoggingClient.Debug(fmt.Sprintf("Sent data: %s", string(exportData)))
+		bodyBytes, errReadingBody := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if errReadingBody != nil {
+			setRetryData()
+			return false, errReadingBody
+		}
+
+		// continues the pipeline if we get a 2xx response
+		span.SetAttribute("http.status_code", strconv.Itoa(response.StatusCode))
+		if response.StatusCode >= 200 && response.StatusCode < 300 {
+			edgexcontext.LoggingClient.Trace("Data exported", "Transport", "HTTP", clients.CorrelationHeader, edgexcontext.CorrelationID)
+			return true, bodyBytes
+		}
+
+		// stops pipeline if non-2xx response that isn't retryable or once the retries are exhausted
+		if attempt >= config.MaxRetries || !config.isRetryable(response.StatusCode) {
+			err = fmt.Errorf("export failed with %d HTTP status code", response.StatusCode)
+			span.SetError(err)
+			setRetryData()
+			return false, err
+		}
+
+		delay := config.retryInterval() << uint(attempt)
+		if retryAfter := parseRetryAfter(response.Header.Get("Retry-After"), time.Now()); retryAfter > delay {
+			delay = retryAfter
+		}
+		edgexcontext.LoggingClient.Debug(fmt.Sprintf("Retrying export after %d HTTP status code in %s", response.StatusCode, delay))
+		select {
+		case <-ctx.Done():
+			setRetryData()
+			return false, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// requestContext returns the context of the request which triggered the pipeline, or the background context
+// when the trigger doesn't provide one.
+func requestContext(edgexcontext *appcontext.Context) syscontext.Context {
+	if edgexcontext.RequestContext != nil {
+		return edgexcontext.RequestContext
 	}
+	return syscontext.Background()
+}
 
-	return true, bodyBytes
+func (config HTTPSenderConfig) isRetryable(statusCode int) bool {
+	statusCodes := config.RetryStatusCodes
+	if statusCodes == nil {
+		statusCodes = DefaultRetryStatusCodes
+	}
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
 
+func (config HTTPSenderConfig) retryInterval() time.Duration {
+	if config.RetryInterval <= 0 {
+		return defaultRetryInterval
+	}
+	return config.RetryInterval
 }
 
-func (sender HTTPSender) setRetryData(ctx *appcontext.Context, exportData []byte) {
-	if sender.PersistOnError {
-		ctx.RetryData = exportData
+// parseRetryAfter returns the duration specified by a Retry-After header, which is either a number of seconds
+// or an HTTP date. Zero is returned when the header is empty or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
 	}
+	return 0
 }
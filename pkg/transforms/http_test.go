@@ -16,14 +16,19 @@
 package transforms
 
 import (
+	syscontext "context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/internal/tracing"
 )
 
 func TestHTTPPost(t *testing.T) {
@@ -110,3 +115,201 @@ func TestHTTPPostInvalidParameter(t *testing.T) {
 	assert.Error(t, result.(error), "Result should be an error")
 	assert.Equal(t, result.(error).Error(), "passed in data must be of type []byte, string or implement json.Marshaler")
 }
+
+func TestHTTPMultipartPost(t *testing.T) {
+	const (
+		msgStr  = "test image data"
+		path    = "/somepath/upload"
+		badPath = "/somepath/bad"
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == badPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		file, header, err := r.FormFile("image")
+		if err != nil {
+			t.Errorf("Unable to read form file: %s", err.Error())
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		readMsg, _ := ioutil.ReadAll(file)
+		if string(readMsg) != msgStr {
+			t.Errorf("Invalid msg received %v, expected %v", string(readMsg), msgStr)
+		}
+		if header.Filename != "reading.jpg" {
+			t.Errorf("Invalid filename received %s, expected %s", header.Filename, "reading.jpg")
+		}
+		if header.Header.Get("Content-Type") != "image/jpeg" {
+			t.Errorf("Unexpected content-type received %s, expected %s", header.Header.Get("Content-Type"), "image/jpeg")
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	tests := []struct {
+		Name          string
+		Path          string
+		PersistOnFail bool
+		Continue      bool
+		RetryDataSet  bool
+	}{
+		{"Successful post", path, true, true, false},
+		{"Failed Post no persist", badPath, false, false, false},
+		{"Failed Post with persist", badPath, true, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			context.RetryData = nil
+			sender := NewHTTPMultipartSender(ts.URL+test.Path, "image", "reading.jpg", "image/jpeg")
+			sender.PersistOnError = test.PersistOnFail
+
+			continuePipeline, _ := sender.HTTPPost(context, []byte(msgStr))
+
+			assert.Equal(t, test.Continue, continuePipeline)
+			assert.Equal(t, test.RetryDataSet, context.RetryData != nil)
+		})
+	}
+}
+
+func TestHTTPMultipartPostNoParameterPassed(t *testing.T) {
+	sender := NewHTTPMultipartSender("", "file", "data.bin", "")
+	continuePipeline, result := sender.HTTPPost(context)
+
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error), "Result should be an error")
+	assert.Equal(t, result.(error).Error(), "No Data Received")
+}
+
+func TestHTTPPostRetry(t *testing.T) {
+	tests := []struct {
+		Name             string
+		StatusCodes      []int
+		RetryStatusCodes []int
+		MaxRetries       int
+		Continue         bool
+		ExpectedAttempts int
+	}{
+		{"Retry on 503 then succeed", []int{http.StatusServiceUnavailable, http.StatusOK}, nil, 3, true, 2},
+		{"Retry on 429 and 504 then succeed", []int{http.StatusTooManyRequests, http.StatusGatewayTimeout, http.StatusOK}, nil, 3, true, 3},
+		{"Retries exhausted", []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}, nil, 1, false, 2},
+		{"No retry on 400", []int{http.StatusBadRequest, http.StatusOK}, nil, 3, false, 1},
+		{"No retry on 401", []int{http.StatusUnauthorized, http.StatusOK}, nil, 3, false, 1},
+		{"No retries configured", []int{http.StatusServiceUnavailable, http.StatusOK}, nil, 0, false, 1},
+		{"Custom retry status codes", []int{http.StatusInternalServerError, http.StatusOK}, []int{http.StatusInternalServerError}, 3, true, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			attempts := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.StatusCodes[attempts])
+				attempts++
+			}
+			ts := httptest.NewServer(http.HandlerFunc(handler))
+			defer ts.Close()
+
+			context.RetryData = nil
+			sender, err := NewHTTPSenderWithConfig(ts.URL, "", true, HTTPSenderConfig{
+				MaxRetries:       test.MaxRetries,
+				RetryStatusCodes: test.RetryStatusCodes,
+				RetryInterval:    time.Millisecond,
+			})
+			if !assert.NoError(t, err) {
+				t.Fatal()
+			}
+
+			continuePipeline, _ := sender.HTTPPost(context, "test message")
+
+			assert.Equal(t, test.Continue, continuePipeline)
+			assert.Equal(t, test.ExpectedAttempts, attempts)
+			assert.Equal(t, !test.Continue, context.RetryData != nil)
+		})
+	}
+}
+
+func TestHTTPPostRequestContextCancelled(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	requestContext, cancel := syscontext.WithCancel(syscontext.Background())
+	edgexcontext := &appcontext.Context{
+		LoggingClient:  context.LoggingClient,
+		RequestContext: requestContext,
+	}
+
+	sender, err := NewHTTPSenderWithConfig(ts.URL, "", true, HTTPSenderConfig{MaxRetries: 3, RetryInterval: time.Hour})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	continuePipeline, result := sender.HTTPPost(edgexcontext, "test message")
+
+	assert.False(t, continuePipeline, "Expected the pipeline to stop when the request is cancelled")
+	assert.Equal(t, syscontext.Canceled, result)
+	assert.NotNil(t, edgexcontext.RetryData, "Expected retry data to be set")
+
+	continuePipeline, result = sender.HTTPPost(edgexcontext, "test message")
+	assert.False(t, continuePipeline, "Expected the pipeline to stop when the request is already cancelled")
+	assert.Error(t, result.(error))
+}
+
+type discardExporter struct{}
+
+func (discardExporter) Export(spans []*tracing.Span) error {
+	return nil
+}
+
+func TestHTTPPostTraceParent(t *testing.T) {
+	var traceParent string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	tracing.SetTracer(tracing.NewTracer("myService", 1, discardExporter{}, nil))
+	defer tracing.SetTracer(nil)
+
+	parent := tracing.StartSpan("pipeline function #0", nil)
+	edgexcontext := &appcontext.Context{
+		LoggingClient:  context.LoggingClient,
+		RequestContext: tracing.ContextWithSpan(syscontext.Background(), parent),
+	}
+
+	sender := NewHTTPSender(ts.URL, "", false)
+	continuePipeline, _ := sender.HTTPPost(edgexcontext, "test message")
+	assert.True(t, continuePipeline)
+
+	spanContext := tracing.ParseTraceParent(traceParent)
+	if !assert.NotNil(t, spanContext, "Expected a valid traceparent header") {
+		t.Fatal()
+	}
+	assert.Equal(t, parent.TraceID, spanContext.TraceID)
+	assert.NotEqual(t, parent.SpanID, spanContext.SpanID, "Expected the HTTP export span to be propagated")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("bogus", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-30*time.Second).Format(http.TimeFormat), now))
+}
@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// clientCertificate holds the client certificate used for mutual TLS, which is reloaded from its files on SIGHUP
+// so renewed certificates are picked up without restarting the service.
+type clientCertificate struct {
+	certPath    string
+	keyPath     string
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+}
+
+func newClientCertificate(certPath string, keyPath string) (*clientCertificate, error) {
+	cert := &clientCertificate{
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+	if err := cert.reload(); err != nil {
+		return nil, err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			// The previous certificate remains in use when the renewed one can't be loaded
+			_ = cert.reload()
+		}
+	}()
+
+	return cert, nil
+}
+
+// reload loads the certificate pair from the certificate and key files
+func (cert *clientCertificate) reload() error {
+	pair, err := tls.LoadX509KeyPair(cert.certPath, cert.keyPath)
+	if err != nil {
+		return fmt.Errorf("unable to load client certificate: %s", err.Error())
+	}
+
+	cert.mutex.Lock()
+	cert.certificate = &pair
+	cert.mutex.Unlock()
+
+	return nil
+}
+
+// getClientCertificate is used as the tls.Config GetClientCertificate callback
+func (cert *clientCertificate) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert.mutex.RLock()
+	defer cert.mutex.RUnlock()
+	return cert.certificate, nil
+}
+
+// newHTTPClient creates the HTTP client for the sender's configuration, which is the default client
+// unless mutual TLS is configured by setting the ClientCertPath.
+func (config HTTPSenderConfig) newHTTPClient() (*http.Client, error) {
+	if config.ClientCertPath == "" {
+		return http.DefaultClient, nil
+	}
+	if config.ClientKeyPath == "" {
+		return nil, errors.New("ClientKeyPath must be specified when ClientCertPath is set")
+	}
+
+	cert, err := newClientCertificate(config.ClientCertPath, config.ClientKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		GetClientCertificate: cert.getClientCertificate,
+	}
+
+	if config.CACertPath != "" {
+		caCert, err := ioutil.ReadFile(config.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate: %s", err.Error())
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in CA certificate file %s", config.CACertPath)
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
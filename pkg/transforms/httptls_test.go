@@ -0,0 +1,147 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeClientCertificate creates a self-signed client certificate with the serial number and writes the PEM encoded
+// certificate and key files in the directory.
+func writeClientCertificate(t *testing.T, dir string, serial int64) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "app-service"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath := filepath.Join(dir, "client.crt")
+	keyPath := filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, certPath, keyPath
+}
+
+func TestHTTPPostMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpsender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	firstCert, certPath, keyPath := writeClientCertificate(t, dir, 1)
+
+	var receivedSerial int64
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedSerial = r.TLS.PeerCertificates[0].SerialNumber.Int64()
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(firstCert)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+
+	caPath := filepath.Join(dir, "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := NewHTTPSenderWithConfig(ts.URL, "", false, HTTPSenderConfig{
+		ClientCertPath: certPath,
+		ClientKeyPath:  keyPath,
+		CACertPath:     caPath,
+	})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	continuePipeline, _ := sender.HTTPPost(context, "test message")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, int64(1), receivedSerial)
+
+	// Renew the client certificate and signal the service to reload it
+	renewedCert, _, _ := writeClientCertificate(t, dir, 2)
+	clientCAs.AddCert(renewedCert)
+	transport := sender.client.Transport.(*http.Transport)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := false
+	for i := 0; i < 100 && !reloaded; i++ {
+		current, _ := transport.TLSClientConfig.GetClientCertificate(nil)
+		reloaded = bytes.Equal(renewedCert.Raw, current.Certificate[0])
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, reloaded, "expected renewed client certificate to be loaded")
+	transport.CloseIdleConnections()
+
+	continuePipeline, _ = sender.HTTPPost(context, "test message")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, int64(2), receivedSerial)
+}
+
+func TestHTTPSenderConfigClientCertificateErrors(t *testing.T) {
+	_, err := NewHTTPSenderWithConfig("", "", false, HTTPSenderConfig{ClientCertPath: "client.crt"})
+	assert.Error(t, err, "expected error for missing ClientKeyPath")
+
+	_, err = NewHTTPSenderWithConfig("", "", false, HTTPSenderConfig{ClientCertPath: "bogus.crt", ClientKeyPath: "bogus.key"})
+	assert.Error(t, err, "expected error for missing certificate files")
+}
@@ -0,0 +1,182 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bufio"
+	syscontext "context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/pkg/util"
+)
+
+// DefaultLuaTimeout is the time a Lua script may run for when the LuaTransformer's Timeout is not set
+const DefaultLuaTimeout = time.Second
+
+// LuaTransformer applies a Lua script to the data from the previous function. The script receives the data as the
+// global string payload and must set the global output to the new data.
+type LuaTransformer struct {
+	ScriptPath string
+	// Timeout is the time the script may run for before it is stopped, which defaults to DefaultLuaTimeout. The
+	// script is also stopped when the request which triggered the pipeline is cancelled.
+	Timeout   time.Duration
+	mutex     sync.RWMutex
+	proto     *lua.FunctionProto
+	loadError error
+}
+
+// NewLuaTransformer creates, initializes and returns a new instance of LuaTransformer. The script is compiled once,
+// call Reload to recompile it after it has been changed.
+func NewLuaTransformer(scriptPath string) *LuaTransformer {
+	transformer := &LuaTransformer{
+		ScriptPath: scriptPath,
+		Timeout:    DefaultLuaTimeout,
+	}
+	_ = transformer.Reload()
+
+	return transformer
+}
+
+// Reload recompiles the script, so the script can be changed without restarting the service, i.e. when the
+// service receives SIGHUP. The previously compiled script remains in use when the script fails to compile.
+func (transformer *LuaTransformer) Reload() error {
+	proto, err := compileLuaScript(transformer.ScriptPath)
+
+	transformer.mutex.Lock()
+	defer transformer.mutex.Unlock()
+	if err != nil {
+		if transformer.proto == nil {
+			transformer.loadError = err
+		}
+		return err
+	}
+	transformer.proto = proto
+	transformer.loadError = nil
+
+	return nil
+}
+
+func compileLuaScript(scriptPath string) (*lua.FunctionProto, error) {
+	file, err := os.Open(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open Lua script: %s", err.Error())
+	}
+	defer file.Close()
+
+	chunk, err := parse.Parse(bufio.NewReader(file), scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse Lua script: %s", err.Error())
+	}
+	proto, err := lua.Compile(chunk, scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile Lua script: %s", err.Error())
+	}
+
+	return proto, nil
+}
+
+// newSandboxedLuaState creates a Lua VM with only the base, table, string and math modules, so scripts have no
+// access to the os and io modules or to loading other files.
+func newSandboxedLuaState() (*lua.LState, error) {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, module := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		err := state.CallByParam(lua.P{
+			Fn:      state.NewFunction(module.open),
+			NRet:    0,
+			Protect: true,
+		}, lua.LString(module.name))
+		if err != nil {
+			state.Close()
+			return nil, err
+		}
+	}
+	for _, name := range []string{"dofile", "loadfile", "require"} {
+		state.SetGlobal(name, lua.LNil)
+	}
+
+	return state, nil
+}
+
+// Transform runs the Lua script with the data from the previous function as the global payload string and passes
+// the global output set by the script to the next function. The script is stopped when it runs for longer than the
+// Timeout. If no previous function exists, then the event that
+// triggered the pipeline, marshaled to json, will be used.
+func (transformer *LuaTransformer) Transform(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+	data, err := util.CoerceType(params[0])
+	if err != nil {
+		return false, err
+	}
+
+	transformer.mutex.RLock()
+	proto, loadError := transformer.proto, transformer.loadError
+	transformer.mutex.RUnlock()
+	if loadError != nil {
+		return false, loadError
+	}
+
+	// A VM is created per call since a Lua VM can't be used concurrently, only the compiled script is shared
+	state, err := newSandboxedLuaState()
+	if err != nil {
+		return false, fmt.Errorf("unable to create Lua VM: %s", err.Error())
+	}
+	defer state.Close()
+
+	timeout := transformer.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLuaTimeout
+	}
+	ctx, cancel := syscontext.WithTimeout(requestContext(edgexcontext), timeout)
+	defer cancel()
+	state.SetContext(ctx)
+
+	state.SetGlobal("payload", lua.LString(string(data)))
+	state.Push(state.NewFunctionFromProto(proto))
+	if err := state.PCall(0, lua.MultRet, nil); err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("Lua script stopped: %s", ctx.Err().Error())
+		}
+		return false, fmt.Errorf("Lua script failed: %s", err.Error())
+	}
+
+	output := state.GetGlobal("output")
+	if output.Type() == lua.LTNil {
+		return false, errors.New("Lua script did not set output")
+	}
+
+	edgexcontext.LoggingClient.Debug("Lua script applied")
+	return true, []byte(lua.LVAsString(output))
+}
@@ -0,0 +1,123 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeLuaScript(t *testing.T, dir string, script string) string {
+	scriptPath := filepath.Join(dir, "transform.lua")
+	if err := ioutil.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return scriptPath
+}
+
+func TestLuaTransform(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lua")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scriptPath := writeLuaScript(t, dir, `output = string.upper(payload)`)
+	transformer := NewLuaTransformer(scriptPath)
+
+	continuePipeline, result := transformer.Transform(context, "test message")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "TEST MESSAGE", string(result.([]byte)))
+
+	// Recompiled on reload
+	writeLuaScript(t, dir, `output = string.lower(payload)`)
+	assert.NoError(t, transformer.Reload())
+	continuePipeline, result = transformer.Transform(context, "Test Message")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "test message", string(result.([]byte)))
+
+	// The previous script remains in use when the script fails to compile
+	writeLuaScript(t, dir, `output = (`)
+	assert.Error(t, transformer.Reload())
+	continuePipeline, result = transformer.Transform(context, "Test Message")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "test message", string(result.([]byte)))
+}
+
+func TestLuaTransformSandbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lua")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		Name   string
+		Script string
+	}{
+		{"os module", `output = os.getenv("HOME")`},
+		{"io module", `output = io.read()`},
+		{"dofile", `dofile("other.lua")`},
+		{"require", `require("os")`},
+		{"no output", `local x = payload`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transformer := NewLuaTransformer(writeLuaScript(t, dir, test.Script))
+			continuePipeline, result := transformer.Transform(context, "test message")
+			assert.False(t, continuePipeline, "Pipeline should stop")
+			assert.Error(t, result.(error))
+		})
+	}
+}
+
+func TestLuaTransformTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lua")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	transformer := NewLuaTransformer(writeLuaScript(t, dir, `while true do end`))
+	transformer.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	continuePipeline, result := transformer.Transform(context, "test message")
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	if !assert.Error(t, result.(error)) {
+		t.Fatal()
+	}
+	assert.Contains(t, result.(error).Error(), "Lua script stopped")
+	assert.True(t, time.Since(start) < 5*time.Second, "script must be stopped after the timeout")
+}
+
+func TestLuaTransformErrors(t *testing.T) {
+	transformer := NewLuaTransformer("bogus.lua")
+	continuePipeline, result := transformer.Transform(context, "test message")
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error), "expected error for missing script")
+
+	continuePipeline, result = transformer.Transform(context)
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Equal(t, "No Data Received", result.(error).Error())
+}
@@ -0,0 +1,89 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/ugorji/go/codec"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+)
+
+// ContentTypeMessagePack is the content type of MessagePack encoded data
+const ContentTypeMessagePack = "application/msgpack"
+
+// MessagePackEncoder encodes the data it receives as MessagePack and decodes MessagePack encoded EdgeX events
+type MessagePackEncoder struct {
+	handle codec.MsgpackHandle
+}
+
+// NewMessagePackEncoder creates, initializes and returns a new instance of MessagePackEncoder
+func NewMessagePackEncoder() *MessagePackEncoder {
+	encoder := &MessagePackEncoder{}
+	// Use the str and bin types of the current MessagePack spec so that strings and []byte can be told apart
+	encoder.handle.WriteExt = true
+	return encoder
+}
+
+// Encode encodes the received data, i.e. an EdgeX event, as MessagePack and returns the MessagePack bytes. The
+// ResponseContentType of the context is set to application/msgpack. It will return an error and stop the pipeline
+// if no data is received or if the data can't be encoded.
+func (encoder *MessagePackEncoder) Encode(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Data Received")
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming to MessagePack")
+	var encoded []byte
+	err := codec.NewEncoderBytes(&encoded, &encoder.handle).Encode(params[0])
+	if err != nil {
+		return false, fmt.Errorf("Error encoding MessagePack: %s", err.Error())
+	}
+
+	edgexcontext.ResponseContentType = ContentTypeMessagePack
+	return true, encoded
+}
+
+// Decode decodes the received MessagePack bytes to an EdgeX event and returns the event. It will return an error
+// and stop the pipeline if no data is received or if the data isn't a MessagePack encoded event.
+func (encoder *MessagePackEncoder) Decode(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Data Received")
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming MessagePack to Event")
+	var data []byte
+	switch input := params[0].(type) {
+	case []byte:
+		data = input
+	case string:
+		data = []byte(input)
+	default:
+		return false, errors.New("Unexpected type received, expecting MessagePack bytes")
+	}
+
+	event := models.Event{}
+	err := codec.NewDecoderBytes(data, &encoder.handle).Decode(&event)
+	if err != nil {
+		return false, fmt.Errorf("Error decoding MessagePack: %s", err.Error())
+	}
+
+	return true, event
+}
@@ -0,0 +1,195 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package proto provides the protobuf encoding of the EdgeX events defined in edgex.proto, so that the events can
+// be exchanged with the services using the code generated from edgex.proto for their language.
+//
+// The encoding is written against the proto3 wire format rather than generated by protoc-gen-go, so that the SDK
+// doesn't depend on the protobuf runtime. It must be kept in sync with edgex.proto.
+package proto
+
+import (
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// EdgeXEventMessageName is the full name of the EdgeXEvent message in edgex.proto
+const EdgeXEventMessageName = "edgex.EdgeXEvent"
+
+// EdgeXEvent is the EdgeXEvent message of edgex.proto
+type EdgeXEvent struct {
+	Id       string
+	Pushed   int64
+	Device   string
+	Created  int64
+	Modified int64
+	Origin   int64
+	Readings []*Reading
+}
+
+// Reading is the Reading message of edgex.proto
+type Reading struct {
+	Id          string
+	Pushed      int64
+	Created     int64
+	Origin      int64
+	Modified    int64
+	Device      string
+	Name        string
+	Value       string
+	BinaryValue []byte
+}
+
+// FromEvent returns the EdgeXEvent message of the EdgeX event
+func FromEvent(event models.Event) *EdgeXEvent {
+	message := &EdgeXEvent{
+		Id:       event.ID,
+		Pushed:   event.Pushed,
+		Device:   event.Device,
+		Created:  event.Created,
+		Modified: event.Modified,
+		Origin:   event.Origin,
+	}
+	for _, reading := range event.Readings {
+		message.Readings = append(message.Readings, &Reading{
+			Id:          reading.Id,
+			Pushed:      reading.Pushed,
+			Created:     reading.Created,
+			Origin:      reading.Origin,
+			Modified:    reading.Modified,
+			Device:      reading.Device,
+			Name:        reading.Name,
+			Value:       reading.Value,
+			BinaryValue: reading.BinaryValue,
+		})
+	}
+	return message
+}
+
+// ToEvent returns the EdgeX event of the EdgeXEvent message
+func (message *EdgeXEvent) ToEvent() models.Event {
+	event := models.Event{
+		ID:       message.Id,
+		Pushed:   message.Pushed,
+		Device:   message.Device,
+		Created:  message.Created,
+		Modified: message.Modified,
+		Origin:   message.Origin,
+	}
+	for _, reading := range message.Readings {
+		event.Readings = append(event.Readings, models.Reading{
+			Id:          reading.Id,
+			Pushed:      reading.Pushed,
+			Created:     reading.Created,
+			Origin:      reading.Origin,
+			Modified:    reading.Modified,
+			Device:      reading.Device,
+			Name:        reading.Name,
+			Value:       reading.Value,
+			BinaryValue: reading.BinaryValue,
+		})
+	}
+	return event
+}
+
+// Marshal encodes the EdgeXEvent message in the protobuf wire format
+func (message *EdgeXEvent) Marshal() []byte {
+	var data []byte
+	data = appendString(data, 1, message.Id)
+	data = appendInt64(data, 2, message.Pushed)
+	data = appendString(data, 3, message.Device)
+	data = appendInt64(data, 4, message.Created)
+	data = appendInt64(data, 5, message.Modified)
+	data = appendInt64(data, 6, message.Origin)
+	for _, reading := range message.Readings {
+		data = appendMessage(data, 7, reading.Marshal())
+	}
+	return data
+}
+
+// Unmarshal decodes the EdgeXEvent message from the protobuf wire format. Unknown fields are skipped.
+func (message *EdgeXEvent) Unmarshal(data []byte) error {
+	*message = EdgeXEvent{}
+	return decodeFields(data, func(field int, value fieldValue) error {
+		var err error
+		switch field {
+		case 1:
+			message.Id, err = value.string()
+		case 2:
+			message.Pushed, err = value.int64()
+		case 3:
+			message.Device, err = value.string()
+		case 4:
+			message.Created, err = value.int64()
+		case 5:
+			message.Modified, err = value.int64()
+		case 6:
+			message.Origin, err = value.int64()
+		case 7:
+			var bytes []byte
+			if bytes, err = value.bytes(); err == nil {
+				reading := &Reading{}
+				if err = reading.Unmarshal(bytes); err == nil {
+					message.Readings = append(message.Readings, reading)
+				}
+			}
+		}
+		return err
+	})
+}
+
+// Marshal encodes the Reading message in the protobuf wire format
+func (message *Reading) Marshal() []byte {
+	var data []byte
+	data = appendString(data, 1, message.Id)
+	data = appendInt64(data, 2, message.Pushed)
+	data = appendInt64(data, 3, message.Created)
+	data = appendInt64(data, 4, message.Origin)
+	data = appendInt64(data, 5, message.Modified)
+	data = appendString(data, 6, message.Device)
+	data = appendString(data, 7, message.Name)
+	data = appendString(data, 8, message.Value)
+	data = appendBytes(data, 9, message.BinaryValue)
+	return data
+}
+
+// Unmarshal decodes the Reading message from the protobuf wire format. Unknown fields are skipped.
+func (message *Reading) Unmarshal(data []byte) error {
+	*message = Reading{}
+	return decodeFields(data, func(field int, value fieldValue) error {
+		var err error
+		switch field {
+		case 1:
+			message.Id, err = value.string()
+		case 2:
+			message.Pushed, err = value.int64()
+		case 3:
+			message.Created, err = value.int64()
+		case 4:
+			message.Origin, err = value.int64()
+		case 5:
+			message.Modified, err = value.int64()
+		case 6:
+			message.Device, err = value.string()
+		case 7:
+			message.Name, err = value.string()
+		case 8:
+			message.Value, err = value.string()
+		case 9:
+			message.BinaryValue, err = value.bytes()
+		}
+		return err
+	})
+}
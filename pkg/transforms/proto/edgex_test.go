@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalWireFormat(t *testing.T) {
+	message := &EdgeXEvent{
+		Device:   "d",
+		Created:  1,
+		Origin:   300,
+		Readings: []*Reading{{Name: "n", Value: "v"}},
+	}
+
+	// Field 3 string "d", field 4 varint 1, field 6 varint 300, field 7 message {field 7 "n", field 8 "v"}
+	expected := []byte{0x1a, 0x01, 'd', 0x20, 0x01, 0x30, 0xac, 0x02, 0x3a, 0x06, 0x3a, 0x01, 'n', 0x42, 0x01, 'v'}
+	assert.Equal(t, expected, message.Marshal())
+}
+
+func TestMarshalEmptyReading(t *testing.T) {
+	message := &EdgeXEvent{Readings: []*Reading{{}}}
+	assert.Equal(t, []byte{0x3a, 0x00}, message.Marshal())
+
+	decoded := &EdgeXEvent{}
+	if !assert.NoError(t, decoded.Unmarshal(message.Marshal())) {
+		t.Fatal()
+	}
+	assert.Len(t, decoded.Readings, 1)
+}
+
+func TestRoundTrip(t *testing.T) {
+	event := models.Event{
+		ID:       "7a1707f0-166f-4c4b-bc9d-1d54c74e0137",
+		Pushed:   -1,
+		Device:   "Random-Float-Device",
+		Created:  1559693753337,
+		Modified: 1559693753338,
+		Origin:   1559693753336,
+		Readings: []models.Reading{
+			{Id: "1", Name: "Float32", Value: "1.5", Device: "Random-Float-Device", Origin: 1559693753336},
+			{Id: "2", Name: "Image", BinaryValue: []byte{0x00, 0xff}},
+		},
+	}
+
+	decoded := &EdgeXEvent{}
+	err := decoded.Unmarshal(FromEvent(event).Marshal())
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, event, decoded.ToEvent())
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	// Field 15 varint, field 16 fixed64, field 17 fixed32 and field 18 bytes, followed by field 3 string "d"
+	data := []byte{0x78, 0x05, 0x81, 0x01, 1, 2, 3, 4, 5, 6, 7, 8, 0x8d, 0x01, 1, 2, 3, 4, 0x92, 0x01, 0x01, 'x', 0x1a, 0x01, 'd'}
+
+	decoded := &EdgeXEvent{}
+	err := decoded.Unmarshal(data)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	assert.Equal(t, "d", decoded.Device)
+}
+
+func TestUnmarshalInvalidData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Truncated tag", []byte{0x80}},
+		{"Truncated varint", []byte{0x20, 0x80}},
+		{"Truncated string", []byte{0x1a, 0x05, 'd'}},
+		{"Truncated fixed64", []byte{0x81, 0x01, 1, 2}},
+		{"Wrong wire type", []byte{0x18, 0x01}},
+		{"Invalid field number", []byte{0x00, 0x01}},
+		{"Unsupported wire type", []byte{0x1b}},
+		{"Invalid reading", []byte{0x3a, 0x02, 0x12, 0x01}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoded := &EdgeXEvent{}
+			assert.Error(t, decoded.Unmarshal(test.data))
+		})
+	}
+}
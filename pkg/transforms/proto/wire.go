@@ -0,0 +1,166 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package proto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The wire types of the protobuf encoding used by edgex.proto
+const (
+	wireVarint          = 0
+	wireFixed64         = 1
+	wireLengthDelimited = 2
+	wireFixed32         = 5
+)
+
+var errTruncated = errors.New("protobuf data is truncated")
+
+func appendVarint(data []byte, value uint64) []byte {
+	for value >= 0x80 {
+		data = append(data, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(data, byte(value))
+}
+
+func appendTag(data []byte, field int, wireType int) []byte {
+	return appendVarint(data, uint64(field)<<3|uint64(wireType))
+}
+
+// appendInt64 appends the int64 field, which proto3 leaves out when it has the default value
+func appendInt64(data []byte, field int, value int64) []byte {
+	if value == 0 {
+		return data
+	}
+	data = appendTag(data, field, wireVarint)
+	return appendVarint(data, uint64(value))
+}
+
+func appendString(data []byte, field int, value string) []byte {
+	return appendBytes(data, field, []byte(value))
+}
+
+func appendBytes(data []byte, field int, value []byte) []byte {
+	if len(value) == 0 {
+		return data
+	}
+	return appendMessage(data, field, value)
+}
+
+// appendMessage appends the embedded message, which is kept even when empty so that repeated messages aren't lost
+func appendMessage(data []byte, field int, value []byte) []byte {
+	data = appendTag(data, field, wireLengthDelimited)
+	data = appendVarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func readVarint(data []byte) (uint64, int, error) {
+	var value uint64
+	for index := 0; index < len(data) && index < 10; index++ {
+		value |= uint64(data[index]&0x7f) << (7 * uint(index))
+		if data[index] < 0x80 {
+			return value, index + 1, nil
+		}
+	}
+	if len(data) < 10 {
+		return 0, 0, errTruncated
+	}
+	return 0, 0, errors.New("protobuf varint is too long")
+}
+
+// fieldValue is the value of a decoded field, either a varint or the bytes of a length delimited field
+type fieldValue struct {
+	wireType int
+	varint   uint64
+	data     []byte
+}
+
+func (value fieldValue) int64() (int64, error) {
+	if value.wireType != wireVarint {
+		return 0, fmt.Errorf("protobuf wire type %d is not a varint", value.wireType)
+	}
+	return int64(value.varint), nil
+}
+
+func (value fieldValue) bytes() ([]byte, error) {
+	if value.wireType != wireLengthDelimited {
+		return nil, fmt.Errorf("protobuf wire type %d is not length delimited", value.wireType)
+	}
+	return value.data, nil
+}
+
+func (value fieldValue) string() (string, error) {
+	bytes, err := value.bytes()
+	return string(bytes), err
+}
+
+// decodeFields calls handle with the number and value of each field of the encoded message, skipping the fixed
+// size fields, which edgex.proto doesn't use
+func decodeFields(data []byte, handle func(field int, value fieldValue) error) error {
+	for len(data) > 0 {
+		tag, length, err := readVarint(data)
+		if err != nil {
+			return err
+		}
+		data = data[length:]
+
+		field := int(tag >> 3)
+		value := fieldValue{wireType: int(tag & 0x7)}
+		if field <= 0 {
+			return fmt.Errorf("invalid protobuf field number %d", field)
+		}
+
+		switch value.wireType {
+		case wireVarint:
+			value.varint, length, err = readVarint(data)
+			if err != nil {
+				return err
+			}
+			data = data[length:]
+		case wireLengthDelimited:
+			size, length, err := readVarint(data)
+			if err != nil {
+				return err
+			}
+			data = data[length:]
+			if size > uint64(len(data)) {
+				return errTruncated
+			}
+			value.data = data[:size]
+			data = data[size:]
+		case wireFixed64, wireFixed32:
+			size := 8
+			if value.wireType == wireFixed32 {
+				size = 4
+			}
+			if size > len(data) {
+				return errTruncated
+			}
+			data = data[size:]
+			continue
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", value.wireType)
+		}
+
+		if err := handle(field, value); err != nil {
+			return fmt.Errorf("invalid protobuf field %d: %s", field, err.Error())
+		}
+	}
+	return nil
+}
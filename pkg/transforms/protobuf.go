@@ -0,0 +1,158 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/pkg/transforms/proto"
+)
+
+// ContentTypeProtobuf is the content type of protobuf encoded data
+const ContentTypeProtobuf = "application/x-protobuf"
+
+// ProtobufEncoder encodes EdgeX events as the EdgeXEvent message of pkg/transforms/proto/edgex.proto
+type ProtobufEncoder struct {
+}
+
+// NewProtobufEncoder creates, initializes and returns a new instance of ProtobufEncoder
+func NewProtobufEncoder() *ProtobufEncoder {
+	return &ProtobufEncoder{}
+}
+
+// TransformToProtobuf encodes the received EdgeX event as an EdgeXEvent protobuf message and returns the protobuf
+// bytes. The ResponseContentType of the context is set to application/x-protobuf. It will return an error and stop
+// the pipeline if a non-edgex event is received or if no data is received.
+func (encoder *ProtobufEncoder) TransformToProtobuf(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Event Received")
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming to protobuf")
+	event, ok := params[0].(models.Event)
+	if !ok {
+		return false, errors.New("Unexpected type received, expecting models.Event")
+	}
+
+	edgexcontext.ResponseContentType = ContentTypeProtobuf
+	return true, proto.FromEvent(event).Marshal()
+}
+
+// ProtoUnmarshaler decodes the bytes of a protobuf message to the value passed to the next pipeline function
+type ProtoUnmarshaler func(data []byte) (interface{}, error)
+
+// ProtoRegistry holds the unmarshalers of the protobuf messages which can be decoded by the ProtobufDecoder, by the
+// full name of the message, i.e. edgex.EdgeXEvent.
+type ProtoRegistry struct {
+	mutex        sync.RWMutex
+	unmarshalers map[string]ProtoUnmarshaler
+}
+
+// NewProtoRegistry creates and returns a ProtoRegistry with the EdgeXEvent message of edgex.proto registered. The
+// EdgeXEvent message is decoded to a models.Event.
+func NewProtoRegistry() *ProtoRegistry {
+	registry := &ProtoRegistry{unmarshalers: make(map[string]ProtoUnmarshaler)}
+	registry.unmarshalers[proto.EdgeXEventMessageName] = func(data []byte) (interface{}, error) {
+		message := &proto.EdgeXEvent{}
+		if err := message.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		return message.ToEvent(), nil
+	}
+	return registry
+}
+
+// Register registers the unmarshaler of the protobuf message with the specified full name, replacing the
+// unmarshaler already registered for the message.
+func (registry *ProtoRegistry) Register(messageName string, unmarshaler ProtoUnmarshaler) error {
+	messageName = strings.TrimSpace(messageName)
+	if messageName == "" {
+		return errors.New("Protobuf message name must be specified")
+	}
+	if unmarshaler == nil {
+		return fmt.Errorf("Unmarshaler for protobuf message %s must not be nil", messageName)
+	}
+
+	registry.mutex.Lock()
+	registry.unmarshalers[messageName] = unmarshaler
+	registry.mutex.Unlock()
+	return nil
+}
+
+func (registry *ProtoRegistry) unmarshaler(messageName string) (ProtoUnmarshaler, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	unmarshaler, ok := registry.unmarshalers[messageName]
+	return unmarshaler, ok
+}
+
+// ProtobufDecoder decodes protobuf messages using the unmarshalers of a ProtoRegistry
+type ProtobufDecoder struct {
+	// MessageName is the full name of the protobuf message received, which defaults to edgex.EdgeXEvent
+	MessageName string
+	registry    *ProtoRegistry
+}
+
+// NewProtobufDecoder creates, initializes and returns a new instance of ProtobufDecoder for the EdgeXEvent message
+// using the registry, or the registry returned by NewProtoRegistry if nil.
+func NewProtobufDecoder(registry *ProtoRegistry) *ProtobufDecoder {
+	if registry == nil {
+		registry = NewProtoRegistry()
+	}
+	return &ProtobufDecoder{
+		MessageName: proto.EdgeXEventMessageName,
+		registry:    registry,
+	}
+}
+
+// TransformFromProtobuf decodes the received protobuf bytes as the MessageName message and returns the decoded
+// value, i.e. a models.Event for the EdgeXEvent message. It will return an error and stop the pipeline if no data
+// is received, if the message isn't registered or if the data can't be decoded.
+func (decoder *ProtobufDecoder) TransformFromProtobuf(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		return false, errors.New("No Data Received")
+	}
+
+	edgexcontext.LoggingClient.Debug("Transforming from protobuf", "message", decoder.MessageName)
+	var data []byte
+	switch input := params[0].(type) {
+	case []byte:
+		data = input
+	case string:
+		data = []byte(input)
+	default:
+		return false, errors.New("Unexpected type received, expecting protobuf bytes")
+	}
+
+	unmarshaler, ok := decoder.registry.unmarshaler(decoder.MessageName)
+	if !ok {
+		return false, fmt.Errorf("Protobuf message %s is not registered", decoder.MessageName)
+	}
+
+	result, err := unmarshaler(data)
+	if err != nil {
+		return false, fmt.Errorf("Error decoding protobuf message %s: %s", decoder.MessageName, err.Error())
+	}
+
+	return true, result
+}
@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	edgexcontext := &appcontext.Context{LoggingClient: context.LoggingClient}
+	eventIn := models.Event{
+		Device:   devID1,
+		Created:  1559693753337,
+		Readings: []models.Reading{{Name: readingName1, Value: readingValue1}},
+	}
+
+	continuePipeline, encoded := NewProtobufEncoder().TransformToProtobuf(edgexcontext, eventIn)
+	if !assert.True(t, continuePipeline) {
+		t.Fatal()
+	}
+	assert.Equal(t, ContentTypeProtobuf, edgexcontext.ResponseContentType)
+	assert.IsType(t, []byte{}, encoded)
+
+	continuePipeline, result := NewProtobufDecoder(nil).TransformFromProtobuf(edgexcontext, encoded)
+	if !assert.True(t, continuePipeline) {
+		t.Fatal()
+	}
+	assert.Equal(t, eventIn, result)
+}
+
+func TestTransformToProtobufBadData(t *testing.T) {
+	continuePipeline, result := NewProtobufEncoder().TransformToProtobuf(context)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Event Received")
+
+	continuePipeline, result = NewProtobufEncoder().TransformToProtobuf(context, "data")
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Unexpected type received, expecting models.Event")
+}
+
+func TestTransformFromProtobufBadData(t *testing.T) {
+	decoder := NewProtobufDecoder(nil)
+
+	continuePipeline, result := decoder.TransformFromProtobuf(context)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "No Data Received")
+
+	continuePipeline, result = decoder.TransformFromProtobuf(context, 42)
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Unexpected type received, expecting protobuf bytes")
+
+	continuePipeline, result = decoder.TransformFromProtobuf(context, []byte{0x1a, 0x05})
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Error decoding protobuf message edgex.EdgeXEvent: protobuf data is truncated")
+
+	decoder.MessageName = "custom.Message"
+	continuePipeline, result = decoder.TransformFromProtobuf(context, []byte{})
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Protobuf message custom.Message is not registered")
+}
+
+func TestProtoRegistryRegister(t *testing.T) {
+	registry := NewProtoRegistry()
+	err := registry.Register("custom.Message", func(data []byte) (interface{}, error) {
+		if len(data) == 0 {
+			return nil, errors.New("empty message")
+		}
+		return string(data), nil
+	})
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	decoder := NewProtobufDecoder(registry)
+	decoder.MessageName = "custom.Message"
+	continuePipeline, result := decoder.TransformFromProtobuf(context, []byte("custom"))
+	assert.True(t, continuePipeline)
+	assert.Equal(t, "custom", result)
+
+	continuePipeline, result = decoder.TransformFromProtobuf(context, []byte{})
+	assert.False(t, continuePipeline)
+	assert.EqualError(t, result.(error), "Error decoding protobuf message custom.Message: empty message")
+
+	assert.EqualError(t, registry.Register(" ", nil), "Protobuf message name must be specified")
+	assert.EqualError(t, registry.Register("custom.Other", nil), "Unmarshaler for protobuf message custom.Other must not be nil")
+}
@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+)
+
+// ConditionalForwarder routes the data from the previous function to one of two sub-pipelines based on a predicate
+type ConditionalForwarder struct {
+	Predicate     func(interface{}) bool
+	TruePipeline  []appcontext.AppFunction
+	FalsePipeline []appcontext.AppFunction
+}
+
+// NewConditionalForwarder creates, initializes and returns a new instance of ConditionalForwarder
+func NewConditionalForwarder(predicate func(interface{}) bool, truePipeline []appcontext.AppFunction, falsePipeline []appcontext.AppFunction) *ConditionalForwarder {
+	return &ConditionalForwarder{
+		Predicate:     predicate,
+		TruePipeline:  truePipeline,
+		FalsePipeline: falsePipeline,
+	}
+}
+
+// Forward evaluates the predicate with the data from the previous function and executes either the TruePipeline or
+// the FalsePipeline with the data, using the same context. The sub-pipeline executes the same way as the functions
+// pipeline: each function receives the result of the previous one and execution stops when a function returns false.
+// The result of the last function executed is passed to the next function, or stops the pipeline when that function
+// returned false. An empty sub-pipeline passes the data through unchanged.
+func (forwarder *ConditionalForwarder) Forward(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	pipeline := forwarder.FalsePipeline
+	if forwarder.Predicate(params[0]) {
+		edgexcontext.LoggingClient.Debug("Forwarding to true pipeline")
+		pipeline = forwarder.TruePipeline
+	} else {
+		edgexcontext.LoggingClient.Debug("Forwarding to false pipeline")
+	}
+
+	result := params[0]
+	for _, function := range pipeline {
+		var continuePipeline bool
+		continuePipeline, result = function(edgexcontext, result)
+		if !continuePipeline {
+			return false, result
+		}
+	}
+
+	return true, result
+}
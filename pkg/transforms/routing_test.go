@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalForward(t *testing.T) {
+	upper := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, strings.ToUpper(params[0].(string))
+	}
+	suffix := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, params[0].(string) + "!"
+	}
+	lower := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return true, strings.ToLower(params[0].(string))
+	}
+	isAlarm := func(data interface{}) bool {
+		return strings.HasPrefix(data.(string), "alarm")
+	}
+
+	forwarder := NewConditionalForwarder(isAlarm, []appcontext.AppFunction{upper, suffix}, []appcontext.AppFunction{lower})
+
+	continuePipeline, result := forwarder.Forward(context, "alarm: high temperature")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "ALARM: HIGH TEMPERATURE!", result)
+
+	continuePipeline, result = forwarder.Forward(context, "Status: OK")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "status: ok", result)
+
+	forwarder = NewConditionalForwarder(isAlarm, nil, nil)
+	continuePipeline, result = forwarder.Forward(context, "Status: OK")
+	assert.True(t, continuePipeline, "Pipeline should continue")
+	assert.Equal(t, "Status: OK", result)
+}
+
+func TestConditionalForwardStops(t *testing.T) {
+	called := false
+	fail := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		return false, errors.New("failed")
+	}
+	next := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		called = true
+		return true, params[0]
+	}
+	setOutput := func(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+		edgexcontext.Complete([]byte(params[0].(string)))
+		return true, params[0]
+	}
+	always := func(data interface{}) bool { return true }
+
+	forwarder := NewConditionalForwarder(always, []appcontext.AppFunction{fail, next}, nil)
+	continuePipeline, result := forwarder.Forward(context, "data")
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+	assert.False(t, called, "functions after a stopped function should not be called")
+
+	// The sub-pipeline shares the context
+	edgexcontext := &appcontext.Context{LoggingClient: context.LoggingClient}
+	forwarder = NewConditionalForwarder(always, []appcontext.AppFunction{setOutput}, nil)
+	forwarder.Forward(edgexcontext, "output")
+	assert.Equal(t, []byte("output"), edgexcontext.OutputData)
+
+	continuePipeline, result = forwarder.Forward(context)
+	assert.False(t, continuePipeline, "Pipeline should stop")
+	assert.Error(t, result.(error))
+}
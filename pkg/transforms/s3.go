@@ -0,0 +1,287 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package transforms
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/appcontext"
+	"github.com/edgexfoundry/app-functions-sdk-go/pkg/util"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+const (
+	// SSES3 is the server-side encryption using S3 managed keys
+	SSES3 = "AES256"
+	// SSEKMS is the server-side encryption using AWS KMS managed keys
+	SSEKMS = "aws:kms"
+
+	// AWSAccessKeyID is the secret store key of the AWS access key id
+	AWSAccessKeyID = "aws_access_key_id"
+	// AWSSecretAccessKey is the secret store key of the AWS secret access key
+	AWSSecretAccessKey = "aws_secret_access_key"
+	// AWSSessionToken is the secret store key of the optional AWS session token, which is required with the
+	// temporary credentials issued by STS, e.g. for IAM roles for service accounts
+	AWSSessionToken = "aws_session_token"
+
+	defaultAWSSecretPath = "aws"
+	unknownDeviceName    = "unknown"
+	amzDateFormat        = "20060102T150405Z"
+)
+
+// S3Exporter uploads the data from the previous function to an AWS S3 bucket
+type S3Exporter struct {
+	Bucket    string
+	Region    string
+	KeyPrefix string
+	// Endpoint overrides the S3 endpoint, i.e. for S3 compatible object stores, in which case path style
+	// addressing is used. Defaults to https://<bucket>.s3.<region>.amazonaws.com
+	Endpoint string
+	// SecretPath is the path in the secret store of the AWS credentials. Defaults to "aws".
+	SecretPath string
+	// ServerSideEncryption is either empty for no encryption, SSES3 or SSEKMS
+	ServerSideEncryption string
+	// KMSKeyID is the optional id of the AWS KMS key used with SSEKMS. The AWS managed key is used when empty.
+	KMSKeyID string
+	// ContentType is the Content-Type of the uploaded objects. Defaults to application/json for EdgeX Events and
+	// application/octet-stream otherwise.
+	ContentType    string
+	PersistOnError bool
+}
+
+// NewS3Exporter creates, initializes and returns a new instance of S3Exporter
+func NewS3Exporter(bucket string, region string, keyPrefix string) *S3Exporter {
+	return &S3Exporter{
+		Bucket:     bucket,
+		Region:     region,
+		KeyPrefix:  keyPrefix,
+		SecretPath: defaultAWSSecretPath,
+	}
+}
+
+// SetServerSideEncryption enables server-side encryption of the uploaded objects using either SSES3 or SSEKMS.
+// The kmsKeyID is only used with SSEKMS.
+func (exporter *S3Exporter) SetServerSideEncryption(encryption string, kmsKeyID string) error {
+	if encryption != SSES3 && encryption != SSEKMS {
+		return fmt.Errorf("unsupported server-side encryption '%s'", encryption)
+	}
+	exporter.ServerSideEncryption = encryption
+	exporter.KMSKeyID = kmsKeyID
+	return nil
+}
+
+// Export will upload the data from the previous function to the S3 bucket at keyPrefix/<deviceName>/<timestamp>.
+// If no previous function exists, then the event that triggered the pipeline will be used, marshaled to json.
+// The device name and timestamp are taken from the event when an EdgeX Event is received, otherwise the
+// device name is "unknown" and the current time is used. The timestamp is in nanoseconds since the epoch.
+// The AWS credentials are loaded from the secret store using the aws_access_key_id and aws_secret_access_key keys,
+// along with the aws_session_token key when the credentials are temporary.
+func (exporter *S3Exporter) Export(edgexcontext *appcontext.Context, params ...interface{}) (bool, interface{}) {
+	if len(params) < 1 {
+		// We didn't receive a result
+		return false, errors.New("No Data Received")
+	}
+
+	deviceName := unknownDeviceName
+	timestamp := time.Now().UnixNano()
+	contentType := "application/octet-stream"
+	var exportData []byte
+	var err error
+	if event, ok := params[0].(models.Event); ok {
+		deviceName = event.Device
+		if event.Origin != 0 {
+			timestamp = event.Origin
+		}
+		contentType = clients.ContentTypeJSON
+		exportData, err = json.Marshal(event)
+	} else {
+		exportData, err = util.CoerceType(params[0])
+	}
+	if err != nil {
+		return false, err
+	}
+
+	credentials, err := exporter.credentials(edgexcontext)
+	if err != nil {
+		exporter.setRetryData(edgexcontext, exportData)
+		return false, err
+	}
+	if exporter.ContentType != "" {
+		contentType = exporter.ContentType
+	}
+
+	key := strings.Trim(exporter.KeyPrefix, "/") + "/" + deviceName + "/" + strconv.FormatInt(timestamp, 10)
+	key = strings.TrimPrefix(key, "/")
+
+	request, err := http.NewRequest(http.MethodPut, exporter.objectURL(key), bytes.NewReader(exportData))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set(clients.ContentType, contentType)
+	if credentials.sessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", credentials.sessionToken)
+	}
+	if exporter.ServerSideEncryption != "" {
+		request.Header.Set("X-Amz-Server-Side-Encryption", exporter.ServerSideEncryption)
+		if exporter.ServerSideEncryption == SSEKMS && exporter.KMSKeyID != "" {
+			request.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", exporter.KMSKeyID)
+		}
+	}
+	signAWSRequest(request, exportData, credentials.accessKeyID, credentials.secretAccessKey, exporter.Region, "s3", time.Now())
+
+	edgexcontext.LoggingClient.Debug("Uploading data to S3", "bucket", exporter.Bucket, "key", key)
+	response, err := http.DefaultClient.Do(request.WithContext(requestContext(edgexcontext)))
+	if err != nil {
+		exporter.setRetryData(edgexcontext, exportData)
+		return false, err
+	}
+	defer response.Body.Close()
+	edgexcontext.LoggingClient.Debug(fmt.Sprintf("Response: %s", response.Status))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		responseBody, _ := ioutil.ReadAll(response.Body)
+		exporter.setRetryData(edgexcontext, exportData)
+		return false, fmt.Errorf("upload to S3 failed with %d HTTP status code: %s", response.StatusCode, string(responseBody))
+	}
+
+	edgexcontext.LoggingClient.Trace("Data exported", "Transport", "S3", clients.CorrelationHeader, edgexcontext.CorrelationID)
+
+	return true, nil
+}
+
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// credentials loads the AWS credentials from the secret store. The session token is optional.
+func (exporter *S3Exporter) credentials(edgexcontext *appcontext.Context) (awsCredentials, error) {
+	if edgexcontext.SecretStoreClient == nil {
+		return awsCredentials{}, errors.New("unable to get secret: SecretStore is missing from configuration")
+	}
+
+	secrets, err := edgexcontext.SecretStoreClient.GetSecrets(exporter.SecretPath)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	credentials := awsCredentials{
+		accessKeyID:     secrets[AWSAccessKeyID],
+		secretAccessKey: secrets[AWSSecretAccessKey],
+		sessionToken:    secrets[AWSSessionToken],
+	}
+	if credentials.accessKeyID == "" || credentials.secretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("the %s and %s secrets must be set at '%s'", AWSAccessKeyID, AWSSecretAccessKey, exporter.SecretPath)
+	}
+
+	return credentials, nil
+}
+
+func (exporter *S3Exporter) objectURL(key string) string {
+	if exporter.Endpoint != "" {
+		return strings.TrimSuffix(exporter.Endpoint, "/") + "/" + exporter.Bucket + "/" + key
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", exporter.Bucket, exporter.Region, key)
+}
+
+func (exporter *S3Exporter) setRetryData(ctx *appcontext.Context, exportData []byte) {
+	if exporter.PersistOnError {
+		ctx.RetryData = exportData
+	}
+}
+
+// signAWSRequest adds the AWS Signature Version 4 Authorization header to the request. All headers already set on
+// the request, plus the host, are signed.
+func signAWSRequest(request *http.Request, payload []byte, accessKeyID string, secretAccessKey string, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	payloadHash := sha256Hex(payload)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalURI(request.URL),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}